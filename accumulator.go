@@ -0,0 +1,156 @@
+package minds
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ToolCallAccumulator reassembles tool calls that arrive as incremental
+// fragments while streaming. Providers such as OpenAI send the tool call ID and
+// function name in the first delta for a given index and then stream the JSON
+// arguments in pieces that must be concatenated before they can be parsed.
+//
+// A call is only surfaced once its accumulated arguments form a complete, valid
+// JSON document, so callers never see a partially streamed argument string.
+//
+// The providers in this module do not stream tool calls, so nothing here uses
+// the accumulator itself. It is a standalone utility for code that consumes a
+// provider's stream directly, such as go-openai's CreateChatCompletionStream.
+//
+// Example:
+//
+//	acc := minds.NewToolCallAccumulator()
+//	for {
+//	    chunk, err := stream.Recv()
+//	    if errors.Is(err, io.EOF) {
+//	        break
+//	    }
+//	    ...
+//	    for _, tc := range chunk.Choices[0].Delta.ToolCalls {
+//	        acc.Add(*tc.Index, tc.ID, tc.Function.Name, tc.Function.Arguments)
+//	    }
+//	}
+//	calls, err := acc.Finish()
+type ToolCallAccumulator struct {
+	mu    sync.Mutex
+	calls map[int]*pendingToolCall
+}
+
+type pendingToolCall struct {
+	id        string
+	typ       string
+	name      string
+	arguments []byte
+}
+
+// NewToolCallAccumulator returns an empty accumulator.
+func NewToolCallAccumulator() *ToolCallAccumulator {
+	return &ToolCallAccumulator{
+		calls: make(map[int]*pendingToolCall),
+	}
+}
+
+// Add records a streamed fragment for the tool call at the given index. The ID
+// and name are only taken from the first fragment that carries them; the
+// arguments fragment is appended to anything received so far.
+func (a *ToolCallAccumulator) Add(index int, id, name, arguments string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	call, ok := a.calls[index]
+	if !ok {
+		call = &pendingToolCall{typ: string(ToolTypeFunction)}
+		a.calls[index] = call
+	}
+
+	if call.id == "" {
+		call.id = id
+	}
+	if call.name == "" {
+		call.name = name
+	}
+	call.arguments = append(call.arguments, arguments...)
+}
+
+// Complete returns, in index order, every tool call whose arguments are
+// currently valid JSON. Calls that are still streaming are omitted.
+func (a *ToolCallAccumulator) Complete() []ToolCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	calls := make([]ToolCall, 0, len(a.calls))
+	for _, idx := range a.indexes() {
+		call := a.calls[idx]
+		if call.name == "" || !json.Valid(call.arguments) {
+			continue
+		}
+		calls = append(calls, call.toolCall(call.arguments))
+	}
+	return calls
+}
+
+// Finish returns all accumulated tool calls in index order once the stream has
+// ended. A call with no arguments is given an empty JSON object. An error is
+// returned if any call is missing a name or its arguments are not valid JSON,
+// which usually means the stream was cut off.
+func (a *ToolCallAccumulator) Finish() ([]ToolCall, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	calls := make([]ToolCall, 0, len(a.calls))
+	for _, idx := range a.indexes() {
+		call := a.calls[idx]
+		if call.name == "" {
+			return nil, fmt.Errorf("tool call at index %d has no function name", idx)
+		}
+
+		args := call.arguments
+		if len(args) == 0 {
+			args = []byte("{}")
+		}
+		if !json.Valid(args) {
+			return nil, fmt.Errorf("tool call `%s` at index %d has incomplete arguments: %s", call.name, idx, args)
+		}
+		calls = append(calls, call.toolCall(args))
+	}
+	return calls, nil
+}
+
+// Len returns the number of distinct tool calls seen so far.
+func (a *ToolCallAccumulator) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.calls)
+}
+
+// Reset discards all accumulated fragments.
+func (a *ToolCallAccumulator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = make(map[int]*pendingToolCall)
+}
+
+func (a *ToolCallAccumulator) indexes() []int {
+	idxs := make([]int, 0, len(a.calls))
+	for idx := range a.calls {
+		idxs = append(idxs, idx)
+	}
+	sort.Ints(idxs)
+	return idxs
+}
+
+func (p *pendingToolCall) toolCall(args []byte) ToolCall {
+	params := make([]byte, len(args))
+	copy(params, args)
+
+	return ToolCall{
+		ID:   p.id,
+		Type: p.typ,
+		Function: FunctionCall{
+			Name:       p.name,
+			Parameters: params,
+		},
+	}
+}
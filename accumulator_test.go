@@ -0,0 +1,81 @@
+package minds
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestToolCallAccumulator(t *testing.T) {
+	t.Run("reassembles fragmented arguments", func(t *testing.T) {
+		is := is.New(t)
+		acc := NewToolCallAccumulator()
+
+		acc.Add(0, "call_1", "get_weather", "")
+		acc.Add(0, "", "", `{"loc`)
+		is.Equal(len(acc.Complete()), 0) // partial JSON must not surface
+
+		acc.Add(0, "", "", `ation": "Par`)
+		is.Equal(len(acc.Complete()), 0)
+
+		acc.Add(0, "", "", `is"}`)
+		calls := acc.Complete()
+		is.Equal(len(calls), 1)
+		is.Equal(calls[0].ID, "call_1")
+		is.Equal(calls[0].Type, "function")
+		is.Equal(calls[0].Function.Name, "get_weather")
+		is.Equal(string(calls[0].Function.Parameters), `{"location": "Paris"}`)
+	})
+
+	t.Run("keeps interleaved calls separate by index", func(t *testing.T) {
+		is := is.New(t)
+		acc := NewToolCallAccumulator()
+
+		acc.Add(1, "call_b", "second", `{"b":`)
+		acc.Add(0, "call_a", "first", `{"a":`)
+		acc.Add(1, "", "", `2}`)
+
+		calls := acc.Complete()
+		is.Equal(len(calls), 1)
+		is.Equal(calls[0].Function.Name, "second")
+
+		acc.Add(0, "", "", `1}`)
+		calls = acc.Complete()
+		is.Equal(len(calls), 2)
+		is.Equal(calls[0].Function.Name, "first") // ordered by index
+		is.Equal(string(calls[0].Function.Parameters), `{"a":1}`)
+		is.Equal(calls[1].Function.Name, "second")
+		is.Equal(string(calls[1].Function.Parameters), `{"b":2}`)
+		is.Equal(acc.Len(), 2)
+	})
+
+	t.Run("finish reports truncated arguments", func(t *testing.T) {
+		is := is.New(t)
+		acc := NewToolCallAccumulator()
+
+		acc.Add(0, "call_1", "search", `{"query": "golang`)
+		_, err := acc.Finish()
+		is.True(err != nil)
+	})
+
+	t.Run("finish defaults empty arguments", func(t *testing.T) {
+		is := is.New(t)
+		acc := NewToolCallAccumulator()
+
+		acc.Add(0, "call_1", "now", "")
+		calls, err := acc.Finish()
+		is.NoErr(err)
+		is.Equal(len(calls), 1)
+		is.Equal(string(calls[0].Function.Parameters), "{}")
+	})
+
+	t.Run("reset clears state", func(t *testing.T) {
+		is := is.New(t)
+		acc := NewToolCallAccumulator()
+
+		acc.Add(0, "call_1", "now", "{}")
+		acc.Reset()
+		is.Equal(acc.Len(), 0)
+		is.Equal(len(acc.Complete()), 0)
+	})
+}
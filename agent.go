@@ -0,0 +1,159 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAgentTurnLimit is returned by RunAgent when the agent is still calling
+// tools after the maximum number of turns.
+var ErrAgentTurnLimit = errors.New("agent reached its turn limit")
+
+// defaultAgentMaxTurns is the turn limit used by RunAgent unless WithMaxTurns
+// sets another.
+const defaultAgentMaxTurns = 10
+
+// AgentStep describes one turn of RunAgent: a generation and the tools it
+// called.
+type AgentStep struct {
+	// Turn is the number of the turn, starting at 1.
+	Turn int
+	// Response is the generator's response for the turn.
+	Response Response
+	// ToolCalls are the tools the model called, with their results. It is
+	// empty on the final turn.
+	ToolCalls []ToolCall
+	// Thread is the thread after the turn's messages were appended.
+	Thread ThreadContext
+	// Duration is how long the turn took.
+	Duration time.Duration
+}
+
+// StopCondition decides whether RunAgent should stop after a turn. Any
+// handlers.SwitchCondition can be used as a StopCondition.
+type StopCondition interface {
+	Evaluate(tc ThreadContext) (bool, error)
+}
+
+// AgentOption configures RunAgent.
+type AgentOption func(*agentOptions)
+
+type agentOptions struct {
+	maxTurns int
+	hooks    []func(AgentStep)
+	stop     StopCondition
+}
+
+// WithMaxTurns sets the maximum number of generations RunAgent makes before
+// returning ErrAgentTurnLimit. The default is 10. A value of zero or less
+// means no limit, so the loop ends only when the model stops calling tools,
+// a stop condition is met or the context is canceled.
+func WithMaxTurns(n int) AgentOption {
+	return func(o *agentOptions) {
+		o.maxTurns = n
+	}
+}
+
+// WithStepHook registers fn to be called after every turn, for example to log
+// progress or stream intermediate results. Hooks run in the order they were
+// added.
+func WithStepHook(fn func(step AgentStep)) AgentOption {
+	return func(o *agentOptions) {
+		o.hooks = append(o.hooks, fn)
+	}
+}
+
+// WithStopCondition makes RunAgent stop after any turn on which cond is met,
+// even if the model called tools.
+func WithStopCondition(cond StopCondition) AgentOption {
+	return func(o *agentOptions) {
+		o.stop = cond
+	}
+}
+
+// RunAgent runs the standard agent loop: it sends the thread to generator
+// with tools available, appends the response and the results of any tools
+// the model called, and repeats until the model answers without calling a
+// tool. It is an alternative to composing handlers for callers who simply
+// want to run a task to completion.
+//
+// Tools are executed by the generator, as the providers in this module do
+// during GenerateContent. Each turn appends an assistant message carrying
+// the tool calls, followed by a RoleTool message per call with its result.
+//
+// ctx is checked before every turn, so canceling it stops the loop between
+// steps; the thread so far is returned with the context's error. Reaching
+// the turn limit returns the thread with an error wrapping
+// ErrAgentTurnLimit.
+//
+// Example:
+//
+//	result, err := minds.RunAgent(ctx, llm, registry, tc,
+//	    minds.WithMaxTurns(20),
+//	    minds.WithStepHook(func(step minds.AgentStep) {
+//	        log.Printf("turn %d: %d tool calls", step.Turn, len(step.ToolCalls))
+//	    }),
+//	)
+func RunAgent(ctx context.Context, generator ContentGenerator, tools ToolRegistry, initialThread ThreadContext, opts ...AgentOption) (ThreadContext, error) {
+	options := agentOptions{maxTurns: defaultAgentMaxTurns}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	tc := initialThread
+	for turn := 1; ; turn++ {
+		if options.maxTurns > 0 && turn > options.maxTurns {
+			return tc, fmt.Errorf("%w of %d", ErrAgentTurnLimit, options.maxTurns)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return tc, err
+		}
+
+		start := time.Now()
+		req := NewRequest(tc.Messages(), WithToolRegistry(tools), WithRequestMetadata(tc.Metadata()))
+		resp, err := generator.GenerateContent(ctx, req)
+		if err != nil {
+			return tc, fmt.Errorf("agent turn %d: %w", turn, err)
+		}
+
+		calls := resp.ToolCalls()
+		messages := Messages{{Role: RoleAssistant, Content: resp.String(), ToolCalls: calls}}
+		for _, call := range calls {
+			messages = append(messages, Message{
+				Role:       RoleTool,
+				Name:       call.Function.Name,
+				Content:    string(call.Function.Result),
+				ToolCallID: call.ID,
+			})
+		}
+		tc = tc.With(AppendMessages(messages...))
+
+		step := AgentStep{
+			Turn:      turn,
+			Response:  resp,
+			ToolCalls: calls,
+			Thread:    tc,
+			Duration:  time.Since(start),
+		}
+		for _, hook := range options.hooks {
+			hook(step)
+		}
+
+		if len(calls) == 0 {
+			return tc, nil
+		}
+
+		if options.stop != nil {
+			stop, err := options.stop.Evaluate(tc)
+			if err != nil {
+				return tc, fmt.Errorf("agent turn %d: stop condition: %w", turn, err)
+			}
+			if stop {
+				return tc, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type agentResponse struct {
+	content string
+	calls   []ToolCall
+}
+
+func (r agentResponse) String() string        { return r.content }
+func (r agentResponse) ToolCalls() []ToolCall { return r.calls }
+
+func TestRunAgent(t *testing.T) {
+	// lookups calls a tool until it has n results, then answers.
+	lookups := func(n int) *stubGenerator {
+		return &stubGenerator{fn: func(req Request) (Response, error) {
+			results := 0
+			for _, msg := range req.Messages {
+				if msg.Role == RoleTool {
+					results++
+				}
+			}
+			if results >= n {
+				return agentResponse{content: "done"}, nil
+			}
+			return agentResponse{calls: []ToolCall{{
+				ID:       "call",
+				Function: FunctionCall{Name: "lookup", Result: []byte(`{"ok": true}`)},
+			}}}, nil
+		}}
+	}
+
+	newThread := func() ThreadContext {
+		return NewThreadContext(context.Background()).WithMessages(Message{Role: RoleUser, Content: "Look it up"})
+	}
+
+	t.Run("runs until the model stops calling tools", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry()
+
+		var steps []AgentStep
+		gen := &stubGenerator{fn: func(req Request) (Response, error) {
+			is.Equal(req.Options.ToolRegistry, registry)
+			return lookups(2).fn(req)
+		}}
+
+		result, err := RunAgent(context.Background(), gen, registry, newThread(), WithStepHook(func(step AgentStep) {
+			steps = append(steps, step)
+		}))
+		is.NoErr(err)
+
+		is.Equal(gen.calls, 3)
+		is.Equal(len(steps), 3)
+		is.Equal(steps[0].Turn, 1)
+		is.Equal(len(steps[0].ToolCalls), 1)
+		is.Equal(len(steps[2].ToolCalls), 0)
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 6) // user, (assistant, tool) x2, answer
+		is.Equal(msgs[1].ToolCalls[0].Function.Name, "lookup")
+		is.Equal(msgs[2].Role, RoleTool)
+		is.Equal(msgs[2].Content, `{"ok": true}`)
+		is.Equal(msgs[5].Content, "done")
+	})
+
+	t.Run("stops at the turn limit", func(t *testing.T) {
+		is := is.New(t)
+		gen := lookups(100)
+
+		result, err := RunAgent(context.Background(), gen, nil, newThread(), WithMaxTurns(3))
+		is.True(errors.Is(err, ErrAgentTurnLimit))
+		is.Equal(gen.calls, 3)
+		is.Equal(len(result.Messages()), 7)
+	})
+
+	t.Run("stops when the condition is met", func(t *testing.T) {
+		is := is.New(t)
+		gen := lookups(100)
+
+		stop := stopFunc(func(tc ThreadContext) (bool, error) {
+			return len(tc.Messages()) >= 5, nil
+		})
+
+		_, err := RunAgent(context.Background(), gen, nil, newThread(), WithStopCondition(stop))
+		is.NoErr(err)
+		is.Equal(gen.calls, 2)
+	})
+
+	t.Run("respects cancellation between steps", func(t *testing.T) {
+		is := is.New(t)
+		gen := lookups(100)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		result, err := RunAgent(ctx, gen, nil, newThread(), WithMaxTurns(0), WithStepHook(func(step AgentStep) {
+			if step.Turn == 2 {
+				cancel()
+			}
+		}))
+		is.True(errors.Is(err, context.Canceled))
+		is.Equal(gen.calls, 2)
+		is.Equal(len(result.Messages()), 5) // the completed steps are kept
+	})
+}
+
+type stopFunc func(tc ThreadContext) (bool, error)
+
+func (f stopFunc) Evaluate(tc ThreadContext) (bool, error) { return f(tc) }
@@ -0,0 +1,97 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned by a BudgetedGenerator once its token budget
+// is spent. Use errors.Is to detect it.
+var ErrBudgetExceeded = errors.New("token budget exceeded")
+
+// BudgetedGenerator wraps a ContentGenerator and enforces a cap on the total
+// tokens used across all of its calls, such as every call in an agent session.
+// It is safe for concurrent use.
+type BudgetedGenerator struct {
+	gen       ContentGenerator
+	maxTokens int
+
+	mu   sync.Mutex
+	used int
+}
+
+// NewBudgetedGenerator returns a generator that stops calling gen once the
+// cumulative Usage().TotalTokens of its responses reaches maxTokens.
+//
+// A call is refused with ErrBudgetExceeded if the budget is already spent, or
+// if the request's MaxOutputTokens alone exceeds what remains. If a response
+// pushes usage over the budget, it is returned together with an error
+// wrapping ErrBudgetExceeded. Responses that do not implement UsageReporter
+// are not counted.
+//
+// Example:
+//
+//	llm := minds.NewBudgetedGenerator(provider, 50_000)
+//	responder := handlers.NewToolResponder("agent", llm)
+//	if _, err := responder.HandleThread(tc, nil); errors.Is(err, minds.ErrBudgetExceeded) {
+//	    log.Printf("stopped after %d tokens", llm.Used())
+//	}
+func NewBudgetedGenerator(gen ContentGenerator, maxTokens int) *BudgetedGenerator {
+	return &BudgetedGenerator{gen: gen, maxTokens: maxTokens}
+}
+
+func (b *BudgetedGenerator) ModelName() string {
+	return b.gen.ModelName()
+}
+
+func (b *BudgetedGenerator) GenerateContent(ctx context.Context, req Request) (Response, error) {
+	remaining := b.Remaining()
+	if remaining <= 0 {
+		return nil, fmt.Errorf("%w: %d of %d tokens used", ErrBudgetExceeded, b.Used(), b.maxTokens)
+	}
+	if req.Options.MaxOutputTokens != nil && *req.Options.MaxOutputTokens > remaining {
+		return nil, fmt.Errorf("%w: request allows %d output tokens but only %d remain", ErrBudgetExceeded, *req.Options.MaxOutputTokens, remaining)
+	}
+
+	resp, err := b.gen.GenerateContent(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	reporter, ok := resp.(UsageReporter)
+	if !ok {
+		return resp, nil
+	}
+
+	b.mu.Lock()
+	b.used += reporter.Usage().TotalTokens
+	used := b.used
+	b.mu.Unlock()
+
+	if used > b.maxTokens {
+		return resp, fmt.Errorf("%w: %d of %d tokens used", ErrBudgetExceeded, used, b.maxTokens)
+	}
+
+	return resp, nil
+}
+
+func (b *BudgetedGenerator) Close() {
+	b.gen.Close()
+}
+
+// Used returns the number of tokens consumed so far.
+func (b *BudgetedGenerator) Used() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// Remaining returns the number of tokens left in the budget, which is
+// negative if the last response overshot it.
+func (b *BudgetedGenerator) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.maxTokens - b.used
+}
@@ -0,0 +1,49 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBudgetedGenerator(t *testing.T) {
+	req := NewRequest(Messages{{Role: RoleUser, Content: "hi"}})
+
+	t.Run("tracks usage and refuses calls once spent", func(t *testing.T) {
+		is := is.New(t)
+		gen := &stubGenerator{fn: func(Request) (Response, error) {
+			return stubResponse{content: "ok", usage: Usage{TotalTokens: 40}}, nil
+		}}
+		budget := NewBudgetedGenerator(gen, 100)
+
+		_, err := budget.GenerateContent(context.Background(), req)
+		is.NoErr(err)
+		_, err = budget.GenerateContent(context.Background(), req)
+		is.NoErr(err)
+		is.Equal(budget.Remaining(), 20)
+
+		// The third response pushes usage over the cap but is still returned.
+		resp, err := budget.GenerateContent(context.Background(), req)
+		is.True(errors.Is(err, ErrBudgetExceeded))
+		is.Equal(resp.String(), "ok")
+		is.Equal(budget.Used(), 120)
+
+		_, err = budget.GenerateContent(context.Background(), req)
+		is.True(errors.Is(err, ErrBudgetExceeded))
+		is.Equal(gen.calls, 3) // refused without calling the provider
+	})
+
+	t.Run("refuses requests whose output limit exceeds the remainder", func(t *testing.T) {
+		is := is.New(t)
+		gen := &stubGenerator{fn: func(Request) (Response, error) {
+			return stubResponse{content: "ok"}, nil
+		}}
+		budget := NewBudgetedGenerator(gen, 100)
+
+		_, err := budget.GenerateContent(context.Background(), NewRequest(req.Messages, WithMaxOutputTokens(500)))
+		is.True(errors.Is(err, ErrBudgetExceeded))
+		is.Equal(gen.calls, 0)
+	})
+}
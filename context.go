@@ -3,10 +3,20 @@ package minds
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// ThreadContext holds the state of a conversation as it flows through
+// handlers.
+//
+// Methods fall into two groups. With and the With* methods return a new
+// ThreadContext and never modify the receiver, so the result can be changed
+// freely without affecting other holders of the original. AppendMessages and
+// SetKeyValue modify the receiver in place, and every holder of it sees the
+// change; they are kept for compatibility, and handlers that may run
+// concurrently or share a thread should prefer With.
 type ThreadContext interface {
 	// Clone returns a deep copy of the ThreadContext.
 	Clone() ThreadContext
@@ -19,14 +29,24 @@ type ThreadContext interface {
 	// Metadata returns a copy of the metadata in the context.
 	Metadata() Metadata
 
+	// AppendMessages adds messages to the receiver in place.
 	AppendMessages(message ...Message)
 
-	// SetKeyValue sets a key-value pair in the metadata.
+	// SetKeyValue sets a key-value pair in the receiver's metadata in place.
 	SetKeyValue(key string, value any)
 
+	// With returns a new ThreadContext with the options applied to a copy of
+	// the receiver's state. The receiver is not modified.
+	With(opts ...ThreadOption) ThreadContext
+
 	// WithContext returns a new ThreadContext with the provided context.
 	WithContext(ctx context.Context) ThreadContext
 
+	// WithTimeout returns a new ThreadContext whose context is canceled after
+	// d, or earlier if the receiver's context is done. Callers must call the
+	// returned cancel function once the work is done to release resources.
+	WithTimeout(d time.Duration) (ThreadContext, context.CancelFunc)
+
 	// WithUUID returns a new ThreadContext with the provided UUID.
 	WithUUID(uuid string) ThreadContext
 
@@ -37,6 +57,63 @@ type ThreadContext interface {
 	WithMetadata(metadata Metadata) ThreadContext
 }
 
+// ThreadState is the copy of a thread's state that ThreadOptions modify when
+// building a new ThreadContext with With.
+type ThreadState struct {
+	Context  context.Context
+	UUID     string
+	Messages Messages
+	Metadata Metadata
+}
+
+// ThreadOption describes a change applied by ThreadContext.With.
+type ThreadOption func(*ThreadState)
+
+// AppendMessages returns a ThreadOption that adds messages to the end of the
+// thread.
+//
+// Example:
+//
+//	next := tc.With(
+//	    minds.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: answer}),
+//	    minds.SetKeyValue("answered", true),
+//	)
+func AppendMessages(messages ...Message) ThreadOption {
+	return func(s *ThreadState) {
+		s.Messages = append(s.Messages, Messages(messages).Clone()...)
+	}
+}
+
+// ReplaceMessages returns a ThreadOption that replaces the thread's messages.
+func ReplaceMessages(messages ...Message) ThreadOption {
+	return func(s *ThreadState) {
+		s.Messages = Messages(messages).Clone()
+	}
+}
+
+// SetKeyValue returns a ThreadOption that sets a key-value pair in the
+// metadata.
+func SetKeyValue(key string, value any) ThreadOption {
+	return func(s *ThreadState) {
+		s.Metadata[key] = value
+	}
+}
+
+// ReplaceMetadata returns a ThreadOption that replaces the thread's metadata
+// with a copy of metadata.
+func ReplaceMetadata(metadata Metadata) ThreadOption {
+	return func(s *ThreadState) {
+		s.Metadata = metadata.Copy()
+	}
+}
+
+// SetContext returns a ThreadOption that replaces the thread's context.
+func SetContext(ctx context.Context) ThreadOption {
+	return func(s *ThreadState) {
+		s.Context = ctx
+	}
+}
+
 type threadContext struct {
 	mu       sync.RWMutex
 	ctx      context.Context
@@ -67,8 +144,37 @@ func (tc *threadContext) Clone() ThreadContext {
 	return &threadContext{
 		ctx:      tc.ctx,
 		uuid:     tc.uuid,
-		metadata: tc.metadata,
-		messages: tc.messages,
+		metadata: tc.metadata.Copy(),
+		messages: tc.messages.Clone(),
+	}
+}
+
+func (tc *threadContext) With(opts ...ThreadOption) ThreadContext {
+	tc.mu.RLock()
+	state := ThreadState{
+		Context:  tc.ctx,
+		UUID:     tc.uuid,
+		Messages: tc.messages.Clone(),
+		Metadata: tc.metadata.Copy(),
+	}
+	tc.mu.RUnlock()
+
+	for _, opt := range opts {
+		opt(&state)
+	}
+
+	if state.Metadata == nil {
+		state.Metadata = Metadata{}
+	}
+	if state.Messages == nil {
+		state.Messages = Messages{}
+	}
+
+	return &threadContext{
+		ctx:      state.Context,
+		uuid:     state.UUID,
+		metadata: state.Metadata,
+		messages: state.Messages,
 	}
 }
 
@@ -81,10 +187,16 @@ func (tc *threadContext) UUID() string {
 }
 
 func (tc *threadContext) Messages() Messages {
-	return tc.messages.Copy()
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.messages.Clone()
 }
 
+// Metadata returns a copy of the metadata, so callers may modify the result
+// without affecting the thread or racing with SetKeyValue.
 func (tc *threadContext) Metadata() Metadata {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return tc.metadata.Copy()
 }
 
@@ -99,6 +211,8 @@ func (tc *threadContext) SetKeyValue(key string, value any) {
 
 // WithContext returns a cloned ThreadContext with the provided context.
 func (tc *threadContext) WithContext(ctx context.Context) ThreadContext {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return &threadContext{
 		ctx:      ctx,
 		uuid:     tc.UUID(),
@@ -107,8 +221,23 @@ func (tc *threadContext) WithContext(ctx context.Context) ThreadContext {
 	}
 }
 
+// WithTimeout returns a cloned ThreadContext whose context times out after d.
+// The returned cancel function must be called once the work is done.
+//
+// Example:
+//
+//	tc, cancel := tc.WithTimeout(10 * time.Second)
+//	defer cancel()
+//	resp, err := llm.GenerateContent(tc.Context(), req)
+func (tc *threadContext) WithTimeout(d time.Duration) (ThreadContext, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(tc.Context(), d)
+	return tc.WithContext(ctx), cancel
+}
+
 // WithUUID returns a cloned ThreadContext with the provided UUID.
 func (tc *threadContext) WithUUID(uuid string) ThreadContext {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return &threadContext{
 		ctx:      tc.Context(),
 		uuid:     uuid,
@@ -119,6 +248,8 @@ func (tc *threadContext) WithUUID(uuid string) ThreadContext {
 
 // WithMessages returns a cloned ThreadContext with the provided messages.
 func (tc *threadContext) WithMessages(messages ...Message) ThreadContext {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return &threadContext{
 		ctx:      tc.Context(),
 		uuid:     tc.UUID(),
@@ -127,12 +258,14 @@ func (tc *threadContext) WithMessages(messages ...Message) ThreadContext {
 	}
 }
 
-// WithMetadata returns a cloned ThreadContext with the provided metadata.
+// WithMetadata returns a cloned ThreadContext with a copy of the provided metadata.
 func (tc *threadContext) WithMetadata(metadata Metadata) ThreadContext {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return &threadContext{
 		ctx:      tc.Context(),
 		uuid:     tc.UUID(),
-		metadata: metadata,
+		metadata: metadata.Copy(),
 		messages: tc.messages,
 	}
 }
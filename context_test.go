@@ -2,6 +2,8 @@ package minds
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -128,3 +130,89 @@ func TestThreadContext(t *testing.T) {
 		is.True(len(finalMsgs) > 0) // Verify messages were actually appended
 	})
 }
+
+func TestThreadContextConcurrentMetadata(t *testing.T) {
+	is := is.New(t)
+	tc := NewThreadContext(context.Background())
+
+	const workers = 50
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tc.SetKeyValue(fmt.Sprintf("key-%d", i), i)
+
+			// Readers and clones run alongside writers.
+			meta := tc.Metadata()
+			meta["scratch"] = i // mutating the copy must not affect tc
+			_ = tc.Clone().Metadata()
+			_ = tc.WithUUID("other").Metadata()
+		}(i)
+	}
+	wg.Wait()
+
+	meta := tc.Metadata()
+	is.Equal(len(meta), workers)
+	_, leaked := meta["scratch"]
+	is.True(!leaked)
+}
+
+func TestThreadContextWith(t *testing.T) {
+	is := is.New(t)
+
+	tc := NewThreadContext(context.Background()).WithMessages(Message{Role: RoleUser, Content: "hi"})
+	tc.SetKeyValue("step", 1)
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "v")
+
+	next := tc.With(
+		AppendMessages(Message{Role: RoleAssistant, Content: "hello"}),
+		SetKeyValue("step", 2),
+		SetContext(ctx),
+	)
+
+	is.Equal(len(next.Messages()), 2)
+	is.Equal(next.Metadata()["step"], 2)
+	is.Equal(next.Context(), ctx)
+	is.Equal(next.UUID(), tc.UUID())
+
+	// The receiver is untouched.
+	is.Equal(len(tc.Messages()), 1)
+	is.Equal(tc.Metadata()["step"], 1)
+	is.Equal(tc.Context(), context.Background())
+
+	// Mutating the result does not leak back either.
+	next.AppendMessages(Message{Role: RoleUser, Content: "again"})
+	next.SetKeyValue("step", 3)
+	is.Equal(len(tc.Messages()), 1)
+	is.Equal(tc.Metadata()["step"], 1)
+
+	replaced := tc.With(ReplaceMessages(), ReplaceMetadata(nil))
+	is.Equal(len(replaced.Messages()), 0)
+	is.Equal(len(replaced.Metadata()), 0)
+}
+
+func TestThreadContextWithTimeout(t *testing.T) {
+	is := is.New(t)
+
+	tc := NewThreadContext(context.Background()).WithMessages(Message{Role: RoleUser, Content: "hi"})
+	tc.SetKeyValue("step", 1)
+
+	bounded, cancel := tc.WithTimeout(time.Hour)
+	_, ok := bounded.Context().Deadline()
+	is.True(ok)
+	is.Equal(bounded.UUID(), tc.UUID())
+	is.Equal(bounded.Messages(), tc.Messages())
+	is.Equal(bounded.Metadata()["step"], 1)
+
+	cancel()
+	is.Equal(bounded.Context().Err(), context.Canceled)
+	is.NoErr(tc.Context().Err()) // the parent is not canceled
+
+	expired, cancel := tc.WithTimeout(time.Millisecond)
+	defer cancel()
+	<-expired.Context().Done()
+	is.Equal(expired.Context().Err(), context.DeadlineExceeded)
+}
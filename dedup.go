@@ -0,0 +1,120 @@
+package minds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DedupGenerator wraps a ContentGenerator and collapses identical requests
+// that are in flight at the same time into a single call. It is safe for
+// concurrent use.
+type DedupGenerator struct {
+	gen ContentGenerator
+
+	mu       sync.Mutex
+	inflight map[string]*dedupCall
+}
+
+// dedupCall is a request in progress and the callers waiting on it.
+type dedupCall struct {
+	done chan struct{}
+	resp Response
+	err  error
+}
+
+// NewDedupGenerator returns a generator that shares the result of a call
+// with every identical request made while it is in progress, like
+// singleflight. It is meant for ensembles that fan work out in parallel, such
+// as handlers.NewFirst or handlers.NewRangeParallel, where several branches
+// may send the same request and the duplicate calls are wasted. Nothing is
+// cached: once a call completes, the next identical request calls gen again.
+//
+// Requests are identical when their messages, options and metadata match.
+// Metadata is not sent to the model, but providers hand it to the tools they
+// run, so threads that differ only in metadata, such as the tenant, are not
+// merged. Requests whose metadata can't be serialized are never shared.
+// Deduplication is opt-in because
+// identical requests are sometimes sent on purpose to get distinct samples:
+// don't use it for the generator of handlers.Vote, whose samples would all
+// collapse into one.
+//
+// Callers sharing a call receive the same Response. If the caller that
+// started the call is canceled, the others receive its error too.
+//
+// Example:
+//
+//	llm := minds.NewDedupGenerator(provider)
+//	fast := handlers.NewWithModel("fast", "gpt-4o-mini", llm)
+//	race := handlers.NewFirst("race", fast, handlers.NewSequence("checked", fast, verify))
+func NewDedupGenerator(gen ContentGenerator) *DedupGenerator {
+	return &DedupGenerator{gen: gen, inflight: make(map[string]*dedupCall)}
+}
+
+func (d *DedupGenerator) ModelName() string {
+	return d.gen.ModelName()
+}
+
+func (d *DedupGenerator) GenerateContent(ctx context.Context, req Request) (Response, error) {
+	key, err := dedupKey(req)
+	if err != nil {
+		// Requests that can't be keyed are simply not shared.
+		return d.gen.GenerateContent(ctx, req)
+	}
+
+	d.mu.Lock()
+	if call, ok := d.inflight[key]; ok {
+		d.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.resp, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &dedupCall{done: make(chan struct{})}
+	d.inflight[key] = call
+	d.mu.Unlock()
+
+	call.resp, call.err = d.gen.GenerateContent(ctx, req)
+
+	d.mu.Lock()
+	delete(d.inflight, key)
+	d.mu.Unlock()
+	close(call.done)
+
+	return call.resp, call.err
+}
+
+func (d *DedupGenerator) Close() {
+	d.gen.Close()
+}
+
+// dedupKey identifies the content of a request, including the metadata its
+// tools will see. The tool registry can't be serialized, so it is identified
+// by its address.
+func dedupKey(req Request) (string, error) {
+	options := req.Options
+	registry := options.ToolRegistry
+	options.ToolRegistry = nil
+
+	data, err := json.Marshal(struct {
+		Options  RequestOptions
+		Registry string
+		Messages Messages
+		Metadata Metadata
+	}{
+		Options:  options,
+		Registry: fmt.Sprintf("%p", registry),
+		Messages: req.Messages,
+		Metadata: req.Metadata,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
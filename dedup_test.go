@@ -0,0 +1,111 @@
+package minds
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDedupGenerator(t *testing.T) {
+	t.Run("identical concurrent requests share a call", func(t *testing.T) {
+		is := is.New(t)
+
+		var calls int32
+		release := make(chan struct{})
+		gen := &stubGenerator{fn: func(req Request) (Response, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return stubResponse{content: "answer to " + req.Messages.Last().Content}, nil
+		}}
+		dedup := NewDedupGenerator(gen)
+
+		req := NewRequest(Messages{{Role: RoleUser, Content: "hi"}}, WithTemperature(0))
+
+		var wg sync.WaitGroup
+		results := make([]string, 5)
+		call := func(i int) {
+			defer wg.Done()
+			resp, err := dedup.GenerateContent(context.Background(), req)
+			if err == nil {
+				results[i] = resp.String()
+			}
+		}
+
+		// Start one call and wait for it to reach the generator, so the rest
+		// find it in flight.
+		wg.Add(1)
+		go call(0)
+		for atomic.LoadInt32(&calls) == 0 {
+			runtime.Gosched()
+		}
+		for i := 1; i < len(results); i++ {
+			wg.Add(1)
+			go call(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		is.Equal(atomic.LoadInt32(&calls), int32(1))
+		for _, result := range results {
+			is.Equal(result, "answer to hi")
+		}
+		is.Equal(len(dedup.inflight), 0) // nothing is kept once done
+	})
+
+	t.Run("completed calls are not cached", func(t *testing.T) {
+		is := is.New(t)
+		gen := &stubGenerator{fn: func(req Request) (Response, error) {
+			return stubResponse{content: "ok"}, nil
+		}}
+		dedup := NewDedupGenerator(gen)
+
+		req := NewRequest(Messages{{Role: RoleUser, Content: "hi"}})
+		_, err := dedup.GenerateContent(context.Background(), req)
+		is.NoErr(err)
+		_, err = dedup.GenerateContent(context.Background(), req)
+		is.NoErr(err)
+		is.Equal(gen.calls, 2)
+	})
+
+	t.Run("unserializable metadata is not shared", func(t *testing.T) {
+		is := is.New(t)
+		gen := &stubGenerator{fn: func(req Request) (Response, error) {
+			return stubResponse{content: "ok"}, nil
+		}}
+		dedup := NewDedupGenerator(gen)
+
+		req := NewRequest(Messages{{Content: "hi"}}, WithRequestMetadata(Metadata{"fn": func() {}}))
+		_, err := dedupKey(req)
+		is.True(err != nil)
+
+		_, err = dedup.GenerateContent(context.Background(), req)
+		is.NoErr(err)
+		is.Equal(gen.calls, 1)
+	})
+
+	t.Run("requests differing in options are distinct", func(t *testing.T) {
+		is := is.New(t)
+
+		a, err := dedupKey(NewRequest(Messages{{Content: "hi"}}, WithModel("a")))
+		is.NoErr(err)
+		b, err := dedupKey(NewRequest(Messages{{Content: "hi"}}, WithModel("b")))
+		is.NoErr(err)
+		c, err := dedupKey(NewRequest(Messages{{Content: "hi"}}, WithModel("a"), WithRequestMetadata(Metadata{"tenant": "acme"})))
+		is.NoErr(err)
+		d, err := dedupKey(NewRequest(Messages{{Content: "hi"}}, WithModel("a"), WithRequestMetadata(Metadata{"tenant": "globex"})))
+		is.NoErr(err)
+		r, err := dedupKey(NewRequest(Messages{{Content: "hi"}}, WithModel("a"), WithToolRegistry(NewToolRegistry())))
+		is.NoErr(err)
+
+		is.True(a != b)
+		is.True(a != c) // tools see the metadata
+		is.True(c != d)
+		is.True(a != r)
+	})
+}
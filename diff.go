@@ -0,0 +1,165 @@
+package minds
+
+import (
+	"reflect"
+)
+
+// MessageChange describes a message that was changed in place between two
+// threads.
+type MessageChange struct {
+	// Index is the position of the message in the later thread.
+	Index  int
+	Before Message
+	After  Message
+}
+
+// MessageDiff describes how one set of messages differs from another.
+type MessageDiff struct {
+	// Added holds messages of after that have no counterpart in before.
+	Added Messages
+	// Removed holds messages of before that have no counterpart in after.
+	Removed Messages
+	// Modified holds messages that were changed in place.
+	Modified []MessageChange
+}
+
+// Empty reports whether the diff contains no changes.
+func (d MessageDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// DiffMessages compares two message slices. Messages that are unchanged are
+// matched up first, using the longest common subsequence, so a message
+// inserted at the front or in the middle of a thread is reported as added
+// rather than shifting every later message. Between matched messages, the
+// unmatched ones are paired in order and reported as modified; any left over
+// are reported as added or removed. The result is deterministic and ordered
+// by position.
+//
+// This is primarily a debugging aid for seeing what a handler did to a thread:
+//
+//	before := tc.Messages()
+//	result, _ := h.HandleThread(tc, nil)
+//	diff := minds.DiffMessages(before, result.Messages())
+func DiffMessages(before, after Messages) MessageDiff {
+	diff := MessageDiff{
+		Added:    Messages{},
+		Removed:  Messages{},
+		Modified: []MessageChange{},
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:].
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			switch {
+			case messageEqual(before[i], after[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// gap reports the unmatched messages before[i0:i] and after[j0:j].
+	gap := func(i0, i, j0, j int) {
+		for i0 < i && j0 < j {
+			diff.Modified = append(diff.Modified, MessageChange{
+				Index:  j0,
+				Before: before[i0],
+				After:  after[j0],
+			})
+			i0++
+			j0++
+		}
+		diff.Removed = append(diff.Removed, before[i0:i]...)
+		diff.Added = append(diff.Added, after[j0:j]...)
+	}
+
+	i, j := 0, 0
+	i0, j0 := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case messageEqual(before[i], after[j]):
+			gap(i0, i, j0, j)
+			i++
+			j++
+			i0, j0 = i, j
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	gap(i0, len(before), j0, len(after))
+
+	return diff
+}
+
+// messageEqual compares two messages, treating nil and empty metadata or tool
+// calls as equal since Copy normalizes one into the other.
+func messageEqual(a, b Message) bool {
+	if a.Role != b.Role || a.Content != b.Content || a.Name != b.Name || a.ToolCallID != b.ToolCallID {
+		return false
+	}
+	if (len(a.Metadata) != 0 || len(b.Metadata) != 0) && !reflect.DeepEqual(a.Metadata, b.Metadata) {
+		return false
+	}
+	if (len(a.ToolCalls) != 0 || len(b.ToolCalls) != 0) && !reflect.DeepEqual(a.ToolCalls, b.ToolCalls) {
+		return false
+	}
+	return true
+}
+
+// ValueChange holds the old and new value of a modified metadata key.
+type ValueChange struct {
+	Before any
+	After  any
+}
+
+// MetadataDiff describes how one Metadata differs from another.
+type MetadataDiff struct {
+	Added    Metadata
+	Removed  Metadata
+	Modified map[string]ValueChange
+}
+
+// Empty reports whether the diff contains no changes.
+func (d MetadataDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// DiffMetadata compares two Metadata maps key by key. Values are compared with
+// reflect.DeepEqual.
+func DiffMetadata(before, after Metadata) MetadataDiff {
+	diff := MetadataDiff{
+		Added:    Metadata{},
+		Removed:  Metadata{},
+		Modified: map[string]ValueChange{},
+	}
+
+	for k, newVal := range after {
+		oldVal, exists := before[k]
+		if !exists {
+			diff.Added[k] = newVal
+			continue
+		}
+		if !reflect.DeepEqual(oldVal, newVal) {
+			diff.Modified[k] = ValueChange{Before: oldVal, After: newVal}
+		}
+	}
+
+	for k, oldVal := range before {
+		if _, exists := after[k]; !exists {
+			diff.Removed[k] = oldVal
+		}
+	}
+
+	return diff
+}
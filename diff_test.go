@@ -0,0 +1,101 @@
+package minds
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDiffMessages(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		is := is.New(t)
+		msgs := Messages{{Role: RoleUser, Content: "hi"}}
+
+		diff := DiffMessages(msgs, msgs.Copy())
+		is.True(diff.Empty())
+	})
+
+	t.Run("added, modified and removed", func(t *testing.T) {
+		is := is.New(t)
+		before := Messages{
+			{Role: RoleSystem, Content: "be nice"},
+			{Role: RoleUser, Content: "hi"},
+		}
+		after := Messages{
+			{Role: RoleSystem, Content: "be terse"},
+			{Role: RoleUser, Content: "hi"},
+			{Role: RoleAssistant, Content: "hello"},
+		}
+
+		diff := DiffMessages(before, after)
+		is.Equal(len(diff.Added), 1)
+		is.Equal(diff.Added[0].Content, "hello")
+		is.Equal(len(diff.Removed), 0)
+		is.Equal(len(diff.Modified), 1)
+		is.Equal(diff.Modified[0].Index, 0)
+		is.Equal(diff.Modified[0].Before.Content, "be nice")
+		is.Equal(diff.Modified[0].After.Content, "be terse")
+
+		diff = DiffMessages(after, before)
+		is.Equal(len(diff.Removed), 1)
+		is.Equal(diff.Removed[0].Content, "hello")
+	})
+
+	t.Run("prepended message", func(t *testing.T) {
+		is := is.New(t)
+		before := Messages{
+			{Role: RoleUser, Content: "hi"},
+			{Role: RoleAssistant, Content: "hello"},
+		}
+		after := append(Messages{{Role: RoleSystem, Content: "The time is 12:00."}}, before...)
+
+		diff := DiffMessages(before, after)
+		is.Equal(len(diff.Modified), 0)
+		is.Equal(len(diff.Removed), 0)
+		is.Equal(len(diff.Added), 1)
+		is.Equal(diff.Added[0].Content, "The time is 12:00.")
+
+		diff = DiffMessages(after, before)
+		is.Equal(len(diff.Removed), 1)
+		is.Equal(len(diff.Added), 0)
+	})
+
+	t.Run("modified in the middle and appended", func(t *testing.T) {
+		is := is.New(t)
+		before := Messages{
+			{Role: RoleUser, Content: "a"},
+			{Role: RoleAssistant, Content: "b"},
+			{Role: RoleUser, Content: "c"},
+		}
+		after := Messages{
+			{Role: RoleUser, Content: "a"},
+			{Role: RoleAssistant, Content: "B"},
+			{Role: RoleUser, Content: "c"},
+			{Role: RoleAssistant, Content: "d"},
+		}
+
+		diff := DiffMessages(before, after)
+		is.Equal(len(diff.Modified), 1)
+		is.Equal(diff.Modified[0].Index, 1)
+		is.Equal(diff.Modified[0].After.Content, "B")
+		is.Equal(len(diff.Added), 1)
+		is.Equal(diff.Added[0].Content, "d")
+		is.Equal(len(diff.Removed), 0)
+	})
+}
+
+func TestDiffMetadata(t *testing.T) {
+	is := is.New(t)
+	before := Metadata{"keep": 1, "change": "a", "drop": true}
+	after := Metadata{"keep": 1, "change": "b", "new": []string{"x"}}
+
+	diff := DiffMetadata(before, after)
+	is.Equal(len(diff.Added), 1)
+	is.Equal(diff.Added["new"], []string{"x"})
+	is.Equal(len(diff.Removed), 1)
+	is.Equal(diff.Removed["drop"], true)
+	is.Equal(len(diff.Modified), 1)
+	is.Equal(diff.Modified["change"], ValueChange{Before: "a", After: "b"})
+
+	is.True(DiffMetadata(before, before.Copy()).Empty())
+}
@@ -0,0 +1,89 @@
+package minds
+
+import (
+	"sync"
+)
+
+// FilteredRegistry returns a view of inner that exposes only the named tools,
+// for offering a subset of a registry to the model. Has, Lookup and List
+// see only those tools, so calls to any other tool are rejected by
+// HandleFunctionCalls as unknown. Everything else is handled by inner: calls
+// made through the view count against inner's limits and budget, are
+// reported to its OnInvoke callbacks, are recorded in the tool history if
+// inner was created with WithToolHistory, and are answered from its store if
+// inner comes from IdempotentRegistry. Tools registered through the view are
+// added to inner and become visible in the view.
+//
+// Example:
+//
+//	registry := minds.NewToolRegistry(minds.WithMaxInvocations(10))
+//	lookupOnly := minds.FilteredRegistry(registry, "search", "lookup")
+func FilteredRegistry(inner ToolRegistry, names ...string) ToolRegistry {
+	visible := make(map[string]bool, len(names))
+	for _, name := range names {
+		visible[name] = true
+	}
+
+	return &filteredRegistry{ToolRegistry: inner, visible: visible}
+}
+
+type filteredRegistry struct {
+	ToolRegistry
+
+	mu      sync.Mutex
+	visible map[string]bool
+}
+
+func (r *filteredRegistry) isVisible(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.visible[name]
+}
+
+func (r *filteredRegistry) show(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.visible[name] = true
+}
+
+func (r *filteredRegistry) Register(tool Tool, opts ...ToolOption) error {
+	if err := r.ToolRegistry.Register(tool, opts...); err != nil {
+		return err
+	}
+	r.show(tool.Name())
+	return nil
+}
+
+func (r *filteredRegistry) RegisterOrReplace(tool Tool, opts ...ToolOption) error {
+	if err := r.ToolRegistry.RegisterOrReplace(tool, opts...); err != nil {
+		return err
+	}
+	r.show(tool.Name())
+	return nil
+}
+
+func (r *filteredRegistry) Has(name string) bool {
+	_, ok := r.Lookup(name)
+	return ok
+}
+
+func (r *filteredRegistry) Lookup(name string) (Tool, bool) {
+	if !r.isVisible(name) {
+		return nil, false
+	}
+	return r.ToolRegistry.Lookup(name)
+}
+
+func (r *filteredRegistry) List() []Tool {
+	var tools []Tool
+	for _, tool := range r.ToolRegistry.List() {
+		if r.isVisible(tool.Name()) {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+func (r *filteredRegistry) unwrap() ToolRegistry {
+	return r.ToolRegistry
+}
@@ -0,0 +1,90 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestFilteredRegistry(t *testing.T) {
+	newRegistry := func(t *testing.T, opts ...ToolRegistryOption) ToolRegistry {
+		t.Helper()
+		registry := NewToolRegistry(opts...)
+		for _, name := range []string{"search", "lookup", "delete"} {
+			if err := registry.Register(newEchoTool(t, name), WithToolLimit(1)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return registry
+	}
+
+	t.Run("exposes only the named tools", func(t *testing.T) {
+		is := is.New(t)
+		view := FilteredRegistry(newRegistry(t), "search", "lookup", "missing")
+
+		is.Equal(len(view.List()), 2)
+		is.True(view.Has("search"))
+		is.True(!view.Has("delete"))
+		_, ok := view.Lookup("delete")
+		is.True(!ok)
+
+		results, err := HandleFunctionCalls(context.Background(), toolCalls("delete"), view)
+		is.NoErr(err)
+		is.True(strings.HasPrefix(string(results[0].Function.Result), "ERROR: `delete` is not a valid tool name"))
+	})
+
+	t.Run("keeps limits, hooks and history of the inner registry", func(t *testing.T) {
+		is := is.New(t)
+		inner := newRegistry(t, WithToolHistory())
+		var invoked []string
+		inner.OnInvoke(func(inv ToolInvocation) { invoked = append(invoked, inv.Name) })
+		view := FilteredRegistry(inner, "search")
+
+		ctx, history := TrackToolHistory(context.Background(), view)
+		is.True(history != nil)
+
+		_, err := HandleFunctionCalls(ctx, toolCalls("search"), view)
+		is.NoErr(err)
+		is.Equal(invoked, []string{"search"})
+
+		_, err = HandleFunctionCalls(ctx, toolCalls("search"), view)
+		is.True(errors.Is(err, ErrToolBudgetExceeded)) // the inner limit of one call applies
+
+		_, err = HandleFunctionCalls(ctx, toolCalls("search"), inner)
+		is.True(errors.Is(err, ErrToolBudgetExceeded)) // and is shared with the inner registry
+
+		tc := history.Record(NewThreadContext(ctx))
+		is.Equal(len(ToolHistory(tc)), 1)
+	})
+
+	t.Run("answers from an idempotent inner registry", func(t *testing.T) {
+		is := is.New(t)
+		inner := IdempotentRegistry(newRegistry(t), &mapKVStore{data: map[string][]byte{}})
+		var calls int
+		inner.OnInvoke(func(ToolInvocation) { calls++ })
+		view := FilteredRegistry(inner, "search")
+		ctx := WithIdempotencyKey(context.Background(), "job-1")
+
+		for i := 0; i < 2; i++ {
+			results, err := HandleFunctionCalls(ctx, toolCalls("search"), view)
+			is.NoErr(err)
+			is.Equal(string(results[0].Function.Result), `{"input":"x"}`)
+		}
+		is.Equal(calls, 1) // the retry was replayed, not counted against the limit
+	})
+
+	t.Run("registers through to the inner registry", func(t *testing.T) {
+		is := is.New(t)
+		inner := newRegistry(t)
+		view := FilteredRegistry(inner, "search")
+
+		is.NoErr(view.Register(newEchoTool(t, "summarize")))
+		is.True(view.Has("summarize"))
+		is.True(inner.Has("summarize"))
+		is.True(errors.Is(view.Register(newEchoTool(t, "delete")), ErrToolAlreadyRegistered))
+		is.True(!view.Has("delete"))
+	})
+}
@@ -3,9 +3,13 @@
 package minds
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
+	"time"
 )
 
 type FunctionCall struct {
@@ -18,16 +22,48 @@ type FunctionCall struct {
 
 type CallableFunc func(context.Context, []byte) ([]byte, error)
 
+// FunctionOption configures how a wrapped function decodes the arguments sent
+// by the model.
+type FunctionOption func(*functionOptions)
+
+type functionOptions struct {
+	disallowUnknownFields bool
+	coerceArguments       bool
+}
+
+// WithDisallowUnknownFields rejects arguments containing fields that are not
+// part of the args struct. By default unknown fields are ignored.
+func WithDisallowUnknownFields(disallow bool) FunctionOption {
+	return func(o *functionOptions) {
+		o.disallowUnknownFields = disallow
+	}
+}
+
+// WithArgumentCoercion converts string values to numbers or booleans where the
+// parameter schema expects them, e.g. `{"count": "3"}` becomes `{"count": 3}`.
+// Models occasionally quote numbers; this avoids failing the call over it.
+func WithArgumentCoercion(coerce bool) FunctionOption {
+	return func(o *functionOptions) {
+		o.coerceArguments = coerce
+	}
+}
+
 // functionWrapper provides a convenient way to wrap Go functions with metadata
 type functionWrapper struct {
 	name        string
 	description string
+	argsType    reflect.Type
 	argsSchema  Definition
 	impl        CallableFunc // The actual function to call
+	options     functionOptions
 }
 
 // WrapFunction takes a `CallableFunc` and wraps it as a `minds.Tool` with the provided name and description.
-func WrapFunction(name, description string, args any, fn CallableFunc) (*functionWrapper, error) {
+//
+// Before fn is called, the arguments are decoded into the args type so that
+// malformed model output is reported with the offending JSON. Options control
+// how strict that decoding is.
+func WrapFunction(name, description string, args any, fn CallableFunc, opts ...FunctionOption) (*functionWrapper, error) {
 	if args == nil {
 		return nil, fmt.Errorf("args must be a non-nil pointer to a struct")
 	}
@@ -45,14 +81,63 @@ func WrapFunction(name, description string, args any, fn CallableFunc) (*functio
 		return nil, fmt.Errorf("invalid function name: `%s`. Must start with a letter or an underscore. Must be alphameric (a-z, A-Z, 0-9), underscores (_), dots (.) or dashes (-), with a maximum length of 64", name)
 	}
 
+	options := functionOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return &functionWrapper{
 		name:        name,
 		description: description,
+		argsType:    fnType,
 		argsSchema:  *params,
 		impl:        fn,
+		options:     options,
 	}, nil
 }
 
+// WrapTypedFunction wraps a typed Go function as a Tool. The parameter schema
+// is generated from In, the model's arguments are unmarshaled into an In
+// before fn is called, and the Out it returns is marshaled to JSON as the
+// result. Empty arguments leave In at its zero value. Errors returned by fn
+// are passed through unchanged. The options are those of WrapFunction.
+//
+// Example:
+//
+//	type weatherArgs struct {
+//	    City string `json:"city" description:"The city to look up"`
+//	}
+//
+//	tool, err := minds.WrapTypedFunction("get_weather", "Get the current weather",
+//	    func(ctx context.Context, args weatherArgs) (Weather, error) {
+//	        return lookupWeather(ctx, args.City)
+//	    })
+func WrapTypedFunction[In, Out any](name, description string, fn func(context.Context, In) (Out, error), opts ...FunctionOption) (Tool, error) {
+	impl := func(ctx context.Context, params []byte) ([]byte, error) {
+		var args In
+		if len(bytes.TrimSpace(params)) > 0 {
+			if err := json.Unmarshal(params, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments for `%s`: %w: %s", name, err, params)
+			}
+		}
+
+		out, err := fn(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal result of `%s`: %w", name, err)
+		}
+
+		return result, nil
+	}
+
+	var args In
+	return WrapFunction(name, description, args, impl, opts...)
+}
+
 func isValidToolName(name string) bool {
 	if len(name) == 0 {
 		return false
@@ -85,16 +170,149 @@ func (f *functionWrapper) Description() string    { return f.description }
 func (f *functionWrapper) Parameters() Definition { return f.argsSchema }
 
 func (f *functionWrapper) Call(ctx context.Context, params []byte) ([]byte, error) {
+	params, err := f.decodeArguments(params)
+	if err != nil {
+		return nil, err
+	}
 	return f.impl(ctx, params)
 }
 
+// decodeArguments applies argument coercion and verifies that params decode
+// into the args type. It returns the (possibly rewritten) params.
+func (f *functionWrapper) decodeArguments(params []byte) ([]byte, error) {
+	if f.argsType == nil || len(bytes.TrimSpace(params)) == 0 {
+		return params, nil
+	}
+
+	params, err := f.coerceArguments(params)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(params))
+	if f.options.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(reflect.New(f.argsType).Interface()); err != nil {
+		return nil, fmt.Errorf("invalid arguments for `%s`: %w: %s", f.name, err, params)
+	}
+
+	return params, nil
+}
+
+// coerceArguments rewrites params according to WithArgumentCoercion. It is a
+// no-op when coercion is disabled. Numbers are decoded as json.Number so
+// values the schema leaves alone, such as large integer IDs, keep their exact
+// text, and params is returned as is when nothing needed coercing.
+func (f *functionWrapper) coerceArguments(params []byte) ([]byte, error) {
+	if !f.options.coerceArguments || len(bytes.TrimSpace(params)) == 0 {
+		return params, nil
+	}
+
+	var data any
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid arguments for `%s`: %w: %s", f.name, err, params)
+	}
+
+	data, changed := coerceValue(f.argsSchema, data)
+	if !changed {
+		return params, nil
+	}
+
+	coerced, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments for `%s`: %w: %s", f.name, err, params)
+	}
+	return coerced, nil
+}
+
+// coerceValue converts string values into the number or boolean types the
+// schema expects and reports whether it changed anything. Values that cannot
+// be converted are returned unchanged.
+func coerceValue(schema Definition, data any) (any, bool) {
+	changed := false
+	switch schema.Type {
+	case Object:
+		obj, ok := data.(map[string]any)
+		if !ok {
+			return data, false
+		}
+		for key, prop := range schema.Properties {
+			if v, exists := obj[key]; exists {
+				var c bool
+				obj[key], c = coerceValue(prop, v)
+				changed = changed || c
+			}
+		}
+		return obj, changed
+	case Array:
+		arr, ok := data.([]any)
+		if !ok || schema.Items == nil {
+			return data, false
+		}
+		for i := range arr {
+			var c bool
+			arr[i], c = coerceValue(*schema.Items, arr[i])
+			changed = changed || c
+		}
+		return arr, changed
+	case Number, Integer:
+		s, ok := data.(string)
+		if !ok {
+			return data, false
+		}
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			// Keep the digits of valid JSON numbers so large integers
+			// survive; other forms such as "+5" are written as parsed.
+			if json.Valid([]byte(s)) {
+				return json.Number(s), true
+			}
+			return n, true
+		}
+	case Boolean:
+		s, ok := data.(string)
+		if !ok {
+			return data, false
+		}
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, true
+		}
+	}
+	return data, false
+}
+
 func (f *functionWrapper) HandleThread(ctx ThreadContext, next ThreadHandler) (ThreadContext, error) {
 	return ctx, nil
 }
 
+// argumentCoercer is implemented by tools that normalize their arguments before
+// validation, such as functions wrapped with WithArgumentCoercion.
+type argumentCoercer interface {
+	coerceArguments([]byte) ([]byte, error)
+}
+
 // HandleFunctionCalls takes an array of ToolCalls and executes the functions they represent
 // using the provided ToolRegistry. It returns an array of ToolCalls with the results of the function calls.
+//
+// Arguments are validated against each tool's Parameters() schema before the
+// tool is called. Invalid arguments are not passed to the tool; instead the
+// validation error is written to the call's Result so the model can correct
+// itself.
+//
+// If the registry's invocation budget is exhausted, HandleFunctionCalls stops
+// and returns an error wrapping ErrToolBudgetExceeded along with the calls,
+// where those already executed have their Result set.
+//
+// Every tool execution is reported to the registry's OnInvoke callbacks and,
+// if ctx comes from TrackToolHistory, to its ToolHistoryRecorder.
+//
+// If the registry comes from IdempotentRegistry and ctx carries an idempotency
+// key, a call already made under that key is answered from the registry's
+// store instead of executing the tool again.
 func HandleFunctionCalls(ctx context.Context, calls []ToolCall, registry ToolRegistry) ([]ToolCall, error) {
+	layers := registryLayers(registry)
 	for i, call := range calls {
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
@@ -113,13 +331,90 @@ func HandleFunctionCalls(ctx context.Context, calls []ToolCall, registry ToolReg
 			continue
 		}
 
-		result, err := f.Call(ctx, fn.Parameters)
-		if err != nil {
-			calls[i].Function.Result = []byte(fmt.Sprintf("ERROR: Tool `%s` failed: %v", fn.Name, err))
+		params := fn.Parameters
+		if c, ok := f.(argumentCoercer); ok {
+			coerced, err := c.coerceArguments(params)
+			if err != nil {
+				calls[i].Function.Result = []byte(fmt.Sprintf("ERROR: Tool `%s` called with invalid arguments: %v", fn.Name, err))
+				continue
+			}
+			params = coerced
+		}
+
+		if err := ValidateArguments(f.Parameters(), params); err != nil {
+			calls[i].Function.Result = []byte(fmt.Sprintf("ERROR: Tool `%s` called with invalid arguments: %v", fn.Name, err))
 			continue
 		}
 
+		result, err := invokeTool(ctx, layers, call, f, params)
+		if err != nil {
+			return calls, err
+		}
 		calls[i].Function.Result = result
 	}
 	return calls, nil
 }
+
+// invokeTool executes a validated call to f, or replays its cached result.
+// Tool failures are returned as an error message in the result; the error is
+// only set when the calls must stop, such as when the budget is exhausted.
+func invokeTool(ctx context.Context, layers []ToolRegistry, call ToolCall, f Tool, params []byte) ([]byte, error) {
+	name := call.Function.Name
+
+	// Hold the caches for the call so that a concurrent identical call waits
+	// for this one and replays its result instead of running the tool too.
+	release, err := acquireResults(ctx, layers, name, params)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	cached, hit, err := cachedResult(ctx, layers, name, params)
+	if err != nil {
+		return nil, err
+	}
+	if hit {
+		return cached, nil
+	}
+
+	for _, layer := range layers {
+		if b, ok := layer.(invocationBudget); ok {
+			if err := b.reserve(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	start := time.Now()
+	result, err := f.Call(ctx, params)
+	inv := ToolInvocation{
+		ID:         call.ID,
+		Name:       name,
+		Arguments:  params,
+		Duration:   time.Since(start),
+		ResultSize: len(result),
+		Err:        err,
+	}
+	if err == nil {
+		inv.Result = result
+	}
+	for _, layer := range layers {
+		if r, ok := layer.(invocationReporter); ok {
+			r.reportInvocation(inv)
+		}
+	}
+	recordToolHistory(ctx, inv)
+	if err != nil {
+		return []byte(fmt.Sprintf("ERROR: Tool `%s` failed: %v", name, err)), nil
+	}
+
+	for _, layer := range layers {
+		if c, ok := layer.(resultCache); ok {
+			if err := c.cacheResult(ctx, name, params, result); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
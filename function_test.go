@@ -0,0 +1,217 @@
+package minds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type weatherArgs struct {
+	City   string  `json:"city"`
+	Days   int     `json:"days"`
+	Temp   float64 `json:"temp"`
+	Metric bool    `json:"metric"`
+}
+
+func echoArgs(_ context.Context, args []byte) ([]byte, error) {
+	return args, nil
+}
+
+func TestWrapFunctionDecoding(t *testing.T) {
+	t.Run("unknown fields are ignored by default", func(t *testing.T) {
+		is := is.New(t)
+		fn, err := WrapFunction("weather", "Get the weather", weatherArgs{}, echoArgs)
+		is.NoErr(err)
+
+		_, err = fn.Call(context.Background(), []byte(`{"city":"Paris","extra":1}`))
+		is.NoErr(err)
+	})
+
+	t.Run("unknown fields rejected when disallowed", func(t *testing.T) {
+		is := is.New(t)
+		fn, err := WrapFunction("weather", "Get the weather", weatherArgs{}, echoArgs,
+			WithDisallowUnknownFields(true))
+		is.NoErr(err)
+
+		_, err = fn.Call(context.Background(), []byte(`{"city":"Paris","extra":1}`))
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), `"extra"`))
+	})
+
+	t.Run("type mismatch reports offending json", func(t *testing.T) {
+		is := is.New(t)
+		fn, err := WrapFunction("weather", "Get the weather", weatherArgs{}, echoArgs)
+		is.NoErr(err)
+
+		_, err = fn.Call(context.Background(), []byte(`{"days":"3"}`))
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), `{"days":"3"}`))
+	})
+
+	t.Run("coerces strings to numbers and booleans", func(t *testing.T) {
+		is := is.New(t)
+		fn, err := WrapFunction("weather", "Get the weather", weatherArgs{}, echoArgs,
+			WithArgumentCoercion(true))
+		is.NoErr(err)
+
+		out, err := fn.Call(context.Background(), []byte(`{"city":"Paris","days":"3","temp":"21.5","metric":"true"}`))
+		is.NoErr(err)
+
+		var args weatherArgs
+		is.NoErr(json.Unmarshal(out, &args))
+		is.Equal(args, weatherArgs{City: "Paris", Days: 3, Temp: 21.5, Metric: true})
+	})
+
+	t.Run("coercion keeps large integers exact", func(t *testing.T) {
+		is := is.New(t)
+		type orderArgs struct {
+			ID       int64 `json:"id"`
+			ParentID int64 `json:"parent_id"`
+		}
+		fn, err := WrapFunction("order", "Get an order", orderArgs{}, echoArgs,
+			WithArgumentCoercion(true))
+		is.NoErr(err)
+
+		params := []byte(`{"id": 9007199254740993, "parent_id": 1}`)
+		out, err := fn.Call(context.Background(), params)
+		is.NoErr(err)
+		is.Equal(string(out), string(params)) // nothing to coerce, so passed as is
+
+		out, err = fn.Call(context.Background(), []byte(`{"id":9007199254740993,"parent_id":"9007199254740995"}`))
+		is.NoErr(err)
+		var args orderArgs
+		is.NoErr(json.Unmarshal(out, &args))
+		is.Equal(args, orderArgs{ID: 9007199254740993, ParentID: 9007199254740995})
+	})
+}
+
+func TestHandleFunctionCallsValidation(t *testing.T) {
+	type lightArgs struct {
+		Room  string `json:"room"`
+		State string `json:"state" enum:"on,off"`
+		Level int    `json:"level,omitempty"`
+	}
+
+	newRegistry := func(t *testing.T, called *int, opts ...FunctionOption) ToolRegistry {
+		fn, err := WrapFunction("lights", "Control the lights", lightArgs{},
+			func(_ context.Context, args []byte) ([]byte, error) {
+				*called++
+				return []byte("ok"), nil
+			}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		registry := NewToolRegistry()
+		if err := registry.Register(fn); err != nil {
+			t.Fatal(err)
+		}
+		return registry
+	}
+
+	call := func(args string) []ToolCall {
+		return []ToolCall{{ID: "1", Function: FunctionCall{Name: "lights", Parameters: []byte(args)}}}
+	}
+
+	tests := []struct {
+		name    string
+		args    string
+		opts    []FunctionOption
+		called  int
+		wantErr string
+	}{
+		{name: "valid", args: `{"room":"den","state":"on"}`, called: 1},
+		{name: "missing required", args: `{"state":"on"}`, wantErr: "room: required field is missing"},
+		{name: "enum violation", args: `{"room":"den","state":"dim"}`, wantErr: `state: value "dim" is not one of [on off]`},
+		{name: "wrong type", args: `{"room":"den","state":"on","level":"high"}`, wantErr: "level: expected integer"},
+		{name: "first invalid field in order", args: `{"room":"den","state":"dim","level":"high"}`, wantErr: "level: expected integer"},
+		{name: "coerced before validation", args: `{"room":"den","state":"on","level":"5"}`, opts: []FunctionOption{WithArgumentCoercion(true)}, called: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			called := 0
+			registry := newRegistry(t, &called, tt.opts...)
+
+			results, err := HandleFunctionCalls(context.Background(), call(tt.args), registry)
+			is.NoErr(err)
+			is.Equal(called, tt.called)
+
+			result := string(results[0].Function.Result)
+			if tt.wantErr == "" {
+				is.Equal(result, "ok")
+				return
+			}
+			is.True(strings.HasPrefix(result, "ERROR: Tool `lights` called with invalid arguments"))
+			is.True(strings.Contains(result, tt.wantErr))
+		})
+	}
+}
+
+func TestWrapTypedFunction(t *testing.T) {
+	type forecast struct {
+		City    string  `json:"city"`
+		Celsius float64 `json:"celsius"`
+	}
+
+	lookup := func(_ context.Context, args weatherArgs) (forecast, error) {
+		if args.City == "" {
+			return forecast{}, errors.New("city is required")
+		}
+		return forecast{City: args.City, Celsius: 21.5}, nil
+	}
+
+	t.Run("schema is generated from the argument type", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := WrapTypedFunction("weather", "Get the weather", lookup)
+		is.NoErr(err)
+
+		is.Equal(tool.Name(), "weather")
+		is.Equal(tool.Description(), "Get the weather")
+		is.Equal(tool.Parameters().Type, Object)
+		is.Equal(tool.Parameters().Properties["city"].Type, String)
+		is.Equal(tool.Parameters().Properties["days"].Type, Integer)
+	})
+
+	t.Run("arguments and result are converted", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := WrapTypedFunction("weather", "Get the weather", lookup)
+		is.NoErr(err)
+
+		out, err := tool.Call(context.Background(), []byte(`{"city":"Paris"}`))
+		is.NoErr(err)
+		is.Equal(string(out), `{"city":"Paris","celsius":21.5}`)
+	})
+
+	t.Run("function errors are returned", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := WrapTypedFunction("weather", "Get the weather", lookup)
+		is.NoErr(err)
+
+		_, err = tool.Call(context.Background(), nil)
+		is.Equal(err.Error(), "city is required")
+	})
+
+	t.Run("invalid arguments are rejected", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := WrapTypedFunction("weather", "Get the weather", lookup)
+		is.NoErr(err)
+
+		_, err = tool.Call(context.Background(), []byte(`{"city":42}`))
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), `{"city":42}`))
+	})
+
+	t.Run("options are applied", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := WrapTypedFunction("weather", "Get the weather", lookup, WithArgumentCoercion(true))
+		is.NoErr(err)
+
+		_, err = tool.Call(context.Background(), []byte(`{"city":"Paris","days":"3"}`))
+		is.NoErr(err)
+	})
+}
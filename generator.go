@@ -0,0 +1,62 @@
+package minds
+
+import "context"
+
+// GeneratorMiddleware wraps a ContentGenerator to intercept every call to
+// GenerateContent. Unlike Middleware, which wraps ThreadHandlers, it applies
+// to each provider call regardless of how the pipeline is structured, so it
+// suits cross-cutting concerns such as adding a tenant ID to every request or
+// scrubbing every response.
+type GeneratorMiddleware interface {
+	Wrap(next ContentGenerator) ContentGenerator
+}
+
+// GeneratorMiddlewareFunc is a function that implements the GeneratorMiddleware interface
+type GeneratorMiddlewareFunc func(next ContentGenerator) ContentGenerator
+
+func (f GeneratorMiddlewareFunc) Wrap(next ContentGenerator) ContentGenerator {
+	return f(next)
+}
+
+// WrapGenerator applies middleware to gen. The first middleware is the
+// outermost: it sees the request first and the response last.
+//
+// Example:
+//
+//	tenant := minds.GeneratorMiddlewareFunc(func(next minds.ContentGenerator) minds.ContentGenerator {
+//	    return minds.InterceptGenerator(next, func(ctx context.Context, req minds.Request) (minds.Response, error) {
+//	        req.Messages = append(minds.Messages{{Role: minds.RoleSystem, Content: "Tenant: acme"}}, req.Messages...)
+//	        return next.GenerateContent(ctx, req)
+//	    })
+//	})
+//	llm = minds.WrapGenerator(llm, tenant, scrubber)
+func WrapGenerator(gen ContentGenerator, middleware ...GeneratorMiddleware) ContentGenerator {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		gen = middleware[i].Wrap(gen)
+	}
+	return gen
+}
+
+// InterceptGenerator returns a ContentGenerator that calls fn for
+// GenerateContent and delegates ModelName and Close to next. It is the usual
+// building block for GeneratorMiddleware.
+func InterceptGenerator(next ContentGenerator, fn func(ctx context.Context, req Request) (Response, error)) ContentGenerator {
+	return &interceptedGenerator{next: next, fn: fn}
+}
+
+type interceptedGenerator struct {
+	next ContentGenerator
+	fn   func(ctx context.Context, req Request) (Response, error)
+}
+
+func (g *interceptedGenerator) ModelName() string {
+	return g.next.ModelName()
+}
+
+func (g *interceptedGenerator) GenerateContent(ctx context.Context, req Request) (Response, error) {
+	return g.fn(ctx, req)
+}
+
+func (g *interceptedGenerator) Close() {
+	g.next.Close()
+}
@@ -0,0 +1,97 @@
+package minds
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// stubResponse is a text Response that also reports usage.
+type stubResponse struct {
+	content string
+	usage   Usage
+}
+
+func (r stubResponse) String() string        { return r.content }
+func (r stubResponse) ToolCalls() []ToolCall { return nil }
+func (r stubResponse) Usage() Usage          { return r.usage }
+
+// stubGenerator answers every request with fn.
+type stubGenerator struct {
+	fn     func(req Request) (Response, error)
+	calls  int
+	closed bool
+}
+
+func (g *stubGenerator) ModelName() string { return "stub-model" }
+
+func (g *stubGenerator) GenerateContent(_ context.Context, req Request) (Response, error) {
+	g.calls++
+	return g.fn(req)
+}
+
+func (g *stubGenerator) Close() { g.closed = true }
+
+func TestWrapGenerator(t *testing.T) {
+	is := is.New(t)
+
+	echo := &stubGenerator{fn: func(req Request) (Response, error) {
+		return stubResponse{content: req.Messages.Last().Content}, nil
+	}}
+
+	var order []string
+	tag := func(name string) GeneratorMiddleware {
+		return GeneratorMiddlewareFunc(func(next ContentGenerator) ContentGenerator {
+			return InterceptGenerator(next, func(ctx context.Context, req Request) (Response, error) {
+				order = append(order, name)
+				req.Messages = append(req.Messages.Copy(), Message{Role: RoleUser, Content: req.Messages.Last().Content + " " + name})
+				resp, err := next.GenerateContent(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				return stubResponse{content: strings.ToUpper(resp.String())}, nil
+			})
+		})
+	}
+
+	gen := WrapGenerator(echo, tag("outer"), tag("inner"))
+	resp, err := gen.GenerateContent(context.Background(), NewRequest(Messages{{Role: RoleUser, Content: "hi"}}))
+	is.NoErr(err)
+	is.Equal(resp.String(), "HI OUTER INNER")
+	is.Equal(order, []string{"outer", "inner"})
+
+	is.Equal(gen.ModelName(), "stub-model")
+	gen.Close()
+	is.True(echo.closed)
+}
+
+func TestRequestMetadata(t *testing.T) {
+	is := is.New(t)
+
+	echo := &stubGenerator{fn: func(req Request) (Response, error) {
+		return stubResponse{content: req.Messages.Last().Content}, nil
+	}}
+
+	tenant := GeneratorMiddlewareFunc(func(next ContentGenerator) ContentGenerator {
+		return InterceptGenerator(next, func(ctx context.Context, req Request) (Response, error) {
+			resp, err := next.GenerateContent(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return stubResponse{content: resp.String() + " for " + req.Metadata["tenant"].(string)}, nil
+		})
+	})
+
+	tc := NewThreadContext(context.Background()).
+		WithMessages(Message{Role: RoleUser, Content: "hi"}).
+		WithMetadata(Metadata{"tenant": "acme"})
+
+	gen := WrapGenerator(echo, tenant)
+	resp, err := gen.GenerateContent(tc.Context(), NewRequest(tc.Messages(), WithRequestMetadata(tc.Metadata())))
+	is.NoErr(err)
+	is.Equal(resp.String(), "hi for acme")
+
+	is.Equal(NewRequest(tc.Messages()).Metadata, nil)
+}
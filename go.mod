@@ -5,5 +5,6 @@ go 1.18
 require (
 	github.com/google/uuid v1.6.0
 	github.com/matryer/is v1.4.1
+	golang.org/x/time v0.9.0
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ErrNotApproved is returned by an Approval handler when the approver rejects
+// the pending tool calls and no rejection handler is configured.
+var ErrNotApproved = errors.New("tool calls were not approved")
+
+// Approver decides whether the pending tool calls may proceed. It may block,
+// for example while waiting on a UI or webhook, and should return early when
+// ctx is done.
+type Approver func(ctx context.Context, calls []minds.ToolCall) (bool, error)
+
+// ApprovalOption configures an Approval handler.
+type ApprovalOption func(*Approval)
+
+// WithRejectionHandler routes rejected threads to handler instead of failing
+// with ErrNotApproved. The handler receives the Approval's next handler.
+func WithRejectionHandler(handler minds.ThreadHandler) ApprovalOption {
+	return func(a *Approval) {
+		a.onReject = handler
+	}
+}
+
+// Approval gates a thread on human approval of the tool calls in the last
+// response.
+type Approval struct {
+	name       string
+	approver   Approver
+	onReject   minds.ThreadHandler
+	middleware []minds.Middleware
+}
+
+// NewApproval creates a human-in-the-loop gate. When the last response
+// contains tool calls, approver is asked whether they may proceed. Approved
+// threads, and threads without tool calls, continue to the next handler.
+// Rejected threads fail with ErrNotApproved, or go to the handler set with
+// WithRejectionHandler.
+//
+// The tool calls are read from metadata under minds.LastToolCallsKey, which
+// providers set when they handle a thread, or else from the last message if
+// it is an assistant message.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - approver: Decides whether the tool calls may proceed
+//   - opts: Optional configuration such as WithRejectionHandler
+//
+// Returns:
+//   - A handler that only continues with approved tool calls
+//
+// Example:
+//
+//	approve := func(ctx context.Context, calls []minds.ToolCall) (bool, error) {
+//	    return promptUser(ctx, calls)
+//	}
+//	gate := handlers.NewApproval("approve", approve)
+//	agent := handlers.NewSequence("agent", llm, gate, runTools)
+func NewApproval(name string, approver Approver, opts ...ApprovalOption) *Approval {
+	a := &Approval{
+		name:       name,
+		approver:   approver,
+		middleware: []minds.Middleware{},
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Use applies middleware to the Approval handler. It wraps the approval
+// check.
+func (a *Approval) Use(middleware ...minds.Middleware) {
+	a.middleware = append(a.middleware, middleware...)
+}
+
+// With returns a new Approval handler with additional middleware, preserving existing state.
+func (a *Approval) With(middleware ...minds.Middleware) *Approval {
+	newApproval := &Approval{
+		name:       a.name,
+		approver:   a.approver,
+		onReject:   a.onReject,
+		middleware: append([]minds.Middleware{}, a.middleware...),
+	}
+	newApproval.Use(middleware...)
+	return newApproval
+}
+
+// HandleThread asks for approval of pending tool calls and routes the thread
+// according to the decision.
+func (a *Approval) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+		return a.gate(tc, next)
+	})
+
+	for i := len(a.middleware) - 1; i >= 0; i-- {
+		handler = a.middleware[i].Wrap(handler)
+	}
+
+	return handler.HandleThread(tc, next)
+}
+
+func (a *Approval) gate(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	calls := pendingToolCalls(tc)
+	if len(calls) > 0 {
+		if tc.Context().Err() != nil {
+			return tc, tc.Context().Err()
+		}
+
+		approved, err := a.approver(tc.Context(), calls)
+		if err != nil {
+			return tc, fmt.Errorf("%s: approval failed: %w", a.name, err)
+		}
+
+		if !approved {
+			if a.onReject != nil {
+				return a.onReject.HandleThread(tc, next)
+			}
+			return tc, fmt.Errorf("%s: %w", a.name, ErrNotApproved)
+		}
+	}
+
+	if next != nil {
+		return next.HandleThread(tc, nil)
+	}
+
+	return tc, nil
+}
+
+// pendingToolCalls returns the tool calls of the last response in the thread.
+func pendingToolCalls(tc minds.ThreadContext) []minds.ToolCall {
+	if calls, ok := tc.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall); ok {
+		return calls
+	}
+
+	messages := tc.Messages()
+	if len(messages) == 0 || messages.Last().Role != minds.RoleAssistant {
+		return nil
+	}
+
+	return messages.Last().ToolCalls
+}
+
+// String returns a string representation of the Approval handler.
+func (a *Approval) String() string {
+	return fmt.Sprintf("Approval(%s)", a.name)
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestApproval(t *testing.T) {
+	calls := []minds.ToolCall{{ID: "call_1", Function: minds.FunctionCall{Name: "delete_file"}}}
+
+	withCalls := func() minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Clean up"}).
+			With(minds.SetKeyValue(minds.LastToolCallsKey, calls))
+	}
+
+	mark := func(name string) minds.ThreadHandler {
+		return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc.With(minds.SetKeyValue("ran", name)), nil
+		})
+	}
+
+	decide := func(approved bool, seen *[]minds.ToolCall) handlers.Approver {
+		return func(_ context.Context, calls []minds.ToolCall) (bool, error) {
+			*seen = calls
+			return approved, nil
+		}
+	}
+
+	t.Run("approved calls proceed", func(t *testing.T) {
+		is := is.New(t)
+		var seen []minds.ToolCall
+
+		result, err := handlers.NewApproval("gate", decide(true, &seen)).HandleThread(withCalls(), mark("next"))
+		is.NoErr(err)
+		is.Equal(result.Metadata()["ran"], "next")
+		is.Equal(seen, calls)
+	})
+
+	t.Run("rejected calls fail", func(t *testing.T) {
+		is := is.New(t)
+		var seen []minds.ToolCall
+
+		result, err := handlers.NewApproval("gate", decide(false, &seen)).HandleThread(withCalls(), mark("next"))
+		is.True(errors.Is(err, handlers.ErrNotApproved))
+		is.Equal(result.Metadata()["ran"], nil)
+	})
+
+	t.Run("rejected calls go to the rejection handler", func(t *testing.T) {
+		is := is.New(t)
+		var seen []minds.ToolCall
+
+		gate := handlers.NewApproval("gate", decide(false, &seen), handlers.WithRejectionHandler(mark("rejected")))
+		result, err := gate.HandleThread(withCalls(), mark("next"))
+		is.NoErr(err)
+		is.Equal(result.Metadata()["ran"], "rejected")
+	})
+
+	t.Run("threads without tool calls skip the approver", func(t *testing.T) {
+		is := is.New(t)
+		asked := false
+		approver := func(context.Context, []minds.ToolCall) (bool, error) {
+			asked = true
+			return false, nil
+		}
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleAssistant, Content: "Done"})
+		result, err := handlers.NewApproval("gate", approver).HandleThread(tc, mark("next"))
+		is.NoErr(err)
+		is.True(!asked)
+		is.Equal(result.Metadata()["ran"], "next")
+	})
+
+	t.Run("tool calls are read from the last assistant message", func(t *testing.T) {
+		is := is.New(t)
+		var seen []minds.ToolCall
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleAssistant, ToolCalls: calls})
+		_, err := handlers.NewApproval("gate", decide(true, &seen)).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(seen, calls)
+	})
+
+	t.Run("approver errors are returned", func(t *testing.T) {
+		is := is.New(t)
+		approver := func(context.Context, []minds.ToolCall) (bool, error) {
+			return false, errHandlerFailed
+		}
+
+		_, err := handlers.NewApproval("gate", approver).HandleThread(withCalls(), mark("next"))
+		is.True(errors.Is(err, errHandlerFailed))
+		is.True(!errors.Is(err, handlers.ErrNotApproved))
+	})
+}
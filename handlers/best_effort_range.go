@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// BestEffortResultsKey is the thread metadata key holding the []ItemResult
+// recorded by BestEffortRange.
+const BestEffortResultsKey = "best_effort_results"
+
+// ItemResult is the outcome of one value processed by BestEffortRange.
+type ItemResult struct {
+	Value any
+	// Err is the error the handler returned for Value, or nil if it
+	// succeeded.
+	Err error
+}
+
+// BestEffortRange executes a handler for each of a list of values, like
+// Range, but carries on past values that fail.
+type BestEffortRange struct {
+	name       string
+	handler    minds.ThreadHandler
+	values     []any
+	middleware []minds.Middleware
+}
+
+// NewBestEffortRange creates a handler for batches where some failures are
+// acceptable, such as bulk classification. Like Range, it runs handler once
+// for each value, with the value in metadata under "range_value". Unlike
+// Range, which stops at the first error, it processes every value and
+// records the outcome of each, in order, as an []ItemResult under
+// BestEffortResultsKey. When the handler fails for a value, the thread it
+// returned is discarded and the next value continues from the thread as it
+// was before the failure.
+//
+// HandleThread returns an error only if the context is canceled; check the
+// results for the errors of individual values.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - handler: The handler to execute for each value
+//   - values: Values to iterate over
+//
+// Returns:
+//   - A handler that processes every value and records each outcome
+//
+// Example:
+//
+//	batch := handlers.NewBestEffortRange("classify", classifier, tickets...)
+//	result, err := batch.HandleThread(tc, nil)
+//	for _, item := range result.Metadata()[handlers.BestEffortResultsKey].([]handlers.ItemResult) {
+//	    if item.Err != nil {
+//	        log.Printf("ticket %v: %v", item.Value, item.Err)
+//	    }
+//	}
+func NewBestEffortRange(name string, handler minds.ThreadHandler, values ...any) *BestEffortRange {
+	if handler == nil {
+		panic(fmt.Sprintf("%s: handler cannot be nil", name))
+	}
+
+	return &BestEffortRange{
+		name:       name,
+		handler:    handler,
+		values:     values,
+		middleware: make([]minds.Middleware, 0),
+	}
+}
+
+// Use adds middleware that will wrap each iteration.
+func (r *BestEffortRange) Use(middleware ...minds.Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// With returns a new BestEffortRange handler with additional middleware, preserving existing state.
+func (r *BestEffortRange) With(middleware ...minds.Middleware) *BestEffortRange {
+	newRange := &BestEffortRange{
+		name:       r.name,
+		handler:    r.handler,
+		values:     r.values,
+		middleware: append([]minds.Middleware{}, r.middleware...),
+	}
+	newRange.Use(middleware...)
+	return newRange
+}
+
+// HandleThread processes every value, records the results and passes the
+// thread on.
+func (r *BestEffortRange) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	current := tc
+	results := make([]ItemResult, 0, len(r.values))
+
+	for _, value := range r.values {
+		if current.Context().Err() != nil {
+			return current, current.Context().Err()
+		}
+
+		meta := current.Metadata()
+		meta["range_value"] = value
+		iteration := current.WithMetadata(meta)
+
+		wrappedHandler := r.handler
+		for i := len(r.middleware) - 1; i >= 0; i-- {
+			wrappedHandler = r.middleware[i].Wrap(wrappedHandler)
+		}
+
+		result, err := wrappedHandler.HandleThread(iteration, nil)
+		if err != nil {
+			results = append(results, ItemResult{Value: value, Err: fmt.Errorf("%s: %w", r.name, err)})
+			continue
+		}
+
+		current = result
+		results = append(results, ItemResult{Value: value})
+	}
+
+	current = current.With(minds.SetKeyValue(BestEffortResultsKey, results))
+
+	if next != nil {
+		return next.HandleThread(current, nil)
+	}
+
+	return current, nil
+}
+
+// String returns a string representation of the BestEffortRange handler.
+func (r *BestEffortRange) String() string {
+	return fmt.Sprintf("BestEffortRange(%s, %d values)", r.name, len(r.values))
+}
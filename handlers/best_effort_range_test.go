@@ -0,0 +1,57 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestBestEffortRange(t *testing.T) {
+	t.Run("processes every value and records the outcomes", func(t *testing.T) {
+		is := is.New(t)
+
+		classify := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			value := tc.Metadata()["range_value"].(string)
+			result := tc.With(minds.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: value}))
+			if value == "bad" {
+				return result, errHandlerFailed
+			}
+			return result, nil
+		})
+
+		tc := minds.NewThreadContext(context.Background())
+		result, err := handlers.NewBestEffortRange("batch", classify, "a", "bad", "c").HandleThread(tc, nil)
+		is.NoErr(err)
+
+		results := result.Metadata()[handlers.BestEffortResultsKey].([]handlers.ItemResult)
+		is.Equal(len(results), 3)
+		is.Equal(results[0].Value, "a")
+		is.NoErr(results[0].Err)
+		is.Equal(results[1].Value, "bad")
+		is.True(errors.Is(results[1].Err, errHandlerFailed))
+		is.NoErr(results[2].Err)
+
+		// The failed value's changes are discarded.
+		msgs := result.Messages()
+		is.Equal(len(msgs), 2)
+		is.Equal(msgs[0].Content, "a")
+		is.Equal(msgs[1].Content, "c")
+	})
+
+	t.Run("stops when the context is canceled", func(t *testing.T) {
+		is := is.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		handler := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			cancel()
+			return tc, nil
+		})
+
+		_, err := handlers.NewBestEffortRange("batch", handler, 1, 2).HandleThread(minds.NewThreadContext(ctx), nil)
+		is.True(errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/chriscow/minds"
+)
+
+// ChainStep is one prompt of a Chain.
+type ChainStep struct {
+	// Name identifies the step. Its output is stored in the thread metadata
+	// under Name, where later prompts can reference it.
+	Name string
+	// Prompt is a text/template executed with the thread metadata, so
+	// {{.outline}} inserts the output of the step named "outline".
+	Prompt string
+	// Generator answers the prompt.
+	Generator minds.ContentGenerator
+}
+
+type chainStep struct {
+	ChainStep
+	tmpl *template.Template
+}
+
+// Chain runs a series of prompts where each one can use the outputs of the
+// ones before it.
+type Chain struct {
+	name       string
+	steps      []chainStep
+	middleware []minds.Middleware
+}
+
+// NewChain creates a handler that runs steps in order. Each step's prompt is
+// executed as a template with the thread metadata, sent to its generator as
+// a user message after the thread's messages, and the response text stored
+// in the metadata under the step's name. Later prompts reference earlier
+// outputs, or any other metadata, by key. Referencing a key that is not set
+// is an error. The thread's messages are not changed; read the outputs from
+// the metadata. NewChain panics if a step has no name or generator, if two
+// steps share a name, or if a prompt is not a valid template.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - steps: The prompts to run, in order
+//
+// Returns:
+//   - A handler that stores the output of each step in metadata
+//
+// Example:
+//
+//	chain := handlers.NewChain("article", []handlers.ChainStep{
+//	    {Name: "outline", Prompt: "Outline an article about {{.topic}}.", Generator: llm},
+//	    {Name: "draft", Prompt: "Write the article from this outline:\n{{.outline}}", Generator: llm},
+//	})
+//	result, err := chain.HandleThread(tc.With(minds.SetKeyValue("topic", "Go generics")), nil)
+//	draft := result.Metadata()["draft"].(string)
+func NewChain(name string, steps []ChainStep) *Chain {
+	seen := map[string]bool{}
+	parsed := make([]chainStep, len(steps))
+	for i, step := range steps {
+		if step.Name == "" {
+			panic(fmt.Sprintf("%s: step %d has no name", name, i))
+		}
+		if seen[step.Name] {
+			panic(fmt.Sprintf("%s: duplicate step %q", name, step.Name))
+		}
+		seen[step.Name] = true
+
+		if step.Generator == nil {
+			panic(fmt.Sprintf("%s: step %q: generator cannot be nil", name, step.Name))
+		}
+
+		tmpl, err := template.New(step.Name).Option("missingkey=error").Parse(step.Prompt)
+		if err != nil {
+			panic(fmt.Sprintf("%s: step %q: invalid prompt template: %v", name, step.Name, err))
+		}
+
+		parsed[i] = chainStep{ChainStep: step, tmpl: tmpl}
+	}
+
+	return &Chain{
+		name:       name,
+		steps:      parsed,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the Chain handler. It wraps the whole chain.
+func (c *Chain) Use(middleware ...minds.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// With returns a new Chain with additional middleware, preserving existing state.
+func (c *Chain) With(middleware ...minds.Middleware) *Chain {
+	newChain := &Chain{
+		name:       c.name,
+		steps:      c.steps,
+		middleware: append([]minds.Middleware{}, c.middleware...),
+	}
+	newChain.Use(middleware...)
+	return newChain
+}
+
+// HandleThread runs each step in order and passes the thread on.
+func (c *Chain) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return c.run(tc)
+	})
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (c *Chain) run(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	current := tc
+	for _, step := range c.steps {
+		if err := current.Context().Err(); err != nil {
+			return tc, err
+		}
+
+		var prompt strings.Builder
+		if err := step.tmpl.Execute(&prompt, map[string]any(current.Metadata())); err != nil {
+			return tc, fmt.Errorf("%s: step %q: failed to execute prompt template: %w", c.name, step.Name, err)
+		}
+
+		messages := append(current.Messages(), minds.Message{Role: minds.RoleUser, Content: prompt.String()})
+		req := minds.NewRequest(messages, minds.WithRequestMetadata(current.Metadata()))
+
+		resp, err := step.Generator.GenerateContent(current.Context(), req)
+		if err != nil {
+			return tc, fmt.Errorf("%s: step %q: error generating content: %w", c.name, step.Name, err)
+		}
+
+		current = current.With(minds.SetKeyValue(step.Name, resp.String()))
+	}
+
+	return current, nil
+}
+
+// String returns a string representation of the Chain handler.
+func (c *Chain) String() string {
+	return fmt.Sprintf("Chain(%s, %d steps)", c.name, len(c.steps))
+}
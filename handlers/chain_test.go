@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestChain(t *testing.T) {
+	newThread := func() minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "I need an article."}).
+			With(minds.SetKeyValue("topic", "Go generics"))
+	}
+
+	t.Run("feeds outputs to later steps", func(t *testing.T) {
+		is := is.New(t)
+		outliner := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("1. Intro 2. Constraints"), nil
+		}}
+		writer := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("Generics arrived in Go 1.18."), nil
+		}}
+
+		chain := handlers.NewChain("article", []handlers.ChainStep{
+			{Name: "outline", Prompt: "Outline an article about {{.topic}}.", Generator: outliner},
+			{Name: "draft", Prompt: "Write the article from this outline:\n{{.outline}}", Generator: writer},
+		})
+
+		result, err := chain.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(result.Metadata()["outline"], "1. Intro 2. Constraints")
+		is.Equal(result.Metadata()["draft"], "Generics arrived in Go 1.18.")
+		is.Equal(result.Messages(), newThread().Messages()) // messages unchanged
+
+		sent := outliner.requests[0].Messages
+		is.Equal(len(sent), 2)
+		is.Equal(sent[0].Content, "I need an article.")
+		is.Equal(sent[1].Role, minds.RoleUser)
+		is.Equal(sent[1].Content, "Outline an article about Go generics.")
+		is.Equal(writer.requests[0].Messages.Last().Content, "Write the article from this outline:\n1. Intro 2. Constraints")
+	})
+
+	t.Run("fails on unknown references", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{}
+
+		chain := handlers.NewChain("article", []handlers.ChainStep{
+			{Name: "draft", Prompt: "Expand {{.outline}}", Generator: provider},
+		})
+
+		_, err := chain.HandleThread(newThread(), nil)
+		is.True(err != nil)
+		is.Equal(provider.Calls(), 0)
+	})
+
+	t.Run("stops at the first failing step", func(t *testing.T) {
+		is := is.New(t)
+		failing := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return nil, errHandlerFailed
+		}}
+		after := &recordingProvider{}
+
+		chain := handlers.NewChain("article", []handlers.ChainStep{
+			{Name: "outline", Prompt: "Outline it.", Generator: failing},
+			{Name: "draft", Prompt: "{{.outline}}", Generator: after},
+		})
+
+		tc := newThread()
+		result, err := chain.HandleThread(tc, nil)
+		is.True(errors.Is(err, errHandlerFailed))
+		is.Equal(after.Calls(), 0)
+		_, ok := result.Metadata()["outline"]
+		is.True(!ok)
+	})
+
+	t.Run("rejects invalid steps", func(t *testing.T) {
+		provider := &recordingProvider{}
+		for name, steps := range map[string][]handlers.ChainStep{
+			"no name":      {{Prompt: "Hi", Generator: provider}},
+			"no generator": {{Name: "a", Prompt: "Hi"}},
+			"duplicate":    {{Name: "a", Prompt: "Hi", Generator: provider}, {Name: "a", Prompt: "Hi", Generator: provider}},
+			"bad template": {{Name: "a", Prompt: "{{.unclosed", Generator: provider}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				is := is.New(t)
+				defer func() {
+					is.True(recover() != nil)
+				}()
+				handlers.NewChain("chain", steps)
+			})
+		}
+	})
+}
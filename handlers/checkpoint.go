@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/chriscow/minds"
+)
+
+// CheckpointState is a thread saved by Checkpoint after a step completes.
+type CheckpointState struct {
+	// Step is the number of steps completed.
+	Step     int            `json:"step"`
+	Messages minds.Messages `json:"messages"`
+	Metadata minds.Metadata `json:"metadata,omitempty"`
+}
+
+// ThreadStore persists the checkpoints of Checkpoint handlers. It must be
+// safe for concurrent use.
+type ThreadStore interface {
+	// Save stores state under key, replacing any state saved before.
+	Save(ctx context.Context, key string, state CheckpointState) error
+	// Load returns the state saved under key and whether there was any.
+	Load(ctx context.Context, key string) (CheckpointState, bool, error)
+}
+
+// NewMemoryThreadStore returns a ThreadStore that keeps checkpoints in
+// memory, for resuming within a process, such as when retrying a failed
+// pipeline.
+func NewMemoryThreadStore() ThreadStore {
+	return &memoryThreadStore{states: make(map[string]CheckpointState)}
+}
+
+type memoryThreadStore struct {
+	mu     sync.Mutex
+	states map[string]CheckpointState
+}
+
+func (s *memoryThreadStore) Save(_ context.Context, key string, state CheckpointState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.Messages = state.Messages.Copy()
+	state.Metadata = state.Metadata.Copy()
+	s.states[key] = state
+	return nil
+}
+
+func (s *memoryThreadStore) Load(_ context.Context, key string) (CheckpointState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[key]
+	if !ok {
+		return CheckpointState{}, false, nil
+	}
+
+	state.Messages = state.Messages.Copy()
+	state.Metadata = state.Metadata.Copy()
+	return state, true, nil
+}
+
+// NewKVThreadStore returns a ThreadStore that saves checkpoints as JSON in
+// kv, for resuming across processes. The thread metadata must be JSON
+// serializable, and values are read back as JSON types: numbers as float64,
+// objects as map[string]any. kv's Load must return an empty value without an
+// error for keys that were never saved.
+func NewKVThreadStore(kv minds.KVStore) ThreadStore {
+	return &kvThreadStore{kv: kv}
+}
+
+type kvThreadStore struct {
+	kv minds.KVStore
+}
+
+func (s *kvThreadStore) Save(ctx context.Context, key string, state CheckpointState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.kv.Save(ctx, []byte(key), data)
+}
+
+func (s *kvThreadStore) Load(ctx context.Context, key string) (CheckpointState, bool, error) {
+	data, err := s.kv.Load(ctx, []byte(key))
+	if err != nil || len(data) == 0 {
+		return CheckpointState{}, false, err
+	}
+
+	var state CheckpointState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return CheckpointState{}, false, err
+	}
+	return state, true, nil
+}
+
+// Checkpoint runs a series of handlers like a sequence, saving the thread
+// after each one so that a failed run can resume where it stopped.
+type Checkpoint struct {
+	name       string
+	store      ThreadStore
+	key        string
+	handlers   []minds.ThreadHandler
+	middleware []minds.Middleware
+}
+
+// NewCheckpoint creates a handler that makes a pipeline resumable. It runs
+// handlers in order and, after each one succeeds, saves the thread to store
+// under key along with the number of steps completed. When it runs again with
+// a checkpoint saved under key, it restores the saved messages and metadata
+// and skips the steps already completed, so a failure in step 4 of 6 reruns
+// only steps 4 to 6. Once every step has completed, running again returns the
+// saved thread without running any step, which lets batch jobs skip inputs
+// they already processed.
+//
+// Use a key that identifies the input, such as a document id. Checkpoints
+// record only how many steps completed, so change the key when the steps
+// change. Middleware wraps each step, as in a sequence.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - store: Where checkpoints are saved
+//   - key: Identifies the run to resume
+//   - handlers: The steps to run in order
+//
+// Returns:
+//   - A handler that runs the remaining steps, saving the thread after each
+//
+// Example:
+//
+//	store := handlers.NewKVThreadStore(kv)
+//	pipeline := handlers.NewCheckpoint("ingest", store, "doc-"+doc.ID, extract, classify, summarize)
+//	result, err := pipeline.HandleThread(tc, nil)
+func NewCheckpoint(name string, store ThreadStore, key string, handlers ...minds.ThreadHandler) *Checkpoint {
+	if store == nil {
+		panic(fmt.Sprintf("%s: store cannot be nil", name))
+	}
+
+	return &Checkpoint{
+		name:       name,
+		store:      store,
+		key:        key,
+		handlers:   handlers,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use adds middleware that will wrap each step.
+func (c *Checkpoint) Use(middleware ...minds.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// With returns a new Checkpoint handler with additional middleware, preserving existing state.
+func (c *Checkpoint) With(middleware ...minds.Middleware) *Checkpoint {
+	newCheckpoint := &Checkpoint{
+		name:       c.name,
+		store:      c.store,
+		key:        c.key,
+		handlers:   c.handlers,
+		middleware: append([]minds.Middleware{}, c.middleware...),
+	}
+	newCheckpoint.Use(middleware...)
+	return newCheckpoint
+}
+
+// HandleThread resumes from the last checkpoint, runs the remaining steps and
+// passes the thread on.
+func (c *Checkpoint) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	state, ok, err := c.store.Load(tc.Context(), c.key)
+	if err != nil {
+		return tc, fmt.Errorf("%s: failed to load checkpoint: %w", c.name, err)
+	}
+
+	current := tc
+	start := 0
+	if ok {
+		current = tc.WithMessages(state.Messages...).WithMetadata(state.Metadata)
+		start = state.Step
+	}
+
+	for i := start; i < len(c.handlers); i++ {
+		handler := c.handlers[i]
+		for j := len(c.middleware) - 1; j >= 0; j-- {
+			handler = c.middleware[j].Wrap(handler)
+		}
+
+		current, err = handler.HandleThread(current, nil)
+		if err != nil {
+			return current, fmt.Errorf("%s: step %d: %w", c.name, i+1, err)
+		}
+
+		state := CheckpointState{Step: i + 1, Messages: current.Messages(), Metadata: current.Metadata()}
+		if err := c.store.Save(current.Context(), c.key, state); err != nil {
+			return current, fmt.Errorf("%s: failed to save checkpoint: %w", c.name, err)
+		}
+	}
+
+	if next != nil {
+		return next.HandleThread(current, nil)
+	}
+
+	return current, nil
+}
+
+// String returns a string representation of the Checkpoint handler.
+func (c *Checkpoint) String() string {
+	return fmt.Sprintf("Checkpoint(%s)", c.name)
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+// mapKVStore is an in-memory minds.KVStore.
+type mapKVStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *mapKVStore) Save(_ context.Context, key []byte, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[string(key)] = value
+	return nil
+}
+
+func (s *mapKVStore) Load(_ context.Context, key []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[string(key)], nil
+}
+
+func TestCheckpoint(t *testing.T) {
+	stores := map[string]func() handlers.ThreadStore{
+		"memory": handlers.NewMemoryThreadStore,
+		"kv": func() handlers.ThreadStore {
+			return handlers.NewKVThreadStore(&mapKVStore{data: map[string][]byte{}})
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			store := newStore()
+
+			runs := map[string]int{}
+			failStep2 := true
+			step := func(label string) minds.ThreadHandler {
+				return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+					runs[label]++
+					if label == "2" && failStep2 {
+						return tc, errHandlerFailed
+					}
+					return tc.With(
+						minds.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: "step " + label}),
+						minds.SetKeyValue("last", label),
+					), nil
+				})
+			}
+
+			pipeline := handlers.NewCheckpoint("pipeline", store, "doc-1", step("1"), step("2"), step("3"))
+			tc := minds.NewThreadContext(context.Background()).
+				WithMessages(minds.Message{Role: minds.RoleUser, Content: "go"})
+
+			_, err := pipeline.HandleThread(tc, nil)
+			is.True(errors.Is(err, errHandlerFailed))
+
+			// Resume: step 1 is skipped.
+			failStep2 = false
+			result, err := pipeline.HandleThread(tc, nil)
+			is.NoErr(err)
+			is.Equal(runs, map[string]int{"1": 1, "2": 2, "3": 1})
+
+			msgs := result.Messages()
+			is.Equal(len(msgs), 4)
+			is.Equal(msgs[1].Content, "step 1")
+			is.Equal(msgs[3].Content, "step 3")
+			is.Equal(result.Metadata()["last"], "3")
+
+			// Complete: nothing runs again.
+			result, err = pipeline.HandleThread(tc, nil)
+			is.NoErr(err)
+			is.Equal(runs, map[string]int{"1": 1, "2": 2, "3": 1})
+			is.Equal(len(result.Messages()), 4)
+
+			// Another key starts over.
+			other := handlers.NewCheckpoint("pipeline", store, "doc-2", step("1"))
+			_, err = other.HandleThread(tc, nil)
+			is.NoErr(err)
+			is.Equal(runs["1"], 2)
+		})
+	}
+}
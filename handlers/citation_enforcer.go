@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chriscow/minds"
+)
+
+const (
+	// SourcesKey is the thread metadata key holding the []Source retrieved
+	// for the conversation, read by CitationEnforcer.
+	SourcesKey = "sources"
+
+	// CitationsKey is the thread metadata key where CitationEnforcer stores
+	// the IDs of the sources cited by the response, as a []string in order of
+	// first citation.
+	CitationsKey = "citations"
+)
+
+// citationEnforcerAttempts is the number of times CitationEnforcer generates
+// a response before giving up when citations keep missing.
+const citationEnforcerAttempts = 3
+
+// ErrMissingCitations is returned by CitationEnforcer when the response
+// still cites none of the sources after every attempt.
+var ErrMissingCitations = errors.New("response does not cite its sources")
+
+// citationMarker matches bracketed citations such as [2] or [doc-1, doc-3].
+var citationMarker = regexp.MustCompile(`\[([^\[\]]+)\]`)
+
+// Source is a document retrieved as context for an answer.
+type Source struct {
+	ID      string
+	Content string
+}
+
+// CitationEnforcer generates the next assistant message and ensures it cites
+// the sources retrieved for the conversation.
+type CitationEnforcer struct {
+	name       string
+	generator  minds.ContentGenerator
+	middleware []minds.Middleware
+}
+
+// NewCitationEnforcer creates a handler for retrieval-augmented answers that
+// must be grounded in their sources. The sources are read from the []Source
+// stored under SourcesKey, typically by the retrieval step, and listed in a
+// system instruction that asks the model to cite them by ID in brackets,
+// such as [2]. A response cites a source if it contains its marker or, for
+// JSON responses, lists its ID in a "citations" field. Markers that match no
+// source are ignored. Responses without citations are regenerated with a
+// stricter instruction, and the handler fails with ErrMissingCitations if
+// they are still missing after three attempts. The IDs of the cited sources
+// are stored under CitationsKey. Threads without sources are answered
+// without the check.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM used to answer
+//
+// Returns:
+//   - A handler that appends an answer citing its sources
+//
+// Example:
+//
+//	tc = tc.With(minds.SetKeyValue(handlers.SourcesKey, []handlers.Source{
+//	    {ID: "1", Content: "The Eiffel Tower is 330 m tall."},
+//	}))
+//	answer := handlers.NewCitationEnforcer("answer", llm)
+//	result, err := answer.HandleThread(tc, nil)
+func NewCitationEnforcer(name string, generator minds.ContentGenerator) *CitationEnforcer {
+	if generator == nil {
+		panic(fmt.Sprintf("%s: generator cannot be nil", name))
+	}
+
+	return &CitationEnforcer{
+		name:       name,
+		generator:  generator,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the CitationEnforcer handler.
+func (c *CitationEnforcer) Use(middleware ...minds.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// With returns a new CitationEnforcer with additional middleware, preserving existing state.
+func (c *CitationEnforcer) With(middleware ...minds.Middleware) *CitationEnforcer {
+	newEnforcer := &CitationEnforcer{
+		name:       c.name,
+		generator:  c.generator,
+		middleware: append([]minds.Middleware{}, c.middleware...),
+	}
+	newEnforcer.Use(middleware...)
+	return newEnforcer
+}
+
+// HandleThread appends an answer citing its sources and passes the thread
+// on.
+func (c *CitationEnforcer) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return c.respond(tc)
+	})
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (c *CitationEnforcer) respond(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	ctx := tc.Context()
+	sources, _ := tc.Metadata()[SourcesKey].([]Source)
+
+	messages := tc.Messages()
+	if len(sources) > 0 {
+		messages = append(messages, minds.Message{Role: minds.RoleSystem, Content: sourcesInstruction(sources)})
+	}
+
+	for attempt := 0; attempt < citationEnforcerAttempts; attempt++ {
+		if ctx.Err() != nil {
+			return tc, ctx.Err()
+		}
+
+		resp, err := c.generator.GenerateContent(ctx, minds.NewRequest(messages, minds.WithRequestMetadata(tc.Metadata())))
+		if err != nil {
+			return tc, fmt.Errorf("%s: error generating content: %w", c.name, err)
+		}
+
+		content := resp.String()
+		citations := findCitations(content, sources)
+		if len(sources) > 0 && len(citations) == 0 {
+			messages = append(messages,
+				minds.Message{Role: minds.RoleAssistant, Content: content},
+				minds.Message{Role: minds.RoleSystem, Content: "Your previous response did not cite any of the sources. " +
+					"Rewrite it so that every claim is supported by a source and cited with the source's ID in " +
+					"brackets, such as [" + sources[0].ID + "]. Only use information found in the sources."},
+			)
+			continue
+		}
+
+		newTc := tc.Clone()
+		newTc.AppendMessages(minds.Message{
+			Role:    minds.RoleAssistant,
+			Content: content,
+		})
+		if len(sources) > 0 {
+			newTc.SetKeyValue(CitationsKey, citations)
+		}
+		return newTc, nil
+	}
+
+	return tc, fmt.Errorf("%s: %w after %d attempts", c.name, ErrMissingCitations, citationEnforcerAttempts)
+}
+
+// sourcesInstruction lists the sources for the model and asks it to cite
+// them.
+func sourcesInstruction(sources []Source) string {
+	var b strings.Builder
+	b.WriteString("Answer using the sources below. Cite the source of each claim with its ID in brackets, " +
+		"such as [" + sources[0].ID + "].\n")
+	for _, s := range sources {
+		fmt.Fprintf(&b, "\n[%s]\n%s\n", s.ID, s.Content)
+	}
+	return b.String()
+}
+
+// findCitations returns the IDs of the sources cited in content, by marker or
+// in the "citations" field of a JSON response, in order of first citation.
+func findCitations(content string, sources []Source) []string {
+	known := map[string]bool{}
+	for _, s := range sources {
+		known[s.ID] = true
+	}
+
+	var ids []string
+	var structured struct {
+		Citations []string `json:"citations"`
+	}
+	if json.Unmarshal([]byte(content), &structured) == nil {
+		ids = append(ids, structured.Citations...)
+	}
+	for _, match := range citationMarker.FindAllStringSubmatch(content, -1) {
+		for _, id := range strings.Split(match[1], ",") {
+			ids = append(ids, strings.TrimSpace(id))
+		}
+	}
+
+	citations := []string{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if known[id] && !seen[id] {
+			seen[id] = true
+			citations = append(citations, id)
+		}
+	}
+	return citations
+}
+
+// String returns a string representation of the CitationEnforcer handler.
+func (c *CitationEnforcer) String() string {
+	return fmt.Sprintf("CitationEnforcer(%s)", c.name)
+}
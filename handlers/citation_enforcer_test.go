@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestCitationEnforcer(t *testing.T) {
+	sources := []handlers.Source{
+		{ID: "1", Content: "The Eiffel Tower is 330 m tall."},
+		{ID: "2", Content: "It was completed in 1889."},
+	}
+
+	newThread := func() minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "How tall is the Eiffel Tower?"}).
+			With(minds.SetKeyValue(handlers.SourcesKey, sources))
+	}
+
+	t.Run("accepts cited answers", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("It is 330 m tall [1], finished in 1889 [2, 1] [7]."), nil
+		}}
+
+		result, err := handlers.NewCitationEnforcer("answer", provider).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 1)
+		is.Equal(result.Messages().Last().Content, "It is 330 m tall [1], finished in 1889 [2, 1] [7].")
+		is.Equal(result.Metadata()[handlers.CitationsKey], []string{"1", "2"}) // unknown [7] ignored
+
+		instruction := provider.requests[0].Messages.Last()
+		is.Equal(instruction.Role, minds.RoleSystem)
+		is.True(strings.Contains(instruction.Content, "[2]\nIt was completed in 1889."))
+	})
+
+	t.Run("reads structured citations", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(`{"answer": "330 m", "citations": ["1"]}`), nil
+		}}
+
+		result, err := handlers.NewCitationEnforcer("answer", provider).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(result.Metadata()[handlers.CitationsKey], []string{"1"})
+	})
+
+	t.Run("regenerates uncited answers", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			if len(req.Messages) > 2 {
+				return newMockTextResponse("It is 330 m tall [1]."), nil
+			}
+			return newMockTextResponse("It is 330 m tall."), nil
+		}}
+
+		result, err := handlers.NewCitationEnforcer("answer", provider).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 2)
+		is.Equal(result.Messages().Last().Content, "It is 330 m tall [1].")
+		is.Equal(len(result.Messages()), 2) // the rejected answer is not kept
+
+		retry := provider.requests[1].Messages
+		is.Equal(retry[len(retry)-2].Content, "It is 330 m tall.")
+		is.Equal(retry.Last().Role, minds.RoleSystem)
+	})
+
+	t.Run("fails after repeated missing citations", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("It is very tall."), nil
+		}}
+
+		_, err := handlers.NewCitationEnforcer("answer", provider).HandleThread(newThread(), nil)
+		is.True(errors.Is(err, handlers.ErrMissingCitations))
+		is.Equal(provider.Calls(), 3)
+	})
+
+	t.Run("answers threads without sources", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("I don't know."), nil
+		}}
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "How tall is the Eiffel Tower?"})
+		result, err := handlers.NewCitationEnforcer("answer", provider).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "I don't know.")
+		is.Equal(len(provider.requests[0].Messages), 1)
+		_, ok := result.Metadata()[handlers.CitationsKey]
+		is.True(!ok)
+	})
+
+	t.Run("generator errors", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return nil, errHandlerFailed
+		}}
+
+		_, err := handlers.NewCitationEnforcer("answer", provider).HandleThread(newThread(), nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/internal/codefence"
+)
+
+// CodeExtractor runs the code blocks in the last assistant message through a
+// tool and adds the output to the thread.
+type CodeExtractor struct {
+	name       string
+	lang       string
+	executor   minds.Tool
+	middleware []minds.Middleware
+}
+
+// NewCodeExtractor creates a handler for the "model writes code, we run it"
+// loop. It finds the code blocks fenced with ```lang in the last assistant
+// message and calls executor with each one, in order, as {"input": code},
+// the arguments taken by the calculator tools. Each result is appended to the
+// thread as a RoleFunction message named after the executor, ready for the
+// model to read on its next turn.
+//
+// If the executor fails, the error is reported to the model in the message
+// instead, as HandleFunctionCalls does, so that it can fix its code. If the
+// last message is not from the assistant or has no matching blocks, the
+// thread is passed through unchanged.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - lang: The language tag of the blocks to run, e.g. "lua". Blocks without
+//     a tag are not run unless lang is empty, which runs every block.
+//   - executor: The tool that runs the code
+//
+// Returns:
+//   - A handler that appends the output of each code block
+//
+// Example:
+//
+//	calc, _ := calculator.NewCalculator(calculator.Lua)
+//	interpreter := handlers.NewSequence("interpreter", llm, handlers.NewCodeExtractor("run", "lua", calc), llm)
+func NewCodeExtractor(name string, lang string, executor minds.Tool) *CodeExtractor {
+	if executor == nil {
+		panic(fmt.Sprintf("%s: executor cannot be nil", name))
+	}
+
+	return &CodeExtractor{
+		name:       name,
+		lang:       lang,
+		executor:   executor,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the CodeExtractor handler.
+func (c *CodeExtractor) Use(middleware ...minds.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// With returns a new CodeExtractor handler with additional middleware, preserving existing state.
+func (c *CodeExtractor) With(middleware ...minds.Middleware) *CodeExtractor {
+	newExtractor := &CodeExtractor{
+		name:       c.name,
+		lang:       c.lang,
+		executor:   c.executor,
+		middleware: append([]minds.Middleware{}, c.middleware...),
+	}
+	newExtractor.Use(middleware...)
+	return newExtractor
+}
+
+// HandleThread runs the code blocks and passes the thread on.
+func (c *CodeExtractor) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return c.run(tc)
+	})
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (c *CodeExtractor) run(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	last := tc.Messages().Last()
+	if last.Role != minds.RoleAssistant {
+		return tc, nil
+	}
+
+	blocks := extractCodeBlocks(last.Content, c.lang)
+	if len(blocks) == 0 {
+		return tc, nil
+	}
+
+	ctx := tc.Context()
+	results := make(minds.Messages, 0, len(blocks))
+	for _, code := range blocks {
+		if ctx.Err() != nil {
+			return tc, ctx.Err()
+		}
+
+		args, err := json.Marshal(struct {
+			Input string `json:"input"`
+		}{Input: code})
+		if err != nil {
+			return tc, fmt.Errorf("%s: failed to marshal code: %w", c.name, err)
+		}
+
+		output, err := c.executor.Call(ctx, args)
+		if err != nil {
+			output = []byte(fmt.Sprintf("ERROR: Tool `%s` failed: %v", c.executor.Name(), err))
+		}
+
+		results = append(results, minds.Message{
+			Role:    minds.RoleFunction,
+			Name:    c.executor.Name(),
+			Content: string(output),
+		})
+	}
+
+	return tc.With(minds.AppendMessages(results...)), nil
+}
+
+// extractCodeBlocks returns the bodies of the blocks in content fenced with
+// ```lang. If lang is empty, every block is returned.
+func extractCodeBlocks(content, lang string) []string {
+	var blocks []string
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		open := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(open, codefence.Fence) {
+			continue
+		}
+
+		// Code may start on the opening line when it has no language tag.
+		var body []string
+		tag, first := codefence.SplitTag(strings.TrimPrefix(open, codefence.Fence))
+		if first != "" {
+			body = append(body, first)
+		}
+
+		end := i + 1
+		for ; end < len(lines); end++ {
+			if strings.TrimSpace(lines[end]) == codefence.Fence {
+				break
+			}
+			body = append(body, lines[end])
+		}
+		if end == len(lines) {
+			break // unterminated fence
+		}
+
+		if lang == "" || strings.EqualFold(tag, lang) {
+			blocks = append(blocks, strings.Join(body, "\n"))
+		}
+		i = end
+	}
+
+	return blocks
+}
+
+// String returns a string representation of the CodeExtractor handler.
+func (c *CodeExtractor) String() string {
+	return fmt.Sprintf("CodeExtractor(%s)", c.name)
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+type codeArgs struct {
+	Input string `json:"input"`
+}
+
+func newEchoExecutor(t *testing.T, run func(code string) (string, error)) minds.Tool {
+	t.Helper()
+	tool, err := minds.WrapFunction("run_code", "Runs code", codeArgs{}, func(_ context.Context, args []byte) ([]byte, error) {
+		var in codeArgs
+		if err := json.Unmarshal(args, &in); err != nil {
+			return nil, err
+		}
+		out, err := run(in.Input)
+		return []byte(out), err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tool
+}
+
+func TestCodeExtractor(t *testing.T) {
+	answer := "Let me compute both.\n```lua\nreturn 1 + 2\n```\nand a note:\n```text\nnot code\n```\n```LUA\nreturn 2 * 3\n```"
+
+	t.Run("runs matching blocks in order", func(t *testing.T) {
+		is := is.New(t)
+
+		var ran []string
+		executor := newEchoExecutor(t, func(code string) (string, error) {
+			ran = append(ran, code)
+			return "ran: " + code, nil
+		})
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleAssistant, Content: answer})
+
+		result, err := handlers.NewCodeExtractor("run", "lua", executor).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(ran, []string{"return 1 + 2", "return 2 * 3"})
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 3)
+		is.Equal(msgs[1].Role, minds.RoleFunction)
+		is.Equal(msgs[1].Name, "run_code")
+		is.Equal(msgs[1].Content, "ran: return 1 + 2")
+		is.Equal(msgs[2].Content, "ran: return 2 * 3")
+	})
+
+	t.Run("reports executor errors to the model", func(t *testing.T) {
+		is := is.New(t)
+
+		executor := newEchoExecutor(t, func(code string) (string, error) {
+			return "", errors.New("syntax error")
+		})
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleAssistant, Content: "```lua\nreturn +\n```"})
+
+		result, err := handlers.NewCodeExtractor("run", "lua", executor).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "ERROR: Tool `run_code` failed: syntax error")
+	})
+
+	t.Run("passes through without code", func(t *testing.T) {
+		is := is.New(t)
+
+		executor := newEchoExecutor(t, func(code string) (string, error) {
+			t.Fatal("executor should not be called")
+			return "", nil
+		})
+
+		for _, msg := range []minds.Message{
+			{Role: minds.RoleAssistant, Content: "No code here."},
+			{Role: minds.RoleUser, Content: "```lua\nreturn 1\n```"},
+			{Role: minds.RoleAssistant, Content: "```lua\nreturn 1"},
+		} {
+			tc := minds.NewThreadContext(context.Background()).WithMessages(msg)
+			result, err := handlers.NewCodeExtractor("run", "lua", executor).HandleThread(tc, nil)
+			is.NoErr(err)
+			is.Equal(len(result.Messages()), 1)
+		}
+	})
+}
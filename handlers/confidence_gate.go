@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ConfidenceKey is the thread metadata key holding the self-rated confidence,
+// between 0 and 1, of the response chosen by ConfidenceGate.
+const ConfidenceKey = "confidence"
+
+const confidenceGatePrompt = `Rate how confident you are that the last assistant message is a correct,
+complete and well-supported answer to the conversation. Respond with a
+confidence between 0 and 1, and the main reason for any doubt.`
+
+// ConfidenceRating is the self-assessment requested by ConfidenceGate.
+type ConfidenceRating struct {
+	Confidence float64 `json:"confidence" description:"Confidence between 0 and 1 that the answer is correct"`
+	Reason     string  `json:"reason" description:"The main reason for any doubt about the answer"`
+}
+
+// ConfidenceGate generates a response, asks the model to rate its confidence
+// in it, and regenerates with a more careful instruction while the rating is
+// below a threshold.
+type ConfidenceGate struct {
+	name       string
+	generator  minds.ContentGenerator
+	threshold  float64
+	maxRetries int
+	middleware []minds.Middleware
+}
+
+// NewConfidenceGate creates a handler that implements a self-reflection loop.
+// Each response is rated by the generator with a structured call. If the
+// confidence is below threshold, the response is regenerated with the
+// rating's reason as feedback, up to maxRetries times. The response with the
+// highest confidence is appended to the thread and its confidence is stored
+// in metadata under ConfidenceKey.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM used to respond and to rate responses
+//   - threshold: Minimum acceptable confidence, between 0 and 1
+//   - maxRetries: Maximum number of regenerations after the first response
+//
+// Returns:
+//   - A handler that appends the most confident response to the thread
+//
+// Example:
+//
+//	gate := handlers.NewConfidenceGate("careful", llm, 0.8, 2)
+//	result, err := gate.HandleThread(tc, nil)
+//	confidence := result.Metadata()[handlers.ConfidenceKey].(float64)
+func NewConfidenceGate(name string, generator minds.ContentGenerator, threshold float64, maxRetries int) *ConfidenceGate {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	return &ConfidenceGate{
+		name:       name,
+		generator:  generator,
+		threshold:  threshold,
+		maxRetries: maxRetries,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the ConfidenceGate handler.
+func (c *ConfidenceGate) Use(middleware ...minds.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// With returns a new ConfidenceGate with additional middleware, preserving existing state.
+func (c *ConfidenceGate) With(middleware ...minds.Middleware) *ConfidenceGate {
+	newGate := &ConfidenceGate{
+		name:       c.name,
+		generator:  c.generator,
+		threshold:  c.threshold,
+		maxRetries: c.maxRetries,
+		middleware: append([]minds.Middleware{}, c.middleware...),
+	}
+	newGate.Use(middleware...)
+	return newGate
+}
+
+// HandleThread appends the most confident response and passes the thread on.
+func (c *ConfidenceGate) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return c.respond(tc)
+	})
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (c *ConfidenceGate) respond(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	ctx := tc.Context()
+	messages := tc.Messages()
+
+	var best string
+	bestConfidence := -1.0
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if ctx.Err() != nil {
+			return tc, ctx.Err()
+		}
+
+		resp, err := c.generator.GenerateContent(ctx, minds.NewRequest(messages))
+		if err != nil {
+			return tc, fmt.Errorf("%s: error generating content: %w", c.name, err)
+		}
+		content := resp.String()
+
+		rating, err := c.rate(tc, append(messages.Copy(), minds.Message{Role: minds.RoleAssistant, Content: content}))
+		if err != nil {
+			return tc, err
+		}
+
+		if rating.Confidence > bestConfidence {
+			best, bestConfidence = content, rating.Confidence
+		}
+
+		if rating.Confidence >= c.threshold {
+			break
+		}
+
+		messages = append(messages,
+			minds.Message{Role: minds.RoleAssistant, Content: content},
+			minds.Message{Role: minds.RoleSystem, Content: fmt.Sprintf(
+				"You were not confident in your previous answer (%.2f): %s\n"+
+					"Be more careful. Reason step by step, check your work and answer again.",
+				rating.Confidence, rating.Reason)},
+		)
+	}
+
+	newTc := tc.Clone()
+	newTc.AppendMessages(minds.Message{
+		Role:    minds.RoleAssistant,
+		Content: best,
+	})
+	newTc.SetKeyValue(ConfidenceKey, bestConfidence)
+
+	return newTc, nil
+}
+
+// rate asks the generator to rate the last message of the conversation.
+func (c *ConfidenceGate) rate(tc minds.ThreadContext, conversation minds.Messages) (ConfidenceRating, error) {
+	var rating ConfidenceRating
+
+	schema, err := minds.NewResponseSchema("ConfidenceRating", "Self-rated confidence in an answer", ConfidenceRating{})
+	if err != nil {
+		return rating, fmt.Errorf("%s: failed to generate schema: %w", c.name, err)
+	}
+
+	messages := append(conversation, minds.Message{Role: minds.RoleSystem, Content: confidenceGatePrompt})
+	resp, err := c.generator.GenerateContent(tc.Context(), minds.NewRequest(messages, minds.WithResponseSchema(*schema)))
+	if err != nil {
+		return rating, fmt.Errorf("%s: error rating response: %w", c.name, err)
+	}
+
+	if err := json.Unmarshal([]byte(resp.String()), &rating); err != nil {
+		return rating, fmt.Errorf("%s: failed to unmarshal confidence rating (%s): %w", c.name, resp.String(), err)
+	}
+
+	return rating, nil
+}
+
+// String returns a string representation of the ConfidenceGate handler.
+func (c *ConfidenceGate) String() string {
+	return fmt.Sprintf("ConfidenceGate(%s)", c.name)
+}
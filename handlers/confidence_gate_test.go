@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestConfidenceGate(t *testing.T) {
+	newThread := func() minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "What is 17 * 23?"},
+		)
+	}
+
+	// rater answers with the scripted confidences in turn and rates each
+	// answer with the confidence of the attempt that produced it.
+	rater := func(confidences ...float64) *recordingProvider {
+		attempt := 0
+		return &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				if req.Options.ResponseSchema != nil {
+					return newMockTextResponse(fmt.Sprintf(`{"confidence": %v, "reason": "unsure"}`, confidences[attempt-1])), nil
+				}
+				attempt++
+				return newMockTextResponse(fmt.Sprintf("answer %d", attempt)), nil
+			},
+		}
+	}
+
+	t.Run("accepts a confident first answer", func(t *testing.T) {
+		is := is.New(t)
+		llm := rater(0.9)
+
+		result, err := handlers.NewConfidenceGate("gate", llm, 0.8, 2).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "answer 1")
+		is.Equal(result.Metadata()[handlers.ConfidenceKey], 0.9)
+		is.Equal(llm.Calls(), 2)
+	})
+
+	t.Run("retries with feedback until confident", func(t *testing.T) {
+		is := is.New(t)
+		llm := rater(0.4, 0.85)
+
+		result, err := handlers.NewConfidenceGate("gate", llm, 0.8, 2).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "answer 2")
+		is.Equal(len(result.Messages()), 2)
+		is.Equal(result.Metadata()[handlers.ConfidenceKey], 0.85)
+		is.Equal(llm.Calls(), 4)
+
+		retry := llm.requests[2].Messages.Last().Content
+		is.True(strings.Contains(retry, "Be more careful"))
+		is.True(strings.Contains(retry, "unsure"))
+	})
+
+	t.Run("keeps the most confident answer when retries run out", func(t *testing.T) {
+		is := is.New(t)
+		llm := rater(0.3, 0.6, 0.5)
+
+		result, err := handlers.NewConfidenceGate("gate", llm, 0.8, 2).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "answer 2")
+		is.Equal(result.Metadata()[handlers.ConfidenceKey], 0.6)
+		is.Equal(llm.Calls(), 6)
+	})
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chriscow/minds"
+)
+
+// ContextInjectorKey tags the system message added by a ContextInjector in its
+// message metadata, so the message is replaced rather than duplicated when the
+// thread passes through the injector again.
+const ContextInjectorKey = "context_injector"
+
+// ContextInjector prepends a system message containing freshly resolved
+// values, such as the current date, so the model can answer questions that
+// depend on them.
+type ContextInjector struct {
+	name       string
+	resolve    func() map[string]string
+	middleware []minds.Middleware
+}
+
+// NewContextInjector creates a handler that prepends a system message listing
+// each field as "key: value". Values are resolved every time the handler runs,
+// and fields are listed in key order.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - fields: Field names mapped to functions that resolve their values
+//
+// Returns:
+//   - A handler that injects the resolved context into the thread
+//
+// Example:
+//
+//	env := handlers.NewContextInjector("env", map[string]func() string{
+//	    "current_date": func() string { return time.Now().Format("2006-01-02") },
+//	    "user_plan":    func() string { return account.Plan },
+//	})
+func NewContextInjector(name string, fields map[string]func() string) *ContextInjector {
+	copied := make(map[string]func() string, len(fields))
+	for k, fn := range fields {
+		copied[k] = fn
+	}
+
+	return &ContextInjector{
+		name: name,
+		resolve: func() map[string]string {
+			values := make(map[string]string, len(copied))
+			for k, fn := range copied {
+				values[k] = fn()
+			}
+			return values
+		},
+		middleware: []minds.Middleware{},
+	}
+}
+
+// CurrentTimeInjector returns a ContextInjector that provides current_date,
+// current_time, weekday and timezone from the local clock. All four are
+// formatted from a single reading of the clock, so they agree even when the
+// handler runs at midnight.
+func CurrentTimeInjector() *ContextInjector {
+	c := NewContextInjector("current-time", nil)
+	c.resolve = func() map[string]string {
+		now := time.Now()
+		return map[string]string{
+			"current_date": now.Format("2006-01-02"),
+			"current_time": now.Format("15:04:05"),
+			"weekday":      now.Weekday().String(),
+			"timezone":     now.Format("MST -07:00"),
+		}
+	}
+	return c
+}
+
+// Use applies middleware to the ContextInjector handler.
+func (c *ContextInjector) Use(middleware ...minds.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// With returns a new ContextInjector with additional middleware, preserving existing state.
+func (c *ContextInjector) With(middleware ...minds.Middleware) *ContextInjector {
+	newInjector := &ContextInjector{
+		name:       c.name,
+		resolve:    c.resolve,
+		middleware: append([]minds.Middleware{}, c.middleware...),
+	}
+	newInjector.Use(middleware...)
+	return newInjector
+}
+
+// HandleThread prepends the context message and passes the thread on.
+func (c *ContextInjector) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return c.inject(tc), nil
+	})
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (c *ContextInjector) inject(tc minds.ThreadContext) minds.ThreadContext {
+	values := c.resolve()
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("Context:")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n%s: %s", k, values[k])
+	}
+
+	messages := minds.Messages{{
+		Role:     minds.RoleSystem,
+		Content:  b.String(),
+		Metadata: minds.Metadata{ContextInjectorKey: c.name},
+	}}
+	for _, msg := range tc.Messages() {
+		if injector, ok := msg.Metadata[ContextInjectorKey].(string); ok && injector == c.name {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+
+	return tc.WithMessages(messages...)
+}
+
+// String returns a string representation of the ContextInjector handler.
+func (c *ContextInjector) String() string {
+	return fmt.Sprintf("ContextInjector(%s)", c.name)
+}
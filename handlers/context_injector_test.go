@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestContextInjector(t *testing.T) {
+	t.Run("prepends resolved fields and replaces on rerun", func(t *testing.T) {
+		is := is.New(t)
+		count := 0
+		injector := handlers.NewContextInjector("env", map[string]func() string{
+			"user": func() string { return "bob" },
+			"run":  func() string { count++; return strings.Repeat("x", count) },
+		})
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleSystem, Content: "Be helpful."},
+			minds.Message{Role: minds.RoleUser, Content: "Hi"},
+		)
+
+		result, err := injector.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 3)
+		is.Equal(msgs[0].Role, minds.RoleSystem)
+		is.Equal(msgs[0].Content, "Context:\nrun: x\nuser: bob")
+
+		result, err = injector.HandleThread(result, nil)
+		is.NoErr(err)
+
+		msgs = result.Messages()
+		is.Equal(len(msgs), 3)
+		is.Equal(msgs[0].Content, "Context:\nrun: xx\nuser: bob")
+		is.Equal(msgs[1].Content, "Be helpful.")
+	})
+
+	t.Run("current time", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background())
+
+		result, err := handlers.CurrentTimeInjector().HandleThread(tc, nil)
+		is.NoErr(err)
+
+		content := result.Messages()[0].Content
+		is.True(strings.Contains(content, "current_date: "+time.Now().Format("2006-01-02")))
+		is.True(strings.Contains(content, "timezone: "))
+
+		// Every field comes from the same reading of the clock.
+		values := map[string]string{}
+		for _, line := range strings.Split(content, "\n")[1:] {
+			key, value, _ := strings.Cut(line, ": ")
+			values[key] = value
+		}
+		at, err := time.ParseInLocation("2006-01-02 15:04:05", values["current_date"]+" "+values["current_time"], time.Local)
+		is.NoErr(err)
+		is.Equal(values["weekday"], at.Weekday().String())
+		is.Equal(values["timezone"], at.Format("MST -07:00"))
+	})
+}
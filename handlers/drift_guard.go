@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+)
+
+// DriftRatioKey is the thread metadata key holding the change ratio
+// DriftGuard measured for the last assistant message.
+const DriftRatioKey = "drift_ratio"
+
+// ErrDriftExceeded is returned by a DriftGuard handler when the assistant's
+// edit changed more of the text than allowed.
+var ErrDriftExceeded = errors.New("edit changed too much of the input")
+
+// DriftGuardOption configures a DriftGuard handler.
+type DriftGuardOption func(*DriftGuard)
+
+// WithRegenerate makes DriftGuard ask generator to revise an edit that
+// changed too much, up to attempts times, before failing with
+// ErrDriftExceeded. The revision replaces the assistant message.
+func WithRegenerate(generator minds.ContentGenerator, attempts int) DriftGuardOption {
+	return func(d *DriftGuard) {
+		d.generator = generator
+		d.attempts = attempts
+	}
+}
+
+// DriftGuard checks that an edit stays close to the text it was asked to
+// edit.
+type DriftGuard struct {
+	name           string
+	maxChangeRatio float64
+	generator      minds.ContentGenerator
+	attempts       int
+	middleware     []minds.Middleware
+}
+
+// NewDriftGuard creates a gate for editing and translation pipelines that
+// catches a model rewriting far more than it was asked to. It compares the
+// last user message with the last assistant message and computes the change
+// ratio: the word-level edit distance between them divided by the length of
+// the longer one, from 0 for identical texts to 1 for completely different
+// ones. The ratio is stored in metadata under DriftRatioKey.
+//
+// Threads whose ratio is at most maxChangeRatio continue to the next
+// handler. Others fail with ErrDriftExceeded, unless WithRegenerate is set,
+// in which case the edit is revised until it passes. Place it after the
+// handler that makes the edit. Threads without both messages pass
+// unchecked.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - maxChangeRatio: The largest share of words the edit may change, e.g. 0.3
+//   - opts: Optional configuration such as WithRegenerate
+//
+// Returns:
+//   - A handler that only continues threads whose edit stayed close to the input
+//
+// Example:
+//
+//	guard := handlers.NewDriftGuard("drift", 0.3, handlers.WithRegenerate(llm, 2))
+//	proofread := handlers.NewSequence("proofread", llm, guard)
+func NewDriftGuard(name string, maxChangeRatio float64, opts ...DriftGuardOption) *DriftGuard {
+	d := &DriftGuard{
+		name:           name,
+		maxChangeRatio: maxChangeRatio,
+		middleware:     []minds.Middleware{},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Use applies middleware to the DriftGuard handler. It wraps the drift check.
+func (d *DriftGuard) Use(middleware ...minds.Middleware) {
+	d.middleware = append(d.middleware, middleware...)
+}
+
+// With returns a new DriftGuard handler with additional middleware, preserving existing state.
+func (d *DriftGuard) With(middleware ...minds.Middleware) *DriftGuard {
+	newGuard := &DriftGuard{
+		name:           d.name,
+		maxChangeRatio: d.maxChangeRatio,
+		generator:      d.generator,
+		attempts:       d.attempts,
+		middleware:     append([]minds.Middleware{}, d.middleware...),
+	}
+	newGuard.Use(middleware...)
+	return newGuard
+}
+
+// HandleThread checks the last edit and routes the thread accordingly.
+func (d *DriftGuard) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+		return d.gate(tc, next)
+	})
+
+	for i := len(d.middleware) - 1; i >= 0; i-- {
+		handler = d.middleware[i].Wrap(handler)
+	}
+
+	return handler.HandleThread(tc, next)
+}
+
+func (d *DriftGuard) gate(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	messages := tc.Messages()
+
+	edit := -1
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == minds.RoleAssistant {
+			edit = i
+			break
+		}
+	}
+
+	input := -1
+	for i := edit - 1; i >= 0; i-- {
+		if messages[i].Role == minds.RoleUser {
+			input = i
+			break
+		}
+	}
+
+	if input < 0 {
+		if next != nil {
+			return next.HandleThread(tc, nil)
+		}
+		return tc, nil
+	}
+
+	original := messages[input].Content
+	ratio := changeRatio(original, messages[edit].Content)
+
+	for attempt := 0; ratio > d.maxChangeRatio && attempt < d.attempts; attempt++ {
+		if tc.Context().Err() != nil {
+			return tc, tc.Context().Err()
+		}
+
+		revised, err := d.revise(tc, messages[:edit+1], ratio)
+		if err != nil {
+			return tc, err
+		}
+
+		messages[edit].Content = revised
+		ratio = changeRatio(original, revised)
+	}
+
+	tc = tc.With(minds.ReplaceMessages(messages...), minds.SetKeyValue(DriftRatioKey, ratio))
+	if ratio > d.maxChangeRatio {
+		return tc, fmt.Errorf("%s: %w: %.0f%% changed, at most %.0f%% allowed", d.name, ErrDriftExceeded, ratio*100, d.maxChangeRatio*100)
+	}
+
+	if next != nil {
+		return next.HandleThread(tc, nil)
+	}
+
+	return tc, nil
+}
+
+// revise asks the generator to redo the edit that ends messages while
+// changing less of the input.
+func (d *DriftGuard) revise(tc minds.ThreadContext, messages minds.Messages, ratio float64) (string, error) {
+	messages = append(messages.Copy(), minds.Message{
+		Role: minds.RoleSystem,
+		Content: fmt.Sprintf("Your response changed %.0f%% of the original text, but at most %.0f%% may change. "+
+			"Revise it to stay closer to the original, changing only what the request requires. "+
+			"Respond with the revised text only.", ratio*100, d.maxChangeRatio*100),
+	})
+
+	resp, err := d.generator.GenerateContent(tc.Context(), minds.NewRequest(messages))
+	if err != nil {
+		return "", fmt.Errorf("%s: error generating content: %w", d.name, err)
+	}
+
+	return resp.String(), nil
+}
+
+// changeRatio returns the word-level edit distance between a and b divided by
+// the number of words in the longer text.
+func changeRatio(a, b string) float64 {
+	x, y := strings.Fields(a), strings.Fields(b)
+
+	longest := len(x)
+	if len(y) > longest {
+		longest = len(y)
+	}
+	if longest == 0 {
+		return 0
+	}
+
+	// Levenshtein distance over words, keeping two rows of the table.
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		curr[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return float64(prev[len(y)]) / float64(longest)
+}
+
+// String returns a string representation of the DriftGuard handler.
+func (d *DriftGuard) String() string {
+	return fmt.Sprintf("DriftGuard(%s, %.2f)", d.name, d.maxChangeRatio)
+}
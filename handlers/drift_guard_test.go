@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestDriftGuard(t *testing.T) {
+	original := "the quick brown fox jumps over the lazy dog"
+	light := "the quick brown fox leaps over the lazy dog"       // 1 of 9 words
+	heavy := "a fast red fox bounds across a sleeping old hound" // most words
+
+	newThread := func(edit string) minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: original},
+			minds.Message{Role: minds.RoleAssistant, Content: edit},
+		)
+	}
+
+	t.Run("passes small edits", func(t *testing.T) {
+		is := is.New(t)
+
+		result, err := handlers.NewDriftGuard("drift", 0.3).HandleThread(newThread(light), nil)
+		is.NoErr(err)
+		is.Equal(result.Metadata()[handlers.DriftRatioKey], 1.0/9)
+	})
+
+	t.Run("fails large edits", func(t *testing.T) {
+		is := is.New(t)
+
+		called := false
+		next := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			called = true
+			return tc, nil
+		})
+
+		_, err := handlers.NewDriftGuard("drift", 0.3).HandleThread(newThread(heavy), next)
+		is.True(errors.Is(err, handlers.ErrDriftExceeded))
+		is.True(!called)
+	})
+
+	t.Run("regenerates large edits", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(light), nil
+		}}
+
+		guard := handlers.NewDriftGuard("drift", 0.3, handlers.WithRegenerate(provider, 2))
+		result, err := guard.HandleThread(newThread(heavy), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 1)
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 2)
+		is.Equal(msgs[1].Content, light)
+
+		req := provider.requests[0]
+		is.Equal(req.Messages.Last().Role, minds.RoleSystem)
+		is.Equal(req.Messages[1].Content, heavy)
+	})
+
+	t.Run("gives up after the attempts", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(heavy), nil
+		}}
+
+		guard := handlers.NewDriftGuard("drift", 0.3, handlers.WithRegenerate(provider, 2))
+		_, err := guard.HandleThread(newThread(heavy), nil)
+		is.True(errors.Is(err, handlers.ErrDriftExceeded))
+		is.Equal(provider.Calls(), 2)
+	})
+
+	t.Run("passes threads without an edit", func(t *testing.T) {
+		is := is.New(t)
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: original})
+		_, err := handlers.NewDriftGuard("drift", 0).HandleThread(tc, nil)
+		is.NoErr(err)
+	})
+}
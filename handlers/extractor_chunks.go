@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/chriscow/minds"
+)
+
+// extract runs fn on tc, or on each chunk of tc if chunking is enabled, and
+// returns the aggregated result.
+func (o extractorOptions) extract(tc minds.ThreadContext, name string, fn func(minds.ThreadContext) (minds.ThreadContext, error)) (minds.ThreadContext, error) {
+	messages := tc.Messages()
+	if o.chunkSize <= 0 || len(messages) <= o.chunkSize {
+		return fn(tc)
+	}
+
+	var chunks []minds.Messages
+	for start := 0; start < len(messages); start += o.chunkSize {
+		end := start + o.chunkSize
+		if end > len(messages) {
+			end = len(messages)
+		}
+		chunks = append(chunks, messages[start:end])
+	}
+
+	concurrency := o.concurrency
+	if concurrency <= 0 || concurrency > len(chunks) {
+		concurrency = len(chunks)
+	}
+
+	results := make([]HandlerResult, len(chunks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk minds.Messages) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := tc.Context().Err(); err != nil {
+				results[i].Error = err
+				return
+			}
+
+			results[i].Context, results[i].Error = fn(tc.With(minds.ReplaceMessages(chunk...)))
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	failed := &chunkErrors{name: name, chunks: len(chunks)}
+	for i, r := range results {
+		if r.Error != nil {
+			failed.indexes = append(failed.indexes, i)
+			failed.errs = append(failed.errs, r.Error)
+		}
+	}
+	if len(failed.errs) > 0 {
+		return tc, failed
+	}
+
+	agg := o.aggregator
+	if agg == nil {
+		agg = DefaultAggregator
+	}
+
+	merged, err := agg(results)
+	if err != nil {
+		return tc, fmt.Errorf("%s aggregation: %w", name, err)
+	}
+
+	return merged, nil
+}
+
+// chunkErrors reports every chunk that failed to extract. It unwraps to the
+// error of the first one.
+type chunkErrors struct {
+	name    string
+	chunks  int
+	indexes []int
+	errs    []error
+}
+
+func (e *chunkErrors) Error() string {
+	failures := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		failures[i] = fmt.Sprintf("chunk %d: %v", e.indexes[i], err)
+	}
+	return fmt.Sprintf("%s: %d of %d chunks failed: %s", e.name, len(e.errs), e.chunks, strings.Join(failures, "; "))
+}
+
+func (e *chunkErrors) Unwrap() error {
+	return e.errs[0]
+}
@@ -0,0 +1,154 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestExtractorChunking(t *testing.T) {
+	newThread := func() minds.ThreadContext {
+		var messages minds.Messages
+		for i := 0; i < 5; i++ {
+			messages = append(messages, minds.Message{Role: minds.RoleUser, Content: fmt.Sprintf("m%d", i)})
+		}
+		return minds.NewThreadContext(context.Background()).WithMessages(messages...)
+	}
+
+	// chunkOf returns the first message index of the chunk sent in req.
+	chunkOf := func(req minds.Request) string {
+		return strings.TrimPrefix(req.Messages[1].Content, "user: ")
+	}
+
+	t.Run("merges chunk results in order", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			first := chunkOf(req)
+			return newMockTextResponse(fmt.Sprintf(
+				`{"pairs": [{"key": "last", "value": %q}, {"key": "seen_%s", "value": "true"}]}`, first, first)), nil
+		}}
+
+		extractor := handlers.NewFreeformExtractor("extract", provider, "Extract facts",
+			handlers.WithChunking(2, 0, nil))
+		result, err := extractor.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 3)
+
+		for _, req := range provider.requests {
+			is.True(len(req.Messages) <= 3) // prompt plus at most two messages
+		}
+
+		is.Equal(result.Metadata()["last"], "m4") // later chunks win
+		is.Equal(result.Metadata()["seen_m0"], true)
+		is.Equal(result.Metadata()["seen_m2"], true)
+		is.Equal(result.Metadata()["seen_m4"], true)
+		is.Equal(result.Messages(), newThread().Messages())
+	})
+
+	t.Run("short threads are extracted at once", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(`{"name": "John"}`), nil
+		}}
+
+		schema, err := minds.NewResponseSchema("person", "A person", struct {
+			Name string `json:"name"`
+		}{})
+		is.NoErr(err)
+
+		extractor := handlers.NewStructuredExtractor("extract", provider, "Extract the person", *schema,
+			handlers.WithChunking(10, 2, nil))
+		result, err := extractor.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 1)
+		is.Equal(result.Metadata()["person"], map[string]any{"name": "John"})
+	})
+
+	t.Run("limits concurrency", func(t *testing.T) {
+		is := is.New(t)
+		var running, peak int32
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return newMockTextResponse(`{"pairs": []}`), nil
+		}}
+
+		extractor := handlers.NewFreeformExtractor("extract", provider, "Extract facts",
+			handlers.WithChunking(1, 2, nil))
+		_, err := extractor.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 5)
+		is.True(atomic.LoadInt32(&peak) <= 2)
+	})
+
+	t.Run("reports every failed chunk", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			if chunkOf(req) == "m2" {
+				return newMockTextResponse(`{"pairs": []}`), nil
+			}
+			return nil, errHandlerFailed
+		}}
+
+		extractor := handlers.NewFreeformExtractor("extract", provider, "Extract facts",
+			handlers.WithChunking(2, 0, nil))
+		tc := newThread()
+		result, err := extractor.HandleThread(tc, nil)
+		is.True(errors.Is(err, errHandlerFailed))
+		is.True(strings.Contains(err.Error(), "2 of 3 chunks failed"))
+		is.True(strings.Contains(err.Error(), "chunk 0:"))
+		is.True(strings.Contains(err.Error(), "chunk 2:"))
+		is.Equal(result.Messages(), tc.Messages())
+	})
+
+	t.Run("uses the given aggregator", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(fmt.Sprintf(`{"pairs": [{"key": "first", "value": %q}]}`, chunkOf(req))), nil
+		}}
+
+		var chunks int
+		keepFirst := func(results []handlers.HandlerResult) (minds.ThreadContext, error) {
+			chunks = len(results)
+			return results[0].Context, nil
+		}
+
+		extractor := handlers.NewFreeformExtractor("extract", provider, "Extract facts",
+			handlers.WithChunking(2, 1, keepFirst))
+		result, err := extractor.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(chunks, 3)
+		is.Equal(result.Metadata()["first"], "m0")
+	})
+
+	t.Run("aggregator errors", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(`{"pairs": []}`), nil
+		}}
+
+		fail := func([]handlers.HandlerResult) (minds.ThreadContext, error) {
+			return nil, errHandlerFailed
+		}
+
+		extractor := handlers.NewFreeformExtractor("extract", provider, "Extract facts",
+			handlers.WithChunking(2, 0, fail))
+		_, err := extractor.HandleThread(newThread(), nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+}
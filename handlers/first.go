@@ -18,8 +18,9 @@ type First struct {
 }
 
 // NewFirst creates a handler that runs multiple handlers in parallel and returns on
-// first success. If all handlers fail, an error containing all handler errors is
-// returned. If no handlers are provided, the thread context is passed to the next
+// first success. The context of the remaining handlers is canceled as soon as
+// one succeeds, aborting their provider calls. If all handlers fail, an error
+// containing all handler errors is returned. If no handlers are provided, the thread context is passed to the next
 // handler unmodified.
 //
 // Parameters:
@@ -130,10 +131,17 @@ func (f *First) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (
 	var errors []error
 	for result := range resultChan {
 		if result.err == nil {
+			// Abort the losing handlers before moving on, so their
+			// provider calls stop instead of running to completion.
+			cancel()
+
+			// The winner's thread carries the now canceled context;
+			// continue with the caller's.
+			winner := result.tc.WithContext(tc.Context())
 			if next != nil {
-				return next.HandleThread(result.tc, nil)
+				return next.HandleThread(winner, nil)
 			}
-			return result.tc, nil
+			return winner, nil
 		}
 		errors = append(errors, fmt.Errorf("%w", result.err))
 	}
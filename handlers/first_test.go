@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -290,3 +291,64 @@ func TestFirst_ThreadContextIsolation(t *testing.T) {
 	is.Equal(h1Meta["handler"], "h1")
 	is.Equal(h2Meta["handler"], "h2")
 }
+
+// blockingGenerator is a ContentGenerator whose calls take delay to complete
+// unless their context is canceled first.
+type blockingGenerator struct {
+	delay    time.Duration
+	response string
+	returned chan error
+}
+
+func (g *blockingGenerator) ModelName() string { return "blocking" }
+
+func (g *blockingGenerator) GenerateContent(ctx context.Context, req minds.Request) (minds.Response, error) {
+	select {
+	case <-ctx.Done():
+		g.returned <- ctx.Err()
+		return nil, ctx.Err()
+	case <-time.After(g.delay):
+		g.returned <- nil
+		return newMockTextResponse(g.response), nil
+	}
+}
+
+func (g *blockingGenerator) Close() {}
+
+func TestFirst_CancelsLosingProviderCalls(t *testing.T) {
+	is := is.New(t)
+
+	generate := func(g minds.ContentGenerator) minds.ThreadHandler {
+		return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			resp, err := g.GenerateContent(tc.Context(), minds.NewRequest(tc.Messages()))
+			if err != nil {
+				return tc, err
+			}
+			return tc.WithMessages(append(tc.Messages(), minds.Message{Role: minds.RoleAssistant, Content: resp.String()})...), nil
+		})
+	}
+
+	fast := &blockingGenerator{delay: 10 * time.Millisecond, response: "fast", returned: make(chan error, 1)}
+	slow := &blockingGenerator{delay: 10 * time.Second, response: "slow", returned: make(chan error, 1)}
+
+	// The next handler must run on a live context even though the winner's
+	// context was canceled to stop the loser.
+	var nextErr error
+	next := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		nextErr = tc.Context().Err()
+		return tc, nil
+	})
+
+	first := handlers.NewFirst("race", generate(fast), generate(slow))
+	result, err := first.HandleThread(minds.NewThreadContext(context.Background()), next)
+	is.NoErr(err)
+	is.NoErr(nextErr)
+	is.Equal(result.Messages().Last().Content, "fast")
+
+	select {
+	case err := <-slow.returned:
+		is.True(errors.Is(err, context.Canceled)) // the loser's call was aborted
+	case <-time.After(time.Second):
+		t.Fatal("losing provider call was not canceled")
+	}
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"strconv"
 
@@ -15,6 +14,7 @@ type FreeformExtractor struct {
 	name       string
 	generator  minds.ContentGenerator
 	prompt     string
+	options    extractorOptions
 	middleware []minds.Middleware
 }
 
@@ -22,11 +22,15 @@ type FreeformExtractor struct {
 // The name parameter is used for debugging and logging.
 // The generator is used to analyze messages with the given prompt.
 // The prompt should instruct the LLM to extract name-value pairs from the conversation.
-func NewFreeformExtractor(name string, generator minds.ContentGenerator, prompt string) *FreeformExtractor {
+// Malformed JSON responses are repaired before parsing unless disabled with
+// WithJSONRepair(false). Use WithChunking to extract from long conversations
+// in concurrent chunks.
+func NewFreeformExtractor(name string, generator minds.ContentGenerator, prompt string, opts ...ExtractorOption) *FreeformExtractor {
 	return &FreeformExtractor{
 		name:       name,
 		generator:  generator,
 		prompt:     prompt,
+		options:    newExtractorOptions(opts...),
 		middleware: []minds.Middleware{},
 	}
 }
@@ -42,6 +46,7 @@ func (f *FreeformExtractor) With(middleware ...minds.Middleware) *FreeformExtrac
 		name:       f.name,
 		generator:  f.generator,
 		prompt:     f.prompt,
+		options:    f.options,
 		middleware: append([]minds.Middleware{}, f.middleware...),
 	}
 	newExtractor.Use(middleware...)
@@ -71,8 +76,24 @@ func (f *FreeformExtractor) HandleThread(tc minds.ThreadContext, next minds.Thre
 	return f.extractData(tc, next)
 }
 
-// extractData performs the actual data extraction logic
+// extractData performs the extraction, in chunks if configured, and passes
+// the result on.
 func (f *FreeformExtractor) extractData(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	newTc, err := f.options.extract(tc, f.name, f.extract)
+	if err != nil {
+		return tc, err
+	}
+
+	// Process next handler if provided
+	if next != nil {
+		return next.HandleThread(newTc, nil)
+	}
+
+	return newTc, nil
+}
+
+// extract performs the actual data extraction logic
+func (f *FreeformExtractor) extract(tc minds.ThreadContext) (minds.ThreadContext, error) {
 	// Create a message that combines the instruction prompt with the conversation context
 	messages := minds.Messages{
 		{Role: minds.RoleSystem, Content: f.prompt},
@@ -112,7 +133,7 @@ func (f *FreeformExtractor) extractData(tc minds.ThreadContext, next minds.Threa
 
 	// Parse the response
 	var result ExtractionResult
-	if err := json.Unmarshal([]byte(resp.String()), &result); err != nil {
+	if err := f.options.unmarshal([]byte(resp.String()), &result); err != nil {
 		return tc, fmt.Errorf("%s: error parsing extraction result: %w", f.name, err)
 	}
 
@@ -125,11 +146,6 @@ func (f *FreeformExtractor) extractData(tc minds.ThreadContext, next minds.Threa
 		newTc.SetKeyValue(pair.Key, value)
 	}
 
-	// Process next handler if provided
-	if next != nil {
-		return next.HandleThread(newTc, nil)
-	}
-
 	return newTc, nil
 }
 
@@ -12,13 +12,15 @@ import (
 type MockContentGenerator struct {
 	response string
 	err      error
+	requests []minds.Request
 }
 
 func (m *MockContentGenerator) ModelName() string {
 	return "mock-model"
 }
 
-func (m *MockContentGenerator) GenerateContent(_ context.Context, _ minds.Request) (minds.Response, error) {
+func (m *MockContentGenerator) GenerateContent(_ context.Context, req minds.Request) (minds.Response, error) {
+	m.requests = append(m.requests, req)
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// languageEnforcerAttempts is the number of times LanguageEnforcer generates a
+// response before giving up when verification keeps failing.
+const languageEnforcerAttempts = 3
+
+// LanguageCheck is the classifier result used by LanguageEnforcer to verify
+// the language of a response.
+type LanguageCheck struct {
+	Language string `json:"language" description:"The language the text is written in"`
+	Matches  bool   `json:"matches" description:"Whether the text is written in the expected language"`
+}
+
+// LanguageEnforcerOption configures a LanguageEnforcer.
+type LanguageEnforcerOption func(*LanguageEnforcer)
+
+// WithVerify enables or disables the classifier call that checks the language
+// of each response. Verification is enabled by default; disabling it saves a
+// call per turn and relies on the system instruction alone.
+func WithVerify(verify bool) LanguageEnforcerOption {
+	return func(l *LanguageEnforcer) {
+		l.verify = verify
+	}
+}
+
+// LanguageEnforcer generates the next assistant message and ensures it is
+// written in a fixed language, regardless of the language of the input.
+type LanguageEnforcer struct {
+	name       string
+	generator  minds.ContentGenerator
+	lang       string
+	verify     bool
+	middleware []minds.Middleware
+}
+
+// NewLanguageEnforcer creates a handler that responds to the thread in lang.
+// A strict system instruction is added to the request, and unless disabled
+// with WithVerify(false), the response is checked by a classifier call and
+// regenerated if it is in the wrong language.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM used to respond and to verify the response language
+//   - lang: The required response language, e.g. "German" or "pt-BR"
+//   - opts: Optional configuration such as WithVerify
+//
+// Returns:
+//   - A handler that appends a response in the required language
+//
+// Example:
+//
+//	german := handlers.NewLanguageEnforcer("german", llm, "German")
+//	result, err := german.HandleThread(tc, nil)
+func NewLanguageEnforcer(name string, generator minds.ContentGenerator, lang string, opts ...LanguageEnforcerOption) *LanguageEnforcer {
+	l := &LanguageEnforcer{
+		name:       name,
+		generator:  generator,
+		lang:       lang,
+		verify:     true,
+		middleware: []minds.Middleware{},
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Use applies middleware to the LanguageEnforcer handler.
+func (l *LanguageEnforcer) Use(middleware ...minds.Middleware) {
+	l.middleware = append(l.middleware, middleware...)
+}
+
+// With returns a new LanguageEnforcer with additional middleware, preserving existing state.
+func (l *LanguageEnforcer) With(middleware ...minds.Middleware) *LanguageEnforcer {
+	newEnforcer := &LanguageEnforcer{
+		name:       l.name,
+		generator:  l.generator,
+		lang:       l.lang,
+		verify:     l.verify,
+		middleware: append([]minds.Middleware{}, l.middleware...),
+	}
+	newEnforcer.Use(middleware...)
+	return newEnforcer
+}
+
+// HandleThread appends a response in the required language and passes the
+// thread on.
+func (l *LanguageEnforcer) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return l.respond(tc)
+	})
+
+	for i := len(l.middleware) - 1; i >= 0; i-- {
+		handler = l.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (l *LanguageEnforcer) respond(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	ctx := tc.Context()
+
+	messages := append(tc.Messages(), minds.Message{
+		Role: minds.RoleSystem,
+		Content: fmt.Sprintf("You must respond only in %s, regardless of the language used by the user "+
+			"or in earlier messages. Do not include translations into other languages.", l.lang),
+	})
+
+	var last LanguageCheck
+	for attempt := 0; attempt < languageEnforcerAttempts; attempt++ {
+		if ctx.Err() != nil {
+			return tc, ctx.Err()
+		}
+
+		resp, err := l.generator.GenerateContent(ctx, minds.NewRequest(messages))
+		if err != nil {
+			return tc, fmt.Errorf("%s: error generating content: %w", l.name, err)
+		}
+
+		content := resp.String()
+		if l.verify {
+			last, err = l.check(tc, content)
+			if err != nil {
+				return tc, err
+			}
+
+			if !last.Matches {
+				messages = append(messages,
+					minds.Message{Role: minds.RoleAssistant, Content: content},
+					minds.Message{Role: minds.RoleSystem, Content: fmt.Sprintf(
+						"Your previous response was written in %s. Rewrite it in %s.", last.Language, l.lang)},
+				)
+				continue
+			}
+		}
+
+		newTc := tc.Clone()
+		newTc.AppendMessages(minds.Message{
+			Role:    minds.RoleAssistant,
+			Content: content,
+		})
+		return newTc, nil
+	}
+
+	return tc, fmt.Errorf("%s: response was in %s instead of %s after %d attempts", l.name, last.Language, l.lang, languageEnforcerAttempts)
+}
+
+// check asks the generator which language content is written in.
+func (l *LanguageEnforcer) check(tc minds.ThreadContext, content string) (LanguageCheck, error) {
+	var result LanguageCheck
+
+	schema, err := minds.NewResponseSchema("LanguageCheck", "Language of the text", LanguageCheck{})
+	if err != nil {
+		return result, fmt.Errorf("%s: failed to generate schema: %w", l.name, err)
+	}
+
+	req := minds.NewRequest(minds.Messages{
+		{Role: minds.RoleSystem, Content: fmt.Sprintf("Identify the language of the text provided by the user and "+
+			"whether it is written in %s. Quoted names, code and loanwords do not count.", l.lang)},
+		{Role: minds.RoleUser, Content: content},
+	}, minds.WithResponseSchema(*schema))
+
+	resp, err := l.generator.GenerateContent(tc.Context(), req)
+	if err != nil {
+		return result, fmt.Errorf("%s: error verifying language: %w", l.name, err)
+	}
+
+	if err := json.Unmarshal([]byte(resp.String()), &result); err != nil {
+		return result, fmt.Errorf("%s: failed to unmarshal language check (%s): %w", l.name, resp.String(), err)
+	}
+
+	return result, nil
+}
+
+// String returns a string representation of the LanguageEnforcer handler.
+func (l *LanguageEnforcer) String() string {
+	return fmt.Sprintf("LanguageEnforcer(%s)", l.name)
+}
@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestLanguageEnforcer(t *testing.T) {
+	newThread := func() minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "What's the weather like?"},
+		)
+	}
+
+	t.Run("regenerates when the response is in the wrong language", func(t *testing.T) {
+		is := is.New(t)
+		replies := []string{"It is sunny.", "Es ist sonnig."}
+		llm := &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				if req.Options.ResponseSchema != nil {
+					if req.Messages.Last().Content == "Es ist sonnig." {
+						return newMockTextResponse(`{"language":"German","matches":true}`), nil
+					}
+					return newMockTextResponse(`{"language":"English","matches":false}`), nil
+				}
+				reply := replies[0]
+				replies = replies[1:]
+				return newMockTextResponse(reply), nil
+			},
+		}
+
+		result, err := handlers.NewLanguageEnforcer("german", llm, "German").HandleThread(newThread(), nil)
+		is.NoErr(err)
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 2)
+		is.Equal(msgs[1].Role, minds.RoleAssistant)
+		is.Equal(msgs[1].Content, "Es ist sonnig.")
+		is.Equal(llm.Calls(), 4)
+	})
+
+	t.Run("skips verification when disabled", func(t *testing.T) {
+		is := is.New(t)
+		var instruction string
+		llm := &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				instruction = req.Messages.Last().Content
+				return newMockTextResponse("Es ist sonnig."), nil
+			},
+		}
+
+		enforcer := handlers.NewLanguageEnforcer("german", llm, "German", handlers.WithVerify(false))
+		result, err := enforcer.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "Es ist sonnig.")
+		is.Equal(llm.Calls(), 1)
+		is.True(strings.Contains(instruction, "respond only in German"))
+	})
+
+	t.Run("gives up after repeated failures", func(t *testing.T) {
+		is := is.New(t)
+		llm := &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				if req.Options.ResponseSchema != nil {
+					return newMockTextResponse(`{"language":"English","matches":false}`), nil
+				}
+				return newMockTextResponse("It is sunny."), nil
+			},
+		}
+
+		tc := newThread()
+		result, err := handlers.NewLanguageEnforcer("german", llm, "German").HandleThread(tc, nil)
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), "English instead of German"))
+		is.Equal(len(result.Messages()), 1)
+	})
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/chriscow/minds"
+)
+
+const (
+	// charsPerToken approximates the length of a token in English text, used
+	// to size chunks without a tokenizer.
+	charsPerToken = 4
+
+	// mapReduceConcurrency is the number of chunks MapReduceSummarize
+	// summarizes at once.
+	mapReduceConcurrency = 8
+)
+
+const mapPrompt = `You are summarizing part %d of %d of a longer document. Summarize this part,
+keeping the key facts, names, figures and conclusions. Respond with the
+summary only.`
+
+const reducePrompt = `The following are summaries of consecutive parts of one document, in order.
+Combine them into a single coherent summary of the whole document. Remove
+repetition, keep the key facts, names, figures and conclusions, and respond
+with the summary only.`
+
+// MapReduceSummarize summarizes a document too long for the model's context
+// window by summarizing it in chunks and combining the results.
+type MapReduceSummarize struct {
+	name       string
+	generator  minds.ContentGenerator
+	chunkSize  int
+	middleware []minds.Middleware
+}
+
+// NewMapReduceSummarize creates a handler that summarizes the content of the
+// last message in the thread. The content is split into chunks of at most
+// chunkSize tokens, breaking at paragraphs, lines or words where possible.
+// Each chunk is summarized in parallel (the map step) and the chunk summaries
+// are combined into a final summary (the reduce step), which is appended as
+// an assistant message. If the chunk summaries are themselves longer than
+// chunkSize, they are summarized again in chunks until they fit.
+//
+// Tokens are estimated at four characters each, so leave headroom below the
+// model's context window for the prompt and the response.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM used to summarize
+//   - chunkSize: Maximum size of each chunk in tokens
+//
+// Returns:
+//   - A handler that appends a summary of the last message
+//
+// Example:
+//
+//	summarize := handlers.NewMapReduceSummarize("summarize", llm, 8000)
+//	tc = tc.WithMessages(minds.Message{Role: minds.RoleUser, Content: report})
+//	result, err := summarize.HandleThread(tc, nil)
+//	summary := result.Messages().Last().Content
+func NewMapReduceSummarize(name string, generator minds.ContentGenerator, chunkSize int) *MapReduceSummarize {
+	if generator == nil {
+		panic(fmt.Sprintf("%s: generator cannot be nil", name))
+	}
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+
+	return &MapReduceSummarize{
+		name:       name,
+		generator:  generator,
+		chunkSize:  chunkSize,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the MapReduceSummarize handler.
+func (m *MapReduceSummarize) Use(middleware ...minds.Middleware) {
+	m.middleware = append(m.middleware, middleware...)
+}
+
+// With returns a new MapReduceSummarize handler with additional middleware, preserving existing state.
+func (m *MapReduceSummarize) With(middleware ...minds.Middleware) *MapReduceSummarize {
+	newSummarize := &MapReduceSummarize{
+		name:       m.name,
+		generator:  m.generator,
+		chunkSize:  m.chunkSize,
+		middleware: append([]minds.Middleware{}, m.middleware...),
+	}
+	newSummarize.Use(middleware...)
+	return newSummarize
+}
+
+// HandleThread summarizes the last message and passes the thread on.
+func (m *MapReduceSummarize) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return m.summarize(tc)
+	})
+
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		handler = m.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (m *MapReduceSummarize) summarize(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	messages := tc.Messages()
+	if len(messages) == 0 {
+		return tc, fmt.Errorf("%s: %w", m.name, minds.ErrNoMessages)
+	}
+
+	text := strings.TrimSpace(messages.Last().Content)
+	if text == "" {
+		return tc, fmt.Errorf("%s: last message is empty", m.name)
+	}
+
+	maxChars := m.chunkSize * charsPerToken
+	chunks := splitText(text, maxChars, "\n\n", "\n", " ")
+
+	// Map until the summaries fit in a single chunk. Stop if a pass does not
+	// shrink the text, so a model that won't summarize can't loop forever.
+	for len(chunks) > 1 {
+		summaries, err := m.mapChunks(tc.Context(), chunks)
+		if err != nil {
+			return tc, fmt.Errorf("%s: %w", m.name, err)
+		}
+
+		next := splitText(strings.Join(summaries, "\n\n"), maxChars, "\n\n", "\n", " ")
+		if len(next) >= len(chunks) {
+			chunks = summaries
+			break
+		}
+		chunks = next
+	}
+
+	summary, err := m.generate(tc.Context(), reducePrompt, strings.Join(chunks, "\n\n"))
+	if err != nil {
+		return tc, fmt.Errorf("%s: error combining summaries: %w", m.name, err)
+	}
+
+	return tc.With(minds.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: summary})), nil
+}
+
+// mapChunks summarizes chunks in parallel and returns the summaries in order.
+// The first error cancels the chunks still in progress.
+func (m *MapReduceSummarize) mapChunks(ctx context.Context, chunks []string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, mapReduceConcurrency)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	summaries := make([]string, len(chunks))
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			summary, err := m.generate(ctx, fmt.Sprintf(mapPrompt, i+1, len(chunks)), chunk)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error summarizing part %d: %w", i+1, err)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+
+			summaries[i] = summary
+		}(i, chunk)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return summaries, ctx.Err()
+}
+
+func (m *MapReduceSummarize) generate(ctx context.Context, instruction, text string) (string, error) {
+	resp, err := m.generator.GenerateContent(ctx, minds.NewRequest(minds.Messages{
+		{Role: minds.RoleSystem, Content: instruction},
+		{Role: minds.RoleUser, Content: text},
+	}))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(resp.String()), nil
+}
+
+// String returns a string representation of the MapReduceSummarize handler.
+func (m *MapReduceSummarize) String() string {
+	return fmt.Sprintf("MapReduceSummarize(%s)", m.name)
+}
+
+// splitText splits text into chunks of at most maxChars characters. It splits
+// on the first separator and packs the pieces back together up to the limit;
+// pieces that are still too long are split on the next separator, and as a
+// last resort mid-word.
+func splitText(text string, maxChars int, separators ...string) []string {
+	if utf8.RuneCountInString(text) <= maxChars {
+		if text = strings.TrimSpace(text); text == "" {
+			return nil
+		}
+		return []string{text}
+	}
+
+	if len(separators) == 0 {
+		runes := []rune(text)
+		chunks := make([]string, 0, len(runes)/maxChars+1)
+		for len(runes) > 0 {
+			n := maxChars
+			if n > len(runes) {
+				n = len(runes)
+			}
+			chunks = append(chunks, string(runes[:n]))
+			runes = runes[n:]
+		}
+		return chunks
+	}
+
+	sep := separators[0]
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+		currentLen = 0
+	}
+
+	for _, part := range strings.Split(text, sep) {
+		partLen := utf8.RuneCountInString(part)
+		if partLen > maxChars {
+			flush()
+			chunks = append(chunks, splitText(part, maxChars, separators[1:]...)...)
+			continue
+		}
+
+		if currentLen > 0 && currentLen+len(sep)+partLen > maxChars {
+			flush()
+		}
+		if currentLen > 0 {
+			current.WriteString(sep)
+			currentLen += len(sep)
+		}
+		current.WriteString(part)
+		currentLen += partLen
+	}
+	flush()
+
+	return chunks
+}
@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestMapReduceSummarize(t *testing.T) {
+	document := "Alpha alpha alpha.\n\nBravo bravo bravo.\n\nCharlie charlie."
+
+	newThread := func(content string) minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: content})
+	}
+
+	t.Run("summarizes chunks and combines them", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			var part, total int
+			if _, err := fmt.Sscanf(req.Messages[0].Content, "You are summarizing part %d of %d", &part, &total); err == nil {
+				return newMockTextResponse(fmt.Sprintf("%d%c", part, req.Messages[1].Content[0])), nil
+			}
+			return newMockTextResponse("final: " + strings.ReplaceAll(req.Messages[1].Content, "\n\n", " ")), nil
+		}}
+
+		// Five tokens is about 20 characters, so each paragraph is a chunk.
+		result, err := handlers.NewMapReduceSummarize("summarize", provider, 5).HandleThread(newThread(document), nil)
+		is.NoErr(err)
+
+		is.Equal(provider.Calls(), 4) // three chunks and the reduce
+		msgs := result.Messages()
+		is.Equal(len(msgs), 2)
+		is.Equal(msgs[1].Role, minds.RoleAssistant)
+		is.Equal(msgs[1].Content, "final: 1A 2B 3C")
+	})
+
+	t.Run("short documents are summarized in one call", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("summary"), nil
+		}}
+
+		result, err := handlers.NewMapReduceSummarize("summarize", provider, 1000).HandleThread(newThread(document), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 1)
+		is.Equal(result.Messages().Last().Content, "summary")
+	})
+
+	t.Run("stops when summaries do not shrink", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(req.Messages[1].Content), nil
+		}}
+
+		_, err := handlers.NewMapReduceSummarize("summarize", provider, 5).HandleThread(newThread(document), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 4)
+	})
+
+	t.Run("returns map errors", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return nil, errHandlerFailed
+		}}
+
+		_, err := handlers.NewMapReduceSummarize("summarize", provider, 5).HandleThread(newThread(document), nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+
+	t.Run("requires a message", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{}
+
+		_, err := handlers.NewMapReduceSummarize("summarize", provider, 5).HandleThread(minds.NewThreadContext(context.Background()), nil)
+		is.True(errors.Is(err, minds.ErrNoMessages))
+	})
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+)
+
+const (
+	// MemorySummaryKey is the thread metadata key holding the running summary
+	// maintained by MemoryWindow. The summary message it injects is tagged with
+	// the same key in its message metadata.
+	MemorySummaryKey = "memory_summary"
+
+	memoryWindowPrompt = `Your task is to maintain a concise running summary of a conversation.
+You will receive the current summary and the messages that are about to be
+dropped from the conversation. Combine them, keeping names, facts, decisions
+and open questions that may matter later. Respond with the updated summary only.`
+)
+
+// MemoryWindow keeps a conversation within a fixed number of recent turns. Older
+// messages are folded into a running summary, stored in thread metadata under
+// MemorySummaryKey, and the thread is rebuilt as:
+//
+//	system messages + summary message + last keepTurns turns
+//
+// A turn starts at a user message and includes every message up to the next
+// user message.
+type MemoryWindow struct {
+	name       string
+	summarizer minds.ContentGenerator
+	keepTurns  int
+	middleware []minds.Middleware
+}
+
+// NewMemoryWindow creates a handler that provides rolling conversational
+// memory. Place it at the front of a pipeline; downstream handlers see the
+// condensed thread.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - summarizer: The LLM used to update the running summary
+//   - keepTurns: Number of recent turns kept verbatim
+//
+// Returns:
+//   - A handler that trims the thread and maintains a summary of older turns
+//
+// Example:
+//
+//	memory := handlers.NewMemoryWindow("memory", llm, 5)
+//	chat := handlers.NewSequence("chat", memory, llm)
+func NewMemoryWindow(name string, summarizer minds.ContentGenerator, keepTurns int) *MemoryWindow {
+	if keepTurns < 0 {
+		keepTurns = 0
+	}
+
+	return &MemoryWindow{
+		name:       name,
+		summarizer: summarizer,
+		keepTurns:  keepTurns,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the MemoryWindow handler.
+func (m *MemoryWindow) Use(middleware ...minds.Middleware) {
+	m.middleware = append(m.middleware, middleware...)
+}
+
+// With returns a new MemoryWindow with additional middleware, preserving existing state.
+func (m *MemoryWindow) With(middleware ...minds.Middleware) *MemoryWindow {
+	newWindow := &MemoryWindow{
+		name:       m.name,
+		summarizer: m.summarizer,
+		keepTurns:  m.keepTurns,
+		middleware: append([]minds.Middleware{}, m.middleware...),
+	}
+	newWindow.Use(middleware...)
+	return newWindow
+}
+
+// HandleThread condenses the thread and passes it to the next handler.
+func (m *MemoryWindow) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return m.condense(tc)
+	})
+
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		handler = m.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (m *MemoryWindow) condense(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	summary, _ := tc.Metadata()[MemorySummaryKey].(string)
+
+	var system, conversation minds.Messages
+	for _, msg := range tc.Messages() {
+		switch {
+		case isMemorySummary(msg):
+			// Rebuilt below from metadata.
+		case msg.Role == minds.RoleSystem && len(conversation) == 0:
+			system = append(system, msg)
+		default:
+			conversation = append(conversation, msg)
+		}
+	}
+
+	split := m.splitIndex(conversation)
+	older, recent := conversation[:split], conversation[split:]
+
+	if len(older) > 0 {
+		ctx := tc.Context()
+		if ctx.Err() != nil {
+			return tc, ctx.Err()
+		}
+
+		var transcript strings.Builder
+		for _, msg := range older {
+			fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, msg.Content)
+		}
+
+		req := minds.NewRequest(minds.Messages{
+			{Role: minds.RoleSystem, Content: memoryWindowPrompt},
+			{Role: minds.RoleUser, Content: fmt.Sprintf("Current summary:\n%s\n\nMessages to fold in:\n%s", summary, transcript.String())},
+		})
+
+		resp, err := m.summarizer.GenerateContent(ctx, req)
+		if err != nil {
+			return tc, fmt.Errorf("%s: error summarizing: %w", m.name, err)
+		}
+		summary = strings.TrimSpace(resp.String())
+	}
+
+	messages := make(minds.Messages, 0, len(system)+1+len(recent))
+	messages = append(messages, system...)
+	if summary != "" {
+		messages = append(messages, minds.Message{
+			Role:     minds.RoleSystem,
+			Content:  fmt.Sprintf("Summary of the earlier conversation:\n%s", summary),
+			Metadata: minds.Metadata{MemorySummaryKey: true},
+		})
+	}
+	messages = append(messages, recent...)
+
+	meta := tc.Metadata()
+	if summary != "" {
+		meta[MemorySummaryKey] = summary
+	}
+
+	return tc.WithMessages(messages...).WithMetadata(meta), nil
+}
+
+// splitIndex returns the index of the first message of the oldest turn to keep.
+func (m *MemoryWindow) splitIndex(conversation minds.Messages) int {
+	if m.keepTurns == 0 {
+		return len(conversation)
+	}
+
+	turns := 0
+	for i := len(conversation) - 1; i >= 0; i-- {
+		if conversation[i].Role != minds.RoleUser {
+			continue
+		}
+		turns++
+		if turns == m.keepTurns {
+			return i
+		}
+	}
+	return 0
+}
+
+func isMemorySummary(msg minds.Message) bool {
+	tagged, _ := msg.Metadata[MemorySummaryKey].(bool)
+	return tagged && msg.Role == minds.RoleSystem
+}
+
+// String returns a string representation of the MemoryWindow handler.
+func (m *MemoryWindow) String() string {
+	return fmt.Sprintf("MemoryWindow(%s)", m.name)
+}
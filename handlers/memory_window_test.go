@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestMemoryWindow(t *testing.T) {
+	t.Run("summarizes older turns and keeps recent ones", func(t *testing.T) {
+		is := is.New(t)
+		var prompts []string
+		llm := &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				prompts = append(prompts, req.Messages.Last().Content)
+				return newMockTextResponse("User is Bob and likes tea."), nil
+			},
+		}
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleSystem, Content: "Be helpful."},
+			minds.Message{Role: minds.RoleUser, Content: "I'm Bob"},
+			minds.Message{Role: minds.RoleAssistant, Content: "Hi Bob"},
+			minds.Message{Role: minds.RoleUser, Content: "I like tea"},
+			minds.Message{Role: minds.RoleAssistant, Content: "Noted"},
+			minds.Message{Role: minds.RoleUser, Content: "What do I like?"},
+		)
+
+		memory := handlers.NewMemoryWindow("memory", llm, 1)
+		result, err := memory.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 3)
+		is.Equal(msgs[0].Content, "Be helpful.")
+		is.Equal(msgs[1].Role, minds.RoleSystem)
+		is.True(strings.Contains(msgs[1].Content, "User is Bob and likes tea."))
+		is.Equal(msgs[2].Content, "What do I like?")
+		is.Equal(result.Metadata()[handlers.MemorySummaryKey], "User is Bob and likes tea.")
+		is.True(strings.Contains(prompts[0], "user: I'm Bob"))
+
+		// The next call folds the summary back in rather than duplicating it.
+		result.AppendMessages(
+			minds.Message{Role: minds.RoleAssistant, Content: "Tea"},
+			minds.Message{Role: minds.RoleUser, Content: "Thanks"},
+		)
+		result, err = memory.HandleThread(result, nil)
+		is.NoErr(err)
+
+		msgs = result.Messages()
+		is.Equal(len(msgs), 3)
+		is.Equal(msgs[2].Content, "Thanks")
+		is.Equal(llm.Calls(), 2)
+		is.True(strings.Contains(prompts[1], "Current summary:\nUser is Bob and likes tea."))
+		is.True(strings.Contains(prompts[1], "user: What do I like?"))
+	})
+
+	t.Run("short threads pass through", func(t *testing.T) {
+		is := is.New(t)
+		llm := &recordingProvider{}
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Hello"},
+		)
+
+		result, err := handlers.NewMemoryWindow("memory", llm, 3).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(len(result.Messages()), 1)
+		is.Equal(llm.Calls(), 0)
+	})
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+)
+
+// MetadataToMessage renders selected metadata values into a message, so data
+// extracted earlier in a pipeline can be given to a later model call.
+type MetadataToMessage struct {
+	name       string
+	keys       []string
+	tmpl       *minds.Prompt
+	middleware []minds.Middleware
+}
+
+// NewMetadataToMessage creates a handler that appends a user message built
+// from the metadata values under keys. The template receives a map from each
+// key to its value; if tmpl is nil, each value is written as "key: value" on
+// its own line, in the order of keys. A missing key is an error.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - keys: The metadata keys to render
+//   - tmpl: Optional template that renders the values
+//
+// Returns:
+//   - A handler that appends the rendered metadata as a message
+//
+// Example:
+//
+//	tmpl := template.Must(template.New("order").Parse("Order {{.order_id}} for {{.customer}}"))
+//	render := handlers.NewMetadataToMessage("render", []string{"order_id", "customer"}, &minds.Prompt{Template: tmpl})
+func NewMetadataToMessage(name string, keys []string, tmpl *minds.Prompt) *MetadataToMessage {
+	return &MetadataToMessage{
+		name:       name,
+		keys:       append([]string{}, keys...),
+		tmpl:       tmpl,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the MetadataToMessage handler.
+func (m *MetadataToMessage) Use(middleware ...minds.Middleware) {
+	m.middleware = append(m.middleware, middleware...)
+}
+
+// With returns a new MetadataToMessage handler with additional middleware, preserving existing state.
+func (m *MetadataToMessage) With(middleware ...minds.Middleware) *MetadataToMessage {
+	newHandler := &MetadataToMessage{
+		name:       m.name,
+		keys:       m.keys,
+		tmpl:       m.tmpl,
+		middleware: append([]minds.Middleware{}, m.middleware...),
+	}
+	newHandler.Use(middleware...)
+	return newHandler
+}
+
+// HandleThread appends the rendered metadata and passes the thread on.
+func (m *MetadataToMessage) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return m.render(tc)
+	})
+
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		handler = m.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (m *MetadataToMessage) render(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	meta := tc.Metadata()
+	data := make(map[string]any, len(m.keys))
+	for _, key := range m.keys {
+		value, ok := meta[key]
+		if !ok {
+			return tc, fmt.Errorf("%s: metadata key %q not found", m.name, key)
+		}
+		data[key] = value
+	}
+
+	var content string
+	if m.tmpl != nil {
+		rendered, err := m.tmpl.Execute(data)
+		if err != nil {
+			return tc, fmt.Errorf("%s: failed to render template: %w", m.name, err)
+		}
+		content = rendered
+	} else {
+		var b strings.Builder
+		for i, key := range m.keys {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s: %v", key, data[key])
+		}
+		content = b.String()
+	}
+
+	return tc.With(minds.AppendMessages(minds.Message{
+		Role:    minds.RoleUser,
+		Content: content,
+	})), nil
+}
+
+// String returns a string representation of the MetadataToMessage handler.
+func (m *MetadataToMessage) String() string {
+	return fmt.Sprintf("MetadataToMessage(%s)", m.name)
+}
+
+// MessageToMetadata stores the content of the last message in metadata, so
+// free text produced by one stage can be read as data by another.
+type MessageToMetadata struct {
+	name       string
+	key        string
+	middleware []minds.Middleware
+}
+
+// NewMessageToMetadata creates a handler that stores the content of the last
+// message in metadata under key. The messages are left unchanged. A thread
+// without messages is an error.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - key: The metadata key to store the content under
+//
+// Returns:
+//   - A handler that copies the last message into metadata
+//
+// Example:
+//
+//	draft := handlers.NewSequence("draft", llm, handlers.NewMessageToMetadata("save", "draft"))
+func NewMessageToMetadata(name, key string) *MessageToMetadata {
+	return &MessageToMetadata{
+		name:       name,
+		key:        key,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the MessageToMetadata handler.
+func (m *MessageToMetadata) Use(middleware ...minds.Middleware) {
+	m.middleware = append(m.middleware, middleware...)
+}
+
+// With returns a new MessageToMetadata handler with additional middleware, preserving existing state.
+func (m *MessageToMetadata) With(middleware ...minds.Middleware) *MessageToMetadata {
+	newHandler := &MessageToMetadata{
+		name:       m.name,
+		key:        m.key,
+		middleware: append([]minds.Middleware{}, m.middleware...),
+	}
+	newHandler.Use(middleware...)
+	return newHandler
+}
+
+// HandleThread stores the last message in metadata and passes the thread on.
+func (m *MessageToMetadata) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		messages := tc.Messages()
+		if len(messages) == 0 {
+			return tc, fmt.Errorf("%s: %w", m.name, minds.ErrNoMessages)
+		}
+
+		return tc.With(minds.SetKeyValue(m.key, messages.Last().Content)), nil
+	})
+
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		handler = m.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+// String returns a string representation of the MessageToMetadata handler.
+func (m *MessageToMetadata) String() string {
+	return fmt.Sprintf("MessageToMetadata(%s)", m.name)
+}
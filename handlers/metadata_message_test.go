@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestMetadataToMessage(t *testing.T) {
+	t.Run("renders template", func(t *testing.T) {
+		is := is.New(t)
+		tmpl := template.Must(template.New("order").Parse("Order {{.order_id}} for {{.customer}}"))
+		tc := minds.NewThreadContext(context.Background()).
+			WithMetadata(minds.Metadata{"order_id": 42, "customer": "Ada", "ignored": true})
+
+		h := handlers.NewMetadataToMessage("render", []string{"order_id", "customer"}, &minds.Prompt{Template: tmpl})
+		result, err := h.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		is.Equal(len(result.Messages()), 1)
+		is.Equal(result.Messages()[0].Role, minds.RoleUser)
+		is.Equal(result.Messages()[0].Content, "Order 42 for Ada")
+		is.Equal(len(tc.Messages()), 0) // original untouched
+	})
+
+	t.Run("default format follows key order", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background()).
+			WithMetadata(minds.Metadata{"b": "two", "a": 1})
+
+		result, err := handlers.NewMetadataToMessage("render", []string{"b", "a"}, nil).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "b: two\na: 1")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background())
+
+		_, err := handlers.NewMetadataToMessage("render", []string{"missing"}, nil).HandleThread(tc, nil)
+		is.True(err != nil)
+	})
+}
+
+func TestMessageToMetadata(t *testing.T) {
+	t.Run("stores last message", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Draft a reply"},
+			minds.Message{Role: minds.RoleAssistant, Content: "Dear customer"},
+		)
+
+		result, err := handlers.NewMessageToMetadata("save", "draft").HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(result.Metadata()["draft"], "Dear customer")
+		is.Equal(len(result.Messages()), 2)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleAssistant, Content: "summary text"},
+		)
+
+		pipeline := handlers.NewSequence("roundtrip",
+			handlers.NewMessageToMetadata("save", "summary"),
+			handlers.NewMetadataToMessage("render", []string{"summary"}, nil),
+		)
+		result, err := pipeline.HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "summary: summary text")
+	})
+
+	t.Run("empty thread", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background())
+
+		_, err := handlers.NewMessageToMetadata("save", "draft").HandleThread(tc, nil)
+		is.True(errors.Is(err, minds.ErrNoMessages))
+	})
+}
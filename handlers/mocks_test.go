@@ -177,8 +177,45 @@ func (m *mockProvider) Close() {
 	// No-op
 }
 
+// recordingProvider is a ContentGenerator that records every request and
+// delegates the response to fn.
+type recordingProvider struct {
+	mu       sync.Mutex
+	calls    int
+	requests []minds.Request
+	fn       func(req minds.Request) (minds.Response, error)
+}
+
+func (r *recordingProvider) ModelName() string {
+	return "recording-model"
+}
+
+func (r *recordingProvider) GenerateContent(ctx context.Context, req minds.Request) (minds.Response, error) {
+	r.mu.Lock()
+	r.calls++
+	r.requests = append(r.requests, req)
+	r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r.fn == nil {
+		return newMockTextResponse(""), nil
+	}
+	return r.fn(req)
+}
+
+func (r *recordingProvider) Close() {}
+
+func (r *recordingProvider) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
 type mockResponse struct {
 	Content string
+	Calls   []minds.ToolCall
 }
 
 func (m mockResponse) String() string {
@@ -186,7 +223,7 @@ func (m mockResponse) String() string {
 }
 
 func (m mockResponse) ToolCalls() []minds.ToolCall {
-	return nil
+	return m.Calls
 }
 
 func newMockTextResponse(content string) minds.Response {
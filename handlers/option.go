@@ -1,6 +1,10 @@
 package handlers
 
-import "github.com/chriscow/minds"
+import (
+	"encoding/json"
+
+	"github.com/chriscow/minds"
+)
 
 type HandlerOption struct {
 	name        string
@@ -26,3 +30,82 @@ func WithPrompt(prompt minds.Prompt) func(*HandlerOption) {
 		ho.prompt = prompt
 	}
 }
+
+// ExtractorOption configures StructuredExtractor and FreeformExtractor.
+type ExtractorOption func(*extractorOptions)
+
+type extractorOptions struct {
+	disableRepair bool
+	confidence    bool
+	chunkSize     int
+	concurrency   int
+	aggregator    ResultAggregator
+}
+
+// WithJSONRepair enables or disables repairing malformed JSON with
+// minds.RepairJSON when the model's response fails to unmarshal. Repair is
+// enabled by default.
+func WithJSONRepair(enabled bool) ExtractorOption {
+	return func(o *extractorOptions) {
+		o.disableRepair = !enabled
+	}
+}
+
+// WithConfidenceField makes StructuredExtractor ask the model how confident it
+// is in its extraction. The schema sent to the model gains a required
+// "_confidence" number between 0 and 1; the value is removed from the
+// extracted data and stored in metadata under "<schema name>_confidence", so
+// the extracted data still matches your own type. The option only applies to
+// schemas whose root is an object and is ignored by FreeformExtractor.
+//
+// The confidence is the model's own assessment. It is not calibrated, so use
+// it to rank or flag extractions rather than as a probability.
+func WithConfidenceField(enabled bool) ExtractorOption {
+	return func(o *extractorOptions) {
+		o.confidence = enabled
+	}
+}
+
+// WithChunking makes the extractor split the conversation into chunks of
+// size messages and run one extraction per chunk, up to concurrency at a
+// time, instead of sending the whole thread in one request. Use it for
+// conversations that exceed the model's context window.
+//
+// Each chunk is extracted from a clone of the thread holding only the
+// chunk's messages. The results are combined in chunk order with agg, the
+// same aggregators Must and Segment take; DefaultAggregator, used if agg is
+// nil, restores the messages and lets later chunks overwrite values
+// extracted from earlier ones. If any chunk fails, the extractor fails with
+// an error listing every failed chunk. A size of zero or less disables
+// chunking, and a concurrency of zero or less runs every chunk at once.
+func WithChunking(size, concurrency int, agg ResultAggregator) ExtractorOption {
+	return func(o *extractorOptions) {
+		o.chunkSize = size
+		o.concurrency = concurrency
+		o.aggregator = agg
+	}
+}
+
+func newExtractorOptions(opts ...ExtractorOption) extractorOptions {
+	var o extractorOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// unmarshal decodes data into v, repairing it first if it is malformed and
+// repair is enabled. The original error is returned if repair fails.
+func (o extractorOptions) unmarshal(data []byte, v any) error {
+	err := json.Unmarshal(data, v)
+	if err == nil || o.disableRepair {
+		return err
+	}
+
+	repaired, repairErr := minds.RepairJSON(data)
+	if repairErr != nil {
+		return err
+	}
+
+	return json.Unmarshal(repaired, v)
+}
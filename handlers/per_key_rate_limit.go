@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chriscow/minds"
+	"golang.org/x/time/rate"
+)
+
+// defaultIdleTimeout is how long a key's limiter is kept after its last use
+// unless changed with WithIdleTimeout.
+const defaultIdleTimeout = 10 * time.Minute
+
+// PerKeyRateLimitOption configures a PerKeyRateLimit handler.
+type PerKeyRateLimitOption func(*PerKeyRateLimit)
+
+// WithIdleTimeout sets how long a key's limiter is kept after its last use.
+// The default is ten minutes.
+func WithIdleTimeout(d time.Duration) PerKeyRateLimitOption {
+	return func(p *PerKeyRateLimit) {
+		p.idleTimeout = d
+	}
+}
+
+// PerKeyRateLimit rate-limits threads separately for each key, such as a
+// user or tenant ID.
+type PerKeyRateLimit struct {
+	name        string
+	keyFn       func(minds.ThreadContext) string
+	limit       rate.Limit
+	burst       int
+	idleTimeout time.Duration
+	limiters    *keyLimiters
+	middleware  []minds.Middleware
+}
+
+// keyLimiters holds the limiter of each key, shared by a PerKeyRateLimit
+// and the copies made by With.
+type keyLimiters struct {
+	mu        sync.Mutex
+	byKey     map[string]*keyLimiter
+	lastSweep time.Time
+}
+
+type keyLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// NewPerKeyRateLimit creates a handler that limits how often threads with the
+// same key pass through, for multi-tenant servers where one busy user should
+// not use up the rate for everyone. Each key returned by keyFn gets its own
+// token bucket allowing limit threads per second with bursts of up to burst.
+// Threads wait for their key's bucket, or until tc.Context() is done, then
+// continue to the next handler.
+//
+// Limiters unused for the idle timeout are evicted to bound memory; see
+// WithIdleTimeout. A limiter is only evicted once its bucket has refilled,
+// so eviction never lets a key exceed its rate.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - keyFn: Returns the key to limit the thread by
+//   - limit: Threads allowed per second for each key
+//   - burst: Threads allowed at once for each key
+//   - opts: Optional configuration such as WithIdleTimeout
+//
+// Returns:
+//   - A handler that rate-limits threads per key
+//
+// Example:
+//
+//	byUser := func(tc minds.ThreadContext) string {
+//	    user, _ := tc.Metadata()["user_id"].(string)
+//	    return user
+//	}
+//	limit := handlers.NewPerKeyRateLimit("user_limit", byUser, rate.Every(time.Second), 5)
+//	chat := handlers.NewSequence("chat", limit, llm)
+func NewPerKeyRateLimit(name string, keyFn func(minds.ThreadContext) string, limit rate.Limit, burst int, opts ...PerKeyRateLimitOption) *PerKeyRateLimit {
+	if keyFn == nil {
+		panic(fmt.Sprintf("%s: keyFn cannot be nil", name))
+	}
+
+	p := &PerKeyRateLimit{
+		name:        name,
+		keyFn:       keyFn,
+		limit:       limit,
+		burst:       burst,
+		idleTimeout: defaultIdleTimeout,
+		limiters:    &keyLimiters{byKey: map[string]*keyLimiter{}},
+		middleware:  []minds.Middleware{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Use applies middleware to the PerKeyRateLimit handler. It wraps the wait
+// for the rate limit.
+func (p *PerKeyRateLimit) Use(middleware ...minds.Middleware) {
+	p.middleware = append(p.middleware, middleware...)
+}
+
+// With returns a new PerKeyRateLimit handler with additional middleware. The
+// new handler shares the limiters of the original, so both count against the
+// same rate.
+func (p *PerKeyRateLimit) With(middleware ...minds.Middleware) *PerKeyRateLimit {
+	newLimit := &PerKeyRateLimit{
+		name:        p.name,
+		keyFn:       p.keyFn,
+		limit:       p.limit,
+		burst:       p.burst,
+		idleTimeout: p.idleTimeout,
+		limiters:    p.limiters,
+		middleware:  append([]minds.Middleware{}, p.middleware...),
+	}
+	newLimit.Use(middleware...)
+	return newLimit
+}
+
+// HandleThread waits for the thread's key to be under the rate limit and
+// passes the thread on.
+func (p *PerKeyRateLimit) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		key := p.keyFn(tc)
+		if err := p.limiter(key).Wait(tc.Context()); err != nil {
+			return tc, fmt.Errorf("%s: rate limit for %q: %w", p.name, key, err)
+		}
+		return tc, nil
+	})
+
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		handler = p.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+// limiter returns the limiter for key, creating it if needed, and evicts
+// idle limiters at most once per idle timeout.
+func (p *PerKeyRateLimit) limiter(key string) *rate.Limiter {
+	s := p.limiters
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= p.idleTimeout {
+		for k, l := range s.byKey {
+			if now.Sub(l.lastUsed) >= p.idleTimeout && l.limiter.TokensAt(now) >= float64(l.limiter.Burst()) {
+				delete(s.byKey, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	l, ok := s.byKey[key]
+	if !ok {
+		l = &keyLimiter{limiter: rate.NewLimiter(p.limit, p.burst)}
+		s.byKey[key] = l
+	}
+	l.lastUsed = now
+
+	return l.limiter
+}
+
+// Len returns the number of keys currently tracked.
+func (p *PerKeyRateLimit) Len() int {
+	p.limiters.mu.Lock()
+	defer p.limiters.mu.Unlock()
+	return len(p.limiters.byKey)
+}
+
+// String returns a string representation of the PerKeyRateLimit handler.
+func (p *PerKeyRateLimit) String() string {
+	return fmt.Sprintf("PerKeyRateLimit(%s)", p.name)
+}
@@ -0,0 +1,114 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+	"golang.org/x/time/rate"
+)
+
+func TestPerKeyRateLimit(t *testing.T) {
+	byUser := func(tc minds.ThreadContext) string {
+		user, _ := tc.Metadata()["user_id"].(string)
+		return user
+	}
+
+	newThread := func(ctx context.Context, user string) minds.ThreadContext {
+		return minds.NewThreadContext(ctx).With(minds.SetKeyValue("user_id", user))
+	}
+
+	t.Run("limits each key separately", func(t *testing.T) {
+		is := is.New(t)
+		limit := handlers.NewPerKeyRateLimit("limit", byUser, rate.Every(time.Hour), 1)
+
+		_, err := limit.HandleThread(newThread(context.Background(), "alice"), nil)
+		is.NoErr(err)
+		_, err = limit.HandleThread(newThread(context.Background(), "bob"), nil)
+		is.NoErr(err) // a different key has its own bucket
+		is.Equal(limit.Len(), 2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = limit.HandleThread(newThread(ctx, "alice"), nil)
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), `"alice"`))
+	})
+
+	t.Run("waits on the thread context", func(t *testing.T) {
+		is := is.New(t)
+		limit := handlers.NewPerKeyRateLimit("limit", byUser, rate.Every(time.Hour), 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := limit.HandleThread(newThread(ctx, "alice"), nil)
+		is.True(errors.Is(err, context.Canceled))
+	})
+
+	t.Run("passes threads on", func(t *testing.T) {
+		is := is.New(t)
+		limit := handlers.NewPerKeyRateLimit("limit", byUser, rate.Every(time.Millisecond), 5)
+
+		called := 0
+		next := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			called++
+			return tc, nil
+		})
+
+		for i := 0; i < 3; i++ {
+			_, err := limit.HandleThread(newThread(context.Background(), "alice"), next)
+			is.NoErr(err)
+		}
+		is.Equal(called, 3)
+	})
+
+	t.Run("evicts idle keys", func(t *testing.T) {
+		is := is.New(t)
+		limit := handlers.NewPerKeyRateLimit("limit", byUser, rate.Every(5*time.Millisecond), 1,
+			handlers.WithIdleTimeout(20*time.Millisecond))
+
+		_, err := limit.HandleThread(newThread(context.Background(), "alice"), nil)
+		is.NoErr(err)
+		_, err = limit.HandleThread(newThread(context.Background(), "bob"), nil)
+		is.NoErr(err)
+		is.Equal(limit.Len(), 2)
+
+		time.Sleep(50 * time.Millisecond)
+		_, err = limit.HandleThread(newThread(context.Background(), "carol"), nil)
+		is.NoErr(err)
+		is.Equal(limit.Len(), 1)
+	})
+
+	t.Run("keeps limiters that have not refilled", func(t *testing.T) {
+		is := is.New(t)
+		limit := handlers.NewPerKeyRateLimit("limit", byUser, rate.Every(time.Hour), 1,
+			handlers.WithIdleTimeout(10*time.Millisecond))
+
+		_, err := limit.HandleThread(newThread(context.Background(), "alice"), nil)
+		is.NoErr(err)
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = limit.HandleThread(newThread(context.Background(), "bob"), nil)
+		is.NoErr(err)
+		is.Equal(limit.Len(), 2) // alice would get a fresh burst if evicted
+	})
+
+	t.Run("With shares limiters", func(t *testing.T) {
+		is := is.New(t)
+		limit := handlers.NewPerKeyRateLimit("limit", byUser, rate.Every(time.Hour), 1)
+		copied := limit.With()
+
+		_, err := limit.HandleThread(newThread(context.Background(), "alice"), nil)
+		is.NoErr(err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = copied.HandleThread(newThread(ctx, "alice"), nil)
+		is.True(err != nil)
+	})
+}
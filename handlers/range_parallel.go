@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chriscow/minds"
+)
+
+// RangeParallel executes a handler once per value like Range, but runs up to a
+// fixed number of values concurrently. Each value runs on its own clone of the
+// thread context with the value stored under "range_value".
+type RangeParallel struct {
+	name            string
+	handler         minds.ThreadHandler
+	concurrency     int
+	values          []any
+	continueOnError bool
+	middleware      []minds.Middleware
+}
+
+// NewRangeParallel creates a handler that processes a thread once for each
+// value, running up to concurrency values at the same time. A concurrency
+// below one runs every value at once.
+//
+// The results are combined in value order, not completion order: the messages
+// each run added are appended to the thread and its metadata is merged with
+// minds.KeepNew, so "range_value" holds the last value afterwards. As with
+// Range, the first error stops processing and cancels the runs in progress;
+// use ContinueOnError to skip failed values instead.
+//
+// Parameters:
+//   - name: Identifier for this range handler
+//   - handler: The handler to execute for each value
+//   - concurrency: Maximum number of values processed at the same time
+//   - values: Values to iterate over
+//
+// Returns:
+//   - A RangeParallel handler that processes the thread once for each value
+//
+// Example:
+//
+//	rng := handlers.NewRangeParallel("classify",
+//	    classifyHandler,
+//	    4,
+//	    "doc1", "doc2", "doc3",
+//	)
+func NewRangeParallel(name string, handler minds.ThreadHandler, concurrency int, values ...any) *RangeParallel {
+	if handler == nil {
+		panic(fmt.Sprintf("%s: handler cannot be nil", name))
+	}
+
+	return &RangeParallel{
+		name:        name,
+		handler:     handler,
+		concurrency: concurrency,
+		values:      values,
+		middleware:  make([]minds.Middleware, 0),
+	}
+}
+
+// ContinueOnError returns a copy of the handler that skips values whose run
+// fails instead of stopping. The successful results are still combined in
+// value order; an error is returned only if every value fails.
+func (r *RangeParallel) ContinueOnError() *RangeParallel {
+	newRange := r.clone()
+	newRange.continueOnError = true
+	return newRange
+}
+
+// Use adds middleware to the handler
+func (r *RangeParallel) Use(middleware ...minds.Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// With returns a new handler with the provided middleware
+func (r *RangeParallel) With(middleware ...minds.Middleware) minds.ThreadHandler {
+	newRange := r.clone()
+	newRange.Use(middleware...)
+	return newRange
+}
+
+func (r *RangeParallel) clone() *RangeParallel {
+	return &RangeParallel{
+		name:            r.name,
+		handler:         r.handler,
+		concurrency:     r.concurrency,
+		values:          r.values,
+		continueOnError: r.continueOnError,
+		middleware:      append([]minds.Middleware{}, r.middleware...),
+	}
+}
+
+// HandleThread implements the ThreadHandler interface
+func (r *RangeParallel) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	if tc.Context().Err() != nil {
+		return tc, tc.Context().Err()
+	}
+
+	ctx, cancel := context.WithCancel(tc.Context())
+	defer cancel()
+
+	// Apply middleware in reverse order for proper nesting
+	wrappedHandler := r.handler
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		wrappedHandler = r.middleware[i].Wrap(wrappedHandler)
+	}
+
+	concurrency := r.concurrency
+	if concurrency < 1 || concurrency > len(r.values) {
+		concurrency = len(r.values)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	results := make([]HandlerResult, len(r.values))
+
+	for i, value := range r.values {
+		wg.Add(1)
+		go func(i int, value any) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				results[i].Error = ctx.Err()
+				return
+			}
+
+			// Setup iteration context with value
+			iterCtx := tc.Clone().WithContext(ctx)
+			meta := iterCtx.Metadata()
+			meta["range_value"] = value
+			iterCtx = iterCtx.WithMetadata(meta)
+
+			result, err := wrappedHandler.HandleThread(iterCtx, nil)
+			results[i] = HandlerResult{Handler: r.handler, Context: result, Error: err}
+
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", r.name, err)
+				}
+				mu.Unlock()
+
+				if !r.continueOnError {
+					cancel() // Stop the remaining values on failure
+				}
+			}
+		}(i, value)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil && !r.continueOnError {
+		return tc, firstErr
+	}
+
+	if err := tc.Context().Err(); err != nil {
+		return tc, err
+	}
+
+	current := tc
+	succeeded := 0
+	for _, result := range results {
+		if result.Error != nil || result.Context == nil {
+			continue
+		}
+
+		succeeded++
+		current = current.WithMetadata(current.Metadata().Merge(result.Context.Metadata(), minds.KeepNew))
+
+		// Append only the messages this run added to the original thread
+		if added := result.Context.Messages(); len(added) > len(tc.Messages()) {
+			current = current.WithMessages(append(current.Messages(), added[len(tc.Messages()):]...)...)
+		}
+	}
+
+	if succeeded == 0 && firstErr != nil {
+		return tc, firstErr
+	}
+
+	// If there's a next handler, execute it with the final context
+	if next != nil {
+		return next.HandleThread(current, nil)
+	}
+
+	return current, nil
+}
+
+// String returns a string representation of the RangeParallel handler
+func (r *RangeParallel) String() string {
+	return fmt.Sprintf("RangeParallel(%s, %d values)", r.name, len(r.values))
+}
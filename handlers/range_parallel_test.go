@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+// echoValue appends a message containing the range value after a delay that
+// shrinks with the value, so later values tend to finish first.
+func echoValue(running, peak *int32) minds.ThreadHandlerFunc {
+	return func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		n := atomic.AddInt32(running, 1)
+		defer atomic.AddInt32(running, -1)
+		for {
+			p := atomic.LoadInt32(peak)
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+
+		value := tc.Metadata()["range_value"].(int)
+		select {
+		case <-time.After(time.Duration(10-value) * 5 * time.Millisecond):
+		case <-tc.Context().Done():
+			return tc, tc.Context().Err()
+		}
+
+		if value < 0 {
+			return tc, fmt.Errorf("value %d failed", value)
+		}
+
+		return tc.With(minds.AppendMessages(minds.Message{
+			Role:    minds.RoleAssistant,
+			Content: fmt.Sprint(value),
+		})), nil
+	}
+}
+
+func TestRangeParallel_OrderAndConcurrency(t *testing.T) {
+	is := is.New(t)
+	var running, peak int32
+	final := &mockHandler{name: "final"}
+
+	ranger := handlers.NewRangeParallel("test", echoValue(&running, &peak), 2, 1, 2, 3, 4, 5)
+	tc := minds.NewThreadContext(context.Background()).WithMessages(
+		minds.Message{Role: minds.RoleUser, Content: "start"},
+	)
+
+	result, err := ranger.HandleThread(tc, final)
+	is.NoErr(err)
+	is.Equal(1, final.Completed())
+	is.True(atomic.LoadInt32(&peak) <= 2)
+
+	var contents []string
+	for _, msg := range result.Messages() {
+		contents = append(contents, msg.Content)
+	}
+	is.Equal(contents, []string{"start", "1", "2", "3", "4", "5"})
+	is.Equal(result.Metadata()["range_value"], 5)
+	is.Equal(len(tc.Messages()), 1) // original untouched
+}
+
+func TestRangeParallel_RunsConcurrently(t *testing.T) {
+	is := is.New(t)
+	var running, peak int32
+
+	ranger := handlers.NewRangeParallel("test", echoValue(&running, &peak), 0, 1, 2, 3, 4)
+	_, err := ranger.HandleThread(minds.NewThreadContext(context.Background()), nil)
+	is.NoErr(err)
+	is.True(atomic.LoadInt32(&peak) > 1)
+}
+
+func TestRangeParallel_StopsOnError(t *testing.T) {
+	is := is.New(t)
+	var running, peak int32
+	final := &mockHandler{name: "final"}
+
+	ranger := handlers.NewRangeParallel("test", echoValue(&running, &peak), 2, 1, -1, 3)
+	_, err := ranger.HandleThread(minds.NewThreadContext(context.Background()), final)
+
+	is.True(err != nil)
+	is.Equal(err.Error(), "test: value -1 failed")
+	is.Equal(0, final.Completed())
+}
+
+func TestRangeParallel_ContinueOnError(t *testing.T) {
+	is := is.New(t)
+	var running, peak int32
+
+	ranger := handlers.NewRangeParallel("test", echoValue(&running, &peak), 2, 1, -1, 3).ContinueOnError()
+	result, err := ranger.HandleThread(minds.NewThreadContext(context.Background()), nil)
+	is.NoErr(err)
+
+	msgs := result.Messages()
+	is.Equal(len(msgs), 2)
+	is.Equal(msgs[0].Content, "1")
+	is.Equal(msgs[1].Content, "3")
+
+	all := handlers.NewRangeParallel("test", echoValue(&running, &peak), 2, -1, -2).ContinueOnError()
+	_, err = all.HandleThread(minds.NewThreadContext(context.Background()), nil)
+	is.True(err != nil)
+}
+
+func TestRangeParallel_ContextCanceled(t *testing.T) {
+	is := is.New(t)
+	handler := &mockHandler{name: "handler"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ranger := handlers.NewRangeParallel("test", handler, 2, "a", "b")
+	_, err := ranger.HandleThread(minds.NewThreadContext(ctx), nil)
+	is.True(errors.Is(err, context.Canceled))
+	is.Equal(0, handler.Completed())
+}
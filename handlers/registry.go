@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/chriscow/minds"
+)
+
+// PipelineSpec is a declarative description of a handler tree that can be
+// loaded from JSON or YAML and assembled with BuildFromSpec.
+//
+// Example (YAML):
+//
+//	type: sequence
+//	name: main
+//	children:
+//	  - type: greet
+//	    name: hello
+//	  - type: switch
+//	    name: route
+//	    cases:
+//	      - key: intent
+//	        value: question
+//	        handler: {type: answer, name: qa}
+//	    default: {type: noop}
+type PipelineSpec struct {
+	// Type selects the HandlerFactory in the Registry, e.g. "sequence".
+	Type string `json:"type" yaml:"type"`
+	// Name is passed to the handler constructor.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Params holds handler specific settings such as "iterations" for "for".
+	Params map[string]any `json:"params,omitempty" yaml:"params,omitempty"`
+	// Children are the nested handlers of composite types.
+	Children []PipelineSpec `json:"children,omitempty" yaml:"children,omitempty"`
+	// Cases and Default are used by the "switch" type.
+	Cases   []SwitchCaseSpec `json:"cases,omitempty" yaml:"cases,omitempty"`
+	Default *PipelineSpec    `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// SwitchCaseSpec describes a Switch case that runs Handler when the thread
+// metadata value for Key equals Value.
+type SwitchCaseSpec struct {
+	Key     string       `json:"key" yaml:"key"`
+	Value   any          `json:"value" yaml:"value"`
+	Handler PipelineSpec `json:"handler" yaml:"handler"`
+}
+
+// HandlerFactory constructs a handler from a spec. Composite handlers use the
+// registry to build their children.
+type HandlerFactory func(spec PipelineSpec, registry *Registry) (minds.ThreadHandler, error)
+
+// Registry maps handler type names to factories. It is safe for concurrent
+// use, so pipelines can be rebuilt while the registry is being extended.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]HandlerFactory
+}
+
+// NewRegistry creates a Registry with the built-in handlers registered:
+// "sequence", "first", "must", "for", "switch" and "noop".
+//
+// Example:
+//
+//	registry := handlers.NewRegistry()
+//	registry.Register("summarize", func(spec handlers.PipelineSpec, _ *handlers.Registry) (minds.ThreadHandler, error) {
+//	    return handlers.NewSummarizer(llm, spec.Params["prompt"].(string)), nil
+//	})
+//	pipeline, err := handlers.BuildFromSpec(spec, registry)
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]HandlerFactory)}
+
+	r.factories["sequence"] = func(spec PipelineSpec, r *Registry) (minds.ThreadHandler, error) {
+		children, err := r.buildChildren(spec)
+		if err != nil {
+			return nil, err
+		}
+		return NewSequence(spec.Name, children...), nil
+	}
+
+	r.factories["first"] = func(spec PipelineSpec, r *Registry) (minds.ThreadHandler, error) {
+		children, err := r.buildChildren(spec)
+		if err != nil {
+			return nil, err
+		}
+		return NewFirst(spec.Name, children...), nil
+	}
+
+	r.factories["must"] = func(spec PipelineSpec, r *Registry) (minds.ThreadHandler, error) {
+		children, err := r.buildChildren(spec)
+		if err != nil {
+			return nil, err
+		}
+		return NewMust(spec.Name, DefaultAggregator, children...), nil
+	}
+
+	r.factories["for"] = func(spec PipelineSpec, r *Registry) (minds.ThreadHandler, error) {
+		if len(spec.Children) != 1 {
+			return nil, fmt.Errorf("%s: for requires exactly one child, got %d", spec.Name, len(spec.Children))
+		}
+		iterations, err := intParam(spec, "iterations")
+		if err != nil {
+			return nil, err
+		}
+		if iterations <= 0 {
+			return nil, fmt.Errorf("%s: param iterations must be positive, got %d", spec.Name, iterations)
+		}
+		child, err := r.Build(spec.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewFor(spec.Name, iterations, child, nil), nil
+	}
+
+	r.factories["switch"] = func(spec PipelineSpec, r *Registry) (minds.ThreadHandler, error) {
+		var defaultHandler minds.ThreadHandler = Noop()
+		if spec.Default != nil {
+			h, err := r.Build(*spec.Default)
+			if err != nil {
+				return nil, err
+			}
+			defaultHandler = h
+		}
+
+		cases := make([]SwitchCase, 0, len(spec.Cases))
+		for _, c := range spec.Cases {
+			h, err := r.Build(c.Handler)
+			if err != nil {
+				return nil, err
+			}
+			cases = append(cases, SwitchCase{
+				Condition: MetadataEquals{Key: c.Key, Value: c.Value},
+				Handler:   h,
+			})
+		}
+		return NewSwitch(spec.Name, defaultHandler, cases...), nil
+	}
+
+	r.factories["noop"] = func(PipelineSpec, *Registry) (minds.ThreadHandler, error) {
+		return Noop(), nil
+	}
+
+	return r
+}
+
+// Register adds a factory for the given handler type. It returns an error if
+// the type is already registered.
+func (r *Registry) Register(handlerType string, factory HandlerFactory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[handlerType]; exists {
+		return fmt.Errorf("handler type %s already registered", handlerType)
+	}
+	r.factories[handlerType] = factory
+	return nil
+}
+
+// Lookup retrieves the factory for a handler type.
+func (r *Registry) Lookup(handlerType string) (HandlerFactory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	factory, ok := r.factories[handlerType]
+	return factory, ok
+}
+
+// Types returns the registered handler types in sorted order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.factories))
+	for t := range r.factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Build constructs the handler described by spec, recursively building any
+// children.
+func (r *Registry) Build(spec PipelineSpec) (minds.ThreadHandler, error) {
+	factory, ok := r.Lookup(spec.Type)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown handler type %q. Registered types: %v", spec.Name, spec.Type, r.Types())
+	}
+
+	h, err := factory(spec, r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error building %s: %w", spec.Name, spec.Type, err)
+	}
+	return h, nil
+}
+
+func (r *Registry) buildChildren(spec PipelineSpec) ([]minds.ThreadHandler, error) {
+	children := make([]minds.ThreadHandler, 0, len(spec.Children))
+	for _, child := range spec.Children {
+		h, err := r.Build(child)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, h)
+	}
+	return children, nil
+}
+
+// BuildFromSpec assembles a handler tree from a declarative spec using the
+// factories in registry. If registry is nil, a registry containing only the
+// built-in handlers is used.
+//
+// Parameters:
+//   - spec: The pipeline description, typically decoded from JSON or YAML
+//   - registry: Factories for the handler types referenced by spec
+//
+// Returns:
+//   - The root handler of the assembled pipeline
+//
+// Example:
+//
+//	var spec handlers.PipelineSpec
+//	yaml.Unmarshal(config, &spec)
+//	pipeline, err := handlers.BuildFromSpec(spec, registry)
+//	result, err := pipeline.HandleThread(tc, nil)
+func BuildFromSpec(spec PipelineSpec, registry *Registry) (minds.ThreadHandler, error) {
+	if registry == nil {
+		registry = NewRegistry()
+	}
+	return registry.Build(spec)
+}
+
+// intParam reads a required integer parameter, accepting the numeric types
+// produced by JSON and YAML decoders.
+func intParam(spec PipelineSpec, key string) (int, error) {
+	v, ok := spec.Params[key]
+	if !ok {
+		return 0, fmt.Errorf("%s: missing required param %s", spec.Name, key)
+	}
+
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case float64:
+		if n != float64(int(n)) {
+			return 0, fmt.Errorf("%s: param %s must be an integer, got %v", spec.Name, key, n)
+		}
+		return int(n), nil
+	default:
+		return 0, fmt.Errorf("%s: param %s must be an integer, got %T", spec.Name, key, v)
+	}
+}
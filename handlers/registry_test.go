@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func appendFactory(spec handlers.PipelineSpec, _ *handlers.Registry) (minds.ThreadHandler, error) {
+	content, _ := spec.Params["content"].(string)
+	return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		tc.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: content})
+		return tc, nil
+	}), nil
+}
+
+func TestBuildFromSpec(t *testing.T) {
+	t.Run("builds nested pipeline from json", func(t *testing.T) {
+		is := is.New(t)
+		registry := handlers.NewRegistry()
+		is.NoErr(registry.Register("append", appendFactory))
+
+		config := `{
+			"type": "sequence",
+			"name": "main",
+			"children": [
+				{"type": "append", "name": "greet", "params": {"content": "hello"}},
+				{"type": "for", "name": "repeat", "params": {"iterations": 2}, "children": [
+					{"type": "append", "params": {"content": "again"}}
+				]},
+				{"type": "switch", "name": "route",
+					"cases": [{"key": "intent", "value": "question", "handler": {"type": "append", "params": {"content": "answer"}}}],
+					"default": {"type": "append", "params": {"content": "fallback"}}
+				}
+			]
+		}`
+
+		var spec handlers.PipelineSpec
+		is.NoErr(json.Unmarshal([]byte(config), &spec))
+
+		pipeline, err := handlers.BuildFromSpec(spec, registry)
+		is.NoErr(err)
+
+		tc := minds.NewThreadContext(context.Background()).WithMetadata(minds.Metadata{"intent": "question"})
+		result, err := pipeline.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		var contents []string
+		for _, m := range result.Messages() {
+			contents = append(contents, m.Content)
+		}
+		is.Equal(contents, []string{"hello", "again", "again", "answer"})
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		is := is.New(t)
+		_, err := handlers.BuildFromSpec(handlers.PipelineSpec{
+			Type:     "sequence",
+			Name:     "main",
+			Children: []handlers.PipelineSpec{{Type: "missing", Name: "child"}},
+		}, nil)
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), `unknown handler type "missing"`))
+	})
+
+	t.Run("for requires iterations", func(t *testing.T) {
+		child := handlers.PipelineSpec{Type: "noop"}
+		for name, params := range map[string]map[string]any{
+			"missing":    nil,
+			"misspelled": {"iteration": 3},
+			"zero":       {"iterations": 0},
+			"negative":   {"iterations": -1},
+		} {
+			t.Run(name, func(t *testing.T) {
+				is := is.New(t)
+				_, err := handlers.BuildFromSpec(handlers.PipelineSpec{
+					Type:     "for",
+					Name:     "repeat",
+					Params:   params,
+					Children: []handlers.PipelineSpec{child},
+				}, nil)
+				is.True(err != nil)
+				is.True(strings.Contains(err.Error(), "iterations"))
+			})
+		}
+	})
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		is := is.New(t)
+		registry := handlers.NewRegistry()
+		is.True(registry.Register("sequence", appendFactory) != nil)
+	})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ResponseSwitch routes a thread based on whether the last model response was
+// text or a tool call, the central decision of an agent loop.
+type ResponseSwitch struct {
+	name       string
+	onText     minds.ThreadHandler
+	onToolCall minds.ThreadHandler
+	onError    minds.ThreadHandler
+	middleware []minds.Middleware
+}
+
+// NewResponseSwitch creates a handler that routes the thread by the type of
+// the last response. The type is read from metadata under
+// minds.LastResponseTypeKey, which providers set when they handle a thread.
+// Without it, the last message is inspected instead: an assistant message
+// with tool calls is a tool call and any other assistant message is text.
+//
+// A nil onText or onToolCall handler passes the thread straight to the next
+// handler. When the type cannot be determined the thread goes to onError, or
+// an error is returned if onError is nil.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - onText: Handler for text responses
+//   - onToolCall: Handler for tool call responses
+//   - onError: Handler for threads whose last response type is unknown
+//
+// Returns:
+//   - A handler that routes the thread by response type
+//
+// Example:
+//
+//	route := handlers.NewResponseSwitch("route", respond, runTools, nil)
+//	agent := handlers.NewSequence("agent", llm, route)
+func NewResponseSwitch(name string, onText, onToolCall, onError minds.ThreadHandler) *ResponseSwitch {
+	return &ResponseSwitch{
+		name:       name,
+		onText:     onText,
+		onToolCall: onToolCall,
+		onError:    onError,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the ResponseSwitch handler. It wraps whichever
+// branch is taken.
+func (s *ResponseSwitch) Use(middleware ...minds.Middleware) {
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// With returns a new ResponseSwitch with additional middleware, preserving existing state.
+func (s *ResponseSwitch) With(middleware ...minds.Middleware) *ResponseSwitch {
+	newSwitch := &ResponseSwitch{
+		name:       s.name,
+		onText:     s.onText,
+		onToolCall: s.onToolCall,
+		onError:    s.onError,
+		middleware: append([]minds.Middleware{}, s.middleware...),
+	}
+	newSwitch.Use(middleware...)
+	return newSwitch
+}
+
+// HandleThread routes the thread to the branch for the last response type.
+func (s *ResponseSwitch) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler
+	switch responseType(tc) {
+	case minds.ResponseTypeText:
+		handler = s.onText
+	case minds.ResponseTypeToolCall:
+		handler = s.onToolCall
+	default:
+		if s.onError == nil {
+			return tc, fmt.Errorf("%s: unknown response type", s.name)
+		}
+		handler = s.onError
+	}
+
+	if handler == nil {
+		if next != nil {
+			return next.HandleThread(tc, nil)
+		}
+		return tc, nil
+	}
+
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i].Wrap(handler)
+	}
+
+	return handler.HandleThread(tc, next)
+}
+
+// responseType returns the type of the last response in the thread.
+func responseType(tc minds.ThreadContext) minds.ResponseType {
+	if rt, ok := tc.Metadata()[minds.LastResponseTypeKey].(minds.ResponseType); ok {
+		return rt
+	}
+
+	messages := tc.Messages()
+	if len(messages) == 0 {
+		return minds.ResponseTypeUnknown
+	}
+
+	switch last := messages.Last(); {
+	case last.Role != minds.RoleAssistant:
+		return minds.ResponseTypeUnknown
+	case len(last.ToolCalls) > 0:
+		return minds.ResponseTypeToolCall
+	default:
+		return minds.ResponseTypeText
+	}
+}
+
+// String returns a string representation of the ResponseSwitch handler.
+func (s *ResponseSwitch) String() string {
+	return fmt.Sprintf("ResponseSwitch(%s)", s.name)
+}
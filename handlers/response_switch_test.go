@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestResponseSwitch(t *testing.T) {
+	branch := func(name string) minds.ThreadHandler {
+		return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+			tc = tc.With(minds.SetKeyValue("branch", name))
+			if next != nil {
+				return next.HandleThread(tc, nil)
+			}
+			return tc, nil
+		})
+	}
+
+	newSwitch := func() *handlers.ResponseSwitch {
+		return handlers.NewResponseSwitch("route", branch("text"), branch("tool"), branch("error"))
+	}
+
+	thread := func(msgs ...minds.Message) minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).WithMessages(msgs...)
+	}
+
+	toolCall := minds.Message{
+		Role:      minds.RoleAssistant,
+		ToolCalls: []minds.ToolCall{{ID: "call_1", Function: minds.FunctionCall{Name: "lookup"}}},
+	}
+
+	tests := []struct {
+		name string
+		tc   minds.ThreadContext
+		want string
+	}{
+		{
+			name: "text from metadata",
+			tc:   thread(toolCall).With(minds.SetKeyValue(minds.LastResponseTypeKey, minds.ResponseTypeText)),
+			want: "text",
+		},
+		{
+			name: "tool call from metadata",
+			tc:   thread().With(minds.SetKeyValue(minds.LastResponseTypeKey, minds.ResponseTypeToolCall)),
+			want: "tool",
+		},
+		{
+			name: "text from last message",
+			tc:   thread(minds.Message{Role: minds.RoleAssistant, Content: "Hi"}),
+			want: "text",
+		},
+		{
+			name: "tool call from last message",
+			tc:   thread(toolCall),
+			want: "tool",
+		},
+		{
+			name: "unknown without a response",
+			tc:   thread(minds.Message{Role: minds.RoleUser, Content: "Hi"}),
+			want: "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			result, err := newSwitch().HandleThread(tt.tc, nil)
+			is.NoErr(err)
+			is.Equal(result.Metadata()["branch"], tt.want)
+		})
+	}
+
+	t.Run("passes the thread to next after the branch", func(t *testing.T) {
+		is := is.New(t)
+		tc := thread(minds.Message{Role: minds.RoleAssistant, Content: "Hi"})
+
+		result, err := newSwitch().HandleThread(tc, branch("next"))
+		is.NoErr(err)
+		is.Equal(result.Metadata()["branch"], "next")
+	})
+
+	t.Run("nil branch passes through", func(t *testing.T) {
+		is := is.New(t)
+		tc := thread(minds.Message{Role: minds.RoleAssistant, Content: "Hi"})
+
+		result, err := handlers.NewResponseSwitch("route", nil, branch("tool"), nil).HandleThread(tc, branch("next"))
+		is.NoErr(err)
+		is.Equal(result.Metadata()["branch"], "next")
+	})
+
+	t.Run("unknown without an error handler fails", func(t *testing.T) {
+		is := is.New(t)
+		_, err := handlers.NewResponseSwitch("route", branch("text"), branch("tool"), nil).HandleThread(thread(), nil)
+		is.True(err != nil)
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ScratchpadKey is the thread metadata key holding the reasoning the model
+// wrote before its answer in the last Scratchpad step.
+const ScratchpadKey = "scratchpad"
+
+const scratchpadPrompt = `Before answering, think the problem through step by step in a private
+scratchpad. Respond with a JSON object with two fields: "reasoning", your
+step-by-step thinking, which the user will not see, and "answer", the final
+answer to show the user. The answer must stand on its own without the
+reasoning.`
+
+// ScratchpadResponse is the structured response requested by Scratchpad.
+type ScratchpadResponse struct {
+	Reasoning string `json:"reasoning" description:"Step-by-step thinking about the problem, hidden from the user"`
+	Answer    string `json:"answer" description:"The final answer shown to the user"`
+}
+
+// Scratchpad asks the model to think before it answers, keeping the thinking
+// out of the conversation.
+type Scratchpad struct {
+	name       string
+	generator  minds.ContentGenerator
+	middleware []minds.Middleware
+}
+
+// NewScratchpad creates a handler that implements the think-then-answer
+// pattern. The generator is asked for a ScratchpadResponse; the answer is
+// appended to the thread as an assistant message and the reasoning is stored
+// in metadata under ScratchpadKey, where it is available for logging but is
+// not shown to the user or sent to the model on later turns.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM used to reason and answer
+//
+// Returns:
+//   - A handler that appends the answer and records the reasoning
+//
+// Example:
+//
+//	think := handlers.NewScratchpad("think", llm)
+//	result, err := think.HandleThread(tc, nil)
+//	log.Println(result.Metadata()[handlers.ScratchpadKey])
+func NewScratchpad(name string, generator minds.ContentGenerator) *Scratchpad {
+	if generator == nil {
+		panic(fmt.Sprintf("%s: generator cannot be nil", name))
+	}
+
+	return &Scratchpad{
+		name:       name,
+		generator:  generator,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the Scratchpad handler.
+func (s *Scratchpad) Use(middleware ...minds.Middleware) {
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// With returns a new Scratchpad handler with additional middleware, preserving existing state.
+func (s *Scratchpad) With(middleware ...minds.Middleware) *Scratchpad {
+	newScratchpad := &Scratchpad{
+		name:       s.name,
+		generator:  s.generator,
+		middleware: append([]minds.Middleware{}, s.middleware...),
+	}
+	newScratchpad.Use(middleware...)
+	return newScratchpad
+}
+
+// HandleThread generates a reasoned answer and passes the thread on.
+func (s *Scratchpad) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return s.think(tc)
+	})
+
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (s *Scratchpad) think(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	schema, err := minds.NewResponseSchema("Scratchpad", "Reasoning followed by an answer", ScratchpadResponse{})
+	if err != nil {
+		return tc, fmt.Errorf("%s: failed to generate schema: %w", s.name, err)
+	}
+
+	messages := append(minds.Messages{{Role: minds.RoleSystem, Content: scratchpadPrompt}}, tc.Messages()...)
+	req := minds.NewRequest(messages,
+		minds.WithResponseSchema(*schema),
+		minds.WithRequestMetadata(tc.Metadata()),
+	)
+
+	resp, err := s.generator.GenerateContent(tc.Context(), req)
+	if err != nil {
+		return tc, fmt.Errorf("%s: error generating content: %w", s.name, err)
+	}
+
+	var pad ScratchpadResponse
+	if err := json.Unmarshal([]byte(resp.String()), &pad); err != nil {
+		return tc, fmt.Errorf("%s: failed to unmarshal scratchpad response (%s): %w", s.name, resp.String(), err)
+	}
+
+	return tc.With(
+		minds.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: pad.Answer}),
+		minds.SetKeyValue(ScratchpadKey, pad.Reasoning),
+	), nil
+}
+
+// String returns a string representation of the Scratchpad handler.
+func (s *Scratchpad) String() string {
+	return fmt.Sprintf("Scratchpad(%s)", s.name)
+}
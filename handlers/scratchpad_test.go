@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestScratchpad(t *testing.T) {
+	t.Run("keeps the reasoning out of the answer", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(`{"reasoning": "17 * 3 = 51, plus 4 is 55", "answer": "55"}`), nil
+		}}
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "What is 17 * 3 + 4?"})
+
+		result, err := handlers.NewScratchpad("think", provider).HandleThread(tc, nil)
+		is.NoErr(err)
+
+		req := provider.requests[0]
+		is.Equal(req.Messages[0].Role, minds.RoleSystem)
+		is.Equal(req.Options.ResponseSchema.Name, "Scratchpad")
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 2)
+		is.Equal(msgs[1].Role, minds.RoleAssistant)
+		is.Equal(msgs[1].Content, "55")
+		is.Equal(result.Metadata()[handlers.ScratchpadKey], "17 * 3 = 51, plus 4 is 55")
+	})
+
+	t.Run("rejects malformed responses", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("55"), nil
+		}}
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "What is 17 * 3 + 4?"})
+
+		_, err := handlers.NewScratchpad("think", provider).HandleThread(tc, nil)
+		is.True(err != nil)
+	})
+
+	t.Run("wraps generator errors", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return nil, errHandlerFailed
+		}}
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Hi"})
+		_, err := handlers.NewScratchpad("think", provider).HandleThread(tc, nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+}
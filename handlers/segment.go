@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// SegmentIndexKey is the metadata key holding the zero-based index of the
+// segment a Segment handler is processing.
+const SegmentIndexKey = "segment_index"
+
+// Segment splits a thread into segments at boundary messages and runs a
+// handler on each segment independently. It is useful for threads that hold
+// several logical sections, such as a batch of transcripts.
+type Segment struct {
+	name       string
+	isBoundary func(minds.Message) bool
+	handler    minds.ThreadHandler
+	aggregator ResultAggregator
+	middleware []minds.Middleware
+}
+
+// NewSegment creates a handler that processes each segment of a thread
+// separately. A boundary message starts a new segment and is its first
+// message; messages before the first boundary form a leading segment. Each
+// segment runs through sub in order, as a clone of the thread holding only
+// the segment's messages, with its index stored in metadata under
+// SegmentIndexKey. The results are combined with agg.
+//
+// If sub fails for any segment, processing stops and the error is returned.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - isBoundary: Reports whether a message starts a new segment
+//   - sub: The handler to run on each segment
+//   - agg: Combines the segment results; DefaultAggregator if nil
+//
+// Returns:
+//   - A handler that processes each segment and aggregates the results
+//
+// Example:
+//
+//	isHeader := func(msg minds.Message) bool {
+//	    return strings.HasPrefix(msg.Content, "## ")
+//	}
+//	perSection := handlers.NewSegment("sections", isHeader, summarizer, nil)
+func NewSegment(name string, isBoundary func(minds.Message) bool, sub minds.ThreadHandler, agg ResultAggregator) *Segment {
+	if sub == nil {
+		panic(fmt.Sprintf("%s: handler cannot be nil", name))
+	}
+
+	if agg == nil {
+		agg = DefaultAggregator
+	}
+
+	return &Segment{
+		name:       name,
+		isBoundary: isBoundary,
+		handler:    sub,
+		aggregator: agg,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the Segment handler, wrapping the handler run on
+// each segment.
+func (s *Segment) Use(middleware ...minds.Middleware) {
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// With returns a new Segment handler with additional middleware, preserving existing state.
+func (s *Segment) With(middleware ...minds.Middleware) *Segment {
+	newSegment := &Segment{
+		name:       s.name,
+		isBoundary: s.isBoundary,
+		handler:    s.handler,
+		aggregator: s.aggregator,
+		middleware: append([]minds.Middleware{}, s.middleware...),
+	}
+	newSegment.Use(middleware...)
+	return newSegment
+}
+
+// HandleThread runs the handler on each segment and passes the aggregated
+// result on.
+func (s *Segment) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	segments := s.split(tc.Messages())
+	if len(segments) == 0 {
+		if next != nil {
+			return next.HandleThread(tc, nil)
+		}
+		return tc, nil
+	}
+
+	handler := s.handler
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i].Wrap(handler)
+	}
+
+	results := make([]HandlerResult, 0, len(segments))
+	for i, segment := range segments {
+		if tc.Context().Err() != nil {
+			return tc, tc.Context().Err()
+		}
+
+		segmentTc := tc.With(
+			minds.ReplaceMessages(segment...),
+			minds.SetKeyValue(SegmentIndexKey, i),
+		)
+
+		result, err := handler.HandleThread(segmentTc, nil)
+		if err != nil {
+			return tc, fmt.Errorf("%s: segment %d: %w", s.name, i, err)
+		}
+
+		results = append(results, HandlerResult{
+			Handler: s.handler,
+			Context: result,
+		})
+	}
+
+	finalTc, err := s.aggregator(results)
+	if err != nil {
+		return tc, fmt.Errorf("%s aggregation: %w", s.name, err)
+	}
+
+	if next != nil {
+		return next.HandleThread(finalTc, nil)
+	}
+
+	return finalTc, nil
+}
+
+// split groups messages into segments, starting a new one at each boundary.
+func (s *Segment) split(messages minds.Messages) []minds.Messages {
+	var segments []minds.Messages
+	var current minds.Messages
+	for _, msg := range messages {
+		if s.isBoundary(msg) && len(current) > 0 {
+			segments = append(segments, current)
+			current = nil
+		}
+		current = append(current, msg)
+	}
+
+	if len(current) > 0 {
+		segments = append(segments, current)
+	}
+
+	return segments
+}
+
+// String returns a string representation of the Segment handler.
+func (s *Segment) String() string {
+	return fmt.Sprintf("Segment(%s)", s.name)
+}
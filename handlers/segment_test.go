@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestSegment(t *testing.T) {
+	isHeader := func(msg minds.Message) bool {
+		return strings.HasPrefix(msg.Content, "## ")
+	}
+
+	tc := minds.NewThreadContext(context.Background()).WithMessages(
+		minds.Message{Role: minds.RoleUser, Content: "preamble"},
+		minds.Message{Role: minds.RoleUser, Content: "## One"},
+		minds.Message{Role: minds.RoleUser, Content: "first body"},
+		minds.Message{Role: minds.RoleUser, Content: "## Two"},
+		minds.Message{Role: minds.RoleUser, Content: "second body"},
+	)
+
+	t.Run("processes each segment independently", func(t *testing.T) {
+		is := is.New(t)
+		var seen [][]string
+		count := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			var contents []string
+			for _, msg := range tc.Messages() {
+				contents = append(contents, msg.Content)
+			}
+			seen = append(seen, contents)
+
+			index := tc.Metadata()[handlers.SegmentIndexKey].(int)
+			return tc.With(
+				minds.ReplaceMessages(minds.Message{Role: minds.RoleAssistant, Content: fmt.Sprintf("segment %d: %d messages", index, len(contents))}),
+				minds.SetKeyValue(fmt.Sprintf("seen_%d", index), true),
+			), nil
+		})
+
+		result, err := handlers.NewSegment("sections", isHeader, count, nil).HandleThread(tc, nil)
+		is.NoErr(err)
+
+		is.Equal(seen, [][]string{
+			{"preamble"},
+			{"## One", "first body"},
+			{"## Two", "second body"},
+		})
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 3)
+		is.Equal(msgs[2].Content, "segment 2: 2 messages")
+		is.Equal(result.Metadata()["seen_0"], true)
+		is.Equal(result.Metadata()["seen_2"], true)
+
+		// The original thread is untouched.
+		is.Equal(len(tc.Messages()), 5)
+	})
+
+	t.Run("stops at the first failing segment", func(t *testing.T) {
+		is := is.New(t)
+		calls := 0
+		failing := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			calls++
+			if tc.Metadata()[handlers.SegmentIndexKey] == 1 {
+				return tc, errHandlerFailed
+			}
+			return tc, nil
+		})
+
+		_, err := handlers.NewSegment("sections", isHeader, failing, nil).HandleThread(tc, nil)
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), "segment 1"))
+		is.Equal(calls, 2)
+	})
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/chriscow/minds"
@@ -15,6 +14,7 @@ type StructuredExtractor struct {
 	generator  minds.ContentGenerator
 	prompt     string
 	schema     minds.ResponseSchema
+	options    extractorOptions
 	middleware []minds.Middleware
 }
 
@@ -23,12 +23,17 @@ type StructuredExtractor struct {
 // The generator is used to analyze messages with the given prompt and schema.
 // The prompt should instruct the LLM to extract structured data from the conversation.
 // The schema defines the structure of the data to extract.
-func NewStructuredExtractor(name string, generator minds.ContentGenerator, prompt string, schema minds.ResponseSchema) *StructuredExtractor {
+// Malformed JSON responses are repaired before parsing unless disabled with
+// WithJSONRepair(false). Use WithConfidenceField to also capture the model's
+// confidence in the extraction.
+// Use WithChunking to extract from long conversations in concurrent chunks.
+func NewStructuredExtractor(name string, generator minds.ContentGenerator, prompt string, schema minds.ResponseSchema, opts ...ExtractorOption) *StructuredExtractor {
 	return &StructuredExtractor{
 		name:       name,
 		generator:  generator,
 		prompt:     prompt,
 		schema:     schema,
+		options:    newExtractorOptions(opts...),
 		middleware: []minds.Middleware{},
 	}
 }
@@ -45,6 +50,7 @@ func (s *StructuredExtractor) With(middleware ...minds.Middleware) *StructuredEx
 		generator:  s.generator,
 		prompt:     s.prompt,
 		schema:     s.schema,
+		options:    s.options,
 		middleware: append([]minds.Middleware{}, s.middleware...),
 	}
 	newExtractor.Use(middleware...)
@@ -74,8 +80,24 @@ func (s *StructuredExtractor) HandleThread(tc minds.ThreadContext, next minds.Th
 	return s.extractData(tc, next)
 }
 
-// extractData performs the actual data extraction logic
+// extractData performs the extraction, in chunks if configured, and passes
+// the result on.
 func (s *StructuredExtractor) extractData(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	newTc, err := s.options.extract(tc, s.name, s.extract)
+	if err != nil {
+		return tc, err
+	}
+
+	// Process next handler if provided
+	if next != nil {
+		return next.HandleThread(newTc, nil)
+	}
+
+	return newTc, nil
+}
+
+// extract performs the actual data extraction logic
+func (s *StructuredExtractor) extract(tc minds.ThreadContext) (minds.ThreadContext, error) {
 	// Create a message that combines the instruction prompt with the conversation context
 	messages := minds.Messages{
 		{Role: minds.RoleSystem, Content: s.prompt},
@@ -90,7 +112,12 @@ func (s *StructuredExtractor) extractData(tc minds.ThreadContext, next minds.Thr
 	}
 
 	// Create and send the request to the LLM
-	req := minds.NewRequest(messages, minds.WithResponseSchema(s.schema))
+	schema := s.schema
+	if s.options.confidence {
+		schema = withConfidenceField(schema)
+	}
+
+	req := minds.NewRequest(messages, minds.WithResponseSchema(schema))
 	resp, err := s.generator.GenerateContent(tc.Context(), req)
 	if err != nil {
 		return tc, fmt.Errorf("%s: error generating content: %w", s.name, err)
@@ -101,21 +128,58 @@ func (s *StructuredExtractor) extractData(tc minds.ThreadContext, next minds.Thr
 
 	// Parse the response as a generic JSON structure
 	var data any
-	if err := json.Unmarshal([]byte(resp.String()), &data); err != nil {
+	if err := s.options.unmarshal([]byte(resp.String()), &data); err != nil {
 		return tc, fmt.Errorf("%s: error parsing structured data: %w", s.name, err)
 	}
 
 	// Store the structured data in metadata using the schema name as the key
 	newTc.SetKeyValue(s.schema.Name, data)
-
-	// Process next handler if provided
-	if next != nil {
-		return next.HandleThread(newTc, nil)
+	if s.options.confidence {
+		if confidence, ok := popConfidence(data); ok {
+			newTc.SetKeyValue(s.schema.Name+"_confidence", confidence)
+		}
 	}
 
 	return newTc, nil
 }
 
+// confidenceField is the property added to the schema by WithConfidenceField.
+const confidenceField = "_confidence"
+
+// withConfidenceField returns a copy of schema with a required confidence
+// property added to its root object.
+func withConfidenceField(schema minds.ResponseSchema) minds.ResponseSchema {
+	if schema.Definition.Type != minds.Object {
+		return schema
+	}
+
+	properties := make(map[string]minds.Definition, len(schema.Definition.Properties)+1)
+	for name, def := range schema.Definition.Properties {
+		properties[name] = def
+	}
+	properties[confidenceField] = minds.Definition{
+		Type:        minds.Number,
+		Description: "Your confidence in the accuracy of this extraction, from 0 (a guess) to 1 (certain)",
+	}
+
+	schema.Definition.Properties = properties
+	schema.Definition.Required = append(append([]string{}, schema.Definition.Required...), confidenceField)
+	return schema
+}
+
+// popConfidence removes the confidence property from data and returns it.
+func popConfidence(data any) (float64, bool) {
+	object, ok := data.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+
+	value, exists := object[confidenceField]
+	delete(object, confidenceField)
+	confidence, ok := value.(float64)
+	return confidence, exists && ok
+}
+
 // String returns a string representation of the StructuredExtractor handler.
 func (s *StructuredExtractor) String() string {
 	return fmt.Sprintf("StructuredExtractor(%s)", s.name)
@@ -188,3 +188,62 @@ func TestStructuredExtractor_WithMiddleware(t *testing.T) {
 	// Check the middleware was applied
 	is.Equal(metadata["address_validated"], true)
 }
+
+func TestStructuredExtractor_RepairsJSON(t *testing.T) {
+	type PersonInfo struct {
+		Name string `json:"name"`
+	}
+
+	schema, err := minds.NewResponseSchema("person_info", "Information about a person", PersonInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generator := &MockContentGenerator{response: "```json\n{name: \"Jane Smith\",}\n```"}
+	tc := minds.NewThreadContext(context.Background()).WithMessages(
+		minds.Message{Role: minds.RoleUser, Content: "Hello, my name is Jane Smith"},
+	)
+
+	t.Run("repairs malformed JSON by default", func(t *testing.T) {
+		is := is.New(t)
+		result, err := NewStructuredExtractor("person", generator, "Extract the name.", *schema).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(result.Metadata()["person_info"], map[string]any{"name": "Jane Smith"})
+	})
+
+	t.Run("repair can be disabled", func(t *testing.T) {
+		is := is.New(t)
+		_, err := NewStructuredExtractor("person", generator, "Extract the name.", *schema, WithJSONRepair(false)).HandleThread(tc, nil)
+		is.True(err != nil)
+	})
+}
+
+func TestStructuredExtractor_WithConfidenceField(t *testing.T) {
+	is := is.New(t)
+
+	type PersonInfo struct {
+		Name string `json:"name"`
+	}
+
+	schema, err := minds.NewResponseSchema("person_info", "Information about a person", PersonInfo{})
+	is.NoErr(err)
+
+	generator := &MockContentGenerator{response: `{"name": "Jane Smith", "_confidence": 0.8}`}
+	extractor := NewStructuredExtractor("person-extractor", generator, "Extract the person's name.", *schema, WithConfidenceField(true))
+
+	tc := minds.NewThreadContext(context.Background())
+	tc.AppendMessages(minds.Message{Role: minds.RoleUser, Content: "I'm Jane Smith"})
+
+	result, err := extractor.HandleThread(tc, nil)
+	is.NoErr(err)
+
+	sent := generator.requests[0].Options.ResponseSchema.Definition
+	is.Equal(sent.Properties["_confidence"].Type, minds.Number)
+	is.Equal(sent.Required, []string{"name", "_confidence"})
+	_, changed := schema.Definition.Properties["_confidence"]
+	is.True(!changed) // the caller's schema is left alone
+
+	metadata := result.Metadata()
+	is.Equal(metadata["person_info"], map[string]any{"name": "Jane Smith"})
+	is.Equal(metadata["person_info_confidence"], 0.8)
+}
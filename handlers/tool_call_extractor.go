@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ErrNoToolCall is returned by ToolCallExtractor when the model answers
+// without calling the tool.
+var ErrNoToolCall = errors.New("model did not call the tool")
+
+// ToolCallExtractor has the model propose a call to a tool and stores the
+// arguments in the thread metadata without running the tool.
+type ToolCallExtractor struct {
+	name       string
+	generator  minds.ContentGenerator
+	tool       minds.Tool
+	middleware []minds.Middleware
+}
+
+// NewToolCallExtractor creates a handler that asks generator to call tool
+// and stores the arguments of the call in the thread metadata under the
+// tool's name, as a map[string]any. The tool is never invoked: use it when
+// the tool only describes the arguments to elicit and your own code acts on
+// them. The request sends the thread's messages with tool as the only tool
+// and requires the model to call it. The arguments are validated against
+// the tool's parameters. The handler fails with ErrNoToolCall if the model
+// does not call the tool.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM that proposes the call
+//   - tool: The tool whose arguments to extract
+//
+// Returns:
+//   - A handler that extracts tool call arguments into metadata
+//
+// Example:
+//
+//	booking, _ := minds.WrapFunction("book_flight", "Books a flight", BookingArgs{}, nil)
+//	extract := handlers.NewToolCallExtractor("booking", llm, booking)
+//	result, err := extract.HandleThread(tc, nil)
+//	args := result.Metadata()["book_flight"].(map[string]any)
+func NewToolCallExtractor(name string, generator minds.ContentGenerator, tool minds.Tool) *ToolCallExtractor {
+	if generator == nil {
+		panic(fmt.Sprintf("%s: generator cannot be nil", name))
+	}
+	if tool == nil {
+		panic(fmt.Sprintf("%s: tool cannot be nil", name))
+	}
+
+	return &ToolCallExtractor{
+		name:       name,
+		generator:  generator,
+		tool:       tool,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the ToolCallExtractor handler.
+func (t *ToolCallExtractor) Use(middleware ...minds.Middleware) {
+	t.middleware = append(t.middleware, middleware...)
+}
+
+// With returns a new ToolCallExtractor with additional middleware, preserving existing state.
+func (t *ToolCallExtractor) With(middleware ...minds.Middleware) *ToolCallExtractor {
+	newExtractor := &ToolCallExtractor{
+		name:       t.name,
+		generator:  t.generator,
+		tool:       t.tool,
+		middleware: append([]minds.Middleware{}, t.middleware...),
+	}
+	newExtractor.Use(middleware...)
+	return newExtractor
+}
+
+// HandleThread extracts the tool call arguments and passes the thread on.
+func (t *ToolCallExtractor) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return t.extract(tc)
+	})
+
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		handler = t.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (t *ToolCallExtractor) extract(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	registry := minds.NewToolRegistry()
+	if err := registry.Register(proposedTool{t.tool}); err != nil {
+		return tc, fmt.Errorf("%s: %w", t.name, err)
+	}
+
+	req := minds.NewRequest(tc.Messages(),
+		minds.WithToolRegistry(registry),
+		minds.WithToolChoice("required"),
+		minds.WithRequestMetadata(tc.Metadata()))
+
+	resp, err := t.generator.GenerateContent(tc.Context(), req)
+	if err != nil {
+		return tc, fmt.Errorf("%s: error generating content: %w", t.name, err)
+	}
+
+	var params []byte
+	found := false
+	for _, call := range resp.ToolCalls() {
+		if call.Function.Name == t.tool.Name() {
+			params = call.Function.Parameters
+			found = true
+			break
+		}
+	}
+	if !found {
+		return tc, fmt.Errorf("%s: %w `%s`", t.name, ErrNoToolCall, t.tool.Name())
+	}
+
+	if err := minds.ValidateArguments(t.tool.Parameters(), params); err != nil {
+		return tc, fmt.Errorf("%s: invalid arguments for `%s`: %w", t.name, t.tool.Name(), err)
+	}
+
+	args := map[string]any{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return tc, fmt.Errorf("%s: error parsing arguments for `%s`: %w", t.name, t.tool.Name(), err)
+		}
+	}
+
+	return tc.With(minds.SetKeyValue(t.tool.Name(), args)), nil
+}
+
+// String returns a string representation of the ToolCallExtractor handler.
+func (t *ToolCallExtractor) String() string {
+	return fmt.Sprintf("ToolCallExtractor(%s, %s)", t.name, t.tool.Name())
+}
+
+// proposedTool describes a tool to the model without running it, so
+// providers that execute tool calls leave the call unanswered.
+type proposedTool struct {
+	minds.Tool
+}
+
+func (proposedTool) Call(context.Context, []byte) ([]byte, error) {
+	return nil, nil
+}
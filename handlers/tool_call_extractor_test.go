@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestToolCallExtractor(t *testing.T) {
+	type bookingArgs struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	executed := false
+	booking, err := minds.WrapFunction("book_flight", "Books a flight", bookingArgs{},
+		func(context.Context, []byte) ([]byte, error) {
+			executed = true
+			return []byte(`"booked"`), nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newThread := func() minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Book me a flight from SFO to JFK"},
+		)
+	}
+
+	// callingProvider proposes a call with args and, like the real
+	// providers, runs it against the request's registry.
+	callingProvider := func(name, args string) *recordingProvider {
+		return &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			calls := []minds.ToolCall{{ID: "call_1", Function: minds.FunctionCall{Name: name, Parameters: []byte(args)}}}
+			calls, err := minds.HandleFunctionCalls(context.Background(), calls, req.Options.ToolRegistry)
+			if err != nil {
+				return nil, err
+			}
+			return mockResponse{Calls: calls}, nil
+		}}
+	}
+
+	t.Run("extracts arguments without running the tool", func(t *testing.T) {
+		is := is.New(t)
+		executed = false
+		provider := callingProvider("book_flight", `{"from":"SFO","to":"JFK"}`)
+
+		var nextCalled bool
+		next := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			nextCalled = true
+			return tc, nil
+		})
+
+		result, err := handlers.NewToolCallExtractor("booking", provider, booking).HandleThread(newThread(), next)
+		is.NoErr(err)
+		is.True(!executed)
+		is.True(nextCalled)
+		is.Equal(result.Metadata()["book_flight"], map[string]any{"from": "SFO", "to": "JFK"})
+		is.Equal(result.Messages(), newThread().Messages())
+
+		req := provider.requests[0]
+		is.Equal(req.Options.ToolChoice, "required")
+		is.Equal(len(req.Options.ToolRegistry.List()), 1)
+		is.Equal(req.Messages, newThread().Messages())
+	})
+
+	t.Run("fails without a call to the tool", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("Sure, where to?"), nil
+		}}
+
+		_, err := handlers.NewToolCallExtractor("booking", provider, booking).HandleThread(newThread(), nil)
+		is.True(errors.Is(err, handlers.ErrNoToolCall))
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		is := is.New(t)
+		provider := callingProvider("book_flight", `{"from":1}`)
+
+		_, err := handlers.NewToolCallExtractor("booking", provider, booking).HandleThread(newThread(), nil)
+		is.True(err != nil)
+		is.True(!errors.Is(err, handlers.ErrNoToolCall))
+	})
+
+	t.Run("generator errors", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return nil, errHandlerFailed
+		}}
+
+		_, err := handlers.NewToolCallExtractor("booking", provider, booking).HandleThread(newThread(), nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+}
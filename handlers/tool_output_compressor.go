@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ToolOutputsKey is the thread metadata key holding the []ToolOutput
+// replaced by ToolOutputCompressor, oldest first.
+const ToolOutputsKey = "tool_outputs"
+
+const toolOutputCompressorPrompt = `Summarize the output of the tool %q for an assistant that called it while
+working on the user's request. Keep every fact, figure, identifier and error
+relevant to the request and drop the rest. Respond with the summary only,
+in at most %d characters.`
+
+// ToolOutput is a tool result that ToolOutputCompressor replaced with a
+// digest, kept for auditing.
+type ToolOutput struct {
+	// CallID is the ID of the tool call, if the provider set one.
+	CallID string
+	Name   string
+	// Output is the full result returned by the tool.
+	Output []byte
+	// Digest is the summary that replaced it.
+	Digest string
+}
+
+// ToolOutputCompressor summarizes long tool results so they fit in the
+// model's context.
+type ToolOutputCompressor struct {
+	name       string
+	generator  minds.ContentGenerator
+	maxChars   int
+	middleware []minds.Middleware
+}
+
+// NewToolOutputCompressor creates a handler for agent loops with verbose
+// tools, such as web fetches or SQL queries, whose results would otherwise
+// fill the context window when fed back to the model. Place it after the
+// handler that executes the tool calls and before the one that appends the
+// results as RoleFunction messages, such as ToolResponder.
+//
+// Every result of the last tool calls longer than maxChars characters is
+// replaced with a digest written by generator, with the last user message as
+// guidance on what to keep. The digest is cut to maxChars if the generator
+// exceeds it. The calls are updated both on the last message and in metadata
+// under minds.LastToolCallsKey. The full outputs are appended as ToolOutput
+// values under ToolOutputsKey. Threads without tool calls, or whose results
+// are all short enough, pass through unchanged.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM used to summarize long results
+//   - maxChars: The longest result, in characters, passed on as is
+//
+// Returns:
+//   - A handler that shortens long tool results
+//
+// Example:
+//
+//	compress := handlers.NewToolOutputCompressor("compress", llm, 4000)
+//	agent := handlers.NewSequence("agent", callTools, compress, handlers.NewToolResponder("answer", llm))
+func NewToolOutputCompressor(name string, generator minds.ContentGenerator, maxChars int) *ToolOutputCompressor {
+	if generator == nil {
+		panic(fmt.Sprintf("%s: generator cannot be nil", name))
+	}
+
+	return &ToolOutputCompressor{
+		name:       name,
+		generator:  generator,
+		maxChars:   maxChars,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the ToolOutputCompressor handler.
+func (c *ToolOutputCompressor) Use(middleware ...minds.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// With returns a new ToolOutputCompressor with additional middleware, preserving existing state.
+func (c *ToolOutputCompressor) With(middleware ...minds.Middleware) *ToolOutputCompressor {
+	newCompressor := &ToolOutputCompressor{
+		name:       c.name,
+		generator:  c.generator,
+		maxChars:   c.maxChars,
+		middleware: append([]minds.Middleware{}, c.middleware...),
+	}
+	newCompressor.Use(middleware...)
+	return newCompressor
+}
+
+// HandleThread shortens long tool results and passes the thread on.
+func (c *ToolOutputCompressor) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return c.compress(tc)
+	})
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (c *ToolOutputCompressor) compress(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	messages := tc.Messages()
+	last := len(messages) - 1
+
+	var calls []minds.ToolCall
+	if last >= 0 && len(messages[last].ToolCalls) > 0 {
+		calls = messages[last].ToolCalls
+	}
+	metaCalls, _ := tc.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall)
+	if calls == nil {
+		calls = metaCalls
+	}
+
+	// Summarize each long result once, even if it appears in both places.
+	digests := map[string]string{}
+	var outputs []ToolOutput
+	for _, call := range calls {
+		result := string(call.Function.Result)
+		if _, done := digests[result]; done || len([]rune(result)) <= c.maxChars {
+			continue
+		}
+
+		if tc.Context().Err() != nil {
+			return tc, tc.Context().Err()
+		}
+
+		digest, err := c.summarize(tc, call)
+		if err != nil {
+			return tc, err
+		}
+
+		digests[result] = digest
+		outputs = append(outputs, ToolOutput{
+			CallID: call.ID,
+			Name:   call.Function.Name,
+			Output: call.Function.Result,
+			Digest: digest,
+		})
+	}
+
+	if len(outputs) == 0 {
+		return tc, nil
+	}
+
+	replace := func(calls []minds.ToolCall) []minds.ToolCall {
+		replaced := append([]minds.ToolCall{}, calls...)
+		for i, call := range replaced {
+			if digest, ok := digests[string(call.Function.Result)]; ok {
+				replaced[i].Function.Result = []byte(digest)
+			}
+		}
+		return replaced
+	}
+
+	var opts []minds.ThreadOption
+	if last >= 0 && len(messages[last].ToolCalls) > 0 {
+		messages[last].ToolCalls = replace(messages[last].ToolCalls)
+		opts = append(opts, minds.ReplaceMessages(messages...))
+	}
+	if metaCalls != nil {
+		opts = append(opts, minds.SetKeyValue(minds.LastToolCallsKey, replace(metaCalls)))
+	}
+
+	history, _ := tc.Metadata()[ToolOutputsKey].([]ToolOutput)
+	history = append(append([]ToolOutput{}, history...), outputs...)
+	opts = append(opts, minds.SetKeyValue(ToolOutputsKey, history))
+
+	return tc.With(opts...), nil
+}
+
+// summarize asks the generator for a digest of the call's result.
+func (c *ToolOutputCompressor) summarize(tc minds.ThreadContext, call minds.ToolCall) (string, error) {
+	var request string
+	for _, msg := range tc.Messages().Only(minds.RoleUser) {
+		request = msg.Content
+	}
+
+	req := minds.NewRequest(minds.Messages{
+		{Role: minds.RoleSystem, Content: fmt.Sprintf(toolOutputCompressorPrompt, call.Function.Name, c.maxChars)},
+		{Role: minds.RoleUser, Content: fmt.Sprintf("Request:\n%s\n\nArguments:\n%s\n\nOutput:\n%s",
+			request, call.Function.Parameters, call.Function.Result)},
+	})
+
+	resp, err := c.generator.GenerateContent(tc.Context(), req)
+	if err != nil {
+		return "", fmt.Errorf("%s: error summarizing output of `%s`: %w", c.name, call.Function.Name, err)
+	}
+
+	digest := []rune(resp.String())
+	if len(digest) > c.maxChars {
+		digest = digest[:c.maxChars]
+	}
+
+	return string(digest), nil
+}
+
+// String returns a string representation of the ToolOutputCompressor handler.
+func (c *ToolOutputCompressor) String() string {
+	return fmt.Sprintf("ToolOutputCompressor(%s, %d chars)", c.name, c.maxChars)
+}
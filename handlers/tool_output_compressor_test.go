@@ -0,0 +1,115 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestToolOutputCompressor(t *testing.T) {
+	long := strings.Repeat("row ", 100)
+
+	newThread := func() minds.ThreadContext {
+		calls := []minds.ToolCall{
+			{ID: "1", Function: minds.FunctionCall{Name: "sql", Parameters: []byte(`{"q":"select"}`), Result: []byte(long)}},
+			{ID: "2", Function: minds.FunctionCall{Name: "time", Result: []byte(`"12:00"`)}},
+		}
+		return minds.NewThreadContext(context.Background()).
+			WithMessages(
+				minds.Message{Role: minds.RoleUser, Content: "How many rows?"},
+				minds.Message{Role: minds.RoleAssistant, ToolCalls: calls},
+			).
+			With(minds.SetKeyValue(minds.LastToolCallsKey, calls))
+	}
+
+	t.Run("summarizes long results", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("100 rows"), nil
+		}}
+
+		result, err := handlers.NewToolOutputCompressor("compress", provider, 50).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 1) // only the long result, once
+
+		calls := result.Messages().Last().ToolCalls
+		is.Equal(string(calls[0].Function.Result), "100 rows")
+		is.Equal(string(calls[1].Function.Result), `"12:00"`)
+
+		metaCalls := result.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall)
+		is.Equal(string(metaCalls[0].Function.Result), "100 rows")
+
+		outputs := result.Metadata()[handlers.ToolOutputsKey].([]handlers.ToolOutput)
+		is.Equal(len(outputs), 1)
+		is.Equal(outputs[0].CallID, "1")
+		is.Equal(outputs[0].Name, "sql")
+		is.Equal(string(outputs[0].Output), long)
+		is.Equal(outputs[0].Digest, "100 rows")
+
+		prompt := provider.requests[0].Messages.Last().Content
+		is.True(strings.Contains(prompt, "How many rows?"))
+		is.True(strings.Contains(prompt, long))
+	})
+
+	t.Run("cuts digests to the limit", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse(strings.Repeat("x", 80)), nil
+		}}
+
+		result, err := handlers.NewToolOutputCompressor("compress", provider, 50).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(len(result.Messages().Last().ToolCalls[0].Function.Result), 50)
+	})
+
+	t.Run("passes short results through", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("digest"), nil
+		}}
+
+		result, err := handlers.NewToolOutputCompressor("compress", provider, 1000).HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(provider.Calls(), 0)
+		is.Equal(string(result.Messages().Last().ToolCalls[0].Function.Result), long)
+		_, ok := result.Metadata()[handlers.ToolOutputsKey]
+		is.True(!ok)
+	})
+
+	t.Run("feeds digests to the tool responder", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			if req.Messages.Last().Role == minds.RoleFunction {
+				return newMockTextResponse("There are 100 rows."), nil
+			}
+			return newMockTextResponse("100 rows"), nil
+		}}
+
+		agent := handlers.NewSequence("agent",
+			handlers.NewToolOutputCompressor("compress", provider, 50),
+			handlers.NewToolResponder("answer", provider),
+		)
+		result, err := agent.HandleThread(newThread(), nil)
+		is.NoErr(err)
+
+		for _, msg := range result.Messages().Only(minds.RoleFunction) {
+			is.True(len(msg.Content) <= 50)
+		}
+		is.Equal(result.Messages().Last().Content, "There are 100 rows.")
+	})
+
+	t.Run("summarizer errors", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return nil, errHandlerFailed
+		}}
+
+		_, err := handlers.NewToolOutputCompressor("compress", provider, 50).HandleThread(newThread(), nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ToolResponder turns executed tool calls into a natural-language answer. When
+// the last response in the thread carried tool calls with results, it appends
+// each result as a RoleFunction message and asks the generator for a final
+// text response, which is appended as an assistant message.
+//
+// The tool calls are read from metadata under minds.LastToolCallsKey, which
+// the providers' HandleThread sets, falling back to the ToolCalls of the last
+// assistant message.
+//
+// This is the "observe then respond" half of an agent loop, packaged as a
+// composable handler.
+type ToolResponder struct {
+	name       string
+	generator  minds.ContentGenerator
+	middleware []minds.Middleware
+}
+
+// NewToolResponder creates a handler that feeds tool results back to the
+// generator for a final answer.
+//
+// If the last response has no tool calls the thread is passed through
+// unchanged. After answering, the recorded tool calls are cleared so a later
+// ToolResponder in the same pipeline does not answer them twice.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - generator: The LLM used to produce the final answer
+//
+// Returns:
+//   - A handler that appends tool results and the generator's answer
+//
+// Example:
+//
+//	responder := handlers.NewToolResponder("answer", llm)
+//	seq := handlers.NewSequence("agent", callTools, responder)
+func NewToolResponder(name string, generator minds.ContentGenerator) *ToolResponder {
+	return &ToolResponder{
+		name:       name,
+		generator:  generator,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the ToolResponder handler.
+func (t *ToolResponder) Use(middleware ...minds.Middleware) {
+	t.middleware = append(t.middleware, middleware...)
+}
+
+// With returns a new ToolResponder with additional middleware, preserving existing state.
+func (t *ToolResponder) With(middleware ...minds.Middleware) *ToolResponder {
+	newResponder := &ToolResponder{
+		name:       t.name,
+		generator:  t.generator,
+		middleware: append([]minds.Middleware{}, t.middleware...),
+	}
+	newResponder.Use(middleware...)
+	return newResponder
+}
+
+// HandleThread appends tool results and the generated answer to the thread.
+func (t *ToolResponder) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return t.respond(tc)
+	})
+
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		handler = t.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (t *ToolResponder) respond(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	calls := pendingToolCalls(tc)
+	if len(calls) == 0 {
+		return tc, nil
+	}
+
+	ctx := tc.Context()
+	if ctx.Err() != nil {
+		return tc, ctx.Err()
+	}
+
+	results := make(minds.Messages, 0, len(calls))
+	for _, call := range calls {
+		results = append(results, minds.Message{
+			Role:       minds.RoleFunction,
+			Name:       call.Function.Name,
+			Content:    string(call.Function.Result),
+			ToolCallID: call.ID,
+		})
+	}
+
+	// Providers record the calls in metadata rather than on the assistant
+	// message, but the tool results must follow the message that made the
+	// calls.
+	messages := tc.Messages()
+	if last := len(messages) - 1; last >= 0 && messages[last].Role == minds.RoleAssistant && len(messages[last].ToolCalls) == 0 {
+		messages[last].ToolCalls = calls
+	}
+
+	newTc := tc.WithMessages(append(messages, results...)...)
+
+	resp, err := t.generator.GenerateContent(ctx, minds.NewRequest(newTc.Messages()))
+	if err != nil {
+		return tc, fmt.Errorf("%s: error generating content: %w", t.name, err)
+	}
+
+	return newTc.With(
+		minds.AppendMessages(minds.Message{
+			Role:    minds.RoleAssistant,
+			Content: resp.String(),
+		}),
+		minds.SetKeyValue(minds.LastResponseTypeKey, minds.ResponseTypeText),
+		minds.SetKeyValue(minds.LastToolCallsKey, []minds.ToolCall(nil)),
+	), nil
+}
+
+// String returns a string representation of the ToolResponder handler.
+func (t *ToolResponder) String() string {
+	return fmt.Sprintf("ToolResponder(%s)", t.name)
+}
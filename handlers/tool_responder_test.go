@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestToolResponder(t *testing.T) {
+	t.Run("appends results and final answer", func(t *testing.T) {
+		is := is.New(t)
+		var captured minds.Request
+		llm := &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				captured = req
+				return newMockTextResponse("It is 21C in Paris."), nil
+			},
+		}
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Weather in Paris?"},
+			minds.Message{
+				Role: minds.RoleAssistant,
+				ToolCalls: []minds.ToolCall{{
+					ID: "call_1",
+					Function: minds.FunctionCall{
+						Name:   "get_weather",
+						Result: []byte(`{"temp": 21}`),
+					},
+				}},
+			},
+		)
+
+		result, err := handlers.NewToolResponder("answer", llm).HandleThread(tc, nil)
+		is.NoErr(err)
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 4)
+		is.Equal(msgs[2].Role, minds.RoleFunction)
+		is.Equal(msgs[2].Name, "get_weather")
+		is.Equal(msgs[2].ToolCallID, "call_1")
+		is.Equal(msgs[2].Content, `{"temp": 21}`)
+		is.Equal(msgs[3].Role, minds.RoleAssistant)
+		is.Equal(msgs[3].Content, "It is 21C in Paris.")
+
+		is.Equal(len(captured.Messages), 3) // generator saw the tool result
+		is.Equal(len(tc.Messages()), 2)     // original thread untouched
+	})
+
+	t.Run("passes through without tool calls", func(t *testing.T) {
+		is := is.New(t)
+		llm := &recordingProvider{}
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleAssistant, Content: "Hello"},
+		)
+
+		result, err := handlers.NewToolResponder("answer", llm).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(len(result.Messages()), 1)
+		is.Equal(llm.Calls(), 0)
+	})
+
+	t.Run("answers tool calls recorded by a provider", func(t *testing.T) {
+		is := is.New(t)
+
+		// provider behaves like the openai and gemini HandleThread: the
+		// tool calls are recorded in metadata, not on the appended message.
+		provider := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+			calls := []minds.ToolCall{{
+				ID: "call_1",
+				Function: minds.FunctionCall{
+					Name:   "get_weather",
+					Result: []byte(`{"temp": 21}`),
+				},
+			}}
+			tc.AppendMessages(minds.Message{Role: minds.RoleAssistant})
+			tc.SetKeyValue(minds.LastResponseTypeKey, minds.ResponseTypeToolCall)
+			tc.SetKeyValue(minds.LastToolCallsKey, calls)
+			return tc, nil
+		})
+
+		var captured minds.Request
+		llm := &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				captured = req
+				return newMockTextResponse("It is 21C in Paris."), nil
+			},
+		}
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Weather in Paris?"},
+		)
+
+		pipeline := handlers.NewSequence("agent", provider, handlers.NewToolResponder("answer", llm))
+		result, err := pipeline.HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(llm.Calls(), 1)
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 4)
+		is.Equal(len(msgs[1].ToolCalls), 1) // calls attached to the assistant turn
+		is.Equal(msgs[2].Role, minds.RoleFunction)
+		is.Equal(msgs[2].ToolCallID, "call_1")
+		is.Equal(msgs[3].Content, "It is 21C in Paris.")
+
+		is.Equal(len(captured.Messages), 3)
+		is.Equal(captured.Messages[1].ToolCalls[0].ID, "call_1")
+
+		is.Equal(result.Metadata()[minds.LastResponseTypeKey], minds.ResponseTypeText)
+		is.Equal(len(result.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall)), 0)
+	})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/chriscow/minds"
+)
+
+// TranscriptFormat selects how a Transcript writes messages.
+type TranscriptFormat int
+
+const (
+	// TranscriptText writes one `Role: content` line per message.
+	TranscriptText TranscriptFormat = iota
+	// TranscriptJSONL writes one JSON object per message, one per line.
+	TranscriptJSONL
+)
+
+// TranscriptOption configures a Transcript handler.
+type TranscriptOption func(*Transcript)
+
+// WithTranscriptIdleTimeout sets how long Transcript remembers a thread after
+// it was last seen. The default is ten minutes.
+func WithTranscriptIdleTimeout(d time.Duration) TranscriptOption {
+	return func(t *Transcript) {
+		t.idleTimeout = d
+	}
+}
+
+// Transcript records a readable log of the conversation to an io.Writer. Each
+// thread's messages are written once; subsequent invocations only append the
+// messages that were added since the last time the thread was seen.
+//
+// Unlike the logging middleware, which emits structured events about handler
+// execution, Transcript produces the conversation itself.
+type Transcript struct {
+	name        string
+	w           io.Writer
+	format      TranscriptFormat
+	idleTimeout time.Duration
+	mu          sync.Mutex
+	written     map[string]*transcriptThread
+	lastSweep   time.Time
+}
+
+// transcriptThread is how much of a thread has been written.
+type transcriptThread struct {
+	count    int
+	lastUsed time.Time
+}
+
+// NewTranscript creates a handler that writes conversation transcripts to w.
+//
+// Transcript can be used directly as a handler in a pipeline, where it writes
+// any messages it has not yet seen, or as middleware via Wrap, where it also
+// writes the messages produced by the wrapped handler once it returns.
+//
+// Threads not seen for the idle timeout are forgotten to bound memory; see
+// WithTranscriptIdleTimeout. If a forgotten thread comes back, its messages
+// are written again from the start.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - w: Destination for the transcript
+//   - format: TranscriptText or TranscriptJSONL
+//   - opts: Optional configuration such as WithTranscriptIdleTimeout
+//
+// Returns:
+//   - A handler that appends new thread messages to w
+//
+// Example:
+//
+//	f, _ := os.Create("chat.log")
+//	transcript := handlers.NewTranscript("audit", f, handlers.TranscriptText)
+//	seq := handlers.NewSequence("chat", llm, transcript)
+func NewTranscript(name string, w io.Writer, format TranscriptFormat, opts ...TranscriptOption) *Transcript {
+	t := &Transcript{
+		name:        name,
+		w:           w,
+		format:      format,
+		idleTimeout: defaultIdleTimeout,
+		written:     make(map[string]*transcriptThread),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// HandleThread writes any unseen messages and passes the thread on unchanged.
+func (t *Transcript) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	if err := t.record(tc); err != nil {
+		return tc, err
+	}
+
+	if next != nil {
+		return next.HandleThread(tc, nil)
+	}
+
+	return tc, nil
+}
+
+// Wrap returns a handler that records the thread before and after next runs,
+// so the transcript captures exactly what the wrapped handler added.
+func (t *Transcript) Wrap(next minds.ThreadHandler) minds.ThreadHandler {
+	return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		if err := t.record(tc); err != nil {
+			return tc, err
+		}
+
+		result, err := next.HandleThread(tc, nil)
+		if result != nil {
+			if rerr := t.record(result); rerr != nil && err == nil {
+				err = rerr
+			}
+		}
+		return result, err
+	})
+}
+
+// record writes the messages of tc that have not been written yet, and
+// forgets idle threads at most once per idle timeout.
+func (t *Transcript) record(tc minds.ThreadContext) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.lastSweep) >= t.idleTimeout {
+		for id, thread := range t.written {
+			if now.Sub(thread.lastUsed) >= t.idleTimeout {
+				delete(t.written, id)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	thread, ok := t.written[tc.UUID()]
+	if !ok {
+		thread = &transcriptThread{}
+		t.written[tc.UUID()] = thread
+	}
+	thread.lastUsed = now
+
+	messages := tc.Messages()
+	start := thread.count
+	if start > len(messages) {
+		// The thread was rewritten (e.g. trimmed or summarized); start over.
+		start = 0
+	}
+
+	for _, msg := range messages[start:] {
+		if err := t.write(tc.UUID(), msg); err != nil {
+			return fmt.Errorf("%s: error writing transcript: %w", t.name, err)
+		}
+	}
+
+	thread.count = len(messages)
+	return nil
+}
+
+func (t *Transcript) write(threadID string, msg minds.Message) error {
+	switch t.format {
+	case TranscriptJSONL:
+		line, err := json.Marshal(struct {
+			ThreadID string `json:"thread_id"`
+			minds.Message
+		}{threadID, msg})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(t.w, "%s\n", line)
+		return err
+	default:
+		_, err := fmt.Fprintf(t.w, "%s: %s\n", msg.Role, msg.Content)
+		return err
+	}
+}
+
+// Len returns the number of threads currently remembered.
+func (t *Transcript) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.written)
+}
+
+// String returns a string representation of the Transcript handler.
+func (t *Transcript) String() string {
+	return fmt.Sprintf("Transcript(%s)", t.name)
+}
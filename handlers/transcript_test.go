@@ -0,0 +1,106 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestTranscript(t *testing.T) {
+	t.Run("writes plain text and only appends new messages", func(t *testing.T) {
+		is := is.New(t)
+		var buf bytes.Buffer
+		transcript := handlers.NewTranscript("audit", &buf, handlers.TranscriptText)
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Hello"},
+			minds.Message{Role: minds.RoleAssistant, Content: "Hi there"},
+		)
+
+		_, err := transcript.HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(buf.String(), "user: Hello\nassistant: Hi there\n")
+
+		tc.AppendMessages(minds.Message{Role: minds.RoleUser, Content: "Bye"})
+		_, err = transcript.HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(buf.String(), "user: Hello\nassistant: Hi there\nuser: Bye\n")
+	})
+
+	t.Run("writes jsonl", func(t *testing.T) {
+		is := is.New(t)
+		var buf bytes.Buffer
+		transcript := handlers.NewTranscript("audit", &buf, handlers.TranscriptJSONL)
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Hello"},
+			minds.Message{Role: minds.RoleAssistant, Content: "Hi"},
+		)
+
+		_, err := transcript.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		is.Equal(len(lines), 2)
+
+		var entry struct {
+			ThreadID string `json:"thread_id"`
+			Role     string `json:"role"`
+			Content  string `json:"content"`
+		}
+		is.NoErr(json.Unmarshal([]byte(lines[1]), &entry))
+		is.Equal(entry.ThreadID, tc.UUID())
+		is.Equal(entry.Role, "assistant")
+		is.Equal(entry.Content, "Hi")
+	})
+
+	t.Run("as middleware captures messages added by the wrapped handler", func(t *testing.T) {
+		is := is.New(t)
+		var buf bytes.Buffer
+		transcript := handlers.NewTranscript("audit", &buf, handlers.TranscriptText)
+
+		reply := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			tc.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: "pong"})
+			return tc, nil
+		})
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "ping"},
+		)
+
+		_, err := transcript.Wrap(reply).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(buf.String(), "user: ping\nassistant: pong\n")
+	})
+
+	t.Run("forgets idle threads", func(t *testing.T) {
+		is := is.New(t)
+		var buf bytes.Buffer
+		transcript := handlers.NewTranscript("audit", &buf, handlers.TranscriptText,
+			handlers.WithTranscriptIdleTimeout(20*time.Millisecond))
+
+		newThread := func() minds.ThreadContext {
+			return minds.NewThreadContext(context.Background()).WithMessages(
+				minds.Message{Role: minds.RoleUser, Content: "Hello"},
+			)
+		}
+
+		_, err := transcript.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		_, err = transcript.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(transcript.Len(), 2)
+
+		time.Sleep(50 * time.Millisecond)
+		_, err = transcript.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(transcript.Len(), 1)
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+const (
+	// TranslatedInputKey is the thread metadata key holding the original text
+	// of the user message TranslateWrapper translated for the inner handler.
+	TranslatedInputKey = "translated_input"
+
+	// TranslatedOutputKey is the thread metadata key holding the original
+	// text of the assistant message TranslateWrapper translated for the user.
+	TranslatedOutputKey = "translated_output"
+)
+
+const translatePrompt = `Translate the user's text into %s. Preserve the meaning, tone and any
+formatting such as lists or code. Respond with the translation only, without
+notes or quotation marks. If the text is already in %s, repeat it unchanged.`
+
+// TranslateWrapper runs a handler in a pivot language, translating the
+// conversation into it on the way in and back out of it on the way out.
+type TranslateWrapper struct {
+	name       string
+	translator minds.ContentGenerator
+	targetLang string
+	modelLang  string
+	inner      minds.ThreadHandler
+	middleware []minds.Middleware
+}
+
+// NewTranslateWrapper creates a handler that lets a model strongest in one
+// language serve users writing in another. The last user message is
+// translated into modelLang before inner runs, and the assistant message
+// inner adds is translated into targetLang afterwards. The thread keeps the
+// translations; the original texts are stored in metadata under
+// TranslatedInputKey and TranslatedOutputKey.
+//
+// If the thread has no user message, it is passed to inner untranslated. If
+// inner adds no assistant message, there is nothing to translate back.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - translator: The LLM used for both translations
+//   - targetLang: The user's language, e.g. "Japanese"
+//   - modelLang: The language inner works in, e.g. "English"
+//   - inner: The handler to run on the translated thread
+//
+// Returns:
+//   - A handler that runs inner in modelLang and answers in targetLang
+//
+// Example:
+//
+//	japanese := handlers.NewTranslateWrapper("ja", translator, "Japanese", "English", llm)
+//	result, err := japanese.HandleThread(tc, nil)
+func NewTranslateWrapper(name string, translator minds.ContentGenerator, targetLang, modelLang string, inner minds.ThreadHandler) *TranslateWrapper {
+	if translator == nil {
+		panic(fmt.Sprintf("%s: translator cannot be nil", name))
+	}
+	if inner == nil {
+		panic(fmt.Sprintf("%s: inner handler cannot be nil", name))
+	}
+
+	return &TranslateWrapper{
+		name:       name,
+		translator: translator,
+		targetLang: targetLang,
+		modelLang:  modelLang,
+		inner:      inner,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the TranslateWrapper handler.
+func (t *TranslateWrapper) Use(middleware ...minds.Middleware) {
+	t.middleware = append(t.middleware, middleware...)
+}
+
+// With returns a new TranslateWrapper handler with additional middleware, preserving existing state.
+func (t *TranslateWrapper) With(middleware ...minds.Middleware) *TranslateWrapper {
+	newWrapper := &TranslateWrapper{
+		name:       t.name,
+		translator: t.translator,
+		targetLang: t.targetLang,
+		modelLang:  t.modelLang,
+		inner:      t.inner,
+		middleware: append([]minds.Middleware{}, t.middleware...),
+	}
+	newWrapper.Use(middleware...)
+	return newWrapper
+}
+
+// HandleThread translates the thread, runs the inner handler and translates
+// its answer back before passing the thread on.
+func (t *TranslateWrapper) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return t.translateAround(tc)
+	})
+
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		handler = t.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (t *TranslateWrapper) translateAround(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	messages := tc.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != minds.RoleUser {
+			continue
+		}
+
+		translated, err := t.translate(tc, messages[i].Content, t.modelLang)
+		if err != nil {
+			return tc, fmt.Errorf("%s: failed to translate input: %w", t.name, err)
+		}
+
+		original := messages[i].Content
+		messages[i].Content = translated
+		tc = tc.With(minds.ReplaceMessages(messages...), minds.SetKeyValue(TranslatedInputKey, original))
+		break
+	}
+
+	before := len(messages)
+	result, err := t.inner.HandleThread(tc, nil)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", t.name, err)
+	}
+
+	answer := result.Messages()
+	for i := len(answer) - 1; i >= before; i-- {
+		if answer[i].Role != minds.RoleAssistant {
+			continue
+		}
+
+		translated, err := t.translate(result, answer[i].Content, t.targetLang)
+		if err != nil {
+			return result, fmt.Errorf("%s: failed to translate output: %w", t.name, err)
+		}
+
+		original := answer[i].Content
+		answer[i].Content = translated
+		return result.With(minds.ReplaceMessages(answer...), minds.SetKeyValue(TranslatedOutputKey, original)), nil
+	}
+
+	return result, nil
+}
+
+// translate asks the translator to translate text into lang.
+func (t *TranslateWrapper) translate(tc minds.ThreadContext, text, lang string) (string, error) {
+	messages := minds.Messages{
+		{Role: minds.RoleSystem, Content: fmt.Sprintf(translatePrompt, lang, lang)},
+		{Role: minds.RoleUser, Content: text},
+	}
+
+	resp, err := t.translator.GenerateContent(tc.Context(), minds.NewRequest(messages))
+	if err != nil {
+		return "", err
+	}
+
+	return resp.String(), nil
+}
+
+// String returns a string representation of the TranslateWrapper handler.
+func (t *TranslateWrapper) String() string {
+	return fmt.Sprintf("TranslateWrapper(%s)", t.name)
+}
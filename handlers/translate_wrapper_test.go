@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestTranslateWrapper(t *testing.T) {
+	// The translator tags text with the language from its instructions.
+	translator := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+		lang := "English"
+		if strings.Contains(req.Messages[0].Content, "Japanese") {
+			lang = "Japanese"
+		}
+		return newMockTextResponse("[" + lang + "] " + req.Messages.Last().Content), nil
+	}}
+
+	t.Run("translates in and out", func(t *testing.T) {
+		is := is.New(t)
+
+		var seen string
+		inner := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			seen = tc.Messages().Last().Content
+			return tc.With(minds.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: "Hello!"})), nil
+		})
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "こんにちは"})
+
+		result, err := handlers.NewTranslateWrapper("ja", translator, "Japanese", "English", inner).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(seen, "[English] こんにちは")
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 2)
+		is.Equal(msgs[0].Content, "[English] こんにちは")
+		is.Equal(msgs[1].Content, "[Japanese] Hello!")
+
+		metadata := result.Metadata()
+		is.Equal(metadata[handlers.TranslatedInputKey], "こんにちは")
+		is.Equal(metadata[handlers.TranslatedOutputKey], "Hello!")
+		is.Equal(tc.Messages()[0].Content, "こんにちは") // original untouched
+	})
+
+	t.Run("earlier assistant messages are not translated", func(t *testing.T) {
+		is := is.New(t)
+
+		inner := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc, nil
+		})
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleAssistant, Content: "Earlier answer"},
+			minds.Message{Role: minds.RoleUser, Content: "ありがとう"},
+		)
+
+		result, err := handlers.NewTranslateWrapper("ja", translator, "Japanese", "English", inner).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(result.Messages()[0].Content, "Earlier answer")
+		_, translated := result.Metadata()[handlers.TranslatedOutputKey]
+		is.True(!translated)
+	})
+
+	t.Run("wraps inner errors", func(t *testing.T) {
+		is := is.New(t)
+
+		inner := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc, errHandlerFailed
+		})
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "こんにちは"})
+
+		_, err := handlers.NewTranslateWrapper("ja", translator, "Japanese", "English", inner).HandleThread(tc, nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+}
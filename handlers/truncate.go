@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/chriscow/minds"
+)
+
+// truncateEllipsis is appended to truncated messages when requested. It is a
+// single rune so it counts as one character towards the limit.
+const truncateEllipsis = "…"
+
+// Truncate shortens the last assistant message to a maximum number of
+// characters, cutting at a word boundary.
+type Truncate struct {
+	name       string
+	maxChars   int
+	ellipsis   bool
+	middleware []minds.Middleware
+}
+
+// NewTruncate creates a handler that trims the last assistant message to at
+// most maxChars characters. Characters are counted as runes, so multibyte
+// text is never split mid-character. The message is cut at the last word
+// boundary that fits; a single word longer than the limit is cut mid-word.
+// Messages within the limit are left unchanged.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - maxChars: Maximum length of the message in characters, including the ellipsis
+//   - ellipsis: Whether to append "…" to truncated messages
+//
+// Returns:
+//   - A handler that truncates the last assistant message
+//
+// Example:
+//
+//	preview := handlers.NewTruncate("preview", 280, true)
+//	chat := handlers.NewSequence("chat", llm, preview)
+func NewTruncate(name string, maxChars int, ellipsis bool) *Truncate {
+	if maxChars < 0 {
+		maxChars = 0
+	}
+
+	return &Truncate{
+		name:       name,
+		maxChars:   maxChars,
+		ellipsis:   ellipsis,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the Truncate handler.
+func (t *Truncate) Use(middleware ...minds.Middleware) {
+	t.middleware = append(t.middleware, middleware...)
+}
+
+// With returns a new Truncate handler with additional middleware, preserving existing state.
+func (t *Truncate) With(middleware ...minds.Middleware) *Truncate {
+	newTruncate := &Truncate{
+		name:       t.name,
+		maxChars:   t.maxChars,
+		ellipsis:   t.ellipsis,
+		middleware: append([]minds.Middleware{}, t.middleware...),
+	}
+	newTruncate.Use(middleware...)
+	return newTruncate
+}
+
+// HandleThread truncates the last assistant message and passes the thread on.
+func (t *Truncate) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return t.truncate(tc), nil
+	})
+
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		handler = t.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (t *Truncate) truncate(tc minds.ThreadContext) minds.ThreadContext {
+	messages := tc.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != minds.RoleAssistant {
+			continue
+		}
+
+		content, truncated := truncateWords(messages[i].Content, t.maxChars, t.ellipsis)
+		if !truncated {
+			return tc
+		}
+
+		messages[i].Content = content
+		return tc.WithMessages(messages...)
+	}
+
+	return tc
+}
+
+// truncateWords shortens s to at most maxChars runes, cutting at the last
+// word boundary that fits. It reports whether s was shortened.
+func truncateWords(s string, maxChars int, ellipsis bool) (string, bool) {
+	runes := []rune(s)
+	if len(runes) <= maxChars {
+		return s, false
+	}
+
+	limit := maxChars
+	if ellipsis && limit > 0 {
+		limit--
+	}
+
+	cut := limit
+	if !unicode.IsSpace(runes[limit]) {
+		// Back up to the start of the word that crosses the limit.
+		for cut > 0 && !unicode.IsSpace(runes[cut-1]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = limit
+		}
+	}
+
+	result := strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace)
+	if ellipsis && maxChars > 0 {
+		result += truncateEllipsis
+	}
+
+	return result, true
+}
+
+// String returns a string representation of the Truncate handler.
+func (t *Truncate) String() string {
+	return fmt.Sprintf("Truncate(%s)", t.name)
+}
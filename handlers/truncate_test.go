@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxChars int
+		ellipsis bool
+		want     string
+	}{
+		{"shorter message is unchanged", "Hello world", 20, true, "Hello world"},
+		{"exact length is unchanged", "Hello world", 11, true, "Hello world"},
+		{"cuts at word boundary", "The quick brown fox jumps", 15, false, "The quick brown"},
+		{"backs up to previous word", "The quick brown fox jumps", 13, false, "The quick"},
+		{"ellipsis counts towards limit", "The quick brown fox jumps", 16, true, "The quick brown…"},
+		{"ellipsis backs up a word", "The quick brown fox jumps", 15, true, "The quick…"},
+		{"long word is cut mid-word", "Supercalifragilistic", 5, false, "Super"},
+		{"multibyte runes are not split", "héllo wörld ünïcode", 12, false, "héllo wörld"},
+		{"multibyte long word", "日本語のテキスト", 4, true, "日本語…"},
+		{"trailing spaces are trimmed", "Hello,    world", 9, false, "Hello,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			tc := minds.NewThreadContext(context.Background()).WithMessages(
+				minds.Message{Role: minds.RoleUser, Content: "Tell me something"},
+				minds.Message{Role: minds.RoleAssistant, Content: tt.content},
+			)
+
+			result, err := handlers.NewTruncate("truncate", tt.maxChars, tt.ellipsis).HandleThread(tc, nil)
+			is.NoErr(err)
+			is.Equal(result.Messages().Last().Content, tt.want)
+			is.True(len([]rune(result.Messages().Last().Content)) <= tt.maxChars)
+		})
+	}
+
+	t.Run("only the last assistant message is truncated", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleAssistant, Content: "An earlier long answer"},
+			minds.Message{Role: minds.RoleAssistant, Content: "The latest long answer"},
+			minds.Message{Role: minds.RoleUser, Content: "A long follow-up question"},
+		)
+
+		result, err := handlers.NewTruncate("truncate", 10, false).HandleThread(tc, nil)
+		is.NoErr(err)
+
+		msgs := result.Messages()
+		is.Equal(msgs[0].Content, "An earlier long answer")
+		is.Equal(msgs[1].Content, "The latest")
+		is.Equal(msgs[2].Content, "A long follow-up question")
+		is.Equal(tc.Messages()[1].Content, "The latest long answer") // original untouched
+	})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// ErrTurnLimitExceeded is returned by a TurnLimit handler when the thread has
+// used up its turns and no limit handler is configured.
+var ErrTurnLimitExceeded = errors.New("conversation turn limit exceeded")
+
+// TurnLimitOption configures a TurnLimit handler.
+type TurnLimitOption func(*TurnLimit)
+
+// WithLimitHandler routes threads that have reached the turn limit to handler
+// instead of failing with ErrTurnLimitExceeded, for example to append a
+// "conversation ended" message. The handler receives the TurnLimit's next
+// handler.
+func WithLimitHandler(handler minds.ThreadHandler) TurnLimitOption {
+	return func(t *TurnLimit) {
+		t.onLimit = handler
+	}
+}
+
+// TurnLimit caps the number of turns in a conversation.
+type TurnLimit struct {
+	name       string
+	maxTurns   int
+	onLimit    minds.ThreadHandler
+	middleware []minds.Middleware
+}
+
+// NewTurnLimit creates a gate that stops conversations after maxTurns turns.
+// A turn is a user message answered by an assistant message, so the count
+// grows by one with each completed exchange regardless of message length.
+// Threads with fewer than maxTurns completed turns continue to the next
+// handler; longer threads fail with ErrTurnLimitExceeded, or go to the
+// handler set with WithLimitHandler. Place it at the front of a pipeline.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - maxTurns: Maximum number of completed turns allowed
+//   - opts: Optional configuration such as WithLimitHandler
+//
+// Returns:
+//   - A handler that only continues conversations under the limit
+//
+// Example:
+//
+//	limit := handlers.NewTurnLimit("limit", 20)
+//	chat := handlers.NewSequence("chat", limit, llm)
+func NewTurnLimit(name string, maxTurns int, opts ...TurnLimitOption) *TurnLimit {
+	t := &TurnLimit{
+		name:       name,
+		maxTurns:   maxTurns,
+		middleware: []minds.Middleware{},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Use applies middleware to the TurnLimit handler. It wraps the limit check.
+func (t *TurnLimit) Use(middleware ...minds.Middleware) {
+	t.middleware = append(t.middleware, middleware...)
+}
+
+// With returns a new TurnLimit handler with additional middleware, preserving existing state.
+func (t *TurnLimit) With(middleware ...minds.Middleware) *TurnLimit {
+	newLimit := &TurnLimit{
+		name:       t.name,
+		maxTurns:   t.maxTurns,
+		onLimit:    t.onLimit,
+		middleware: append([]minds.Middleware{}, t.middleware...),
+	}
+	newLimit.Use(middleware...)
+	return newLimit
+}
+
+// HandleThread checks the number of turns and routes the thread accordingly.
+func (t *TurnLimit) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+		return t.gate(tc, next)
+	})
+
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		handler = t.middleware[i].Wrap(handler)
+	}
+
+	return handler.HandleThread(tc, next)
+}
+
+func (t *TurnLimit) gate(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	if turns := countTurns(tc.Messages()); turns >= t.maxTurns {
+		if t.onLimit != nil {
+			return t.onLimit.HandleThread(tc, next)
+		}
+		return tc, fmt.Errorf("%s: %w: %d of %d turns used", t.name, ErrTurnLimitExceeded, turns, t.maxTurns)
+	}
+
+	if next != nil {
+		return next.HandleThread(tc, nil)
+	}
+
+	return tc, nil
+}
+
+// countTurns returns the number of user messages answered by an assistant
+// message. Consecutive user messages before an answer count as one turn.
+func countTurns(messages minds.Messages) int {
+	turns := 0
+	pending := false
+	for _, msg := range messages {
+		switch msg.Role {
+		case minds.RoleUser:
+			pending = true
+		case minds.RoleAssistant, minds.RoleAI, minds.RoleModel:
+			if pending {
+				turns++
+				pending = false
+			}
+		}
+	}
+	return turns
+}
+
+// String returns a string representation of the TurnLimit handler.
+func (t *TurnLimit) String() string {
+	return fmt.Sprintf("TurnLimit(%s, %d)", t.name, t.maxTurns)
+}
@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func newConversation(turns int) minds.ThreadContext {
+	var messages minds.Messages
+	for i := 0; i < turns; i++ {
+		messages = append(messages,
+			minds.Message{Role: minds.RoleUser, Content: "question"},
+			minds.Message{Role: minds.RoleAssistant, Content: "answer"},
+		)
+	}
+	messages = append(messages, minds.Message{Role: minds.RoleUser, Content: "next question"})
+
+	return minds.NewThreadContext(context.Background()).WithMessages(
+		append(minds.Messages{{Role: minds.RoleSystem, Content: "Be helpful."}}, messages...)...,
+	)
+}
+
+func TestTurnLimit(t *testing.T) {
+	t.Run("allows conversations under the limit", func(t *testing.T) {
+		is := is.New(t)
+		next := newMockHandler("next")
+
+		_, err := handlers.NewTurnLimit("limit", 3).HandleThread(newConversation(2), next)
+		is.NoErr(err)
+		is.Equal(next.Completed(), 1)
+	})
+
+	t.Run("rejects conversations at the limit", func(t *testing.T) {
+		is := is.New(t)
+		next := newMockHandler("next")
+
+		_, err := handlers.NewTurnLimit("limit", 3).HandleThread(newConversation(3), next)
+		is.True(errors.Is(err, handlers.ErrTurnLimitExceeded))
+		is.Equal(next.Completed(), 0)
+	})
+
+	t.Run("stops a sequence", func(t *testing.T) {
+		is := is.New(t)
+		llm := newMockHandler("llm")
+
+		chat := handlers.NewSequence("chat", handlers.NewTurnLimit("limit", 1), llm)
+		_, err := chat.HandleThread(newConversation(1), nil)
+		is.True(errors.Is(err, handlers.ErrTurnLimitExceeded))
+		is.Equal(llm.Completed(), 0)
+	})
+
+	t.Run("routes to the limit handler", func(t *testing.T) {
+		is := is.New(t)
+		ended := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc.With(minds.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: "This conversation has ended."})), nil
+		})
+
+		limit := handlers.NewTurnLimit("limit", 2, handlers.WithLimitHandler(ended))
+		result, err := limit.HandleThread(newConversation(2), nil)
+		is.NoErr(err)
+		is.Equal(result.Messages().Last().Content, "This conversation has ended.")
+	})
+
+	t.Run("unanswered messages do not count", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "one"},
+			minds.Message{Role: minds.RoleUser, Content: "two"},
+			minds.Message{Role: minds.RoleAssistant, Content: "answer"},
+			minds.Message{Role: minds.RoleUser, Content: "three"},
+		)
+
+		_, err := handlers.NewTurnLimit("limit", 2).HandleThread(tc, nil)
+		is.NoErr(err)
+	})
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/chriscow/minds"
+)
+
+// VoteResult is the tally produced by a Vote handler. It is stored in thread
+// metadata under the handler's name.
+type VoteResult[T comparable] struct {
+	// Winner is the answer with the most votes.
+	Winner T
+
+	// Votes is the number of samples that chose Winner.
+	Votes int
+
+	// Counts maps every distinct answer to its number of votes.
+	Counts map[T]int
+
+	// Samples is the number of samples that produced a valid answer.
+	Samples int
+
+	// Tied is true when another answer received as many votes as Winner.
+	Tied bool
+}
+
+// voteAnswer wraps the answer so that scalar types still produce an object
+// schema, as structured output requires.
+type voteAnswer[T comparable] struct {
+	Answer T `json:"answer" description:"The answer"`
+}
+
+// Vote runs the same structured prompt several times in parallel and takes the
+// majority answer, a technique known as self-consistency decoding. It reduces
+// the variance of classification and short-answer tasks.
+type Vote[T comparable] struct {
+	name       string
+	generator  minds.ContentGenerator
+	prompt     string
+	samples    int
+	middleware []minds.Middleware
+}
+
+// NewVote creates a handler that generates samples answers of type T in
+// parallel and tallies them by equality. The tally is stored as a
+// VoteResult[T] in metadata under name.
+//
+// Ties are broken deterministically in favor of the answer whose first vote
+// came from the lowest-numbered sample, and flagged with VoteResult.Tied.
+// Samples that fail or return an invalid answer are not counted; an error is
+// returned only if no sample succeeds.
+//
+// Parameters:
+//   - name: Identifier for this handler and the metadata key for the result
+//   - generator: The LLM used to generate each sample
+//   - prompt: System prompt describing the question to answer
+//   - samples: Number of parallel generations
+//
+// Returns:
+//   - A handler that stores the majority answer in metadata
+//
+// Example:
+//
+//	vote := handlers.NewVote[string]("sentiment", llm, "Classify the sentiment as positive, negative or neutral.", 5)
+//	result, err := vote.HandleThread(tc, nil)
+//	tally := result.Metadata()["sentiment"].(handlers.VoteResult[string])
+func NewVote[T comparable](name string, generator minds.ContentGenerator, prompt string, samples int) *Vote[T] {
+	if samples < 1 {
+		samples = 1
+	}
+
+	return &Vote[T]{
+		name:       name,
+		generator:  generator,
+		prompt:     prompt,
+		samples:    samples,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the Vote handler.
+func (v *Vote[T]) Use(middleware ...minds.Middleware) {
+	v.middleware = append(v.middleware, middleware...)
+}
+
+// With returns a new Vote handler with additional middleware, preserving existing state.
+func (v *Vote[T]) With(middleware ...minds.Middleware) *Vote[T] {
+	newVote := &Vote[T]{
+		name:       v.name,
+		generator:  v.generator,
+		prompt:     v.prompt,
+		samples:    v.samples,
+		middleware: append([]minds.Middleware{}, v.middleware...),
+	}
+	newVote.Use(middleware...)
+	return newVote
+}
+
+// HandleThread tallies the sampled answers and passes the thread on.
+func (v *Vote[T]) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return v.vote(tc)
+	})
+
+	for i := len(v.middleware) - 1; i >= 0; i-- {
+		handler = v.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (v *Vote[T]) vote(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	ctx := tc.Context()
+	if ctx.Err() != nil {
+		return tc, ctx.Err()
+	}
+
+	schema, err := minds.NewResponseSchema("Answer", "The answer to the question", voteAnswer[T]{})
+	if err != nil {
+		return tc, fmt.Errorf("%s: failed to generate schema: %w", v.name, err)
+	}
+
+	messages := append(minds.Messages{{Role: minds.RoleSystem, Content: v.prompt}}, tc.Messages()...)
+
+	// Each sample writes only its own slot, so results stay in sample order.
+	answers := make([]T, v.samples)
+	errs := make([]error, v.samples)
+
+	var wg sync.WaitGroup
+	for i := 0; i < v.samples; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			resp, err := v.generator.GenerateContent(ctx, minds.NewRequest(messages.Copy(), minds.WithResponseSchema(*schema)))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			var answer voteAnswer[T]
+			if err := json.Unmarshal([]byte(resp.String()), &answer); err != nil {
+				errs[i] = fmt.Errorf("invalid answer (%s): %w", resp.String(), err)
+				return
+			}
+			answers[i] = answer.Answer
+		}(i)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return tc, ctx.Err()
+	}
+
+	result := VoteResult[T]{Counts: make(map[T]int)}
+	var order []T
+	for i, answer := range answers {
+		if errs[i] != nil {
+			continue
+		}
+		if result.Counts[answer] == 0 {
+			order = append(order, answer)
+		}
+		result.Counts[answer]++
+		result.Samples++
+	}
+
+	if result.Samples == 0 {
+		return tc, fmt.Errorf("%s: all %d samples failed, first error: %w", v.name, v.samples, errs[0])
+	}
+
+	for _, answer := range order {
+		switch count := result.Counts[answer]; {
+		case count > result.Votes:
+			result.Winner, result.Votes, result.Tied = answer, count, false
+		case count == result.Votes:
+			result.Tied = true
+		}
+	}
+
+	newTc := tc.Clone()
+	newTc.SetKeyValue(v.name, result)
+
+	return newTc, nil
+}
+
+// String returns a string representation of the Vote handler.
+func (v *Vote[T]) String() string {
+	return fmt.Sprintf("Vote(%s)", v.name)
+}
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestVote(t *testing.T) {
+	tc := minds.NewThreadContext(context.Background()).WithMessages(
+		minds.Message{Role: minds.RoleUser, Content: "I love this product!"},
+	)
+
+	// scripted returns the answers in call order.
+	scripted := func(answers ...string) *recordingProvider {
+		var n int32
+		return &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				answer := answers[atomic.AddInt32(&n, 1)-1]
+				if answer == "" {
+					return nil, errors.New("sample failed")
+				}
+				return newMockTextResponse(fmt.Sprintf(`{"answer": %q}`, answer)), nil
+			},
+		}
+	}
+
+	t.Run("majority wins", func(t *testing.T) {
+		is := is.New(t)
+		llm := scripted("positive", "neutral", "positive", "", "positive")
+
+		result, err := handlers.NewVote[string]("sentiment", llm, "Classify the sentiment.", 5).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(llm.Calls(), 5)
+
+		tally := result.Metadata()["sentiment"].(handlers.VoteResult[string])
+		is.Equal(tally.Winner, "positive")
+		is.Equal(tally.Votes, 3)
+		is.Equal(tally.Samples, 4)
+		is.Equal(tally.Counts, map[string]int{"positive": 3, "neutral": 1})
+		is.True(!tally.Tied)
+	})
+
+	t.Run("ties are flagged", func(t *testing.T) {
+		is := is.New(t)
+		llm := scripted("neutral", "positive", "neutral", "positive")
+
+		result, err := handlers.NewVote[string]("sentiment", llm, "Classify the sentiment.", 4).HandleThread(tc, nil)
+		is.NoErr(err)
+
+		tally := result.Metadata()["sentiment"].(handlers.VoteResult[string])
+		is.Equal(tally.Votes, 2)
+		is.True(tally.Tied)
+	})
+
+	t.Run("fails when every sample fails", func(t *testing.T) {
+		is := is.New(t)
+		llm := scripted("", "")
+
+		_, err := handlers.NewVote[string]("sentiment", llm, "Classify the sentiment.", 2).HandleThread(tc, nil)
+		is.True(err != nil)
+	})
+
+	t.Run("scalar answers", func(t *testing.T) {
+		is := is.New(t)
+		llm := &recordingProvider{
+			fn: func(req minds.Request) (minds.Response, error) {
+				return newMockTextResponse(`{"answer": true}`), nil
+			},
+		}
+
+		result, err := handlers.NewVote[bool]("spam", llm, "Is this spam?", 3).HandleThread(tc, nil)
+		is.NoErr(err)
+
+		tally := result.Metadata()["spam"].(handlers.VoteResult[bool])
+		is.Equal(tally.Winner, true)
+		is.Equal(tally.Votes, 3)
+	})
+}
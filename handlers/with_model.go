@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// WithModel runs a generator against the thread with a specific model,
+// overriding the generator's default for this step only. It lets one provider
+// serve pipeline steps that need different models, such as a cheap model for
+// routing and a stronger one for the final answer.
+type WithModel struct {
+	name       string
+	model      string
+	generator  minds.ContentGenerator
+	middleware []minds.Middleware
+}
+
+// NewWithModel creates a handler that sends the thread to generator with
+// the request's ModelName set to model, and appends the response as an
+// assistant message. The generator must honor minds.WithModel; the openai
+// and gemini providers do.
+//
+// Like a provider's HandleThread, it records the response type and tool
+// calls in the thread metadata under minds.LastResponseTypeKey and
+// minds.LastToolCallsKey.
+//
+// Parameters:
+//   - name: Identifier for this handler
+//   - model: The model to use for this step
+//   - generator: The LLM to send the thread to
+//
+// Returns:
+//   - A handler that generates a response with the given model
+//
+// Example:
+//
+//	route := handlers.NewWithModel("route", "gpt-4o-mini", llm)
+//	answer := handlers.NewWithModel("answer", "gpt-4o", llm)
+//	pipeline := handlers.NewSequence("pipeline", route, answer)
+func NewWithModel(name, model string, generator minds.ContentGenerator) *WithModel {
+	if generator == nil {
+		panic(fmt.Sprintf("%s: generator cannot be nil", name))
+	}
+
+	return &WithModel{
+		name:       name,
+		model:      model,
+		generator:  generator,
+		middleware: []minds.Middleware{},
+	}
+}
+
+// Use applies middleware to the WithModel handler.
+func (w *WithModel) Use(middleware ...minds.Middleware) {
+	w.middleware = append(w.middleware, middleware...)
+}
+
+// With returns a new WithModel handler with additional middleware, preserving existing state.
+func (w *WithModel) With(middleware ...minds.Middleware) *WithModel {
+	newHandler := &WithModel{
+		name:       w.name,
+		model:      w.model,
+		generator:  w.generator,
+		middleware: append([]minds.Middleware{}, w.middleware...),
+	}
+	newHandler.Use(middleware...)
+	return newHandler
+}
+
+// HandleThread generates a response with the configured model and passes the
+// thread on.
+func (w *WithModel) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
+	var handler minds.ThreadHandler = minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return w.generate(tc)
+	})
+
+	for i := len(w.middleware) - 1; i >= 0; i-- {
+		handler = w.middleware[i].Wrap(handler)
+	}
+
+	result, err := handler.HandleThread(tc, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		return next.HandleThread(result, nil)
+	}
+
+	return result, nil
+}
+
+func (w *WithModel) generate(tc minds.ThreadContext) (minds.ThreadContext, error) {
+	req := minds.NewRequest(tc.Messages(),
+		minds.WithModel(w.model),
+		minds.WithRequestMetadata(tc.Metadata()),
+	)
+
+	resp, err := w.generator.GenerateContent(tc.Context(), req)
+	if err != nil {
+		return tc, fmt.Errorf("%s: error generating content: %w", w.name, err)
+	}
+
+	responseType := minds.ResponseTypeText
+	if len(resp.ToolCalls()) > 0 {
+		responseType = minds.ResponseTypeToolCall
+	}
+
+	return tc.With(
+		minds.AppendMessages(minds.Message{
+			Role:      minds.RoleAssistant,
+			Content:   resp.String(),
+			ToolCalls: resp.ToolCalls(),
+		}),
+		minds.SetKeyValue(minds.LastResponseTypeKey, responseType),
+		minds.SetKeyValue(minds.LastToolCallsKey, resp.ToolCalls()),
+	), nil
+}
+
+// String returns a string representation of the WithModel handler.
+func (w *WithModel) String() string {
+	return fmt.Sprintf("WithModel(%s, %s)", w.name, w.model)
+}
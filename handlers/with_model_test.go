@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/handlers"
+	"github.com/matryer/is"
+)
+
+func TestWithModel(t *testing.T) {
+	t.Run("overrides the model for the step", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return newMockTextResponse("answer from " + *req.Options.ModelName), nil
+		}}
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Hi"}).
+			WithMetadata(minds.Metadata{"tenant": "acme"})
+
+		pipeline := handlers.NewSequence("pipeline",
+			handlers.NewWithModel("route", "cheap-model", provider),
+			handlers.NewWithModel("answer", "strong-model", provider),
+		)
+		result, err := pipeline.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		is.Equal(provider.Calls(), 2)
+		is.Equal(*provider.requests[0].Options.ModelName, "cheap-model")
+		is.Equal(*provider.requests[1].Options.ModelName, "strong-model")
+		is.Equal(provider.requests[1].Metadata["tenant"], "acme")
+
+		msgs := result.Messages()
+		is.Equal(len(msgs), 3)
+		is.Equal(msgs[1].Content, "answer from cheap-model")
+		is.Equal(msgs[2].Role, minds.RoleAssistant)
+		is.Equal(msgs[2].Content, "answer from strong-model")
+		is.Equal(result.Metadata()[minds.LastResponseTypeKey], minds.ResponseTypeText)
+	})
+
+	t.Run("wraps generator errors", func(t *testing.T) {
+		is := is.New(t)
+		provider := &recordingProvider{fn: func(req minds.Request) (minds.Response, error) {
+			return nil, errHandlerFailed
+		}}
+
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Hi"})
+		_, err := handlers.NewWithModel("route", "cheap-model", provider).HandleThread(tc, nil)
+		is.True(errors.Is(err, errHandlerFailed))
+	})
+}
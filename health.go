@@ -0,0 +1,51 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnauthorized indicates that a provider rejected its credentials,
+	// usually because the API key is missing, invalid or lacks permission.
+	ErrUnauthorized = errors.New("provider rejected the credentials")
+
+	// ErrUnavailable indicates that a provider could not be reached or is
+	// temporarily unable to serve requests.
+	ErrUnavailable = errors.New("provider is unavailable")
+)
+
+// Pinger is implemented by providers that can check they are reachable and
+// that their credentials are valid without generating content.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingError is returned by a failed Ping. Kind is ErrUnauthorized,
+// ErrUnavailable, or nil when the failure fits neither, so callers can test
+// the cause with errors.Is:
+//
+//	if err := llm.Ping(ctx); errors.Is(err, minds.ErrUnauthorized) {
+//	    log.Fatal("check the API key")
+//	}
+type PingError struct {
+	Kind error
+	Err  error
+}
+
+func (e *PingError) Error() string {
+	if e.Kind == nil {
+		return fmt.Sprintf("ping failed: %v", e.Err)
+	}
+	return fmt.Sprintf("ping failed: %v: %v", e.Kind, e.Err)
+}
+
+func (e *PingError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the error's Kind.
+func (e *PingError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
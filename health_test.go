@@ -0,0 +1,29 @@
+package minds
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPingError(t *testing.T) {
+	is := is.New(t)
+
+	cause := errors.New("401 Unauthorized")
+	var err error = &PingError{Kind: ErrUnauthorized, Err: cause}
+
+	is.True(errors.Is(err, ErrUnauthorized))
+	is.True(!errors.Is(err, ErrUnavailable))
+	is.True(errors.Is(err, cause))
+	is.Equal(err.Error(), "ping failed: provider rejected the credentials: 401 Unauthorized")
+
+	var pingErr *PingError
+	is.True(errors.As(err, &pingErr))
+	is.Equal(pingErr.Kind, ErrUnauthorized)
+
+	unknown := &PingError{Err: cause}
+	is.True(!errors.Is(unknown, ErrUnauthorized))
+	is.True(!errors.Is(unknown, ErrUnavailable))
+	is.Equal(unknown.Error(), "ping failed: 401 Unauthorized")
+}
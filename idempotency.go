@@ -0,0 +1,225 @@
+package minds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+type idempotencyContextKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx carrying key, which identifies a
+// unit of work that may be retried, such as an order or a job id. Tool calls
+// executed by HandleFunctionCalls with ctx through an IdempotentRegistry are
+// run once per key: retries replay the first result instead of repeating the
+// side effect, and an identical call made while the first is still running,
+// as by an attempt that timed out, waits for it and replays its result.
+//
+// Example:
+//
+//	ctx := minds.WithIdempotencyKey(tc.Context(), "order-"+order.ID)
+//	result, err := pipeline.HandleThread(tc.WithContext(ctx), nil)
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyContextKey{}, key)
+}
+
+// IdempotencyKey returns the key set on ctx with WithIdempotencyKey, or an
+// empty string if there is none.
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyContextKey{}).(string)
+	return key
+}
+
+// resultCache is implemented by registries that remember tool results, such
+// as the one returned by IdempotentRegistry. HandleFunctionCalls checks it
+// before calling a tool and fills it after a successful call. When registries
+// holding caches wrap one another, a hit in any of them answers the call and a
+// result is saved in all of them.
+type resultCache interface {
+	// acquire blocks while an identical call is in progress and returns a
+	// function that ends this one.
+	acquire(ctx context.Context, name string, args []byte) (release func(), err error)
+	cachedResult(ctx context.Context, name string, args []byte) ([]byte, bool, error)
+	cacheResult(ctx context.Context, name string, args []byte, result []byte) error
+}
+
+// IdempotentRegistry wraps a registry so that tools with side effects, such
+// as sending an email or charging a card, can be used safely in pipelines
+// that retry, like those using the Retry middleware. When HandleFunctionCalls
+// runs with a context carrying an idempotency key, see WithIdempotencyKey,
+// each successful result is saved in store under a hash of the key, the tool
+// name and its arguments. A later call with the same key, tool and arguments
+// returns the saved result without calling the tool, without counting
+// against the registry's limits and without being reported to OnInvoke.
+//
+// An identical call made while the first is still running waits for it and
+// replays its result. This only covers calls through the same registry;
+// processes sharing store are not coordinated.
+//
+// Calls made without a key are always executed, so repeating an action on
+// purpose still works. Failed calls are not saved and run again when
+// retried. Arguments match when they are equal as JSON, regardless of
+// whitespace and key order. store's Load must return an empty value without
+// an error for keys that were never saved.
+//
+// Example:
+//
+//	registry := minds.IdempotentRegistry(minds.NewToolRegistry(), store)
+//	registry.Register(sendEmail)
+func IdempotentRegistry(inner ToolRegistry, store KVStore) ToolRegistry {
+	return &idempotentRegistry{ToolRegistry: inner, store: store, inflight: make(map[string]chan struct{})}
+}
+
+type idempotentRegistry struct {
+	ToolRegistry
+	store KVStore
+
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+}
+
+// cachedEntry is a tool result as saved in the store. Wrapping the result
+// distinguishes an empty result from a missing one.
+type cachedEntry struct {
+	Result []byte `json:"result"`
+}
+
+// acquire waits until no call with the same key, tool and arguments is in
+// progress in this process and marks this one as in progress. Calls without a
+// key are never held.
+func (r *idempotentRegistry) acquire(ctx context.Context, name string, args []byte) (func(), error) {
+	key := IdempotencyKey(ctx)
+	if key == "" {
+		return func() {}, nil
+	}
+
+	id := string(idempotencyStoreKey(key, name, args))
+	for {
+		r.mu.Lock()
+		done, busy := r.inflight[id]
+		if !busy {
+			done = make(chan struct{})
+			r.inflight[id] = done
+			r.mu.Unlock()
+
+			return func() {
+				r.mu.Lock()
+				delete(r.inflight, id)
+				r.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (r *idempotentRegistry) cachedResult(ctx context.Context, name string, args []byte) ([]byte, bool, error) {
+	key := IdempotencyKey(ctx)
+	if key == "" {
+		return nil, false, nil
+	}
+
+	data, err := r.store.Load(ctx, idempotencyStoreKey(key, name, args))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load cached result of `%s`: %w", name, err)
+	}
+	if len(data) == 0 {
+		return nil, false, nil
+	}
+
+	var entry cachedEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cached result of `%s`: %w", name, err)
+	}
+	return entry.Result, true, nil
+}
+
+func (r *idempotentRegistry) cacheResult(ctx context.Context, name string, args []byte, result []byte) error {
+	key := IdempotencyKey(ctx)
+	if key == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(cachedEntry{Result: result})
+	if err != nil {
+		return err
+	}
+
+	if err := r.store.Save(ctx, idempotencyStoreKey(key, name, args), data); err != nil {
+		return fmt.Errorf("failed to cache result of `%s`: %w", name, err)
+	}
+	return nil
+}
+
+func (r *idempotentRegistry) unwrap() ToolRegistry {
+	return r.ToolRegistry
+}
+
+// idempotencyStoreKey returns the store key for a call to the named tool with
+// args under an idempotency key. Arguments are normalized so that equal JSON
+// hashes the same.
+func idempotencyStoreKey(key, name string, args []byte) []byte {
+	var v any
+	if err := json.Unmarshal(args, &v); err == nil {
+		if normalized, err := json.Marshal(v); err == nil {
+			args = normalized
+		}
+	}
+
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(key), []byte(name), args} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+
+	return []byte("minds/idempotency/" + hex.EncodeToString(h.Sum(nil)))
+}
+
+// cachedResult returns the first result saved for a call to the named tool
+// with args in any of the registry layers.
+func cachedResult(ctx context.Context, layers []ToolRegistry, name string, args []byte) ([]byte, bool, error) {
+	for _, layer := range layers {
+		c, ok := layer.(resultCache)
+		if !ok {
+			continue
+		}
+		if result, hit, err := c.cachedResult(ctx, name, args); err != nil || hit {
+			return result, hit, err
+		}
+	}
+	return nil, false, nil
+}
+
+// acquireResults acquires the call in every registry layer holding a cache,
+// outermost first, and returns a function releasing them all.
+func acquireResults(ctx context.Context, layers []ToolRegistry, name string, args []byte) (func(), error) {
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	for _, layer := range layers {
+		c, ok := layer.(resultCache)
+		if !ok {
+			continue
+		}
+		r, err := c.acquire(ctx, name, args)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	return release, nil
+}
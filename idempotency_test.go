@@ -0,0 +1,204 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// mapKVStore is an in-memory KVStore.
+type mapKVStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *mapKVStore) Save(_ context.Context, key []byte, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[string(key)] = value
+	return nil
+}
+
+func (s *mapKVStore) Load(_ context.Context, key []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[string(key)], nil
+}
+
+func TestIdempotentRegistry(t *testing.T) {
+	newRegistry := func(t *testing.T, opts ...ToolRegistryOption) (ToolRegistry, *int) {
+		t.Helper()
+		calls := 0
+		tool, err := WrapFunction("charge", "Charge the card", struct {
+			Input string `json:"input"`
+		}{}, func(ctx context.Context, args []byte) ([]byte, error) {
+			calls++
+			if string(args) == `{"input":"fail"}` {
+				return nil, errors.New("declined")
+			}
+			return args, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		registry := IdempotentRegistry(NewToolRegistry(opts...), &mapKVStore{data: map[string][]byte{}})
+		if err := registry.Register(tool); err != nil {
+			t.Fatal(err)
+		}
+		return registry, &calls
+	}
+
+	t.Run("same key executes once", func(t *testing.T) {
+		is := is.New(t)
+		registry, calls := newRegistry(t)
+		ctx := WithIdempotencyKey(context.Background(), "order-1")
+
+		results, err := HandleFunctionCalls(ctx, toolCalls("charge"), registry)
+		is.NoErr(err)
+		is.Equal(string(results[0].Function.Result), `{"input":"x"}`)
+
+		// A retry with equivalent arguments replays the result.
+		retry := []ToolCall{{Function: FunctionCall{Name: "charge", Parameters: []byte(`{ "input": "x" }`)}}}
+		results, err = HandleFunctionCalls(ctx, retry, registry)
+		is.NoErr(err)
+		is.Equal(string(results[0].Function.Result), `{"input":"x"}`)
+		is.Equal(*calls, 1)
+	})
+
+	t.Run("different key executes again", func(t *testing.T) {
+		is := is.New(t)
+		registry, calls := newRegistry(t)
+
+		_, err := HandleFunctionCalls(WithIdempotencyKey(context.Background(), "order-1"), toolCalls("charge"), registry)
+		is.NoErr(err)
+		_, err = HandleFunctionCalls(WithIdempotencyKey(context.Background(), "order-2"), toolCalls("charge"), registry)
+		is.NoErr(err)
+		is.Equal(*calls, 2)
+	})
+
+	t.Run("no key executes every time", func(t *testing.T) {
+		is := is.New(t)
+		registry, calls := newRegistry(t)
+
+		_, err := HandleFunctionCalls(context.Background(), toolCalls("charge", "charge"), registry)
+		is.NoErr(err)
+		is.Equal(*calls, 2)
+		is.Equal(IdempotencyKey(context.Background()), "")
+	})
+
+	t.Run("failures are not cached", func(t *testing.T) {
+		is := is.New(t)
+		registry, calls := newRegistry(t)
+		ctx := WithIdempotencyKey(context.Background(), "order-1")
+		failing := func() []ToolCall {
+			return []ToolCall{{Function: FunctionCall{Name: "charge", Parameters: []byte(`{"input":"fail"}`)}}}
+		}
+
+		_, err := HandleFunctionCalls(ctx, failing(), registry)
+		is.NoErr(err)
+		results, err := HandleFunctionCalls(ctx, failing(), registry)
+		is.NoErr(err)
+		is.Equal(string(results[0].Function.Result), "ERROR: Tool `charge` failed: declined")
+		is.Equal(*calls, 2)
+	})
+
+	t.Run("replays do not consume budget", func(t *testing.T) {
+		is := is.New(t)
+		registry, calls := newRegistry(t, WithMaxInvocations(1))
+		ctx := WithIdempotencyKey(context.Background(), "order-1")
+
+		_, err := HandleFunctionCalls(ctx, toolCalls("charge", "charge", "charge"), registry)
+		is.NoErr(err)
+		is.Equal(*calls, 1)
+
+		// The inner registry's limits still apply to new calls.
+		_, err = HandleFunctionCalls(WithIdempotencyKey(ctx, "order-2"), toolCalls("charge"), registry)
+		is.True(errors.Is(err, ErrToolBudgetExceeded))
+	})
+
+	t.Run("nested registries replay the inner cache", func(t *testing.T) {
+		is := is.New(t)
+		inner, calls := newRegistry(t)
+		ctx := WithIdempotencyKey(context.Background(), "order-1")
+
+		_, err := HandleFunctionCalls(ctx, toolCalls("charge"), inner)
+		is.NoErr(err)
+
+		outer := IdempotentRegistry(FilteredRegistry(inner, "charge"), &mapKVStore{data: map[string][]byte{}})
+		results, err := HandleFunctionCalls(ctx, toolCalls("charge"), outer)
+		is.NoErr(err)
+		is.Equal(string(results[0].Function.Result), `{"input":"x"}`)
+		is.Equal(*calls, 1)
+	})
+
+	t.Run("overlapping calls execute once", func(t *testing.T) {
+		is := is.New(t)
+
+		var calls int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+		tool, err := WrapFunction("charge", "Charge the card", struct {
+			Input string `json:"input"`
+		}{}, func(ctx context.Context, args []byte) ([]byte, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return args, nil
+		})
+		is.NoErr(err)
+
+		registry := IdempotentRegistry(NewToolRegistry(), &mapKVStore{data: map[string][]byte{}})
+		is.NoErr(registry.Register(tool))
+		ctx := WithIdempotencyKey(context.Background(), "order-1")
+
+		var wg sync.WaitGroup
+		results := make([]string, 4)
+		run := func(i int) {
+			defer wg.Done()
+			out, err := HandleFunctionCalls(ctx, toolCalls("charge"), registry)
+			if err == nil {
+				results[i] = string(out[0].Function.Result)
+			}
+		}
+
+		// Start one attempt and wait for it to reach the tool, so the rest
+		// overlap with it.
+		wg.Add(1)
+		go run(0)
+		<-started
+		for i := 1; i < len(results); i++ {
+			wg.Add(1)
+			go run(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		is.Equal(atomic.LoadInt32(&calls), int32(1))
+		for _, result := range results {
+			is.Equal(result, `{"input":"x"}`)
+		}
+	})
+
+	t.Run("a waiting call gives up when canceled", func(t *testing.T) {
+		is := is.New(t)
+		registry := IdempotentRegistry(NewToolRegistry(), &mapKVStore{data: map[string][]byte{}}).(*idempotentRegistry)
+		ctx := WithIdempotencyKey(context.Background(), "order-1")
+
+		release, err := registry.acquire(ctx, "charge", []byte(`{}`))
+		is.NoErr(err)
+		defer release()
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = registry.acquire(canceled, "charge", []byte(`{}`))
+		is.True(errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,81 @@
+package codefence
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fence opens and closes a markdown code block.
+const Fence = "```"
+
+// SplitTag splits the text following an opening fence into the language tag
+// on its first line and the body after that line. The first word is only
+// taken as a tag if it looks like a language name, such as json, c++ or
+// objective-c. Otherwise, as in ```{"a": 1}, the text is all body and the tag
+// is empty. Anything after the tag that looks like content, as in
+// ```json {"a": 1}, is kept as the start of the body; other text on the line
+// is an info string and is dropped.
+func SplitTag(text string) (tag, body string) {
+	line, rest, found := strings.Cut(text, "\n")
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		if found {
+			return "", rest
+		}
+		return "", text
+	}
+
+	if !isTag(fields[0]) {
+		return "", text
+	}
+
+	remainder := strings.TrimLeft(strings.TrimPrefix(strings.TrimLeft(line, " \t"), fields[0]), " \t")
+	if !isContent(remainder) {
+		return fields[0], rest
+	}
+	if found {
+		return fields[0], remainder + "\n" + rest
+	}
+	return fields[0], remainder
+}
+
+// First returns the tag and body of the first fenced block in text. A block
+// without a closing fence runs to the end of text. ok is false if text has no
+// fence.
+func First(text string) (tag, body string, ok bool) {
+	start := strings.Index(text, Fence)
+	if start < 0 {
+		return "", text, false
+	}
+
+	tag, body = SplitTag(text[start+len(Fence):])
+	if end := strings.Index(body, Fence); end >= 0 {
+		body = body[:end]
+	}
+
+	return tag, body, true
+}
+
+// isTag reports whether word looks like a language name.
+func isTag(word string) bool {
+	for i, r := range word {
+		if i == 0 && !unicode.IsLetter(r) {
+			return false
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#._-", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isContent reports whether text starts like code or data rather than an info
+// string.
+func isContent(text string) bool {
+	if text == "" {
+		return false
+	}
+	r := rune(text[0])
+	return strings.ContainsRune(`{["`, r) || unicode.IsDigit(r)
+}
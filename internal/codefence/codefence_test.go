@@ -0,0 +1,64 @@
+package codefence
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSplitTag(t *testing.T) {
+	tests := []struct {
+		name, text, tag, body string
+	}{
+		{"language tag", "json\n{\"a\": 1}\n", "json", "{\"a\": 1}\n"},
+		{"info string", "python title=run.py\nprint(1)\n", "python", "print(1)\n"},
+		{"symbols in tag", "c++\nint x;\n", "c++", "int x;\n"},
+		{"blank first line", "\n[1, 2]\n", "", "[1, 2]\n"},
+		{"body on the opening line", "{\"a\":1,\n\"b\":2}\n", "", "{\"a\":1,\n\"b\":2}\n"},
+		{"code on the opening line", "print(1)\n", "", "print(1)\n"},
+		{"tag only", "json", "json", ""},
+		{"content after tag", "json {\"a\":1}", "json", "{\"a\":1}"},
+		{"content after tag spans lines", "json {\"a\":1,\n\"b\":2}\n", "json", "{\"a\":1,\n\"b\":2}\n"},
+		{"array after tag", "json [1, 2]\n", "json", "[1, 2]\n"},
+		{"number is not a tag", "42", "", "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			tag, body := SplitTag(tt.text)
+			is.Equal(tag, tt.tag)
+			is.Equal(body, tt.body)
+		})
+	}
+}
+
+func TestFirst(t *testing.T) {
+	is := is.New(t)
+
+	tag, body, ok := First("Sure!\n```json\n{\"a\": 1}\n```\nand ```go\nx\n```")
+	is.True(ok)
+	is.Equal(tag, "json")
+	is.Equal(body, "{\"a\": 1}\n")
+
+	_, body, ok = First("```{\"a\":1,\n\"b\":2}\n```")
+	is.True(ok)
+	is.Equal(body, "{\"a\":1,\n\"b\":2}\n")
+
+	_, body, ok = First("```json\n{\"a\": 1")
+	is.True(ok) // unterminated
+	is.Equal(body, "{\"a\": 1")
+
+	tag, body, ok = First("```json {\"a\":1}```")
+	is.True(ok)
+	is.Equal(tag, "json")
+	is.Equal(body, "{\"a\":1}")
+
+	_, body, ok = First("```42```")
+	is.True(ok)
+	is.Equal(body, "42")
+
+	_, body, ok = First("no fence")
+	is.True(!ok)
+	is.Equal(body, "no fence")
+}
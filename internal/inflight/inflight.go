@@ -0,0 +1,83 @@
+package inflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShutdown is returned by Begin once Close or Shutdown has been called.
+var ErrShutdown = errors.New("provider is shut down")
+
+// Tracker tracks outstanding requests. The zero value is ready to use.
+type Tracker struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	closed  bool
+	nextID  int
+	cancels map[int]context.CancelFunc
+}
+
+// Begin registers a request. The returned context is canceled if the tracker
+// is closed while the request is running; done must be called when the
+// request finishes.
+func (t *Tracker) Begin(ctx context.Context) (context.Context, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ctx, func() {}, ErrShutdown
+	}
+
+	if t.cancels == nil {
+		t.cancels = make(map[int]context.CancelFunc)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	id := t.nextID
+	t.nextID++
+	t.cancels[id] = cancel
+	t.wg.Add(1)
+
+	return ctx, func() {
+		t.mu.Lock()
+		delete(t.cancels, id)
+		t.mu.Unlock()
+		cancel()
+		t.wg.Done()
+	}, nil
+}
+
+// Close rejects new requests and cancels those in flight.
+func (t *Tracker) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	for _, cancel := range t.cancels {
+		cancel()
+	}
+}
+
+// Shutdown rejects new requests and waits for those in flight to finish. If
+// ctx is done first, the remaining requests are canceled and ctx.Err() is
+// returned.
+func (t *Tracker) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		t.Close()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// headerTransport adds fixed headers to every request sent through it.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	for key, values := range t.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// WithHeaders returns a copy of client whose transport adds headers to every
+// request, replacing any values already set for the same keys. A nil client
+// is treated as http.DefaultClient.
+func WithHeaders(client *http.Client, headers http.Header) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	wrapped := *client
+	wrapped.Transport = &headerTransport{base: base, headers: headers}
+	return &wrapped
+}
+
+// WithProxy returns a copy of client that sends requests through the proxy
+// at proxyURL. A nil client is treated as http.DefaultClient. The client's
+// transport must be an *http.Transport, or nil for http.DefaultTransport.
+func WithProxy(client *http.Client, proxyURL string) (*http.Client, error) {
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("cannot set a proxy on a client with a %T transport; configure the proxy on the client instead", base)
+	}
+
+	transport = transport.Clone()
+	transport.Proxy = http.ProxyURL(proxy)
+
+	wrapped := *client
+	wrapped.Transport = transport
+	return &wrapped, nil
+}
@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestTransport(t *testing.T) {
+	t.Run("proxies and adds headers", func(t *testing.T) {
+		is := is.New(t)
+
+		var proxied, key string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+			key = r.Header.Get("X-Api-Key")
+		}))
+		defer proxy.Close()
+
+		client, err := WithProxy(&http.Client{}, proxy.URL)
+		is.NoErr(err)
+		client = WithHeaders(client, http.Header{"X-Api-Key": {"secret"}})
+
+		req, err := http.NewRequest(http.MethodGet, "http://api.invalid/v1/models", nil)
+		is.NoErr(err)
+		req.Header.Set("X-Api-Key", "caller")
+
+		resp, err := client.Do(req)
+		is.NoErr(err)
+		resp.Body.Close()
+		is.Equal(proxied, "http://api.invalid/v1/models")
+		is.Equal(key, "secret")
+		is.Equal(req.Header.Get("X-Api-Key"), "caller") // the caller's request is not modified
+	})
+
+	t.Run("rejects transports it can't configure", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := WithProxy(&http.Client{Transport: &headerTransport{base: http.DefaultTransport}}, "http://proxy.invalid")
+		is.True(err != nil)
+
+		_, err = WithProxy(nil, "://bad")
+		is.True(err != nil)
+	})
+}
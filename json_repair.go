@@ -0,0 +1,163 @@
+package minds
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/chriscow/minds/internal/codefence"
+)
+
+// ErrNoJSON is returned by RepairJSON when the input contains no JSON object
+// or array.
+var ErrNoJSON = errors.New("no JSON object or array found")
+
+// RepairJSON fixes the most common ways models mangle structured output. It
+// strips markdown code fences, extracts the first balanced JSON object or
+// array from any surrounding prose, quotes bare object keys and removes
+// trailing commas. The result is returned only if it is valid JSON.
+//
+// Example:
+//
+//	fixed, err := minds.RepairJSON([]byte("Sure!\n```json\n{name: \"Bob\", \"tags\": [\"a\",],}\n```"))
+//	// fixed == {"name": "Bob", "tags": ["a"]}
+func RepairJSON(data []byte) ([]byte, error) {
+	doc, err := extractJSON(stripCodeFence(data))
+	if err != nil {
+		return nil, err
+	}
+
+	repaired := normalizeJSON(doc)
+	if !json.Valid(repaired) {
+		return nil, errors.New("unable to repair JSON")
+	}
+
+	return repaired, nil
+}
+
+// stripCodeFence returns the body of the first fenced code block in data, or
+// data unchanged if it has no fence.
+func stripCodeFence(data []byte) []byte {
+	_, body, ok := codefence.First(string(data))
+	if !ok {
+		return data
+	}
+	return []byte(body)
+}
+
+// extractJSON returns the first balanced object or array in data.
+func extractJSON(data []byte) ([]byte, error) {
+	start := bytes.IndexAny(data, "{[")
+	if start < 0 {
+		return nil, ErrNoJSON
+	}
+
+	depth := 0
+	inString := false
+	for i := start; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return data[start : i+1], nil
+			}
+		}
+	}
+
+	return nil, errors.New("unbalanced JSON")
+}
+
+// normalizeJSON quotes bare object keys and drops trailing commas.
+func normalizeJSON(data []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	// prev is the last significant byte written outside of a string.
+	var prev byte
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(data) && data[end] != '"' {
+				if data[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(data) {
+				end = len(data) - 1
+			}
+			out.Write(data[i : end+1])
+			i = end
+			prev = '"'
+
+		case c == ',':
+			if next := nextSignificant(data, i+1); next == '}' || next == ']' {
+				continue
+			}
+			out.WriteByte(c)
+			prev = c
+
+		case isIdentStart(c) && (prev == '{' || prev == ','):
+			end := i
+			for end < len(data) && isIdentPart(data[end]) {
+				end++
+			}
+			if nextSignificant(data, end) == ':' {
+				out.WriteByte('"')
+				out.Write(data[i:end])
+				out.WriteByte('"')
+			} else {
+				out.Write(data[i:end])
+			}
+			i = end - 1
+			prev = 'a'
+
+		default:
+			out.WriteByte(c)
+			if !isSpace(c) {
+				prev = c
+			}
+		}
+	}
+
+	return out.Bytes()
+}
+
+func nextSignificant(data []byte, from int) byte {
+	for i := from; i < len(data); i++ {
+		if !isSpace(data[i]) {
+			return data[i]
+		}
+	}
+	return 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c == '-' || (c >= '0' && c <= '9')
+}
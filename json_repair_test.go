@@ -0,0 +1,51 @@
+package minds
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"valid input is unchanged", `{"a": 1}`, `{"a": 1}`},
+		{"code fence", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"surrounding prose", "Here you go: {\"a\": [1, 2]} Let me know!", `{"a": [1, 2]}`},
+		{"trailing commas", `{"a": [1, 2,], "b": 3,}`, `{"a": [1, 2], "b": 3}`},
+		{"bare keys", `{name: "Bob", is_admin: true, "n": null}`, `{"name": "Bob", "is_admin": true, "n": null}`},
+		{"strings are untouched", `{"a": "x, }", "b": "{c: 1,}",}`, `{"a": "x, }", "b": "{c: 1,}"}`},
+		{"escaped quotes", `{"a": "say \"hi\",",}`, `{"a": "say \"hi\","}`},
+		{"top-level array", "```\n[{a: 1},]\n```", `[{"a": 1}]`},
+		{"fence opening on the JSON line", "```{\"a\":1,\n\"b\":2}\n```", `{"a":1,
+"b":2}`},
+		{"single-line tagged fence", "```json {\"a\":1}```", `{"a":1}`},
+		{"JSON after the tag", "```json {\"a\":1,\n\"b\":2}\n```", `{"a":1,
+"b":2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			got, err := RepairJSON([]byte(tt.input))
+			is.NoErr(err)
+			is.Equal(string(got), tt.want)
+		})
+	}
+
+	t.Run("no JSON", func(t *testing.T) {
+		is := is.New(t)
+		_, err := RepairJSON([]byte("I can't help with that."))
+		is.True(errors.Is(err, ErrNoJSON))
+	})
+
+	t.Run("unrepairable", func(t *testing.T) {
+		is := is.New(t)
+		_, err := RepairJSON([]byte(`{"a": }`))
+		is.True(err != nil)
+	})
+}
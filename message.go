@@ -2,8 +2,20 @@ package minds
 
 import (
 	"encoding/json"
+	"errors"
 )
 
+// Role identifies the author of a message. The canonical roles are the
+// constants below. Providers translate them to their own vocabulary and
+// return an error wrapping ErrUnsupportedRole for any other role.
+//
+//   - RoleUser: input from the end user. An empty role is treated as RoleUser.
+//   - RoleSystem, RoleDeveloper: instructions to the model.
+//   - RoleAssistant: output from the model. RoleModel and RoleAI are aliases
+//     used by some providers.
+//   - RoleTool: the result of a tool call, linked to the call by ToolCallID.
+//   - RoleFunction: the legacy name for a tool result. Providers send it as
+//     RoleTool when ToolCallID is set.
 type Role string
 
 const (
@@ -17,6 +29,19 @@ const (
 	RoleDeveloper Role = "developer"
 )
 
+// ErrUnsupportedRole is returned by providers for messages whose role they
+// cannot send.
+var ErrUnsupportedRole = errors.New("unsupported role")
+
+// IsValid reports whether r is one of the canonical roles.
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleAssistant, RoleSystem, RoleFunction, RoleTool, RoleAI, RoleModel, RoleDeveloper:
+		return true
+	}
+	return false
+}
+
 type Message struct {
 	Role       Role       `json:"role"`
 	Content    string     `json:"content"`
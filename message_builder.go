@@ -0,0 +1,170 @@
+package minds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ErrInvalidMessageOrder is returned by MessageBuilder.BuildValidated when the
+// roles of the messages are not in an order models accept.
+var ErrInvalidMessageOrder = errors.New("invalid message order")
+
+// MessageBuilder builds Messages with a fluent API. Create one with
+// NewMessages. Struct literals remain a valid way to build Messages; the
+// builder is a shorthand for the common cases.
+type MessageBuilder struct {
+	messages Messages
+	err      error
+}
+
+// NewMessages returns an empty MessageBuilder.
+//
+// Example:
+//
+//	messages := minds.NewMessages().
+//	    System("You are a helpful assistant.").
+//	    UserTemplate("Summarize {{.Title}} in one line.", article).
+//	    Build()
+func NewMessages() *MessageBuilder {
+	return &MessageBuilder{messages: Messages{}}
+}
+
+// System appends a system message.
+func (b *MessageBuilder) System(content string) *MessageBuilder {
+	return b.Add(Message{Role: RoleSystem, Content: content})
+}
+
+// User appends a user message.
+func (b *MessageBuilder) User(content string) *MessageBuilder {
+	return b.Add(Message{Role: RoleUser, Content: content})
+}
+
+// Assistant appends an assistant message.
+func (b *MessageBuilder) Assistant(content string) *MessageBuilder {
+	return b.Add(Message{Role: RoleAssistant, Content: content})
+}
+
+// SystemTemplate appends a system message whose content is the text/template
+// tmpl executed with data.
+func (b *MessageBuilder) SystemTemplate(tmpl string, data any) *MessageBuilder {
+	return b.addTemplate(RoleSystem, tmpl, data)
+}
+
+// UserTemplate appends a user message whose content is the text/template tmpl
+// executed with data.
+func (b *MessageBuilder) UserTemplate(tmpl string, data any) *MessageBuilder {
+	return b.addTemplate(RoleUser, tmpl, data)
+}
+
+// AssistantTemplate appends an assistant message whose content is the
+// text/template tmpl executed with data.
+func (b *MessageBuilder) AssistantTemplate(tmpl string, data any) *MessageBuilder {
+	return b.addTemplate(RoleAssistant, tmpl, data)
+}
+
+// Add appends messages as they are, for roles or fields the other methods
+// don't cover, such as tool results.
+func (b *MessageBuilder) Add(messages ...Message) *MessageBuilder {
+	b.messages = append(b.messages, messages...)
+	return b
+}
+
+func (b *MessageBuilder) addTemplate(role Role, tmpl string, data any) *MessageBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	t, err := template.New(string(role)).Parse(tmpl)
+	if err != nil {
+		b.err = fmt.Errorf("message %d: failed to parse template: %w", len(b.messages), err)
+		return b
+	}
+
+	var content strings.Builder
+	if err := t.Execute(&content, data); err != nil {
+		b.err = fmt.Errorf("message %d: failed to execute template: %w", len(b.messages), err)
+		return b
+	}
+
+	return b.Add(Message{Role: role, Content: content.String()})
+}
+
+// Err returns the first error from a template method, or nil.
+func (b *MessageBuilder) Err() error {
+	return b.err
+}
+
+// Build returns a copy of the messages built so far. It panics if a template
+// failed to parse or execute; use BuildValidated, or check Err, when the
+// templates or their data come from outside the program.
+func (b *MessageBuilder) Build() Messages {
+	if b.err != nil {
+		panic(b.err)
+	}
+
+	return append(Messages{}, b.messages...)
+}
+
+// BuildValidated returns a copy of the messages built so far, or an error if
+// a template failed or the roles are out of order. It returns an error
+// wrapping ErrInvalidMessageOrder unless:
+//   - there is at least one message and every role is a canonical Role or
+//     empty
+//   - system and developer messages only appear before all others
+//   - the first message after them is a user message
+//   - user and assistant messages alternate
+//   - tool and function results only follow an assistant message or another
+//     result
+func (b *MessageBuilder) BuildValidated() (Messages, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if err := validateMessageOrder(b.messages); err != nil {
+		return nil, err
+	}
+
+	return append(Messages{}, b.messages...), nil
+}
+
+func validateMessageOrder(messages Messages) error {
+	if len(messages) == 0 {
+		return ErrNoMessages
+	}
+
+	var prev Role
+	for i, msg := range messages {
+		role := msg.Role
+		switch role {
+		case "":
+			role = RoleUser
+		case RoleModel, RoleAI:
+			role = RoleAssistant
+		case RoleDeveloper:
+			role = RoleSystem
+		case RoleFunction:
+			role = RoleTool
+		}
+
+		if !role.IsValid() {
+			return fmt.Errorf("%w: message %d has unknown role %q", ErrInvalidMessageOrder, i, msg.Role)
+		}
+
+		switch {
+		case role == RoleSystem && prev != "" && prev != RoleSystem:
+			return fmt.Errorf("%w: message %d is a system message after the conversation started", ErrInvalidMessageOrder, i)
+		case role != RoleSystem && (prev == "" || prev == RoleSystem) && role != RoleUser:
+			return fmt.Errorf("%w: message %d: the conversation must start with a user message, not %s", ErrInvalidMessageOrder, i, msg.Role)
+		case role == RoleTool && prev != RoleAssistant && prev != RoleTool:
+			return fmt.Errorf("%w: message %d is a tool result that does not follow an assistant message", ErrInvalidMessageOrder, i)
+		case (role == RoleUser || role == RoleAssistant) && role == prev:
+			return fmt.Errorf("%w: messages %d and %d are both %s messages", ErrInvalidMessageOrder, i-1, i, role)
+		}
+
+		prev = role
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package minds
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	t.Run("builds messages", func(t *testing.T) {
+		is := is.New(t)
+
+		messages := NewMessages().
+			System("Be brief.").
+			UserTemplate("Summarize {{.Title}}.", struct{ Title string }{"Hamlet"}).
+			Assistant("A prince hesitates.").
+			User("Thanks").
+			Build()
+
+		is.Equal(messages, Messages{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Summarize Hamlet."},
+			{Role: RoleAssistant, Content: "A prince hesitates."},
+			{Role: RoleUser, Content: "Thanks"},
+		})
+	})
+
+	t.Run("templates are not HTML escaped", func(t *testing.T) {
+		is := is.New(t)
+
+		messages := NewMessages().UserTemplate("Is {{.}} true?", "a < b && c").Build()
+		is.Equal(messages[0].Content, "Is a < b && c true?")
+	})
+
+	t.Run("template errors", func(t *testing.T) {
+		is := is.New(t)
+
+		b := NewMessages().User("hi").UserTemplate("{{.Missing", nil)
+		is.True(b.Err() != nil)
+
+		_, err := b.BuildValidated()
+		is.True(err != nil)
+
+		defer func() {
+			is.True(recover() != nil) // Build panics
+		}()
+		b.Build()
+	})
+
+	t.Run("build returns a copy", func(t *testing.T) {
+		is := is.New(t)
+
+		b := NewMessages().User("hi")
+		messages := b.Build()
+		messages[0].Content = "changed"
+		is.Equal(b.Build()[0].Content, "hi")
+	})
+}
+
+func TestMessageBuilderBuildValidated(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *MessageBuilder
+		valid   bool
+	}{
+		{"system then user", NewMessages().System("s").User("u").Assistant("a"), true},
+		{"tool result after assistant", NewMessages().User("u").Add(
+			Message{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "1"}}},
+			Message{Role: RoleTool, ToolCallID: "1", Content: "42"},
+		).Assistant("It's 42."), true},
+		{"empty", NewMessages(), false},
+		{"late system", NewMessages().User("u").System("s"), false},
+		{"starts with assistant", NewMessages().System("s").Assistant("a"), false},
+		{"consecutive users", NewMessages().User("u").User("u"), false},
+		{"stray tool result", NewMessages().User("u").Add(Message{Role: RoleTool, Content: "42"}), false},
+		{"unknown role", NewMessages().Add(Message{Role: "narrator", Content: "n"}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			messages, err := tt.builder.BuildValidated()
+			if tt.valid {
+				is.NoErr(err)
+				is.True(len(messages) > 0)
+				return
+			}
+
+			is.True(errors.Is(err, ErrInvalidMessageOrder) || errors.Is(err, ErrNoMessages))
+		})
+	}
+}
@@ -2,31 +2,70 @@ package minds
 
 import (
 	"errors"
+	"unicode/utf8"
 )
 
 var (
 	ErrNoMessages = errors.New("no messages in thread")
 )
 
+const (
+	// estimatedCharsPerToken is the average length of a token in English
+	// text, used by EstimateTokens.
+	estimatedCharsPerToken = 4
+
+	// estimatedTokensPerMessage covers the role and delimiters providers add
+	// around each message.
+	estimatedTokensPerMessage = 4
+)
+
 type Messages []Message
 
-// Copy returns a deep copy of the messages
+// Copy returns a deep copy of the messages. It is equivalent to Clone.
 func (m Messages) Copy() Messages {
+	return m.Clone()
+}
+
+// Clone returns a deep copy of the messages that can be mutated without
+// affecting the original. The slice, each message's Metadata map, its
+// ToolCalls slice and the Parameters and Result bytes of each tool call are
+// all copied. Metadata values themselves are copied shallowly.
+func (m Messages) Clone() Messages {
 	copied := make(Messages, len(m))
 	for i, msg := range m {
-		newMsg := Message{
+		copied[i] = Message{
 			Role:       msg.Role,
 			Content:    msg.Content,
 			Name:       msg.Name,
 			Metadata:   msg.Metadata.Copy(),
 			ToolCallID: msg.ToolCallID,
-			ToolCalls:  msg.ToolCalls,
+			ToolCalls:  cloneToolCalls(msg.ToolCalls),
 		}
-		copied[i] = newMsg
 	}
 	return copied
 }
 
+func cloneToolCalls(calls []ToolCall) []ToolCall {
+	if calls == nil {
+		return nil
+	}
+
+	copied := make([]ToolCall, len(calls))
+	for i, call := range calls {
+		copied[i] = call
+		copied[i].Function.Parameters = cloneBytes(call.Function.Parameters)
+		copied[i].Function.Result = cloneBytes(call.Function.Result)
+	}
+	return copied
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
 // Last returns the last message in the slice of messages. NOTE: This will
 // return an empty message if there are no messages in the slice.
 func (m Messages) Last() Message {
@@ -86,3 +125,26 @@ func (m Messages) TokenCount(tokenizer TokenCounter) (int, error) {
 
 	return total, nil
 }
+
+// EstimateTokens returns a rough count of the tokens in the messages, at
+// about four characters per token plus a fixed overhead per message. The
+// content, name and tool calls of each message are counted. It needs no
+// tokenizer, so it suits logging, metrics and rough budgeting, but it can be
+// off by a wide margin for code, non-English text or unusual formatting. Use
+// TokenCount with a provider's tokenizer, such as openai.NewTokenizer, when
+// the count must be exact.
+func (m Messages) EstimateTokens() int {
+	total := 0
+	for _, msg := range m {
+		chars := utf8.RuneCountInString(msg.Content) + utf8.RuneCountInString(msg.Name)
+		for _, call := range msg.ToolCalls {
+			chars += utf8.RuneCountInString(call.Function.Name) +
+				utf8.RuneCount(call.Function.Parameters) +
+				utf8.RuneCount(call.Function.Result)
+		}
+
+		total += estimatedTokensPerMessage + (chars+estimatedCharsPerToken-1)/estimatedCharsPerToken
+	}
+
+	return total
+}
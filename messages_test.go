@@ -0,0 +1,98 @@
+package minds
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMessagesClone(t *testing.T) {
+	is := is.New(t)
+
+	original := Messages{
+		{Role: RoleUser, Content: "What's the weather?", Metadata: Metadata{"lang": "en"}},
+		{
+			Role: RoleAssistant,
+			ToolCalls: []ToolCall{{
+				ID: "call_1",
+				Function: FunctionCall{
+					Name:       "get_weather",
+					Parameters: []byte(`{"city":"Paris"}`),
+					Result:     []byte(`{"temp":21}`),
+				},
+			}},
+		},
+	}
+
+	clone := original.Clone()
+	clone[0].Content = "changed"
+	clone[0].Metadata["lang"] = "fr"
+	clone[1].ToolCalls[0].ID = "call_2"
+	clone[1].ToolCalls[0].Function.Parameters[2] = 'X'
+	clone[1].ToolCalls[0].Function.Result[0] = '['
+	clone = append(clone, Message{Role: RoleUser, Content: "extra"})
+
+	is.Equal(len(original), 2)
+	is.Equal(original[0].Content, "What's the weather?")
+	is.Equal(original[0].Metadata["lang"], "en")
+	is.Equal(original[1].ToolCalls[0].ID, "call_1")
+	is.Equal(string(original[1].ToolCalls[0].Function.Parameters), `{"city":"Paris"}`)
+	is.Equal(string(original[1].ToolCalls[0].Function.Result), `{"temp":21}`)
+}
+
+func TestThreadContextCloneIsolation(t *testing.T) {
+	is := is.New(t)
+
+	tc := NewThreadContext(context.Background()).WithMessages(Message{
+		Role:      RoleAssistant,
+		ToolCalls: []ToolCall{{ID: "call_1", Function: FunctionCall{Name: "search"}}},
+	})
+	tc.SetKeyValue("step", 1)
+
+	clone := tc.Clone()
+	clone.AppendMessages(Message{Role: RoleUser, Content: "more"})
+	clone.SetKeyValue("step", 2)
+
+	msgs := clone.Messages()
+	msgs[0].ToolCalls[0].ID = "mutated"
+
+	is.Equal(len(tc.Messages()), 1)
+	is.Equal(tc.Messages()[0].ToolCalls[0].ID, "call_1")
+	is.Equal(tc.Metadata()["step"], 1)
+}
+
+func TestRoleIsValid(t *testing.T) {
+	is := is.New(t)
+
+	for _, role := range []Role{RoleUser, RoleAssistant, RoleSystem, RoleFunction, RoleTool, RoleAI, RoleModel, RoleDeveloper} {
+		is.True(role.IsValid())
+	}
+
+	is.True(!Role("").IsValid())
+	is.True(!Role("narrator").IsValid())
+}
+
+func TestMessagesEstimateTokens(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(Messages{}.EstimateTokens(), 0)
+	is.Equal(Messages{{Role: RoleUser}}.EstimateTokens(), 4)                       // overhead only
+	is.Equal(Messages{{Role: RoleUser, Content: "abcdefgh"}}.EstimateTokens(), 6)  // 8 chars
+	is.Equal(Messages{{Role: RoleUser, Content: "abcdefghi"}}.EstimateTokens(), 7) // rounds up
+	is.Equal(Messages{{Role: RoleUser, Content: "日本語です"}}.EstimateTokens(), 6)     // counts runes, not bytes
+
+	withCall := Messages{{
+		Role: RoleAssistant,
+		ToolCalls: []ToolCall{{
+			Function: FunctionCall{Name: "time", Parameters: []byte(`{}`), Result: []byte(`"12:00"`)},
+		}},
+	}}
+	is.Equal(withCall.EstimateTokens(), 4+4) // 13 chars
+
+	conversation := Messages{
+		{Role: RoleSystem, Content: "You are helpful."},
+		{Role: RoleUser, Content: "Hello there"},
+	}
+	is.Equal(conversation.EstimateTokens(), conversation[:1].EstimateTokens()+conversation[1:].EstimateTokens())
+}
@@ -0,0 +1,93 @@
+package minds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MetadataToolName is the name of the tool returned by MetadataTool.
+const MetadataToolName = "get_context"
+
+type threadMetadataContextKey struct{}
+
+// WithThreadMetadata returns a copy of ctx carrying the metadata of the thread
+// a request is made for, so that tools called with ctx can read it. Providers
+// set it from Request.Metadata before executing tool calls.
+func WithThreadMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, threadMetadataContextKey{}, metadata)
+}
+
+// ThreadMetadata returns the thread metadata set on ctx with
+// WithThreadMetadata, or nil if there is none. Tools must treat it as read
+// only.
+func ThreadMetadata(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(threadMetadataContextKey{}).(Metadata)
+	return metadata
+}
+
+// MetadataTool returns a tool named "get_context" that lets the model read
+// the values of the given keys from the metadata of the current thread, for
+// agentic flows where earlier handlers store state the model needs. The tool
+// reads the metadata with ThreadMetadata, so it works with providers used as
+// ThreadHandlers, or with GenerateContent when Request.Metadata is set. The
+// value is returned as JSON; keys that are not set return null.
+//
+// Only the listed keys can be read, and they are listed in the tool's schema.
+// Everything the tool returns is sent to the model provider and can end up in
+// the model's answer, so don't expose keys holding secrets, credentials or
+// personal data. A model following injected instructions may also call the
+// tool to read any exposed key, so treat exposed values as visible to the
+// user.
+//
+// Example:
+//
+//	registry := minds.NewToolRegistry()
+//	registry.Register(minds.MetadataTool("customer_tier", "open_ticket_ids"))
+func MetadataTool(keys ...string) Tool {
+	description := "Get a value stored in the context of the current conversation. Available keys: " + strings.Join(keys, ", ")
+
+	args := struct {
+		Key string `json:"key" description:"The key of the value to get"`
+	}{}
+
+	tool, err := WrapFunction(MetadataToolName, description, args, func(ctx context.Context, params []byte) ([]byte, error) {
+		var args struct {
+			Key string `json:"key"`
+		}
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+
+		allowed := false
+		for _, key := range keys {
+			if key == args.Key {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("unknown key %q; available keys: %s", args.Key, strings.Join(keys, ", "))
+		}
+
+		value, err := json.Marshal(ThreadMetadata(ctx)[args.Key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value of %q: %w", args.Key, err)
+		}
+
+		return value, nil
+	})
+	if err != nil {
+		// The name and arguments are fixed, so this cannot happen.
+		panic(err)
+	}
+
+	if len(keys) > 0 {
+		key := tool.argsSchema.Properties["key"]
+		key.Enum = append([]string{}, keys...)
+		tool.argsSchema.Properties["key"] = key
+	}
+
+	return tool
+}
@@ -0,0 +1,54 @@
+package minds
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMetadataTool(t *testing.T) {
+	registry := NewToolRegistry()
+	if err := registry.Register(MetadataTool("tier", "ticket_ids")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithThreadMetadata(context.Background(), Metadata{
+		"tier":       "gold",
+		"ticket_ids": []int{7, 9},
+		"api_key":    "secret",
+	})
+
+	call := func(ctx context.Context, args string) string {
+		calls := []ToolCall{{Function: FunctionCall{Name: MetadataToolName, Parameters: []byte(args)}}}
+		results, err := HandleFunctionCalls(ctx, calls, registry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(results[0].Function.Result)
+	}
+
+	t.Run("reads listed keys", func(t *testing.T) {
+		is := is.New(t)
+		is.Equal(call(ctx, `{"key":"tier"}`), `"gold"`)
+		is.Equal(call(ctx, `{"key":"ticket_ids"}`), `[7,9]`)
+	})
+
+	t.Run("rejects other keys", func(t *testing.T) {
+		is := is.New(t)
+		is.True(strings.HasPrefix(call(ctx, `{"key":"api_key"}`), "ERROR"))
+	})
+
+	t.Run("missing values are null", func(t *testing.T) {
+		is := is.New(t)
+		is.Equal(call(context.Background(), `{"key":"tier"}`), "null")
+	})
+
+	t.Run("schema lists the keys", func(t *testing.T) {
+		is := is.New(t)
+		tool, _ := registry.Lookup(MetadataToolName)
+		is.Equal(tool.Parameters().Properties["key"].Enum, []string{"tier", "ticket_ids"})
+		is.True(strings.Contains(tool.Description(), "tier, ticket_ids"))
+	})
+}
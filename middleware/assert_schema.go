@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+)
+
+// ErrSchemaViolation is returned by AssertSchema when the response does not
+// match the schema. The error also wraps a *minds.ValidationError naming the
+// offending field; use errors.As to read it.
+var ErrSchemaViolation = errors.New("response does not match schema")
+
+// AssertSchema creates a middleware that validates the most recent assistant
+// message against schema after the wrapped handler runs. The message must be
+// JSON matching the schema's Definition: required fields present, types
+// matching and enum values allowed. A violation fails the handler with an
+// error wrapping ErrSchemaViolation and a *minds.ValidationError whose Path
+// names the offending field, such as "items[2].price". Put it inside Retry to
+// regenerate invalid responses, and after StripCodeFences for models that
+// fence their JSON.
+//
+// Example usage:
+//
+//	schema, _ := minds.NewResponseSchema("weather_report", "A weather report", WeatherReport{})
+//	llm.Use(
+//	    middleware.Retry("retry", retry.WithAttempts(3)),
+//	    middleware.AssertSchema("assert", *schema),
+//	)
+func AssertSchema(name string, schema minds.ResponseSchema) minds.Middleware {
+	return &schemaAsserter{name: name, schema: schema}
+}
+
+// schemaAsserter validates the last assistant message against a schema.
+type schemaAsserter struct {
+	name   string
+	schema minds.ResponseSchema
+}
+
+// Wrap applies the schema validation to a handler.
+func (s *schemaAsserter) Wrap(next minds.ThreadHandler) minds.ThreadHandler {
+	return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		result, err := next.HandleThread(tc, nil)
+		if err != nil {
+			return result, err
+		}
+
+		messages := result.Messages()
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role != minds.RoleAssistant {
+				continue
+			}
+
+			content := messages[i].Content
+			if strings.TrimSpace(content) == "" {
+				return result, &schemaViolation{name: s.name, schema: s.schema.Name,
+					err: &minds.ValidationError{Reason: "empty response"}}
+			}
+
+			if err := minds.ValidateArguments(s.schema.Definition, []byte(content)); err != nil {
+				return result, &schemaViolation{name: s.name, schema: s.schema.Name, err: err}
+			}
+
+			return result, nil
+		}
+
+		return result, fmt.Errorf("%s: no assistant message to validate", s.name)
+	})
+}
+
+// schemaViolation is the error returned by AssertSchema. It matches
+// ErrSchemaViolation and unwraps to the validation error.
+type schemaViolation struct {
+	name   string
+	schema string
+	err    error
+}
+
+func (e *schemaViolation) Error() string {
+	return fmt.Sprintf("%s: %v %q: %v", e.name, ErrSchemaViolation, e.schema, e.err)
+}
+
+func (e *schemaViolation) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
+
+func (e *schemaViolation) Unwrap() error {
+	return e.err
+}
+
+// String returns a string representation of the middleware.
+func (s *schemaAsserter) String() string {
+	return fmt.Sprintf("AssertSchema(%s, %s)", s.name, s.schema.Name)
+}
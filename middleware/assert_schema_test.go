@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/middleware"
+	"github.com/matryer/is"
+)
+
+func TestAssertSchema(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	type order struct {
+		Status string `json:"status" enum:"open,shipped"`
+		Items  []item `json:"items"`
+	}
+
+	schema, err := minds.NewResponseSchema("order", "An order", order{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	respond := func(content string) minds.ThreadHandler {
+		return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc.WithMessages(append(tc.Messages(), minds.Message{Role: minds.RoleAssistant, Content: content})...), nil
+		})
+	}
+
+	run := func(handler minds.ThreadHandler) error {
+		tc := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Show my order"})
+		_, err := middleware.AssertSchema("assert", *schema).Wrap(handler).HandleThread(tc, nil)
+		return err
+	}
+
+	t.Run("accepts valid responses", func(t *testing.T) {
+		is := is.New(t)
+		is.NoErr(run(respond(`{"status": "open", "items": [{"name": "pen", "price": 1.5}]}`)))
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		path    string
+	}{
+		{"missing field", `{"status": "open"}`, "items"},
+		{"wrong type", `{"status": "open", "items": [{"name": "pen", "price": "cheap"}]}`, "items[0].price"},
+		{"invalid enum", `{"status": "lost", "items": []}`, "status"},
+		{"invalid JSON", `not json`, ""},
+		{"empty response", ``, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			err := run(respond(tt.content))
+			is.True(errors.Is(err, middleware.ErrSchemaViolation))
+
+			var verr *minds.ValidationError
+			is.True(errors.As(err, &verr))
+			is.Equal(verr.Path, tt.path)
+		})
+	}
+
+	t.Run("requires an assistant message", func(t *testing.T) {
+		is := is.New(t)
+		noop := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc, nil
+		})
+		is.True(run(noop) != nil)
+	})
+}
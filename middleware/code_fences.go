@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/internal/codefence"
+)
+
+// StripCodeFences creates a middleware that removes markdown code fences from
+// the most recent assistant message after the wrapped handler runs. Some
+// models wrap output in ```json fences even in JSON mode; this strips the
+// opening fence with its language tag and the closing fence. Messages that
+// are not fenced are left untouched.
+//
+// Example usage:
+//
+//	extractor.Use(middleware.StripCodeFences("unfence"))
+func StripCodeFences(name string) minds.Middleware {
+	return &codeFenceStripper{name: name}
+}
+
+// codeFenceStripper removes code fences from the last assistant message.
+type codeFenceStripper struct {
+	name string
+}
+
+// Wrap applies the code fence stripping to a handler.
+func (s *codeFenceStripper) Wrap(next minds.ThreadHandler) minds.ThreadHandler {
+	return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		result, err := next.HandleThread(tc, nil)
+		if err != nil {
+			return result, err
+		}
+
+		messages := result.Messages()
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role != minds.RoleAssistant {
+				continue
+			}
+
+			content, ok := stripCodeFence(messages[i].Content)
+			if !ok {
+				return result, nil
+			}
+
+			messages[i].Content = content
+			return result.WithMessages(messages...), nil
+		}
+
+		return result, nil
+	})
+}
+
+// String returns a string representation of the middleware.
+func (s *codeFenceStripper) String() string {
+	return fmt.Sprintf("StripCodeFences(%s)", s.name)
+}
+
+// stripCodeFence returns the body of content if it is wrapped in a code
+// fence, and false otherwise.
+func stripCodeFence(content string) (string, bool) {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, codefence.Fence) || !strings.HasSuffix(trimmed, codefence.Fence) || len(trimmed) < 2*len(codefence.Fence) {
+		return content, false
+	}
+
+	inner := trimmed[len(codefence.Fence) : len(trimmed)-len(codefence.Fence)]
+	_, body := codefence.SplitTag(inner)
+
+	// A single-line block such as ```true``` has no room for a tag; its only
+	// word is the content.
+	if strings.TrimSpace(body) == "" && !strings.Contains(inner, "\n") {
+		body = inner
+	}
+	return strings.TrimSpace(body), true
+}
@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/middleware"
+	"github.com/matryer/is"
+)
+
+func TestStripCodeFences(t *testing.T) {
+	respond := func(content string) minds.ThreadHandler {
+		return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			tc.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: content})
+			return tc, nil
+		})
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"json fence", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"bare fence", "```\n[1, 2]\n```", "[1, 2]"},
+		{"fence opening on the JSON line", "```{\"a\": 1,\n\"b\": 2}```", "{\"a\": 1,\n\"b\": 2}"},
+		{"single line", "```{\"a\": 1}```", `{"a": 1}`},
+		{"single line with tag", "```json {\"a\":1}```", `{"a":1}`},
+		{"single line number", "```42```", "42"},
+		{"single line word", "```true```", "true"},
+		{"surrounding whitespace", "\n```yaml\na: 1\n```\n", "a: 1"},
+		{"no fence", `{"a": 1}`, `{"a": 1}`},
+		{"fence inside prose", "Use this:\n```go\nx := 1\n```\nDone.", "Use this:\n```go\nx := 1\n```\nDone."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			handler := middleware.StripCodeFences("unfence").Wrap(respond(tt.content))
+
+			result, err := handler.HandleThread(minds.NewThreadContext(context.Background()), nil)
+			is.NoErr(err)
+			is.Equal(result.Messages().Last().Content, tt.want)
+		})
+	}
+
+	t.Run("only the latest assistant message is changed", func(t *testing.T) {
+		is := is.New(t)
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleAssistant, Content: "```\nold\n```"},
+			minds.Message{Role: minds.RoleUser, Content: "```\nuser\n```"},
+		)
+
+		handler := middleware.StripCodeFences("unfence").Wrap(respond("```json\nnew\n```"))
+		result, err := handler.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		msgs := result.Messages()
+		is.Equal(msgs[0].Content, "```\nold\n```")
+		is.Equal(msgs[1].Content, "```\nuser\n```")
+		is.Equal(msgs[2].Content, "new")
+	})
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/chriscow/minds"
+)
+
+// rotation configures the log file rotation of JSONFileLogging.
+type rotation struct {
+	maxSize    int64
+	maxBackups int
+}
+
+// WithRotation rotates the log file written by JSONFileLogging once it
+// reaches maxSizeMB megabytes. The current file is renamed with a ".1"
+// suffix, older backups are shifted to ".2", ".3" and so on, and at most
+// maxBackups backups are kept. It has no effect on Logging.
+func WithRotation(maxSizeMB, maxBackups int) LoggingOption {
+	return func(o *LoggingOptions) {
+		o.rotation.maxSize = int64(maxSizeMB) * 1024 * 1024
+		o.rotation.maxBackups = maxBackups
+	}
+}
+
+// FileLogging is a logging middleware that writes JSON lines to a file.
+// Close it to close the file once the handlers are no longer used.
+type FileLogging struct {
+	minds.Middleware
+	file *rotatingFile
+}
+
+// Close closes the log file.
+func (f *FileLogging) Close() error {
+	return f.file.Close()
+}
+
+// JSONFileLogging creates a logging middleware that appends each entry to the
+// file at path as a line of JSON, creating the file if needed. It accepts the
+// same options as Logging, plus WithRotation; any logger set with WithLogger
+// is replaced by the file logger. Every entry is written regardless of its
+// level.
+//
+// Example:
+//
+//	audit, err := middleware.JSONFileLogging("audit", "minds.jsonl",
+//	    middleware.WithRotation(10, 5),
+//	    middleware.WithLogMetadata(false),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer audit.Close()
+//	pipeline.Use(audit)
+func JSONFileLogging(name, path string, opts ...LoggingOption) (*FileLogging, error) {
+	options := NewLoggingOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	file, err := openRotatingFile(path, options.rotation)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	options.Logger = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	return &FileLogging{
+		Middleware: &logger{name: name, options: options},
+		file:       file,
+	}, nil
+}
+
+// rotatingFile is an append-only file that is rotated when it grows past a
+// maximum size. A zero maxSize disables rotation.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	rotation rotation
+	file     *os.File
+	size     int64
+}
+
+func openRotatingFile(path string, r rotation) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, rotation: r}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write writes p to the file, rotating it first if p would take it past the
+// maximum size. A single entry larger than the maximum is still written whole.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.rotation.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.rotation.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups, moves the current file to the first backup and
+// opens a new file.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	if f.rotation.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+		return f.open()
+	}
+
+	os.Remove(f.backup(f.rotation.maxBackups))
+	for i := f.rotation.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+
+	if err := os.Rename(f.path, f.backup(1)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	return f.open()
+}
+
+func (f *rotatingFile) backup(n int) string {
+	return fmt.Sprintf("%s.%d", f.path, n)
+}
+
+// Close closes the file. Later writes fail with os.ErrClosed.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,97 @@
+package middleware_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/middleware"
+	"github.com/matryer/is"
+)
+
+func TestJSONFileLogging(t *testing.T) {
+	echo := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		return tc, nil
+	})
+
+	readLines := func(is *is.I, path string) []map[string]any {
+		file, err := os.Open(path)
+		is.NoErr(err)
+		defer file.Close()
+
+		var lines []map[string]any
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(nil, 4*1024*1024)
+		for scanner.Scan() {
+			var line map[string]any
+			is.NoErr(json.Unmarshal(scanner.Bytes(), &line)) // every line is JSON
+			lines = append(lines, line)
+		}
+		is.NoErr(scanner.Err())
+		return lines
+	}
+
+	t.Run("writes JSON lines", func(t *testing.T) {
+		is := is.New(t)
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		audit, err := middleware.JSONFileLogging("audit", path, middleware.WithLogMetadata(false))
+		is.NoErr(err)
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(minds.Message{Role: minds.RoleUser, Content: "hello"})
+		_, err = audit.Wrap(echo).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.NoErr(audit.Close())
+
+		lines := readLines(is, path)
+		is.Equal(len(lines), 2)
+		is.Equal(lines[0]["msg"], "entering handler")
+		is.Equal(lines[1]["msg"], "exiting handler")
+
+		thread := lines[1]["thread"].(map[string]any)
+		is.Equal(thread["handler"], "audit")
+		is.Equal(thread["thread_id"], tc.UUID())
+		is.True(thread["messages"] != nil)
+		is.Equal(thread["metadata"], nil) // disabled with WithLogMetadata
+	})
+
+	t.Run("rotates the file", func(t *testing.T) {
+		is := is.New(t)
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		audit, err := middleware.JSONFileLogging("audit", path, middleware.WithRotation(1, 2))
+		is.NoErr(err)
+		defer audit.Close()
+
+		// Each exit entry logs the ~400KB message, so the 1MB limit is
+		// crossed several times.
+		big := strings.Repeat("x", 400*1024)
+		tc := minds.NewThreadContext(context.Background()).WithMessages(minds.Message{Role: minds.RoleUser, Content: big})
+		handler := audit.Wrap(echo)
+		for i := 0; i < 8; i++ {
+			_, err := handler.HandleThread(tc, nil)
+			is.NoErr(err)
+		}
+
+		for _, name := range []string{path, path + ".1", path + ".2"} {
+			info, err := os.Stat(name)
+			is.NoErr(err)
+			is.True(info.Size() <= 1024*1024)
+			readLines(is, name)
+		}
+
+		_, err = os.Stat(path + ".3")
+		is.True(os.IsNotExist(err)) // only maxBackups backups are kept
+	})
+
+	t.Run("reports an unwritable path", func(t *testing.T) {
+		is := is.New(t)
+		_, err := middleware.JSONFileLogging("audit", filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+		is.True(err != nil)
+	})
+}
@@ -14,6 +14,9 @@ type LoggingOptions struct {
 	LogMessages bool
 	LogMetadata bool
 	LogLevels   LogLevels
+
+	// rotation is used only by JSONFileLogging.
+	rotation rotation
 }
 
 // LogLevels specifies log levels for different events.
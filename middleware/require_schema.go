@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// RequireSchema creates a middleware that makes the wrapped provider answer
+// with JSON matching T. It generates a response schema named name from T and
+// sets it under minds.ResponseSchemaKey for the wrapped handler; providers
+// acting as ThreadHandlers request it in place of their own, so the model is
+// constrained to the schema even when the caller didn't configure one. The
+// schema applies only to the wrapped handler and is removed from the thread
+// afterwards.
+//
+// name must be a valid schema name for the provider, such as
+// "weather_report". If a schema can't be generated from T, the middleware
+// returns the error when it runs.
+//
+// Example usage:
+//
+//	llm.Use(middleware.RequireSchema[WeatherReport]("weather_report"))
+func RequireSchema[T any](name string) minds.Middleware {
+	var v T
+	schema, err := minds.NewResponseSchema(name, fmt.Sprintf("A %s response", name), v)
+	return &schemaRequirer{name: name, schema: schema, err: err}
+}
+
+// schemaRequirer sets a response schema for the wrapped handler.
+type schemaRequirer struct {
+	name   string
+	schema *minds.ResponseSchema
+	err    error
+}
+
+// Wrap applies the response schema to a handler.
+func (s *schemaRequirer) Wrap(next minds.ThreadHandler) minds.ThreadHandler {
+	return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		if s.err != nil {
+			return tc, fmt.Errorf("%s: failed to generate schema: %w", s.name, s.err)
+		}
+
+		previous, hadPrevious := tc.Metadata()[minds.ResponseSchemaKey]
+		result, err := next.HandleThread(tc.With(minds.SetKeyValue(minds.ResponseSchemaKey, s.schema)), nil)
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		metadata := result.Metadata()
+		if hadPrevious {
+			metadata[minds.ResponseSchemaKey] = previous
+		} else {
+			delete(metadata, minds.ResponseSchemaKey)
+		}
+
+		return result.WithMetadata(metadata), nil
+	})
+}
+
+// String returns a string representation of the middleware.
+func (s *schemaRequirer) String() string {
+	return fmt.Sprintf("RequireSchema(%s)", s.name)
+}
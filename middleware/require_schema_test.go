@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/middleware"
+	"github.com/matryer/is"
+)
+
+type weatherReport struct {
+	City    string  `json:"city"`
+	Celsius float64 `json:"celsius"`
+}
+
+func TestRequireSchema(t *testing.T) {
+	t.Run("sets the schema for the wrapped handler only", func(t *testing.T) {
+		is := is.New(t)
+
+		var seen *minds.ResponseSchema
+		handler := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			seen = minds.ThreadResponseSchema(tc)
+			return tc, nil
+		})
+
+		tc := minds.NewThreadContext(context.Background())
+		result, err := middleware.RequireSchema[weatherReport]("weather_report").Wrap(handler).HandleThread(tc, nil)
+		is.NoErr(err)
+
+		is.True(seen != nil)
+		is.Equal(seen.Name, "weather_report")
+		is.Equal(len(seen.Definition.Properties), 2)
+		is.True(minds.ThreadResponseSchema(result) == nil)
+	})
+
+	t.Run("restores an outer schema", func(t *testing.T) {
+		is := is.New(t)
+
+		outer, err := minds.NewResponseSchema("outer", "Outer", struct{}{})
+		is.NoErr(err)
+
+		handler := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc, nil
+		})
+
+		tc := minds.NewThreadContext(context.Background()).With(minds.SetKeyValue(minds.ResponseSchemaKey, outer))
+		result, err := middleware.RequireSchema[weatherReport]("weather_report").Wrap(handler).HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(minds.ThreadResponseSchema(result), outer)
+	})
+
+	t.Run("reports schema errors", func(t *testing.T) {
+		is := is.New(t)
+
+		handler := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			t.Fatal("handler should not run")
+			return tc, nil
+		})
+
+		_, err := middleware.RequireSchema[chan int]("bad").Wrap(handler).HandleThread(minds.NewThreadContext(context.Background()), nil)
+		is.True(err != nil)
+	})
+
+	t.Run("returns handler errors", func(t *testing.T) {
+		is := is.New(t)
+		errFailed := errors.New("provider unavailable")
+
+		handler := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return nil, errFailed
+		})
+
+		result, err := middleware.RequireSchema[weatherReport]("weather_report").Wrap(handler).HandleThread(minds.NewThreadContext(context.Background()), nil)
+		is.True(errors.Is(err, errFailed))
+		is.Equal(result, nil)
+	})
+}
@@ -15,12 +15,14 @@ import (
 //   - Flexible backoff strategies
 //   - Configurable retry criteria
 //   - Optional timeout propagation
+//   - Optional rewinding of the thread between attempts
 //
 // Default behavior:
 //   - 3 retry attempts
 //   - No delay between attempts
 //   - Retries on any error
 //   - Timeout propagation enabled
+//   - Attempts share the thread; a failed attempt's changes are visible to the next
 //
 // Example usage:
 //
@@ -36,7 +38,8 @@ import (
 //   - An attempt succeeds
 //   - Maximum attempts are reached
 //   - Retry criteria returns false
-//   - Context is canceled (if timeout propagation is enabled)
+//   - Context is canceled (if timeout propagation is enabled), including
+//     during the backoff between attempts
 func Retry(name string, opts ...retry.Option) minds.Middleware {
 	return minds.MiddlewareFunc(func(next minds.ThreadHandler) minds.ThreadHandler {
 		return &retryMiddleware{
@@ -71,6 +74,12 @@ func (r *retryMiddleware) HandleThread(tc minds.ThreadContext, _ minds.ThreadHan
 
 	var lastErr error
 
+	// Snapshot the thread so each attempt can start from the same state
+	var snapshot minds.ThreadContext
+	if r.config.Rewind {
+		snapshot = tc.Clone()
+	}
+
 	for attempt := 0; attempt < r.config.Attempts; attempt++ {
 		// Handle timeout propagation
 		if r.config.PropagateTimeout {
@@ -82,7 +91,12 @@ func (r *retryMiddleware) HandleThread(tc minds.ThreadContext, _ minds.ThreadHan
 		}
 
 		// Attempt execution
-		result, err := r.next.HandleThread(tc, nil)
+		input := tc
+		if snapshot != nil {
+			input = snapshot.Clone()
+		}
+
+		result, err := r.next.HandleThread(input, nil)
 		if err == nil {
 			return result, nil
 		}
@@ -94,9 +108,21 @@ func (r *retryMiddleware) HandleThread(tc minds.ThreadContext, _ minds.ThreadHan
 
 		lastErr = err
 
+		// Don't wait after the final attempt; its error is returned as is
+		if attempt == r.config.Attempts-1 {
+			break
+		}
+
 		// Apply backoff strategy if defined
-		if r.config.Backoff != nil {
-			time.Sleep(r.config.Backoff(attempt))
+		var delay time.Duration
+		switch {
+		case r.config.ErrorBackoff != nil:
+			delay = r.config.ErrorBackoff(attempt, err)
+		case r.config.Backoff != nil:
+			delay = r.config.Backoff(attempt)
+		}
+		if err := r.wait(tc, delay); err != nil {
+			return tc, fmt.Errorf("%s: context canceled: %w", r.name, err)
 		}
 	}
 
@@ -104,6 +130,30 @@ func (r *retryMiddleware) HandleThread(tc minds.ThreadContext, _ minds.ThreadHan
 	return tc, fmt.Errorf("%s: all %d attempts failed, last error: %w", r.name, r.config.Attempts, lastErr)
 }
 
+// wait sleeps for delay between attempts. With timeout propagation enabled,
+// the wait ends early with the context's error if it is canceled, so a
+// canceled handler does not linger in backoff.
+func (r *retryMiddleware) wait(tc minds.ThreadContext, delay time.Duration) error {
+	if !r.config.PropagateTimeout {
+		time.Sleep(delay)
+		return nil
+	}
+
+	if delay <= 0 {
+		return tc.Context().Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-tc.Context().Done():
+		return tc.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // configureRetry applies provided options to a retry configuration.
 func configureRetry(opts ...retry.Option) *retry.Options {
 	config := retry.NewDefaultOptions()
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"errors"
 	"time"
 
 	"github.com/chriscow/minds"
@@ -12,6 +13,10 @@ type Option func(*Options)
 // BackoffStrategy defines how delay increases between attempts.
 type BackoffStrategy func(attempt int) time.Duration
 
+// ErrorBackoffStrategy defines the delay before the next attempt based on
+// the error of the failed one.
+type ErrorBackoffStrategy func(attempt int, err error) time.Duration
+
 // Criteria defines when a retry should be attempted.
 type Criteria func(tc minds.ThreadContext, attempt int, err error) bool
 
@@ -19,8 +24,10 @@ type Criteria func(tc minds.ThreadContext, attempt int, err error) bool
 type Options struct {
 	Attempts         int
 	Backoff          BackoffStrategy
+	ErrorBackoff     ErrorBackoffStrategy
 	ShouldRetry      Criteria
 	PropagateTimeout bool
+	Rewind           bool
 }
 
 // NewDefaultOptions returns default retry options.
@@ -40,6 +47,24 @@ func DefaultBackoff(delay time.Duration) BackoffStrategy {
 	}
 }
 
+// ExponentialBackoff doubles the delay after every attempt, starting at base
+// and never exceeding max. A max of zero or less means no limit.
+func ExponentialBackoff(base, max time.Duration) BackoffStrategy {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 0; i < attempt; i++ {
+			delay *= 2
+			if max > 0 && delay >= max {
+				return max
+			}
+		}
+		if max > 0 && delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
 // DefaultCriteria retries on all errors.
 func DefaultCriteria(tc minds.ThreadContext, attempt int, err error) bool {
 	return err != nil
@@ -59,6 +84,36 @@ func WithBackoff(strategy BackoffStrategy) Option {
 	}
 }
 
+// RespectRetryAfter waits for the delay the server suggested when the error
+// carries one, and falls back to the given strategy otherwise. An error
+// carries a delay if it, or an error it wraps, has a RetryAfter method
+// returning a positive duration, as openai.APIError does for 429 responses
+// with a Retry-After header. It takes precedence over WithBackoff.
+//
+// Example:
+//
+//	middleware.Retry("api-retry",
+//	    retry.WithAttempts(5),
+//	    retry.RespectRetryAfter(retry.ExponentialBackoff(time.Second, 30*time.Second)),
+//	)
+func RespectRetryAfter(fallback BackoffStrategy) Option {
+	return func(config *Options) {
+		config.ErrorBackoff = func(attempt int, err error) time.Duration {
+			var ra interface{ RetryAfter() time.Duration }
+			if errors.As(err, &ra) {
+				if delay := ra.RetryAfter(); delay > 0 {
+					return delay
+				}
+			}
+
+			if fallback == nil {
+				return 0
+			}
+			return fallback(attempt)
+		}
+	}
+}
+
 // WithRetryCriteria sets a custom retry criteria.
 func WithRetryCriteria(criteria Criteria) Option {
 	return func(config *Options) {
@@ -72,3 +127,12 @@ func WithoutTimeoutPropagation() Option {
 		config.PropagateTimeout = false
 	}
 }
+
+// WithRewind makes every attempt start from the thread as it was before the
+// first attempt, discarding any messages or metadata a failed attempt added.
+// Each attempt runs on a fresh clone of that snapshot.
+func WithRewind(enabled bool) Option {
+	return func(config *Options) {
+		config.Rewind = enabled
+	}
+}
@@ -3,6 +3,7 @@ package middleware_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -147,8 +148,10 @@ func TestRetryOptions(t *testing.T) {
 			return tc, errors.New("always fails")
 		})
 
+		var backoffAttempts []int
 		backoff := func(attempt int) time.Duration {
-			return time.Duration(attempt) * 10 * time.Millisecond
+			backoffAttempts = append(backoffAttempts, attempt)
+			return time.Duration(attempt+1) * 10 * time.Millisecond
 		}
 
 		retry := middleware.Retry("retry_backoff", retry.WithAttempts(3), retry.WithBackoff(backoff))
@@ -159,6 +162,7 @@ func TestRetryOptions(t *testing.T) {
 
 		is.True(err != nil)                                   // Expect an error
 		is.Equal(callCount, 3)                                // Should retry exactly 3 times
+		is.Equal(backoffAttempts, []int{0, 1})                // No wait after the final attempt
 		is.True(time.Since(startTime) >= 30*time.Millisecond) // Total backoff duration
 	})
 
@@ -363,3 +367,141 @@ func TestRetryMiddleware_StopOnCertainErrors(t *testing.T) {
 	is.True(err != nil)    // Expect an error
 	is.Equal(callCount, 2) // Should stop retrying after "critical error"
 }
+
+func TestRetryMiddleware_Rewind(t *testing.T) {
+	// appendThenFail appends a partial answer and fails until the third
+	// attempt, recording how many messages each attempt started with.
+	appendThenFail := func(seen *[]int) minds.ThreadHandler {
+		return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			*seen = append(*seen, len(tc.Messages()))
+			tc.AppendMessages(minds.Message{Role: minds.RoleAssistant, Content: "partial"})
+			tc.SetKeyValue("attempt", len(*seen))
+			if len(*seen) < 3 {
+				return tc, errors.New("validation failed")
+			}
+			return tc, nil
+		})
+	}
+
+	newThread := func() minds.ThreadContext {
+		return minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Hi"})
+	}
+
+	t.Run("resets the thread between attempts", func(t *testing.T) {
+		is := is.New(t)
+		var seen []int
+
+		handler := middleware.Retry("retry_test", retry.WithAttempts(3), retry.WithRewind(true)).Wrap(appendThenFail(&seen))
+
+		tc := newThread()
+		result, err := handler.HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(seen, []int{1, 1, 1})            // every attempt starts clean
+		is.Equal(len(result.Messages()), 2)       // only the successful attempt's message
+		is.Equal(result.Metadata()["attempt"], 3) // and its metadata
+		is.Equal(len(tc.Messages()), 1)           // the caller's thread is untouched
+	})
+
+	t.Run("attempts share the thread without rewind", func(t *testing.T) {
+		is := is.New(t)
+		var seen []int
+
+		handler := middleware.Retry("retry_test", retry.WithAttempts(3)).Wrap(appendThenFail(&seen))
+
+		_, err := handler.HandleThread(newThread(), nil)
+		is.NoErr(err)
+		is.Equal(seen, []int{1, 2, 3})
+	})
+}
+
+// rateLimitError is an error that suggests a delay before retrying.
+type rateLimitError struct {
+	delay time.Duration
+}
+
+func (e rateLimitError) Error() string             { return "rate limited" }
+func (e rateLimitError) RetryAfter() time.Duration { return e.delay }
+
+func TestRetryMiddleware_RespectRetryAfter(t *testing.T) {
+	t.Run("waits for the suggested delay", func(t *testing.T) {
+		is := is.New(t)
+		errs := []error{
+			fmt.Errorf("generate: %w", rateLimitError{delay: 30 * time.Millisecond}),
+			errors.New("plain failure"),
+		}
+
+		callCount := 0
+		mockHandler := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			callCount++
+			if callCount <= len(errs) {
+				return tc, errs[callCount-1]
+			}
+			return tc, nil
+		})
+
+		var fallbackAttempts []int
+		fallback := func(attempt int) time.Duration {
+			fallbackAttempts = append(fallbackAttempts, attempt)
+			return time.Millisecond
+		}
+
+		handler := middleware.Retry("retry_after", retry.WithAttempts(3), retry.RespectRetryAfter(fallback)).Wrap(mockHandler)
+
+		start := time.Now()
+		_, err := handler.HandleThread(minds.NewThreadContext(context.Background()), nil)
+		is.NoErr(err)
+		is.Equal(callCount, 3)
+		is.True(time.Since(start) >= 30*time.Millisecond) // waited for the server's delay
+		is.Equal(fallbackAttempts, []int{1})              // fallback only for the plain error
+	})
+
+	t.Run("returns the final error without waiting", func(t *testing.T) {
+		is := is.New(t)
+		failing := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return tc, rateLimitError{delay: time.Hour}
+		})
+
+		handler := middleware.Retry("retry_after", retry.WithAttempts(1), retry.RespectRetryAfter(nil)).Wrap(failing)
+
+		start := time.Now()
+		_, err := handler.HandleThread(minds.NewThreadContext(context.Background()), nil)
+		is.True(err != nil)
+		is.True(time.Since(start) < time.Second)
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		is := is.New(t)
+		backoff := retry.ExponentialBackoff(100*time.Millisecond, time.Second)
+
+		is.Equal(backoff(0), 100*time.Millisecond)
+		is.Equal(backoff(1), 200*time.Millisecond)
+		is.Equal(backoff(3), 800*time.Millisecond)
+		is.Equal(backoff(4), time.Second)
+		is.Equal(backoff(100), time.Second)
+	})
+}
+
+func TestRetryMiddleware_CancelDuringBackoff(t *testing.T) {
+	is := is.New(t)
+	calls := 0
+
+	failing := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		calls++
+		return tc, errors.New("temporary failure")
+	})
+
+	handler := middleware.Retry("retry_test",
+		retry.WithAttempts(3),
+		retry.WithBackoff(retry.DefaultBackoff(10*time.Second)),
+	).Wrap(failing)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := handler.HandleThread(minds.NewThreadContext(ctx), nil)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.Equal(calls, 1)                       // no attempt after cancellation
+	is.True(time.Since(start) < time.Second) // backoff ended early
+}
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/chriscow/minds"
+)
+
+// ToolSelector creates a middleware that narrows the tools sent to the model
+// to the topK most relevant to the conversation. It embeds the last user
+// message and each tool's name and description, ranks the tools in registry
+// by cosine similarity to the message and sets a view of registry exposing
+// only the best matches under minds.ToolRegistryKey for the wrapped handler.
+// Providers acting as ThreadHandlers use that registry in place of their own
+// for the request, so a large tool set costs only the tokens of the tools
+// that are likely to be called.
+//
+// Tool embeddings are computed once and cached, so each request embeds only
+// the user message and any tools added since. Embeddings are requested with
+// an empty model name, so the embedder's default model is used. If the thread
+// has no user message, or registry holds no more than topK tools, the thread
+// is passed on unchanged. A topK below 1 is treated as 1.
+//
+// The selected registry is a view of registry made with
+// minds.FilteredRegistry, so its limits, budget, OnInvoke callbacks, tool
+// history and idempotent results still apply to the calls made through it.
+//
+// Example usage:
+//
+//	llm.Use(middleware.ToolSelector("tool_rag", embedder, registry, 5))
+func ToolSelector(name string, embedder minds.Embedder, registry minds.ToolRegistry, topK int) minds.Middleware {
+	if topK < 1 {
+		topK = 1
+	}
+
+	return &toolSelector{
+		name:       name,
+		embedder:   embedder,
+		registry:   registry,
+		topK:       topK,
+		embeddings: make(map[string][]float32),
+	}
+}
+
+// toolSelector scopes the tool registry to the tools most relevant to the
+// last user message.
+type toolSelector struct {
+	name     string
+	embedder minds.Embedder
+	registry minds.ToolRegistry
+	topK     int
+
+	mu         sync.Mutex
+	embeddings map[string][]float32 // keyed by tool text
+}
+
+// Wrap applies tool selection to a handler.
+func (s *toolSelector) Wrap(next minds.ThreadHandler) minds.ThreadHandler {
+	return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+		query, ok := lastUserMessage(tc.Messages())
+		tools := s.registry.List()
+		if !ok || len(tools) <= s.topK {
+			return next.HandleThread(tc, nil)
+		}
+
+		selected, err := s.selectTools(query, tools)
+		if err != nil {
+			return tc, fmt.Errorf("%s: %w", s.name, err)
+		}
+
+		names := make([]string, len(selected))
+		for i, tool := range selected {
+			names[i] = tool.Name()
+		}
+		scoped := minds.FilteredRegistry(s.registry, names...)
+
+		previous, hadPrevious := tc.Metadata()[minds.ToolRegistryKey]
+		result, err := next.HandleThread(tc.With(minds.SetKeyValue(minds.ToolRegistryKey, scoped)), nil)
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		// The scoped registry applies only to the wrapped handler, so restore
+		// whatever was set before.
+		metadata := result.Metadata()
+		if hadPrevious {
+			metadata[minds.ToolRegistryKey] = previous
+		} else {
+			delete(metadata, minds.ToolRegistryKey)
+		}
+
+		return result.WithMetadata(metadata), nil
+	})
+}
+
+// String returns a string representation of the middleware.
+func (s *toolSelector) String() string {
+	return fmt.Sprintf("ToolSelector(%s)", s.name)
+}
+
+// selectTools returns the topK tools most similar to query, best first.
+func (s *toolSelector) selectTools(query string, tools []minds.Tool) ([]minds.Tool, error) {
+	// List has no defined order; sort by name so ties rank consistently.
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name() < tools[j].Name() })
+
+	texts := make([]string, len(tools))
+	for i, tool := range tools {
+		texts[i] = toolText(tool)
+	}
+
+	vectors, err := s.embed(query, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	queryVector := vectors[query]
+	scores := make(map[string]float64, len(tools))
+	for i, tool := range tools {
+		scores[tool.Name()] = cosineSimilarity(queryVector, vectors[texts[i]])
+	}
+
+	sort.SliceStable(tools, func(i, j int) bool {
+		return scores[tools[i].Name()] > scores[tools[j].Name()]
+	})
+
+	return tools[:s.topK], nil
+}
+
+// embed returns embeddings for query and texts, keyed by input. Tool
+// embeddings are cached; the query is always embedded.
+func (s *toolSelector) embed(query string, texts []string) (map[string][]float32, error) {
+	vectors := make(map[string][]float32, len(texts)+1)
+	input := []string{query}
+
+	s.mu.Lock()
+	for _, text := range texts {
+		if v, ok := s.embeddings[text]; ok {
+			vectors[text] = v
+		} else if _, queued := vectors[text]; !queued {
+			vectors[text] = nil
+			input = append(input, text)
+		}
+	}
+	s.mu.Unlock()
+
+	embeddings, err := s.embedder.CreateEmbeddings("", input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings: %w", err)
+	}
+	if len(embeddings) != len(input) {
+		return nil, fmt.Errorf("failed to create embeddings: got %d embeddings for %d inputs", len(embeddings), len(input))
+	}
+
+	s.mu.Lock()
+	for i, text := range input[1:] {
+		s.embeddings[text] = embeddings[i+1]
+		vectors[text] = embeddings[i+1]
+	}
+	s.mu.Unlock()
+
+	// Set the query last in case it matches a tool's text exactly.
+	vectors[query] = embeddings[0]
+	return vectors, nil
+}
+
+// toolText is the text embedded for a tool.
+func toolText(tool minds.Tool) string {
+	return tool.Name() + ": " + tool.Description()
+}
+
+// lastUserMessage returns the content of the most recent user message.
+func lastUserMessage(messages minds.Messages) (string, bool) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == minds.RoleUser {
+			return messages[i].Content, true
+		}
+	}
+	return "", false
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if either
+// is a zero vector or their lengths differ.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
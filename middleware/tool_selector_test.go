@@ -0,0 +1,165 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/middleware"
+	"github.com/matryer/is"
+)
+
+// keywordEmbedder embeds text as a one-hot vector of the first keyword it
+// contains.
+type keywordEmbedder struct {
+	keywords []string
+	inputs   int
+}
+
+func (e *keywordEmbedder) CreateEmbeddings(model string, input []string) ([][]float32, error) {
+	e.inputs += len(input)
+	embeddings := make([][]float32, len(input))
+	for i, text := range input {
+		vector := make([]float32, len(e.keywords))
+		for k, keyword := range e.keywords {
+			if strings.Contains(strings.ToLower(text), keyword) {
+				vector[k] = 1
+				break
+			}
+		}
+		embeddings[i] = vector
+	}
+	return embeddings, nil
+}
+
+func TestToolSelector(t *testing.T) {
+	newRegistry := func(t *testing.T) minds.ToolRegistry {
+		registry := minds.NewToolRegistry()
+		for _, tool := range []struct{ name, desc string }{
+			{"get_weather", "Get the weather forecast for a city"},
+			{"get_stock_price", "Get the latest stock price for a ticker"},
+			{"create_event", "Create a calendar event"},
+		} {
+			fn, err := minds.WrapFunction(tool.name, tool.desc, struct{}{}, func(context.Context, []byte) ([]byte, error) {
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := registry.Register(fn); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return registry
+	}
+
+	capture := func(scoped *minds.ToolRegistry) minds.ThreadHandler {
+		return minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			*scoped = minds.ThreadToolRegistry(tc)
+			return tc, nil
+		})
+	}
+
+	t.Run("selects the most relevant tools", func(t *testing.T) {
+		is := is.New(t)
+		embedder := &keywordEmbedder{keywords: []string{"weather", "stock", "calendar"}}
+
+		var scoped minds.ToolRegistry
+		handler := middleware.ToolSelector("tool_rag", embedder, newRegistry(t), 1).Wrap(capture(&scoped))
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "What's the weather like in Paris?"},
+		)
+		result, err := handler.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		is.True(scoped != nil)
+		is.Equal(len(scoped.List()), 1)
+		is.True(scoped.Has("get_weather"))
+		is.Equal(minds.ThreadToolRegistry(result), nil) // scoped to the wrapped handler
+	})
+
+	t.Run("keeps the limits and hooks of the registry", func(t *testing.T) {
+		is := is.New(t)
+		embedder := &keywordEmbedder{keywords: []string{"weather", "stock", "calendar"}}
+
+		registry := newRegistry(t)
+		var invoked []string
+		registry.OnInvoke(func(inv minds.ToolInvocation) { invoked = append(invoked, inv.Name) })
+		weather, _ := registry.Lookup("get_weather")
+		is.NoErr(registry.RegisterOrReplace(weather, minds.WithToolLimit(1)))
+
+		var scoped minds.ToolRegistry
+		handler := middleware.ToolSelector("tool_rag", embedder, registry, 1).Wrap(capture(&scoped))
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "What's the weather like in Paris?"},
+		)
+		_, err := handler.HandleThread(tc, nil)
+		is.NoErr(err)
+
+		call := []minds.ToolCall{{Function: minds.FunctionCall{Name: "get_weather", Parameters: []byte(`{}`)}}}
+		_, err = minds.HandleFunctionCalls(context.Background(), call, scoped)
+		is.NoErr(err)
+		is.Equal(invoked, []string{"get_weather"})
+
+		_, err = minds.HandleFunctionCalls(context.Background(), call, scoped)
+		is.True(errors.Is(err, minds.ErrToolBudgetExceeded))
+	})
+
+	t.Run("tool embeddings are cached", func(t *testing.T) {
+		is := is.New(t)
+		embedder := &keywordEmbedder{keywords: []string{"weather", "stock", "calendar"}}
+
+		var scoped minds.ToolRegistry
+		handler := middleware.ToolSelector("tool_rag", embedder, newRegistry(t), 2).Wrap(capture(&scoped))
+
+		for _, question := range []string{"How is AAPL stock doing?", "Add a calendar entry"} {
+			tc := minds.NewThreadContext(context.Background()).WithMessages(
+				minds.Message{Role: minds.RoleUser, Content: question},
+			)
+			_, err := handler.HandleThread(tc, nil)
+			is.NoErr(err)
+		}
+
+		is.Equal(embedder.inputs, 5) // three tools once, plus each question
+		is.Equal(len(scoped.List()), 2)
+		is.True(scoped.Has("create_event"))
+	})
+
+	t.Run("small registries are passed through", func(t *testing.T) {
+		is := is.New(t)
+		embedder := &keywordEmbedder{}
+
+		var scoped minds.ToolRegistry
+		handler := middleware.ToolSelector("tool_rag", embedder, newRegistry(t), 3).Wrap(capture(&scoped))
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "Hello"},
+		)
+		_, err := handler.HandleThread(tc, nil)
+		is.NoErr(err)
+		is.Equal(scoped, nil)
+		is.Equal(embedder.inputs, 0)
+	})
+
+	t.Run("returns handler errors", func(t *testing.T) {
+		is := is.New(t)
+		embedder := &keywordEmbedder{keywords: []string{"weather", "stock", "calendar"}}
+		errFailed := errors.New("provider unavailable")
+
+		failing := minds.ThreadHandlerFunc(func(tc minds.ThreadContext, _ minds.ThreadHandler) (minds.ThreadContext, error) {
+			return nil, errFailed
+		})
+		handler := middleware.ToolSelector("tool_rag", embedder, newRegistry(t), 1).Wrap(failing)
+
+		tc := minds.NewThreadContext(context.Background()).WithMessages(
+			minds.Message{Role: minds.RoleUser, Content: "What's the weather like in Paris?"},
+		)
+		result, err := handler.HandleThread(tc, nil)
+		is.True(errors.Is(err, errFailed))
+		is.Equal(result, nil)
+	})
+}
@@ -0,0 +1,85 @@
+package minds
+
+import (
+	"fmt"
+	"strings"
+)
+
+const prefillInstruction = `Begin your response with exactly the following text and continue from it:
+%s`
+
+// WithPrefill treats the request's trailing assistant message as the start
+// of the response for the model to continue, rather than a finished turn.
+// Prefilling constrains the format of the answer, for example forcing JSON
+// by prefilling "{". The response text includes the prefill, so "{" and the
+// model's continuation read as one JSON object.
+//
+// Providers that support prefilling, such as openrouter, send the message as
+// is. Those that do not, such as openai and gemini, replace it with an
+// instruction to begin the response with its text and add the text to the
+// start of the response if the model left it out; see EmulatePrefill. The
+// option has no effect on requests that do not end with an assistant message
+// without tool calls.
+//
+// Example:
+//
+//	messages := minds.Messages{
+//	    {Role: minds.RoleUser, Content: "List three colors as JSON."},
+//	    {Role: minds.RoleAssistant, Content: `{"colors": [`},
+//	}
+//	resp, err := llm.GenerateContent(ctx, minds.NewRequest(messages, minds.WithPrefill()))
+func WithPrefill() RequestOption {
+	return func(o *RequestOptions) {
+		o.Prefill = true
+	}
+}
+
+// SplitPrefill returns the messages of req before its prefill and the text of
+// the prefill. The prefill is empty if req does not use WithPrefill or does
+// not end with an assistant message without tool calls.
+func SplitPrefill(req Request) (Messages, string) {
+	messages := req.Messages
+	if !req.Options.Prefill || len(messages) == 0 {
+		return messages, ""
+	}
+
+	last := messages[len(messages)-1]
+	switch last.Role {
+	case RoleAssistant, RoleModel, RoleAI:
+	default:
+		return messages, ""
+	}
+	if last.Content == "" || len(last.ToolCalls) > 0 {
+		return messages, ""
+	}
+
+	return messages[:len(messages)-1], last.Content
+}
+
+// EmulatePrefill returns the messages to send in place of a prefill for
+// providers that cannot continue an assistant message: messages, the
+// conversation before the prefill, followed by a system message telling the
+// model to begin its response with prefill. Wrap the response with
+// PrefillResponse so it starts with the prefill even if the model does not
+// comply.
+func EmulatePrefill(messages Messages, prefill string) Messages {
+	instruction := Message{Role: RoleSystem, Content: fmt.Sprintf(prefillInstruction, prefill)}
+	return append(append(Messages{}, messages...), instruction)
+}
+
+// PrefillResponse returns resp with prefill added to the start of its text
+// unless the text already starts with it, as it does when the model repeats
+// the prefill. Responses with tool calls and no text are returned as is, as
+// is resp if prefill is empty.
+func PrefillResponse(resp Response, prefill string) Response {
+	if prefill == "" {
+		return resp
+	}
+
+	return MapResponse(resp, func(text string) string {
+		if strings.HasPrefix(text, prefill) || (text == "" && len(resp.ToolCalls()) > 0) {
+			return text
+		}
+		return prefill + text
+	})
+}
@@ -0,0 +1,50 @@
+package minds
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSplitPrefill(t *testing.T) {
+	is := is.New(t)
+
+	messages := Messages{
+		{Role: RoleUser, Content: "List three colors as JSON."},
+		{Role: RoleAssistant, Content: "{"},
+	}
+
+	before, prefill := SplitPrefill(NewRequest(messages, WithPrefill()))
+	is.Equal(prefill, "{")
+	is.Equal(before, messages[:1])
+
+	_, prefill = SplitPrefill(NewRequest(messages))
+	is.Equal(prefill, "") // only with WithPrefill
+
+	_, prefill = SplitPrefill(NewRequest(messages[:1], WithPrefill()))
+	is.Equal(prefill, "") // not an assistant message
+
+	withCall := append(messages[:1:1], Message{Role: RoleAssistant, Content: "{", ToolCalls: []ToolCall{{ID: "1"}}})
+	_, prefill = SplitPrefill(NewRequest(withCall, WithPrefill()))
+	is.Equal(prefill, "")
+
+	emulated := EmulatePrefill(before, "{")
+	is.Equal(len(emulated), 2)
+	is.Equal(emulated[1].Role, RoleSystem)
+	is.True(strings.HasSuffix(emulated[1].Content, "{"))
+	is.Equal(len(before), 1) // not modified
+}
+
+func TestPrefillResponse(t *testing.T) {
+	is := is.New(t)
+
+	resp := PrefillResponse(stubResponse{content: `"a": 1}`}, "{")
+	is.Equal(resp.String(), `{"a": 1}`)
+
+	resp = PrefillResponse(stubResponse{content: `{"a": 1}`}, "{")
+	is.Equal(resp.String(), `{"a": 1}`) // the model repeated the prefill
+
+	resp = PrefillResponse(stubResponse{content: "text"}, "")
+	is.Equal(resp.String(), "text")
+}
@@ -8,6 +8,36 @@ type ContentGenerator interface {
 	Close()
 }
 
+// ModelInfo describes a model offered by a provider. Fields the provider does
+// not report are left empty.
+type ModelInfo struct {
+	// ID is the model name to pass to the provider, such as "gpt-4o-mini".
+	ID string
+
+	// Name is a human-readable name for the model.
+	Name string
+
+	// Description is a short description of the model.
+	Description string
+
+	// ContextWindow is the maximum number of input tokens, or 0 if unknown.
+	ContextWindow int
+
+	// MaxOutputTokens is the maximum number of output tokens, or 0 if unknown.
+	MaxOutputTokens int
+
+	// Capabilities lists the features the model supports, using the
+	// provider's own names, such as Gemini's "generateContent" or
+	// OpenRouter's "tools".
+	Capabilities []string
+}
+
+// ModelLister is implemented by providers that can list their available
+// models.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
 type Embedder interface {
 	CreateEmbeddings(model string, input []string) ([][]float32, error)
 }
@@ -0,0 +1,130 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// BlockedError is returned by GenerateContent when Gemini withholds a
+// response for safety or recitation reasons, either because the prompt was
+// blocked or because the generated candidate was. Use errors.As to tell a
+// blocked response apart from network or API errors:
+//
+//	var blocked *gemini.BlockedError
+//	if errors.As(err, &blocked) {
+//	    fmt.Println("Sorry, that request was blocked.")
+//	}
+type BlockedError struct {
+	// PromptBlocked is true when the prompt was blocked and no candidates were
+	// generated, and false when the generated candidate was blocked.
+	PromptBlocked bool
+
+	// Reason is the prompt's block reason or the candidate's finish reason,
+	// such as "BlockReasonSafety" or "FinishReasonRecitation".
+	Reason string
+
+	// Categories are the harm categories whose safety rating caused the
+	// block. It may be empty, for example when the reason is recitation.
+	Categories []genai.HarmCategory
+
+	// SafetyRatings are all the safety ratings Gemini reported.
+	SafetyRatings []*genai.SafetyRating
+
+	err error
+}
+
+func (e *BlockedError) Error() string {
+	target := "response"
+	if e.PromptBlocked {
+		target = "prompt"
+	}
+
+	msg := fmt.Sprintf("gemini blocked the %s: %s", target, e.Reason)
+	if len(e.Categories) > 0 {
+		names := make([]string, len(e.Categories))
+		for i, c := range e.Categories {
+			names[i] = c.String()
+		}
+		msg += " (" + strings.Join(names, ", ") + ")"
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying genai error, if any.
+func (e *BlockedError) Unwrap() error {
+	return e.err
+}
+
+// blockedError converts a genai.BlockedError in err's chain to a
+// BlockedError. It reports false if err was not caused by a block.
+func blockedError(err error) (*BlockedError, bool) {
+	var blocked *genai.BlockedError
+	if !errors.As(err, &blocked) {
+		return nil, false
+	}
+
+	var result *BlockedError
+	switch {
+	case blocked.PromptFeedback != nil:
+		result = newPromptBlockedError(blocked.PromptFeedback)
+	case blocked.Candidate != nil:
+		result = &BlockedError{
+			Reason:        blocked.Candidate.FinishReason.String(),
+			Categories:    blockedCategories(blocked.Candidate.SafetyRatings),
+			SafetyRatings: blocked.Candidate.SafetyRatings,
+		}
+	default:
+		result = &BlockedError{Reason: genai.BlockReasonOther.String()}
+	}
+
+	result.err = err
+	return result, true
+}
+
+func newPromptBlockedError(feedback *genai.PromptFeedback) *BlockedError {
+	return &BlockedError{
+		PromptBlocked: true,
+		Reason:        feedback.BlockReason.String(),
+		Categories:    blockedCategories(feedback.SafetyRatings),
+		SafetyRatings: feedback.SafetyRatings,
+	}
+}
+
+// checkCandidates returns an error if raw has no usable candidate. A response
+// without candidates but with prompt feedback was blocked.
+func checkCandidates(raw *genai.GenerateContentResponse) error {
+	if len(raw.Candidates) == 0 {
+		if raw.PromptFeedback != nil {
+			return newPromptBlockedError(raw.PromptFeedback)
+		}
+		return fmt.Errorf("no candidates in Gemini response")
+	}
+
+	candidate := raw.Candidates[0]
+	if candidate.Content == nil {
+		if categories := blockedCategories(candidate.SafetyRatings); len(categories) > 0 {
+			return &BlockedError{
+				Reason:        candidate.FinishReason.String(),
+				Categories:    categories,
+				SafetyRatings: candidate.SafetyRatings,
+			}
+		}
+		return fmt.Errorf("candidate content is nil (finish reason: %s)", candidate.FinishReason)
+	}
+
+	return nil
+}
+
+func blockedCategories(ratings []*genai.SafetyRating) []genai.HarmCategory {
+	var categories []genai.HarmCategory
+	for _, rating := range ratings {
+		if rating != nil && rating.Blocked {
+			categories = append(categories, rating.Category)
+		}
+	}
+	return categories
+}
@@ -0,0 +1,95 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/matryer/is"
+)
+
+func TestBlockedError(t *testing.T) {
+	harassment := &genai.SafetyRating{Category: genai.HarmCategoryHarassment, Probability: genai.HarmProbabilityHigh, Blocked: true}
+	violence := &genai.SafetyRating{Category: genai.HarmCategoryDangerousContent, Probability: genai.HarmProbabilityLow}
+
+	t.Run("blocked prompt", func(t *testing.T) {
+		is := is.New(t)
+		genaiErr := &genai.BlockedError{PromptFeedback: &genai.PromptFeedback{
+			BlockReason:   genai.BlockReasonSafety,
+			SafetyRatings: []*genai.SafetyRating{harassment, violence},
+		}}
+
+		blocked, ok := blockedError(fmt.Errorf("send message: %w", genaiErr))
+		is.True(ok)
+		is.True(blocked.PromptBlocked)
+		is.Equal(blocked.Reason, "BlockReasonSafety")
+		is.Equal(blocked.Categories, []genai.HarmCategory{genai.HarmCategoryHarassment})
+		is.Equal(len(blocked.SafetyRatings), 2)
+		is.Equal(blocked.Error(), "gemini blocked the prompt: BlockReasonSafety (HarmCategoryHarassment)")
+		is.True(errors.Is(blocked, genaiErr))
+	})
+
+	t.Run("blocked candidate", func(t *testing.T) {
+		is := is.New(t)
+		genaiErr := &genai.BlockedError{Candidate: &genai.Candidate{
+			FinishReason:  genai.FinishReasonRecitation,
+			SafetyRatings: []*genai.SafetyRating{violence},
+		}}
+
+		blocked, ok := blockedError(genaiErr)
+		is.True(ok)
+		is.True(!blocked.PromptBlocked)
+		is.Equal(blocked.Reason, "FinishReasonRecitation")
+		is.Equal(len(blocked.Categories), 0)
+		is.Equal(blocked.Error(), "gemini blocked the response: FinishReasonRecitation")
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		is := is.New(t)
+		_, ok := blockedError(errors.New("connection reset"))
+		is.True(!ok)
+	})
+}
+
+func TestCheckCandidates(t *testing.T) {
+	t.Run("no candidates with prompt feedback", func(t *testing.T) {
+		is := is.New(t)
+		err := checkCandidates(&genai.GenerateContentResponse{
+			PromptFeedback: &genai.PromptFeedback{BlockReason: genai.BlockReasonOther},
+		})
+
+		var blocked *BlockedError
+		is.True(errors.As(err, &blocked))
+		is.True(blocked.PromptBlocked)
+		is.Equal(blocked.Reason, "BlockReasonOther")
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		is := is.New(t)
+		err := checkCandidates(&genai.GenerateContentResponse{})
+
+		var blocked *BlockedError
+		is.True(err != nil)
+		is.True(!errors.As(err, &blocked))
+	})
+
+	t.Run("candidate without content", func(t *testing.T) {
+		is := is.New(t)
+		err := checkCandidates(&genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+			FinishReason:  genai.FinishReasonSafety,
+			SafetyRatings: []*genai.SafetyRating{{Category: genai.HarmCategorySexuallyExplicit, Blocked: true}},
+		}}})
+
+		var blocked *BlockedError
+		is.True(errors.As(err, &blocked))
+		is.Equal(blocked.Categories, []genai.HarmCategory{genai.HarmCategorySexuallyExplicit})
+	})
+
+	t.Run("usable candidate", func(t *testing.T) {
+		is := is.New(t)
+		is.NoErr(checkCandidates(&genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+			Content: &genai.Content{Role: "model", Parts: []genai.Part{genai.Text("hi")}},
+		}}}))
+	})
+}
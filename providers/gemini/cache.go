@@ -0,0 +1,115 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chriscow/minds"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// CacheHandle refers to content cached on the Gemini API with
+// Provider.CreateCache. Pass it to WithCachedContent so requests reuse the
+// cached content instead of sending it again.
+type CacheHandle struct {
+	// Name is the resource name of the cached content, e.g. "cachedContents/abc123".
+	Name string
+
+	// Model is the model the content was cached for. Providers using the
+	// cache must be configured with the same model.
+	Model string
+
+	// ExpireTime is when the cached content is deleted by the API.
+	ExpireTime time.Time
+
+	client *genai.Client
+}
+
+// CreateCache uploads content to Gemini's context cache for ttl. System
+// messages become the cached system instruction and the provider's registered
+// tools are cached along with the content, since requests that use cached
+// content cannot set either.
+//
+// Gemini requires a minimum amount of content to be cached, and only specific
+// model versions (e.g. "gemini-1.5-flash-001") support caching.
+//
+// Example:
+//
+//	cache, err := provider.CreateCache(ctx, minds.Messages{
+//	    {Role: minds.RoleSystem, Content: "Answer questions about the attached report."},
+//	    {Role: minds.RoleUser, Content: report},
+//	}, time.Hour)
+//	if err != nil {
+//	    return err
+//	}
+//	defer cache.Delete(ctx)
+//
+//	qa, err := gemini.NewProvider(ctx, gemini.WithModel(cache.Model), gemini.WithCachedContent(cache))
+func (p *Provider) CreateCache(ctx context.Context, content minds.Messages, ttl time.Duration) (*CacheHandle, error) {
+	sysPrompt, contents, err := convertMessages(content)
+	if err != nil {
+		return nil, err
+	}
+
+	cc := &genai.CachedContent{
+		Model:             p.options.modelName,
+		SystemInstruction: sysPrompt,
+		Contents:          contents,
+		Expiration:        genai.ExpireTimeOrTTL{TTL: ttl},
+	}
+
+	if cc.SystemInstruction == nil && p.options.systemPrompt != nil {
+		cc.SystemInstruction = &genai.Content{Parts: []genai.Part{genai.Text(*p.options.systemPrompt)}, Role: "system"}
+	}
+
+	tools, err := p.functionDeclarations(p.options.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tools) > 0 {
+		cc.Tools = []*genai.Tool{{
+			FunctionDeclarations: tools,
+		}}
+	}
+
+	created, err := p.client.CreateCachedContent(p.withHeaders(ctx), cc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cached content: %w", err)
+	}
+
+	return newCacheHandle(p.client, created), nil
+}
+
+// Refresh extends the lifetime of the cached content to ttl from now.
+func (h *CacheHandle) Refresh(ctx context.Context, ttl time.Duration) error {
+	updated, err := h.client.UpdateCachedContent(ctx, &genai.CachedContent{Name: h.Name}, &genai.CachedContentToUpdate{
+		Expiration: &genai.ExpireTimeOrTTL{TTL: ttl},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh cached content %s: %w", h.Name, err)
+	}
+
+	h.ExpireTime = updated.Expiration.ExpireTime
+	return nil
+}
+
+// Delete removes the cached content. Providers still configured with the
+// handle will fail until they are recreated without it.
+func (h *CacheHandle) Delete(ctx context.Context) error {
+	if err := h.client.DeleteCachedContent(ctx, h.Name); err != nil {
+		return fmt.Errorf("failed to delete cached content %s: %w", h.Name, err)
+	}
+	return nil
+}
+
+func newCacheHandle(client *genai.Client, cc *genai.CachedContent) *CacheHandle {
+	return &CacheHandle{
+		Name:       cc.Name,
+		Model:      cc.Model,
+		ExpireTime: cc.Expiration.ExpireTime,
+		client:     client,
+	}
+}
@@ -0,0 +1,152 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/chriscow/minds"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// FilesKey is the message metadata key under which AttachFiles stores the
+// []FileHandle attached to a message.
+const FilesKey = "gemini_files"
+
+// FileHandle refers to a file uploaded to the Gemini File API with
+// Provider.UploadFile. Attach it to a user message with AttachFiles to ask
+// about its content.
+type FileHandle struct {
+	// Name is the resource name of the file, e.g. "files/abc123".
+	Name string
+
+	// URI is the URI the model uses to read the file.
+	URI string
+
+	// MIMEType is the type of the file, e.g. "application/pdf".
+	MIMEType string
+
+	// ExpireTime is when the file is deleted by the API. Uploaded files are
+	// kept for 48 hours.
+	ExpireTime time.Time
+
+	client *genai.Client
+}
+
+// Expired reports whether the file has been deleted by the API because its
+// lifetime has passed.
+func (h FileHandle) Expired() bool {
+	return !h.ExpireTime.IsZero() && !time.Now().Before(h.ExpireTime)
+}
+
+// UploadFile uploads the content of r to the Gemini File API so it can be
+// attached to messages with AttachFiles. Use it for content too large to send
+// inline, such as long PDFs, audio or video. mimeType is the IANA type of the
+// content, e.g. "application/pdf"; if empty, the API infers it.
+//
+// Large files are processed before they can be used. UploadFile waits until
+// the file is ready or ctx is done. Files are deleted by the API after 48
+// hours; call Delete to remove them sooner.
+//
+// Example:
+//
+//	f, err := os.Open("report.pdf")
+//	...
+//	file, err := provider.UploadFile(ctx, f, "application/pdf")
+//	if err != nil {
+//	    return err
+//	}
+//	defer file.Delete(ctx)
+//
+//	question := gemini.AttachFiles(minds.Message{
+//	    Role:    minds.RoleUser,
+//	    Content: "Summarize the findings in section 3.",
+//	}, *file)
+func (p *Provider) UploadFile(ctx context.Context, r io.Reader, mimeType string) (*FileHandle, error) {
+	ctx = p.withHeaders(ctx)
+
+	file, err := p.client.UploadFile(ctx, "", r, &genai.UploadFileOptions{MIMEType: mimeType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	for file.State == genai.FileStateProcessing {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to upload file %s: %w", file.Name, ctx.Err())
+		case <-time.After(time.Second):
+		}
+
+		if file, err = p.client.GetFile(ctx, file.Name); err != nil {
+			return nil, fmt.Errorf("failed to get file: %w", err)
+		}
+	}
+
+	if file.State == genai.FileStateFailed {
+		return nil, fmt.Errorf("failed to process file %s: %v", file.Name, file.Error)
+	}
+
+	return newFileHandle(p.client, file), nil
+}
+
+// DeleteFile removes an uploaded file by name. Use it for files uploaded
+// elsewhere; FileHandle.Delete removes a file you hold a handle for.
+func (p *Provider) DeleteFile(ctx context.Context, name string) error {
+	if err := p.client.DeleteFile(p.withHeaders(ctx), name); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete removes the uploaded file. Messages that still reference it will
+// fail to send.
+func (h *FileHandle) Delete(ctx context.Context) error {
+	if err := h.client.DeleteFile(ctx, h.Name); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", h.Name, err)
+	}
+	return nil
+}
+
+// AttachFiles returns msg with files attached. The Gemini provider sends them
+// ahead of the message's text, so the text can refer to them. Other providers
+// ignore the attachment.
+func AttachFiles(msg minds.Message, files ...FileHandle) minds.Message {
+	metadata := minds.Metadata{}
+	for k, v := range msg.Metadata {
+		metadata[k] = v
+	}
+
+	attached, _ := metadata[FilesKey].([]FileHandle)
+	metadata[FilesKey] = append(append([]FileHandle{}, attached...), files...)
+
+	msg.Metadata = metadata
+	return msg
+}
+
+// fileParts converts the files attached to msg to Gemini parts. It returns an
+// error if any of them has expired, since the API would reject the request.
+func fileParts(msg minds.Message) ([]genai.Part, error) {
+	files, _ := msg.Metadata[FilesKey].([]FileHandle)
+
+	parts := make([]genai.Part, 0, len(files))
+	for _, file := range files {
+		if file.Expired() {
+			return nil, fmt.Errorf("file %s expired at %s", file.Name, file.ExpireTime.Format(time.RFC3339))
+		}
+		parts = append(parts, genai.FileData{MIMEType: file.MIMEType, URI: file.URI})
+	}
+
+	return parts, nil
+}
+
+func newFileHandle(client *genai.Client, file *genai.File) *FileHandle {
+	return &FileHandle{
+		Name:       file.Name,
+		URI:        file.URI,
+		MIMEType:   file.MIMEType,
+		ExpireTime: file.ExpirationTime,
+		client:     client,
+	}
+}
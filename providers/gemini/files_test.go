@@ -0,0 +1,49 @@
+package gemini
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/google/generative-ai-go/genai"
+	"github.com/matryer/is"
+)
+
+func TestAttachFiles(t *testing.T) {
+	is := is.New(t)
+
+	report := FileHandle{
+		Name:       "files/report",
+		URI:        "https://generativelanguage.googleapis.com/v1beta/files/report",
+		MIMEType:   "application/pdf",
+		ExpireTime: time.Now().Add(48 * time.Hour),
+	}
+
+	msg := minds.Message{Role: minds.RoleUser, Content: "Summarize section 3.", Metadata: minds.Metadata{"source": "upload"}}
+	attached := AttachFiles(msg, report)
+	is.Equal(len(msg.Metadata), 1) // the original message is unchanged
+
+	_, history, err := convertMessages(minds.Messages{attached})
+	is.NoErr(err)
+	is.Equal(history[0].Parts, []genai.Part{
+		genai.FileData{MIMEType: "application/pdf", URI: report.URI},
+		genai.Text("Summarize section 3."),
+	})
+
+	t.Run("a file alone is a valid message", func(t *testing.T) {
+		is := is.New(t)
+		_, history, err := convertMessages(minds.Messages{AttachFiles(minds.Message{Role: minds.RoleUser}, report)})
+		is.NoErr(err)
+		is.Equal(len(history[0].Parts), 1)
+	})
+
+	t.Run("expired files are rejected", func(t *testing.T) {
+		is := is.New(t)
+		expired := report
+		expired.ExpireTime = time.Now().Add(-time.Minute)
+		is.True(expired.Expired())
+
+		_, _, err := convertMessages(minds.Messages{AttachFiles(msg, expired)})
+		is.True(err != nil)
+	})
+}
@@ -72,6 +72,7 @@ func (p *Provider) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler
 
 	req := minds.Request{
 		Messages: messages,
+		Metadata: tc.Metadata(),
 	}
 
 	for i, m := range req.Messages {
@@ -81,10 +82,21 @@ func (p *Provider) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler
 		}
 	}
 
-	resp, err := p.GenerateContent(tc.Context(), req)
+	registry := p.options.registry
+	if r := minds.ThreadToolRegistry(tc); r != nil {
+		registry = r
+		req.Options.ToolRegistry = r
+	}
+	if schema := minds.ThreadResponseSchema(tc); schema != nil {
+		req.Options.ResponseSchema = schema
+	}
+
+	ctx, history := minds.TrackToolHistory(tc.Context(), registry)
+	resp, err := p.GenerateContent(ctx, req)
 	if err != nil {
 		return tc, err
 	}
+	tc = history.Record(tc)
 
 	msg := minds.Message{
 		Role:    minds.RoleAssistant,
@@ -94,6 +106,15 @@ func (p *Provider) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler
 
 	tc.AppendMessages(msg)
 
+	// Record what kind of response this was so downstream handlers, such as
+	// handlers.ResponseSwitch, can branch on it.
+	responseType := minds.ResponseTypeText
+	if len(resp.ToolCalls()) > 0 {
+		responseType = minds.ResponseTypeToolCall
+	}
+	tc.SetKeyValue(minds.LastResponseTypeKey, responseType)
+	tc.SetKeyValue(minds.LastToolCallsKey, resp.ToolCalls())
+
 	if next != nil {
 		return next.HandleThread(tc, nil)
 	}
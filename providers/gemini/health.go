@@ -0,0 +1,65 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/chriscow/minds"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// Ping checks that the API is reachable and the API key is valid by fetching
+// the first page of available models, which does not consume tokens. A
+// failure is returned as a *minds.PingError whose Kind is
+// minds.ErrUnauthorized or minds.ErrUnavailable when the cause is known.
+//
+// Example:
+//
+//	if err := llm.Ping(ctx); errors.Is(err, minds.ErrUnauthorized) {
+//	    log.Fatal("GEMINI_API_KEY is invalid")
+//	}
+func (p *Provider) Ping(ctx context.Context) error {
+	ctx, done, err := p.requests.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	_, err = p.client.ListModels(p.withHeaders(ctx)).Next()
+	if err != nil && err != iterator.Done {
+		return pingError(err)
+	}
+
+	return nil
+}
+
+// pingError classifies a failed request by its HTTP status or, if no
+// response was received, as a connectivity failure. Gemini reports an
+// invalid API key as a 400 Bad Request with the reason API_KEY_INVALID.
+func pingError(err error) error {
+	var apiErr *googleapi.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &apiErr):
+	case errors.As(err, &netErr):
+		return &minds.PingError{Kind: minds.ErrUnavailable, Err: err}
+	default:
+		return &minds.PingError{Err: err}
+	}
+
+	var kind error
+	switch status := apiErr.Code; {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		kind = minds.ErrUnauthorized
+	case status == http.StatusBadRequest && strings.Contains(apiErr.Body+apiErr.Message, "API_KEY_INVALID"):
+		kind = minds.ErrUnauthorized
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		kind = minds.ErrUnavailable
+	}
+
+	return &minds.PingError{Kind: kind, Err: err}
+}
@@ -0,0 +1,52 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/matryer/is"
+	"google.golang.org/api/googleapi"
+)
+
+func TestPingError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{
+			name: "invalid API key",
+			err: fmt.Errorf("list models: %w", &googleapi.Error{
+				Code:    400,
+				Message: "API key not valid. Please pass a valid API key.",
+				Body:    `{"error": {"code": 400, "status": "INVALID_ARGUMENT", "details": [{"reason": "API_KEY_INVALID"}]}}`,
+			}),
+			kind: minds.ErrUnauthorized,
+		},
+		{name: "permission denied", err: &googleapi.Error{Code: 403}, kind: minds.ErrUnauthorized},
+		{name: "server error", err: &googleapi.Error{Code: 503}, kind: minds.ErrUnavailable},
+		{name: "rate limited", err: &googleapi.Error{Code: 429}, kind: minds.ErrUnavailable},
+		{name: "other bad request", err: &googleapi.Error{Code: 400, Message: "bad model"}, kind: nil},
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			kind: minds.ErrUnavailable,
+		},
+		{name: "unknown", err: errors.New("boom"), kind: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			var pingErr *minds.PingError
+			is.True(errors.As(pingError(tt.err), &pingErr))
+			is.Equal(pingErr.Kind, tt.kind)
+			is.True(errors.Is(pingErr, tt.err))
+		})
+	}
+}
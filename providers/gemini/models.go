@@ -0,0 +1,46 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+)
+
+// ListModels returns the models available to the API key. Capabilities are
+// the model's supported generation methods, such as "generateContent" and
+// "embedContent".
+func (p *Provider) ListModels(ctx context.Context) ([]minds.ModelInfo, error) {
+	var models []minds.ModelInfo
+
+	it := p.client.ListModels(p.withHeaders(ctx))
+	for {
+		info, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list models: %w", err)
+		}
+
+		models = append(models, modelInfo(info))
+	}
+
+	return models, nil
+}
+
+// modelInfo converts a Gemini model description. The "models/" prefix is
+// removed from the ID so it can be passed to WithModel.
+func modelInfo(info *genai.ModelInfo) minds.ModelInfo {
+	return minds.ModelInfo{
+		ID:              strings.TrimPrefix(info.Name, "models/"),
+		Name:            info.DisplayName,
+		Description:     info.Description,
+		ContextWindow:   int(info.InputTokenLimit),
+		MaxOutputTokens: int(info.OutputTokenLimit),
+		Capabilities:    info.SupportedGenerationMethods,
+	}
+}
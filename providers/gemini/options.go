@@ -21,6 +21,10 @@ type Options struct {
 	registry        minds.ToolRegistry
 	systemPrompt    *string
 	httpClient      *http.Client
+	proxyURL        string
+	retryMax        *int
+	headers         http.Header
+	cachedContent   string
 }
 
 type Option func(*Options)
@@ -92,17 +96,83 @@ func WithToolRegistry(registry minds.ToolRegistry) Option {
 	}
 }
 
-func WithClient(client *http.Client) Option {
+// WithCachedContent makes every request reuse content cached with
+// Provider.CreateCache. The cached system instruction and tools replace the
+// provider's, so system messages in requests are not sent. The provider must
+// use the same model as the cache.
+func WithCachedContent(handle *CacheHandle) Option {
+	return func(o *Options) {
+		o.cachedContent = handle.Name
+	}
+}
+
+// WithHeader adds a header to every request sent to the API, for gateways
+// that require extra headers for routing or analytics. Headers are attached
+// to each call's context rather than to the HTTP client, so they do not
+// interfere with API key authentication.
+func WithHeader(key, value string) Option {
+	return func(o *Options) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+		o.headers.Set(key, value)
+	}
+}
+
+// WithHeaders adds each of the given headers to every request sent to the
+// API. See WithHeader.
+func WithHeaders(headers map[string]string) Option {
+	return func(o *Options) {
+		for key, value := range headers {
+			WithHeader(key, value)(o)
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to call the API, for full control
+// over TLS, proxies and timeouts. The API key is added to each request by
+// wrapping the client's transport.
+func WithHTTPClient(client *http.Client) Option {
 	return func(o *Options) {
 		o.httpClient = client
 	}
 }
 
+// WithClient sets the HTTP client used to call the API. It is the same as
+// WithHTTPClient.
+func WithClient(client *http.Client) Option {
+	return WithHTTPClient(client)
+}
+
+// WithProxy sends requests to the API through the HTTP proxy at proxyURL,
+// such as "http://proxy.corp.example:3128". It applies to the client set with
+// WithHTTPClient too, as long as its transport is an *http.Transport;
+// NewProvider returns an error otherwise, or if proxyURL is invalid. Retries
+// set with WithRetry go through the proxy as well.
+func WithProxy(proxyURL string) Option {
+	return func(o *Options) {
+		o.proxyURL = proxyURL
+	}
+}
+
+// WithRetry retries failed requests up to max times with a go-retryablehttp
+// client. Each attempt is sent with the client set with WithHTTPClient, or a
+// default client, including any proxy set with WithProxy.
 func WithRetry(max int) Option {
 	return func(o *Options) {
-		client := retryablehttp.NewClient()
-		client.RetryMax = max
+		o.retryMax = &max
+	}
+}
 
-		o.httpClient = client.StandardClient()
+// withRetry returns a client that retries failed requests up to max times,
+// sending each attempt with client. A nil client uses the retrying client's
+// default.
+func withRetry(client *http.Client, max int) *http.Client {
+	retry := retryablehttp.NewClient()
+	retry.RetryMax = max
+	if client != nil {
+		retry.HTTPClient = client
 	}
+
+	return retry.StandardClient()
 }
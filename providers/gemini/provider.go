@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/internal/inflight"
+	"github.com/chriscow/minds/internal/transport"
+	"github.com/googleapis/gax-go/v2/callctx"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
@@ -19,8 +23,9 @@ const defaultModel = "gemini-1.5-flash"
 // const OpenAICompatURL = "https://generativelanguage.googleapis.com/v1beta/openai/"
 
 type Provider struct {
-	client  *genai.Client
-	options Options
+	client   *genai.Client
+	options  Options
+	requests inflight.Tracker
 }
 
 // NewProvider creates a new Gemini provider. If no model name is provided, the
@@ -56,9 +61,26 @@ func NewProvider(ctx context.Context, opts ...Option) (*Provider, error) {
 	// 	options.httpClient = client.StandardClient()
 	// }
 
+	httpClient := options.httpClient
+	if options.proxyURL != "" {
+		var err error
+		httpClient, err = transport.WithProxy(httpClient, options.proxyURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if options.retryMax != nil {
+		httpClient = withRetry(httpClient, *options.retryMax)
+	}
+	if httpClient != nil {
+		// The Gemini client only adds the API key itself when it creates the
+		// HTTP client, so a client set with WithHTTPClient needs it added.
+		httpClient = transport.WithHeaders(httpClient, http.Header{"X-Goog-Api-Key": {options.apiKey}})
+	}
+
 	goptions := []option.ClientOption{
 		option.WithAPIKey(options.apiKey),
-		option.WithHTTPClient(options.httpClient),
+		option.WithHTTPClient(httpClient),
 	}
 
 	if options.baseURL != "" {
@@ -85,8 +107,39 @@ func NewProvider(ctx context.Context, opts ...Option) (*Provider, error) {
 	return &p, nil
 }
 
+// Close stops the provider immediately. In-flight requests are canceled, new
+// requests fail with ErrShutdown and the underlying client is closed. Use
+// Shutdown to let in-flight requests finish first.
 func (p *Provider) Close() {
+	p.requests.Close()
+	p.client.Close()
+}
+
+// Shutdown stops the provider gracefully. New requests fail with ErrShutdown
+// while in-flight requests are allowed to finish, after which the underlying
+// client is closed. If ctx is done before they complete, the remaining
+// requests are canceled as with Close and ctx.Err() is returned.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.requests.Shutdown(ctx)
 	p.client.Close()
+	return err
+}
+
+// withHeaders attaches the headers set with WithHeader to ctx. The Gemini
+// client sends headers found in the call context with each request.
+func (p *Provider) withHeaders(ctx context.Context) context.Context {
+	if len(p.options.headers) == 0 {
+		return ctx
+	}
+
+	keyvals := make([]string, 0, 2*len(p.options.headers))
+	for key, values := range p.options.headers {
+		for _, value := range values {
+			keyvals = append(keyvals, key, value)
+		}
+	}
+
+	return callctx.SetHeaders(ctx, keyvals...)
 }
 
 func (p *Provider) ModelName() string {
@@ -98,96 +151,117 @@ func (p *Provider) GenerateContent(ctx context.Context, req minds.Request) (mind
 		return nil, ctx.Err()
 	}
 
-	model, err := p.getModel()
+	ctx, done, err := p.requests.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create model: %w", err)
+		return nil, err
 	}
+	defer done()
+	ctx = p.withHeaders(ctx)
 
-	// Convert functions to Gemini format
-	tools := make([]*genai.FunctionDeclaration, 0)
-	for _, f := range p.options.registry.List() {
-		schema, err := convertSchema(f.Parameters())
-		if err != nil {
-			return nil, err
+	model, history, prompt, err := p.prepareModel(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw *genai.GenerateContentResponse
+	if req.Options.Candidates > 1 && len(history) == 0 {
+		// Chat sessions always ask for one candidate, so single-turn requests
+		// for several go to the model directly. Multi-turn requests get one.
+		raw, err = model.GenerateContent(ctx, prompt...)
+	} else {
+		cs := model.StartChat()
+		cs.History = history
+		raw, err = cs.SendMessage(ctx, prompt...)
+	}
+	if err != nil {
+		if blocked, ok := blockedError(err); ok {
+			return nil, blocked
 		}
 
-		tools = append(tools, &genai.FunctionDeclaration{
-			Name:        f.Name(),
-			Description: f.Description(),
-			Parameters:  schema,
-		})
+		err2 := errors.Unwrap(err)
+		if googErr, ok := err2.(*googleapi.Error); ok {
+			return nil, fmt.Errorf("%s", googErr.Body)
+		}
+
+		return nil, err
+	}
+
+	resp, err := p.parseResponse(ctx, req, raw)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(tools) > 0 {
-		model.Tools = []*genai.Tool{{
-			FunctionDeclarations: tools,
-		}}
+	_, prefill := minds.SplitPrefill(req)
+	return minds.PrefillResponse(resp, prefill), nil
+}
+
+// functionCallingModes maps the tool choices of minds.WithToolChoice to
+// Gemini's function calling modes.
+var functionCallingModes = map[string]genai.FunctionCallingMode{
+	"auto":     genai.FunctionCallingAuto,
+	"required": genai.FunctionCallingAny,
+	"none":     genai.FunctionCallingNone,
+}
+
+// prepareModel configures a model for req and converts its messages into the
+// chat history and the prompt to send. It makes no network calls.
+func (p *Provider) prepareModel(req minds.Request) (*genai.GenerativeModel, []*genai.Content, []genai.Part, error) {
+	modelName := p.options.modelName
+	if req.Options.ModelName != nil {
+		modelName = *req.Options.ModelName
 	}
 
-	// TODO: Gemini is not generating the model on the fly
-	// The model is created when the client is created
-	cs := model.StartChat()
+	model, err := p.getModel(modelName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create model: %w", err)
+	}
 
-	var sysPrompt *genai.Content
-	history := []*genai.Content{}
+	// Cached content already carries the system prompt and tools, and the API
+	// rejects requests that set them again.
+	if p.options.cachedContent == "" {
+		tools, err := p.functionDeclarations(p.toolRegistry(req))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if len(tools) > 0 {
+			model.Tools = []*genai.Tool{{
+				FunctionDeclarations: tools,
+			}}
+
+			if mode, ok := functionCallingModes[req.Options.ToolChoice]; ok {
+				model.ToolConfig = &genai.ToolConfig{
+					FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: mode},
+				}
+			}
+		}
+	}
 
 	if req.Options.ResponseSchema != nil {
 		schema, err := convertSchema(req.Options.ResponseSchema.Definition)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert schema: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to convert schema: %w", err)
 		}
 
 		model.ResponseMIMEType = "application/json"
 		model.ResponseSchema = schema
+	} else if req.Options.JSONMode {
+		model.ResponseMIMEType = "application/json"
 	}
 
-	for i, msg := range req.Messages {
-		if msg.Role == minds.RoleSystem {
-			if sysPrompt == nil {
-				sysPrompt = &genai.Content{Parts: []genai.Part{}, Role: "system"}
-			}
-			part := genai.Text(msg.Content)
-			sysPrompt.Parts = append(sysPrompt.Parts, part)
+	// Gemini cannot continue a model turn, so prefills are emulated below.
+	messages, prefill := minds.SplitPrefill(req)
 
-		} else if msg.Role == minds.RoleFunction {
-			response := make(map[string]any)
-			if err := json.Unmarshal([]byte(msg.Content), &response); err != nil {
-				response["result"] = msg.Content
-			}
-			history = append(history, &genai.Content{
-				Parts: []genai.Part{
-					genai.FunctionResponse{
-						Name:     msg.Name,
-						Response: response,
-					},
-				},
-			})
-		} else if msg.Role == minds.RoleAssistant {
-			history = append(history, &genai.Content{
-				Role:  string(minds.RoleModel),
-				Parts: []genai.Part{genai.Text(msg.Content)},
-			})
-		} else {
-			if msg.Content == "" {
-				return nil, fmt.Errorf("message content at index %d is empty", i)
-			}
+	sysPrompt, history, err := convertMessages(messages)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-			if msg.Role == "" {
-				msg.Role = minds.RoleUser
-			}
-			if msg.Role == minds.RoleAssistant {
-				msg.Role = minds.RoleModel
-			}
-			history = append(history, &genai.Content{
-				Parts: []genai.Part{
-					genai.Text(msg.Content),
-				},
-				Role: string(msg.Role),
-			})
-		}
+	if len(history) == 0 {
+		return nil, nil, nil, errors.New("request has no messages to send besides system messages")
 	}
 
-	if sysPrompt != nil {
+	if sysPrompt != nil && p.options.cachedContent == "" {
 		// The Gemini provider typically sets the system prompt with a construction
 		// option but to be compatible with OpenAI, we allow the system prompt to be
 		// set in the request as well.
@@ -197,15 +271,32 @@ func (p *Provider) GenerateContent(ctx context.Context, req minds.Request) (mind
 	prompt := history[len(history)-1].Parts // The prompt is the last message
 	history = history[:len(history)-1]
 
-	cs.History = history
-
-	raw, err := cs.SendMessage(ctx, prompt...)
-	if err != nil {
-		err2 := errors.Unwrap(err)
-		if googErr, ok := err2.(*googleapi.Error); ok {
-			return nil, fmt.Errorf("%s", googErr.Body)
+	if prefill != "" {
+		// Add the instruction to the system prompt, or to the prompt when
+		// cached content holds the system prompt.
+		instruction := genai.Text(minds.EmulatePrefill(nil, prefill)[0].Content)
+		switch {
+		case p.options.cachedContent != "":
+			prompt = append(prompt, instruction)
+		case model.SystemInstruction == nil:
+			model.SystemInstruction = &genai.Content{Parts: []genai.Part{instruction}, Role: "system"}
+		default:
+			model.SystemInstruction.Parts = append(model.SystemInstruction.Parts, instruction)
 		}
+	}
 
+	if req.Options.Candidates > 1 && len(history) == 0 {
+		count := int32(req.Options.Candidates)
+		model.CandidateCount = &count
+	}
+
+	return model, history, prompt, nil
+}
+
+// parseResponse checks raw, runs the function calls in its first candidate
+// and wraps it as a Response.
+func (p *Provider) parseResponse(ctx context.Context, req minds.Request, raw *genai.GenerateContentResponse) (minds.Response, error) {
+	if err := checkCandidates(raw); err != nil {
 		return nil, err
 	}
 
@@ -229,7 +320,7 @@ func (p *Provider) GenerateContent(ctx context.Context, req minds.Request) (mind
 		})
 	}
 
-	calls, err = minds.HandleFunctionCalls(ctx, calls, p.options.registry)
+	calls, err := minds.HandleFunctionCalls(minds.WithThreadMetadata(ctx, req.Metadata), calls, p.toolRegistry(req))
 	if err != nil {
 		return nil, err
 	}
@@ -237,8 +328,113 @@ func (p *Provider) GenerateContent(ctx context.Context, req minds.Request) (mind
 	return NewResponse(raw, calls)
 }
 
-func (p *Provider) getModel() (*genai.GenerativeModel, error) {
-	model := p.client.GenerativeModel(p.options.modelName)
+// toolRegistry returns the registry to use for req: the request's own if it
+// sets one, otherwise the provider's.
+func (p *Provider) toolRegistry(req minds.Request) minds.ToolRegistry {
+	if req.Options.ToolRegistry != nil {
+		return req.Options.ToolRegistry
+	}
+	return p.options.registry
+}
+
+// functionDeclarations converts the tools in registry to Gemini's format.
+func (p *Provider) functionDeclarations(registry minds.ToolRegistry) ([]*genai.FunctionDeclaration, error) {
+	tools := make([]*genai.FunctionDeclaration, 0)
+	for _, f := range registry.List() {
+		schema, err := convertSchema(f.Parameters())
+		if err != nil {
+			return nil, err
+		}
+
+		tools = append(tools, &genai.FunctionDeclaration{
+			Name:        f.Name(),
+			Description: f.Description(),
+			Parameters:  schema,
+		})
+	}
+
+	return tools, nil
+}
+
+// convertMessages splits messages into a system instruction and the chat
+// history in Gemini's format.
+func convertMessages(messages minds.Messages) (*genai.Content, []*genai.Content, error) {
+	var sysPrompt *genai.Content
+	history := []*genai.Content{}
+
+	for i, msg := range messages {
+		switch msg.Role {
+		case minds.RoleSystem, minds.RoleDeveloper:
+			if sysPrompt == nil {
+				sysPrompt = &genai.Content{Parts: []genai.Part{}, Role: "system"}
+			}
+			part := genai.Text(msg.Content)
+			sysPrompt.Parts = append(sysPrompt.Parts, part)
+
+		case minds.RoleFunction, minds.RoleTool:
+			response := make(map[string]any)
+			if err := json.Unmarshal([]byte(msg.Content), &response); err != nil {
+				response["result"] = msg.Content
+			}
+			history = append(history, &genai.Content{
+				Parts: []genai.Part{
+					genai.FunctionResponse{
+						Name:     msg.Name,
+						Response: response,
+					},
+				},
+			})
+
+		case minds.RoleAssistant, minds.RoleModel, minds.RoleAI:
+			// Tool calls become functionCall parts, so the functionResponse
+			// parts that follow answer a call Gemini can see.
+			var parts []genai.Part
+			if msg.Content != "" || len(msg.ToolCalls) == 0 {
+				parts = append(parts, genai.Text(msg.Content))
+			}
+			for _, call := range msg.ToolCalls {
+				args := make(map[string]any)
+				if len(call.Function.Parameters) > 0 {
+					if err := json.Unmarshal(call.Function.Parameters, &args); err != nil {
+						return nil, nil, fmt.Errorf("message %d: invalid arguments for `%s`: %w", i, call.Function.Name, err)
+					}
+				}
+				parts = append(parts, genai.FunctionCall{Name: call.Function.Name, Args: args})
+			}
+
+			history = append(history, &genai.Content{
+				Role:  string(minds.RoleModel),
+				Parts: parts,
+			})
+
+		case minds.RoleUser, "":
+			parts, err := fileParts(msg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("message %d: %w", i, err)
+			}
+
+			if msg.Content == "" && len(parts) == 0 {
+				return nil, nil, fmt.Errorf("message content at index %d is empty", i)
+			}
+			if msg.Content != "" {
+				parts = append(parts, genai.Text(msg.Content))
+			}
+
+			history = append(history, &genai.Content{
+				Parts: parts,
+				Role:  string(minds.RoleUser),
+			})
+
+		default:
+			return nil, nil, fmt.Errorf("message %d: %w: %q", i, minds.ErrUnsupportedRole, msg.Role)
+		}
+	}
+
+	return sysPrompt, history, nil
+}
+
+func (p *Provider) getModel(modelName string) (*genai.GenerativeModel, error) {
+	model := p.client.GenerativeModel(modelName)
 	model.Temperature = p.options.temperature
 	model.MaxOutputTokens = p.options.maxOutputTokens
 
@@ -247,7 +443,9 @@ func (p *Provider) getModel() (*genai.GenerativeModel, error) {
 		model.ResponseSchema = p.options.schema
 	}
 
-	if p.options.systemPrompt != nil {
+	if p.options.cachedContent != "" {
+		model.CachedContentName = p.options.cachedContent
+	} else if p.options.systemPrompt != nil {
 		model.SystemInstruction = &genai.Content{Parts: []genai.Part{genai.Text(*p.options.systemPrompt)}, Role: "system"}
 	}
 
@@ -3,12 +3,15 @@ package gemini
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/chriscow/minds"
 	"github.com/google/generative-ai-go/genai"
+	"github.com/googleapis/gax-go/v2/callctx"
 	"github.com/matryer/is"
 )
 
@@ -167,3 +170,326 @@ func TestProvider_GenerateContent_WithToolRegistry(t *testing.T) {
 	is.NoErr(json.Unmarshal(toolCalls[0].Function.Result, &result)) // Should be able to parse the result
 	is.Equal(result["result"], 6)                                   // Ensure the mock function was called correctly
 }
+
+func TestConvertMessages(t *testing.T) {
+	is := is.New(t)
+
+	sysPrompt, history, err := convertMessages(minds.Messages{
+		{Role: minds.RoleSystem, Content: "Answer questions about the report."},
+		{Role: minds.RoleUser, Content: "The report."},
+		{Role: minds.RoleAssistant, Content: "Got it."},
+	})
+	is.NoErr(err)
+
+	is.Equal(sysPrompt.Parts, []genai.Part{genai.Text("Answer questions about the report.")})
+	is.Equal(len(history), 2)
+	is.Equal(history[0].Role, string(minds.RoleUser))
+	is.Equal(history[1].Role, string(minds.RoleModel))
+
+	_, _, err = convertMessages(minds.Messages{{Role: minds.RoleUser}})
+	is.True(err != nil) // empty user content is rejected
+}
+
+func TestConvertMessages_Roles(t *testing.T) {
+	is := is.New(t)
+
+	sysPrompt, history, err := convertMessages(minds.Messages{
+		{Role: minds.RoleDeveloper, Content: "Be brief."},
+		{Content: "What's 2+2?"},
+		{Role: minds.RoleAI, Content: "Let me check."},
+		{Role: minds.RoleTool, Name: "add", Content: `{"sum": 4}`, ToolCallID: "call_1"},
+	})
+	is.NoErr(err)
+
+	is.Equal(len(sysPrompt.Parts), 1)
+	is.Equal(history[0].Role, string(minds.RoleUser))
+	is.Equal(history[1].Role, string(minds.RoleModel))
+	is.Equal(history[2].Parts[0], genai.FunctionResponse{Name: "add", Response: map[string]any{"sum": float64(4)}})
+
+	_, _, err = convertMessages(minds.Messages{{Role: "narrator", Content: "Meanwhile..."}})
+	is.True(errors.Is(err, minds.ErrUnsupportedRole))
+}
+
+func TestConvertMessages_ToolCalls(t *testing.T) {
+	is := is.New(t)
+
+	_, history, err := convertMessages(minds.Messages{
+		{Role: minds.RoleUser, Content: "What's 2+2?"},
+		{Role: minds.RoleAssistant, ToolCalls: []minds.ToolCall{{
+			ID:       "call_1",
+			Function: minds.FunctionCall{Name: "add", Parameters: []byte(`{"a": 2, "b": 2}`)},
+		}}},
+		{Role: minds.RoleFunction, Name: "add", Content: `{"sum": 4}`, ToolCallID: "call_1"},
+	})
+	is.NoErr(err)
+
+	is.Equal(len(history), 3)
+	is.Equal(history[1].Role, string(minds.RoleModel))
+	is.Equal(history[1].Parts, []genai.Part{
+		genai.FunctionCall{Name: "add", Args: map[string]any{"a": float64(2), "b": float64(2)}},
+	})
+	is.Equal(history[2].Parts[0], genai.FunctionResponse{Name: "add", Response: map[string]any{"sum": float64(4)}})
+
+	_, _, err = convertMessages(minds.Messages{
+		{Role: minds.RoleAssistant, ToolCalls: []minds.ToolCall{{Function: minds.FunctionCall{Name: "add", Parameters: []byte(`{`)}}}},
+	})
+	is.True(err != nil) // arguments must be JSON
+}
+
+func TestProvider_WithHeaders(t *testing.T) {
+	is := is.New(t)
+
+	var options Options
+	WithHeader("X-Title", "minds")(&options)
+	WithHeaders(map[string]string{"HTTP-Referer": "https://example.com"})(&options)
+	p := &Provider{options: options}
+
+	headers := callctx.HeadersFromContext(p.withHeaders(context.Background()))
+	is.Equal(headers["X-Title"], []string{"minds"})
+	is.Equal(headers["Http-Referer"], []string{"https://example.com"})
+
+	ctx := context.Background()
+	is.Equal((&Provider{}).withHeaders(ctx), ctx) // no headers leaves ctx untouched
+}
+
+func TestModelInfo(t *testing.T) {
+	is := is.New(t)
+
+	info := modelInfo(&genai.ModelInfo{
+		Name:                       "models/gemini-1.5-flash",
+		DisplayName:                "Gemini 1.5 Flash",
+		InputTokenLimit:            1048576,
+		OutputTokenLimit:           8192,
+		SupportedGenerationMethods: []string{"generateContent", "countTokens"},
+	})
+
+	is.Equal(info.ID, "gemini-1.5-flash")
+	is.Equal(info.Name, "Gemini 1.5 Flash")
+	is.Equal(info.ContextWindow, 1048576)
+	is.Equal(info.MaxOutputTokens, 8192)
+	is.Equal(info.Capabilities, []string{"generateContent", "countTokens"})
+}
+
+func TestProvider_ModelOverride(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		http.Error(w, `{"error": {"code": 500, "message": "not implemented"}}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	provider, err := NewProvider(ctx, WithBaseURL(server.URL), WithAPIKey("test"), WithModel("gemini-default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}
+
+	t.Run("uses the provider's model by default", func(t *testing.T) {
+		is := is.New(t)
+		paths = nil
+		_, err := provider.GenerateContent(ctx, minds.NewRequest(messages))
+		is.True(err != nil) // the test server always fails
+		is.Equal(len(paths), 1)
+		is.True(strings.Contains(paths[0], "/models/gemini-default:"))
+	})
+
+	t.Run("uses the request's model when set", func(t *testing.T) {
+		is := is.New(t)
+		paths = nil
+		_, err := provider.GenerateContent(ctx, minds.NewRequest(messages, minds.WithModel("gemini-override")))
+		is.True(err != nil) // the test server always fails
+		is.Equal(len(paths), 1)
+		is.True(strings.Contains(paths[0], "/models/gemini-override:"))
+		is.Equal(provider.ModelName(), "gemini-default")
+	})
+}
+
+func TestProvider_PrepareModel(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("converts the request", func(t *testing.T) {
+		is := is.New(t)
+
+		tool, err := minds.WrapFunction("lookup", "Looks things up", struct {
+			Query string `json:"query"`
+		}{}, func(context.Context, []byte) ([]byte, error) { return nil, nil })
+		is.NoErr(err)
+		registry := minds.NewToolRegistry()
+		is.NoErr(registry.Register(tool))
+
+		schema, err := minds.NewResponseSchema("answer", "An answer", struct {
+			Answer string `json:"answer"`
+		}{})
+		is.NoErr(err)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"), WithModel("gemini-default"))
+		is.NoErr(err)
+
+		model, history, prompt, err := provider.prepareModel(minds.NewRequest(minds.Messages{
+			{Role: minds.RoleSystem, Content: "Be brief."},
+			{Role: minds.RoleUser, Content: "Hi"},
+			{Role: minds.RoleAssistant, Content: "Hello!"},
+			{Role: minds.RoleUser, Content: "Look up Go"},
+		}, minds.WithModel("gemini-override"), minds.WithToolRegistry(registry), minds.WithResponseSchema(*schema)))
+		is.NoErr(err)
+
+		is.Equal(model.SystemInstruction.Parts, []genai.Part{genai.Text("Be brief.")})
+		is.Equal(len(model.Tools), 1)
+		is.Equal(model.Tools[0].FunctionDeclarations[0].Name, "lookup")
+		is.Equal(model.ResponseMIMEType, "application/json")
+		is.True(model.ResponseSchema != nil)
+		is.True(model.CandidateCount == nil)
+
+		is.Equal(len(history), 2)
+		is.Equal(history[0].Role, string(minds.RoleUser))
+		is.Equal(history[1].Role, string(minds.RoleModel))
+		is.Equal(prompt, []genai.Part{genai.Text("Look up Go")})
+	})
+
+	t.Run("maps the tool choice", func(t *testing.T) {
+		is := is.New(t)
+
+		tool, err := minds.WrapFunction("lookup", "Looks things up", struct {
+			Query string `json:"query"`
+		}{}, func(context.Context, []byte) ([]byte, error) { return nil, nil })
+		is.NoErr(err)
+		registry := minds.NewToolRegistry()
+		is.NoErr(registry.Register(tool))
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"))
+		is.NoErr(err)
+
+		messages := minds.Messages{{Role: minds.RoleUser, Content: "Look up Go"}}
+		model, _, _, err := provider.prepareModel(minds.NewRequest(messages,
+			minds.WithToolRegistry(registry), minds.WithToolChoice("required")))
+		is.NoErr(err)
+		is.Equal(model.ToolConfig.FunctionCallingConfig.Mode, genai.FunctionCallingAny)
+
+		model, _, _, err = provider.prepareModel(minds.NewRequest(messages, minds.WithToolRegistry(registry)))
+		is.NoErr(err)
+		is.True(model.ToolConfig == nil)
+	})
+
+	t.Run("asks for JSON without a schema", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"))
+		is.NoErr(err)
+
+		model, _, _, err := provider.prepareModel(minds.NewRequest(
+			minds.Messages{{Role: minds.RoleUser, Content: "Describe Go as JSON."}}, minds.WithJSONMode()))
+		is.NoErr(err)
+		is.Equal(model.ResponseMIMEType, "application/json")
+		is.True(model.ResponseSchema == nil)
+	})
+
+	t.Run("asks for candidates on single turns", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"))
+		is.NoErr(err)
+
+		model, _, _, err := provider.prepareModel(minds.NewRequest(
+			minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}, minds.WithCandidates(3)))
+		is.NoErr(err)
+		is.Equal(*model.CandidateCount, int32(3))
+	})
+
+	t.Run("emulates prefills", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"), WithSystemPrompt("Be brief."))
+		is.NoErr(err)
+
+		model, history, prompt, err := provider.prepareModel(minds.NewRequest(minds.Messages{
+			{Role: minds.RoleUser, Content: "List three colors as JSON."},
+			{Role: minds.RoleAssistant, Content: `{"colors": [`},
+		}, minds.WithPrefill()))
+		is.NoErr(err)
+
+		is.Equal(len(history), 0)
+		is.Equal(prompt, []genai.Part{genai.Text("List three colors as JSON.")})
+		is.Equal(len(model.SystemInstruction.Parts), 2)
+		is.Equal(model.SystemInstruction.Parts[0], genai.Text("Be brief."))
+		instruction := string(model.SystemInstruction.Parts[1].(genai.Text))
+		is.True(strings.HasSuffix(instruction, `{"colors": [`))
+	})
+
+	t.Run("rejects requests with only system messages", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"))
+		is.NoErr(err)
+
+		_, _, _, err = provider.prepareModel(minds.NewRequest(minds.Messages{{Role: minds.RoleSystem, Content: "Be brief."}}))
+		is.True(err != nil)
+	})
+}
+
+func TestProvider_ParseResponse(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs function calls", func(t *testing.T) {
+		is := is.New(t)
+
+		tool, err := minds.WrapFunction("double", "Doubles a value", struct {
+			Value int `json:"value"`
+		}{}, func(_ context.Context, args []byte) ([]byte, error) {
+			var params struct {
+				Value int `json:"value"`
+			}
+			if err := json.Unmarshal(args, &params); err != nil {
+				return nil, err
+			}
+			return json.Marshal(map[string]int{"result": params.Value * 2})
+		})
+		is.NoErr(err)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"), WithTool(tool))
+		is.NoErr(err)
+
+		resp, err := provider.parseResponse(ctx, minds.Request{}, &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Role: "model", Parts: []genai.Part{
+				genai.FunctionCall{Name: "double", Args: map[string]any{"value": 3}},
+			}}}},
+		})
+		is.NoErr(err)
+
+		calls := resp.ToolCalls()
+		is.Equal(len(calls), 1)
+		is.Equal(calls[0].Function.Name, "double")
+		is.Equal(string(calls[0].Function.Result), `{"result":6}`)
+	})
+
+	t.Run("returns text", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"))
+		is.NoErr(err)
+
+		resp, err := provider.parseResponse(ctx, minds.Request{}, &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Role: "model", Parts: []genai.Part{genai.Text("Hello, world!")}}}},
+		})
+		is.NoErr(err)
+		is.Equal(resp.String(), "Hello, world!")
+		is.Equal(len(resp.ToolCalls()), 0)
+	})
+
+	t.Run("rejects empty candidates", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(ctx, WithAPIKey("test"))
+		is.NoErr(err)
+
+		for _, raw := range []*genai.GenerateContentResponse{
+			{},
+			{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonOther}}},
+			{Candidates: []*genai.Candidate{{Content: &genai.Content{Role: "model"}}}},
+		} {
+			_, err := provider.parseResponse(ctx, minds.Request{}, raw)
+			is.True(err != nil)
+		}
+	})
+}
@@ -21,10 +21,6 @@ func NewResponse(resp *genai.GenerateContentResponse, calls []minds.ToolCall) (*
 		return nil, fmt.Errorf("no candidates in Gemini response")
 	}
 
-	if len(resp.Candidates) > 1 {
-		return nil, fmt.Errorf("more than one candidate in Gemini response")
-	}
-
 	candidate := resp.Candidates[0]
 	if candidate.Content == nil {
 		return nil, fmt.Errorf("candidate content is nil")
@@ -60,12 +56,44 @@ func (r *Response) String() string {
 	return string(text)
 }
 
+// Candidates returns the text of every candidate, requested with
+// minds.WithCandidates. Tool calls are only read from the first.
+func (r *Response) Candidates() []string {
+	candidates := make([]string, 0, len(r.raw.Candidates))
+	for _, candidate := range r.raw.Candidates {
+		var text string
+		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
+			if t, ok := candidate.Content.Parts[0].(genai.Text); ok {
+				text = string(t)
+			}
+		}
+		candidates = append(candidates, text)
+	}
+	return candidates
+}
+
 // ToolCalls returns the function call details if this is a function call response.
 // Returns nil and false if this isn't a function call.
 func (r *Response) ToolCalls() []minds.ToolCall {
 	return r.calls
 }
 
+// Usage returns the token counts reported by Gemini. CachedTokens is the part
+// of the prompt read from cached content.
+func (r *Response) Usage() minds.Usage {
+	meta := r.raw.UsageMetadata
+	if meta == nil {
+		return minds.Usage{}
+	}
+
+	return minds.Usage{
+		PromptTokens:     int(meta.PromptTokenCount),
+		CompletionTokens: int(meta.CandidatesTokenCount),
+		TotalTokens:      int(meta.TotalTokenCount),
+		CachedTokens:     int(meta.CachedContentTokenCount),
+	}
+}
+
 // Raw returns the underlying Gemini response
 func (r *Response) Raw() *genai.GenerateContentResponse {
 	return r.raw
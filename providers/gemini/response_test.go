@@ -0,0 +1,25 @@
+package gemini
+
+import (
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/google/generative-ai-go/genai"
+	"github.com/matryer/is"
+)
+
+func TestNewResponse_Candidates(t *testing.T) {
+	is := is.New(t)
+
+	raw := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{Content: &genai.Content{Role: "model", Parts: []genai.Part{genai.Text("Hello, world!")}}},
+			{Content: &genai.Content{Role: "model", Parts: []genai.Part{genai.Text("Hi there!")}}},
+		},
+	}
+
+	resp, err := NewResponse(raw, nil)
+	is.NoErr(err)
+	is.Equal(resp.String(), "Hello, world!") // the first candidate
+	is.Equal(minds.Candidates(resp), []string{"Hello, world!", "Hi there!"})
+}
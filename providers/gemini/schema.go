@@ -2,6 +2,7 @@ package gemini
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/chriscow/minds"
 
@@ -21,7 +22,43 @@ func GenerateSchema(v any) (*genai.Schema, error) {
 	return convertSchema(*d)
 }
 
+// maxRefDepth is how many times a ref is expanded along one path of a
+// schema. Gemini schemas cannot contain refs, so recursive types are
+// unrolled this many levels deep.
+const maxRefDepth = 3
+
+// convertSchema converts d to Gemini's schema format. Gemini has no refs, so
+// refs are expanded inline up to maxRefDepth levels; a property whose ref
+// would go deeper is left out of its object.
 func convertSchema(d minds.Definition) (*genai.Schema, error) {
+	schema, err := convertDefinition(d, d, 0)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("schema refers to itself")
+	}
+	return schema, nil
+}
+
+// convertDefinition converts d, resolving refs within root. It returns nil
+// without an error if d cannot be expanded within maxRefDepth.
+func convertDefinition(root, d minds.Definition, depth int) (*genai.Schema, error) {
+	if d.Ref != "" {
+		if depth >= maxRefDepth {
+			return nil, nil
+		}
+
+		target, err := resolveRef(root, d.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if d.Description != "" {
+			target.Description = d.Description
+		}
+		return convertDefinition(root, target, depth+1)
+	}
+
 	schema := &genai.Schema{}
 
 	// Map the type
@@ -37,10 +74,13 @@ func convertSchema(d minds.Definition) (*genai.Schema, error) {
 	case minds.Array:
 		schema.Type = genai.TypeArray
 		if d.Items != nil {
-			items, err := convertSchema(*d.Items)
+			items, err := convertDefinition(root, *d.Items, depth)
 			if err != nil {
 				return nil, fmt.Errorf("error converting array items: %w", err)
 			}
+			if items == nil {
+				return nil, nil
+			}
 			schema.Items = items
 		}
 	case minds.Object:
@@ -48,15 +88,20 @@ func convertSchema(d minds.Definition) (*genai.Schema, error) {
 		if len(d.Properties) > 0 {
 			schema.Properties = make(map[string]*genai.Schema)
 			for key, prop := range d.Properties {
-				propSchema, err := convertSchema(prop)
+				propSchema, err := convertDefinition(root, prop, depth)
 				if err != nil {
 					return nil, fmt.Errorf("error converting property %s: %w", key, err)
 				}
+				if propSchema == nil {
+					continue // too deeply nested to expand
+				}
 				schema.Properties[key] = propSchema
 			}
 		}
-		if len(d.Required) > 0 {
-			schema.Required = d.Required
+		for _, key := range d.Required {
+			if _, ok := schema.Properties[key]; ok {
+				schema.Required = append(schema.Required, key)
+			}
 		}
 	case minds.Null:
 		schema.Type = genai.TypeUnspecified
@@ -78,6 +123,21 @@ func convertSchema(d minds.Definition) (*genai.Schema, error) {
 	return schema, nil
 }
 
+// resolveRef returns the definition ref points to within root.
+func resolveRef(root minds.Definition, ref string) (minds.Definition, error) {
+	if ref == "#" {
+		return root, nil
+	}
+
+	if name := strings.TrimPrefix(ref, "#/$defs/"); name != ref {
+		if def, ok := root.Defs[name]; ok {
+			return def, nil
+		}
+	}
+
+	return minds.Definition{}, fmt.Errorf("unresolved schema ref %q", ref)
+}
+
 func reflectMindsDefinition(schema *genai.Schema) (*minds.Definition, error) {
 	definition := &minds.Definition{}
 
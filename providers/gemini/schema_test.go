@@ -0,0 +1,74 @@
+package gemini
+
+import (
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/google/generative-ai-go/genai"
+	"github.com/matryer/is"
+)
+
+type comment struct {
+	Author  string    `json:"author"`
+	Replies []comment `json:"replies"`
+	Parent  *comment  `json:"parent,omitempty"`
+}
+
+type thread struct {
+	Title    string    `json:"title"`
+	Comments []comment `json:"comments"`
+}
+
+func TestConvertSchema_Refs(t *testing.T) {
+	t.Run("unrolls recursive types to a fixed depth", func(t *testing.T) {
+		is := is.New(t)
+
+		schema, err := GenerateSchema(comment{})
+		is.NoErr(err)
+
+		levels := 0
+		for s := schema; s != nil; {
+			levels++
+			is.Equal(s.Type, genai.TypeObject)
+			is.Equal(s.Properties["author"].Type, genai.TypeString)
+			replies, ok := s.Properties["replies"]
+			if !ok {
+				is.True(!containsString(s.Required, "replies")) // dropped properties are not required
+				break
+			}
+			s = replies.Items
+		}
+		is.Equal(levels, maxRefDepth+1) // the root plus maxRefDepth expansions
+	})
+
+	t.Run("resolves $defs", func(t *testing.T) {
+		is := is.New(t)
+
+		schema, err := GenerateSchema(thread{})
+		is.NoErr(err)
+
+		comments := schema.Properties["comments"]
+		is.Equal(comments.Type, genai.TypeArray)
+		is.Equal(comments.Items.Type, genai.TypeObject)
+		is.Equal(comments.Items.Properties["replies"].Items.Properties["author"].Type, genai.TypeString)
+	})
+
+	t.Run("rejects unknown refs", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := convertSchema(minds.Definition{
+			Type:       minds.Object,
+			Properties: map[string]minds.Definition{"x": {Ref: "#/$defs/missing"}},
+		})
+		is.True(err != nil)
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
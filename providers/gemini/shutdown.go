@@ -0,0 +1,7 @@
+package gemini
+
+import "github.com/chriscow/minds/internal/inflight"
+
+// ErrShutdown is returned by GenerateContent once Close or Shutdown has been
+// called.
+var ErrShutdown = inflight.ErrShutdown
@@ -9,7 +9,7 @@ import (
 // Implement the TokenCounter interface for the Gemini provider.
 func (p *Provider) CountTokens(text string) (int, error) {
 	ctx := context.Background()
-	model, err := p.getModel()
+	model, err := p.getModel(p.options.modelName)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,55 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/matryer/is"
+)
+
+func TestTransport(t *testing.T) {
+	for name, client := range map[string]Option{
+		"custom client":   WithHTTPClient(&http.Client{}),
+		"retrying client": WithRetry(0),
+	} {
+		t.Run("proxies and authenticates a "+name, func(t *testing.T) {
+			is := is.New(t)
+
+			var host, key string
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				host = r.URL.Host
+				key = r.Header.Get("x-goog-api-key")
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer proxy.Close()
+
+			provider, err := NewProvider(context.Background(),
+				WithAPIKey("secret"),
+				WithBaseURL("http://generativelanguage.invalid"),
+				client,
+				WithProxy(proxy.URL),
+			)
+			is.NoErr(err)
+			defer provider.Close()
+
+			req := minds.Request{Messages: minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}}
+			_, _ = provider.GenerateContent(context.Background(), req)
+			is.Equal(host, "generativelanguage.invalid")
+			is.Equal(key, "secret")
+		})
+	}
+
+	t.Run("rejects proxies for clients it can't configure", func(t *testing.T) {
+		is := is.New(t)
+
+		fileClient := &http.Client{Transport: http.NewFileTransport(http.Dir("."))}
+		_, err := NewProvider(context.Background(), WithAPIKey("secret"), WithHTTPClient(fileClient), WithProxy("http://proxy.invalid"))
+		is.True(err != nil)
+
+		_, err = NewProvider(context.Background(), WithAPIKey("secret"), WithProxy("://bad"))
+		is.True(err != nil)
+	})
+}
@@ -0,0 +1,156 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// APIError is returned by GenerateContent when the API responds with an
+// error status. It wraps the underlying go-openai error and adds the delay
+// the server asked for before retrying, if any. The delay is read from the
+// retry-after-ms or Retry-After response headers.
+//
+// Example:
+//
+//	var apiErr *openai.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+//	    time.Sleep(apiErr.RetryAfter())
+//	}
+type APIError struct {
+	StatusCode int
+	Err        error
+
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the delay the server suggested before retrying, or zero
+// if it did not suggest one. It satisfies the interface used by
+// retry.RespectRetryAfter.
+func (e *APIError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// apiError wraps err in an APIError if it carries an HTTP status.
+func apiError(err error, retryAfter time.Duration) error {
+	status := 0
+
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	default:
+		return err
+	}
+
+	return &APIError{StatusCode: status, Err: err, retryAfter: retryAfter}
+}
+
+// retryAfterRecorder holds the Retry-After delay of the last response sent
+// on a request context.
+type retryAfterRecorder struct {
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func (r *retryAfterRecorder) get() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.delay
+}
+
+func (r *retryAfterRecorder) set(delay time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delay = delay
+}
+
+type retryAfterKey struct{}
+
+// recordRetryAfter returns a context whose requests, when sent through a
+// retryAfterTransport, record the server's Retry-After delay in the returned
+// recorder.
+func recordRetryAfter(ctx context.Context) (context.Context, *retryAfterRecorder) {
+	recorder := &retryAfterRecorder{}
+	return context.WithValue(ctx, retryAfterKey{}, recorder), recorder
+}
+
+// retryAfterTransport records the Retry-After header of every response whose
+// request context carries a retryAfterRecorder.
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if recorder, ok := req.Context().Value(retryAfterKey{}).(*retryAfterRecorder); ok {
+		recorder.set(parseRetryAfter(resp.Header, time.Now()))
+	}
+
+	return resp, nil
+}
+
+// withRetryAfter returns a copy of client whose transport records Retry-After
+// headers. A nil client is treated as http.DefaultClient.
+func withRetryAfter(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	wrapped := *client
+	wrapped.Transport = &retryAfterTransport{base: base}
+	return &wrapped
+}
+
+// parseRetryAfter returns the retry delay from the retry-after-ms header,
+// which OpenAI sends for sub-second delays, or the standard Retry-After
+// header in seconds or as an HTTP date. It returns zero if neither is set
+// or valid.
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
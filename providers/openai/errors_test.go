@@ -0,0 +1,63 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/matryer/is"
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestGenerateContent_APIError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(WithBaseURL(server.URL), WithHeader("X-Test", "1"))
+	is.NoErr(err)
+
+	_, err = provider.GenerateContent(context.Background(), minds.NewRequest(minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}))
+
+	var apiErr *APIError
+	is.True(errors.As(err, &apiErr))
+	is.Equal(apiErr.StatusCode, http.StatusTooManyRequests)
+	is.Equal(apiErr.RetryAfter(), 7*time.Second)
+
+	var openaiErr *openai.APIError
+	is.True(errors.As(err, &openaiErr)) // the go-openai error is still available
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"seconds", http.Header{"Retry-After": {"20"}}, 20 * time.Second},
+		{"http date", http.Header{"Retry-After": {now.Add(90 * time.Second).Format(http.TimeFormat)}}, 90 * time.Second},
+		{"date in the past", http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, 0},
+		{"milliseconds take precedence", http.Header{"Retry-After-Ms": {"1500"}, "Retry-After": {"2"}}, 1500 * time.Millisecond},
+		{"invalid", http.Header{"Retry-After": {"soon"}}, 0},
+		{"missing", http.Header{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(parseRetryAfter(tt.header, now), tt.want)
+		})
+	}
+}
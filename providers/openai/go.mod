@@ -2,7 +2,7 @@ module github.com/chriscow/minds/providers/openai
 
 go 1.18
 
-// replace github.com/chriscow/minds => ../../
+replace github.com/chriscow/minds => ../../
 
 require (
 	github.com/chriscow/minds v0.0.5
@@ -16,5 +16,6 @@ require (
 	github.com/dlclark/regexp2 v1.9.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
+	golang.org/x/time v0.9.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
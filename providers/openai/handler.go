@@ -9,18 +9,12 @@ import (
 // HandleMessage implements the ThreadHandler interface for the OpenAI provider.
 func (p *Provider) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler) (minds.ThreadContext, error) {
 
-	messages := minds.Messages{}
-	if p.options.systemPrompt != nil {
-		messages = append(messages, minds.Message{
-			Role:    minds.RoleSystem,
-			Content: *p.options.systemPrompt,
-		})
-	}
-
-	messages = append(messages, tc.Messages()...)
+	// The system prompt is added by GenerateContent.
+	messages := tc.Messages()
 
 	req := minds.Request{
 		Messages: messages,
+		Metadata: tc.Metadata(),
 	}
 
 	for i, m := range req.Messages {
@@ -30,10 +24,21 @@ func (p *Provider) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler
 		}
 	}
 
-	resp, err := p.GenerateContent(tc.Context(), req)
+	registry := p.options.registry
+	if r := minds.ThreadToolRegistry(tc); r != nil {
+		registry = r
+		req.Options.ToolRegistry = r
+	}
+	if schema := minds.ThreadResponseSchema(tc); schema != nil {
+		req.Options.ResponseSchema = schema
+	}
+
+	ctx, history := minds.TrackToolHistory(tc.Context(), registry)
+	resp, err := p.GenerateContent(ctx, req)
 	if err != nil {
 		return tc, fmt.Errorf("failed to generate content: %w", err)
 	}
+	tc = history.Record(tc)
 	// fmt.Printf("[%s] %s\n", p.options.name, resp.String())
 
 	msg := minds.Message{
@@ -44,6 +49,15 @@ func (p *Provider) HandleThread(tc minds.ThreadContext, next minds.ThreadHandler
 
 	tc.AppendMessages(msg)
 
+	// Record what kind of response this was so downstream handlers, such as
+	// handlers.ResponseSwitch, can branch on it.
+	responseType := minds.ResponseTypeText
+	if len(resp.ToolCalls()) > 0 {
+		responseType = minds.ResponseTypeToolCall
+	}
+	tc.SetKeyValue(minds.LastResponseTypeKey, responseType)
+	tc.SetKeyValue(minds.LastToolCallsKey, resp.ToolCalls())
+
 	if next != nil {
 		return next.HandleThread(tc, nil)
 	}
@@ -43,6 +43,177 @@ func TestHandleMessage(t *testing.T) {
 		is.Equal(len(messages), 2)
 		is.Equal(messages[1].Role, minds.RoleAssistant)
 		is.Equal(messages[1].Content, "Hello, world!")
+
+		is.Equal(result.Metadata()[minds.LastResponseTypeKey], minds.ResponseTypeText)
+		is.Equal(len(result.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall)), 0)
+	})
+
+	t.Run("records tool calls in metadata", func(t *testing.T) {
+		is := is.New(t)
+
+		tool, err := newMockTool()
+		is.NoErr(err)
+		registry := minds.NewToolRegistry()
+		is.NoErr(registry.Register(tool))
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(newMockToolCallResponse())
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL), WithToolRegistry(registry))
+		is.NoErr(err)
+
+		thread := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{
+				Role: minds.RoleUser, Content: "Double 3",
+			})
+
+		result, err := provider.HandleThread(thread, nil)
+		is.NoErr(err)
+		is.Equal(len(result.Messages()), 2) // the appended message is unchanged
+
+		is.Equal(result.Metadata()[minds.LastResponseTypeKey], minds.ResponseTypeToolCall)
+		calls := result.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall)
+		is.Equal(len(calls), 1)
+		is.Equal(calls[0].ID, "12345")
+		is.Equal(calls[0].Function.Name, "mock_function")
+	})
+
+	t.Run("records tool history when enabled", func(t *testing.T) {
+		is := is.New(t)
+
+		tool, err := newMockTool()
+		is.NoErr(err)
+		registry := minds.NewToolRegistry(minds.WithToolHistory())
+		is.NoErr(registry.Register(tool))
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(newMockToolCallResponse())
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL), WithToolRegistry(registry))
+		is.NoErr(err)
+
+		thread := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{
+				Role: minds.RoleUser, Content: "Double 3",
+			})
+
+		result, err := provider.HandleThread(thread, nil)
+		is.NoErr(err)
+		result, err = provider.HandleThread(result, nil)
+		is.NoErr(err)
+
+		history := minds.ToolHistory(result)
+		is.Equal(len(history), 2) // one entry per turn
+		is.Equal(history[0].ID, "12345")
+		is.Equal(history[0].Name, "mock_function")
+		is.NoErr(history[0].Err)
+		is.True(len(history[0].Result) > 0)
+	})
+
+	t.Run("passes thread metadata to tools", func(t *testing.T) {
+		is := is.New(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resp := newMockToolCallResponse()
+			resp.Choices[0].Message.ToolCalls[0].Function.Name = minds.MetadataToolName
+			resp.Choices[0].Message.ToolCalls[0].Function.Arguments = `{"key":"tier"}`
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resp)
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL), WithTool(minds.MetadataTool("tier")))
+		is.NoErr(err)
+
+		thread := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "What plan am I on?"}).
+			With(minds.SetKeyValue("tier", "gold"))
+
+		result, err := provider.HandleThread(thread, nil)
+		is.NoErr(err)
+
+		calls := result.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall)
+		is.Equal(string(calls[0].Function.Result), `"gold"`)
+	})
+
+	t.Run("uses the thread's tool registry", func(t *testing.T) {
+		is := is.New(t)
+
+		tool, err := newMockTool()
+		is.NoErr(err)
+		scoped := minds.NewToolRegistry()
+		is.NoErr(scoped.Register(tool))
+
+		var body struct {
+			Tools []struct {
+				Function struct {
+					Name string `json:"name"`
+				} `json:"function"`
+			} `json:"tools"`
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(newMockToolCallResponse())
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL)) // no tools of its own
+		is.NoErr(err)
+
+		thread := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Double 3"}).
+			With(minds.SetKeyValue(minds.ToolRegistryKey, scoped))
+
+		result, err := provider.HandleThread(thread, nil)
+		is.NoErr(err)
+		is.Equal(len(body.Tools), 1)
+		is.Equal(body.Tools[0].Function.Name, "mock_function")
+
+		calls := result.Metadata()[minds.LastToolCallsKey].([]minds.ToolCall)
+		is.True(!strings.HasPrefix(string(calls[0].Function.Result), "ERROR")) // executed with the scoped registry
+	})
+
+	t.Run("uses the thread's response schema", func(t *testing.T) {
+		is := is.New(t)
+
+		schema, err := minds.NewResponseSchema("answer", "An answer", struct {
+			Answer string `json:"answer"`
+		}{})
+		is.NoErr(err)
+
+		var body struct {
+			ResponseFormat struct {
+				Type       string `json:"type"`
+				JSONSchema struct {
+					Name string `json:"name"`
+				} `json:"json_schema"`
+			} `json:"response_format"`
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(newMockTextResponse())
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+
+		thread := minds.NewThreadContext(context.Background()).
+			WithMessages(minds.Message{Role: minds.RoleUser, Content: "Hi"}).
+			With(minds.SetKeyValue(minds.ResponseSchemaKey, schema))
+
+		_, err = provider.HandleThread(thread, nil)
+		is.NoErr(err)
+		is.Equal(body.ResponseFormat.Type, "json_schema")
+		is.Equal(body.ResponseFormat.JSONSchema.Name, "answer")
 	})
 
 	t.Run("returns error on failure", func(t *testing.T) {
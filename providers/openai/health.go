@@ -0,0 +1,63 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/chriscow/minds"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Ping checks that the API is reachable and the API key is valid by listing
+// the available models, which does not consume tokens. A failure is returned
+// as a *minds.PingError whose Kind is minds.ErrUnauthorized or
+// minds.ErrUnavailable when the cause is known.
+//
+// Example:
+//
+//	if err := llm.Ping(ctx); errors.Is(err, minds.ErrUnauthorized) {
+//	    log.Fatal("OPENAI_API_KEY is invalid")
+//	}
+func (p *Provider) Ping(ctx context.Context) error {
+	ctx, done, err := p.requests.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if _, err := p.client.ListModels(ctx); err != nil {
+		return pingError(err)
+	}
+
+	return nil
+}
+
+// pingError classifies a failed request by its HTTP status or, if no
+// response was received, as a connectivity failure.
+func pingError(err error) error {
+	status := 0
+
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	case errors.As(err, &netErr):
+		return &minds.PingError{Kind: minds.ErrUnavailable, Err: err}
+	}
+
+	var kind error
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		kind = minds.ErrUnauthorized
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		kind = minds.ErrUnavailable
+	}
+
+	return &minds.PingError{Kind: kind, Err: err}
+}
@@ -0,0 +1,70 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/matryer/is"
+)
+
+func TestProvider_Ping(t *testing.T) {
+	serve := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+	}
+
+	t.Run("succeeds when models can be listed", func(t *testing.T) {
+		is := is.New(t)
+		server := serve(http.StatusOK, `{"object": "list", "data": []}`)
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+		is.NoErr(provider.Ping(context.Background()))
+	})
+
+	t.Run("reports an invalid API key", func(t *testing.T) {
+		is := is.New(t)
+		server := serve(http.StatusUnauthorized, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error", "code": "invalid_api_key"}}`)
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+
+		err = provider.Ping(context.Background())
+		is.True(errors.Is(err, minds.ErrUnauthorized))
+		is.True(!errors.Is(err, minds.ErrUnavailable))
+	})
+
+	t.Run("reports a server outage as unavailable", func(t *testing.T) {
+		is := is.New(t)
+		server := serve(http.StatusServiceUnavailable, `{"error": {"message": "overloaded", "type": "server_error"}}`)
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+		is.True(errors.Is(provider.Ping(context.Background()), minds.ErrUnavailable))
+	})
+
+	t.Run("reports an unreachable server as unavailable", func(t *testing.T) {
+		is := is.New(t)
+		server := serve(http.StatusOK, "")
+		server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+
+		err = provider.Ping(context.Background())
+		is.True(errors.Is(err, minds.ErrUnavailable))
+
+		var pingErr *minds.PingError
+		is.True(errors.As(err, &pingErr))
+	})
+}
@@ -18,7 +18,13 @@ type Options struct {
 	tools           []minds.Tool
 	registry        minds.ToolRegistry
 	systemPrompt    *string
+	systemMerge     SystemMergeStrategy
 	httpClient      *http.Client
+	proxyURL        string
+	retryMax        *int
+	headers         http.Header
+	customRoles     []minds.Role
+	nativePrefill   bool
 }
 
 type Option func(*Options)
@@ -47,6 +53,8 @@ func WithModel(model string) Option {
 	}
 }
 
+// WithTemperature sets the sampling temperature. It is ignored for reasoning
+// models such as o3-mini and o4-mini, which only support the default.
 func WithTemperature(temperature float32) Option {
 	return func(o *Options) {
 		o.temperature = &temperature
@@ -91,17 +99,120 @@ func WithSystemPrompt(prompt string) Option {
 	}
 }
 
-func WithClient(client *http.Client) Option {
+// SystemMergeStrategy controls how the system prompt set with WithSystemPrompt
+// is combined with system messages at the start of a request.
+type SystemMergeStrategy int
+
+const (
+	// SystemConcat joins the system prompt and the request's leading system
+	// messages into a single system message, in that order. It is the
+	// default, since some OpenAI-compatible endpoints accept only one system
+	// message.
+	SystemConcat SystemMergeStrategy = iota
+	// SystemPrepend sends the system prompt as a separate system message
+	// before the request's messages.
+	SystemPrepend
+	// SystemReplace drops the system prompt when the request starts with a
+	// system message of its own.
+	SystemReplace
+)
+
+// WithSystemMergeStrategy sets how the system prompt is combined with the
+// request's own system messages. System messages later in the request, such
+// as instructions added mid-conversation, are left where they are.
+func WithSystemMergeStrategy(strategy SystemMergeStrategy) Option {
+	return func(o *Options) {
+		o.systemMerge = strategy
+	}
+}
+
+// WithCustomRoles allows messages with the given roles to be sent to the API
+// verbatim. Use it with OpenAI-compatible servers that accept roles beyond the
+// canonical minds roles; any other unknown role is rejected with
+// minds.ErrUnsupportedRole.
+func WithCustomRoles(roles ...minds.Role) Option {
+	return func(o *Options) {
+		o.customRoles = append(o.customRoles, roles...)
+	}
+}
+
+// WithHeader adds a header to every request sent to the API. It is useful for
+// OpenAI-compatible gateways that require extra headers, such as OpenRouter's
+// HTTP-Referer and X-Title. The header is added by wrapping the transport of
+// the HTTP client, so it also applies to a client set with WithHTTPClient.
+func WithHeader(key, value string) Option {
+	return func(o *Options) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+		o.headers.Set(key, value)
+	}
+}
+
+// WithHeaders adds each of the given headers to every request sent to the
+// API. See WithHeader.
+func WithHeaders(headers map[string]string) Option {
+	return func(o *Options) {
+		for key, value := range headers {
+			WithHeader(key, value)(o)
+		}
+	}
+}
+
+// WithNativePrefill sends the prefill of requests made with minds.WithPrefill
+// as a trailing assistant message for the model to continue. Enable it for
+// OpenAI-compatible APIs that support prefilling, such as OpenRouter. The
+// OpenAI API does not, so by default the prefill is emulated with
+// minds.EmulatePrefill.
+func WithNativePrefill(enabled bool) Option {
+	return func(o *Options) {
+		o.nativePrefill = enabled
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to call the API, for full control
+// over TLS, proxies and timeouts.
+func WithHTTPClient(client *http.Client) Option {
 	return func(o *Options) {
 		o.httpClient = client
 	}
 }
 
+// WithClient sets the HTTP client used to call the API. It is the same as
+// WithHTTPClient.
+func WithClient(client *http.Client) Option {
+	return WithHTTPClient(client)
+}
+
+// WithProxy sends requests to the API through the HTTP proxy at proxyURL,
+// such as "http://proxy.corp.example:3128". It applies to the client set with
+// WithHTTPClient too, as long as its transport is an *http.Transport;
+// NewProvider returns an error otherwise, or if proxyURL is invalid. Retries
+// set with WithRetry go through the proxy as well.
+func WithProxy(proxyURL string) Option {
+	return func(o *Options) {
+		o.proxyURL = proxyURL
+	}
+}
+
+// WithRetry retries failed requests up to max times with a go-retryablehttp
+// client. Each attempt is sent with the client set with WithHTTPClient, or a
+// default client, including any proxy set with WithProxy.
 func WithRetry(max int) Option {
 	return func(o *Options) {
-		client := retryablehttp.NewClient()
-		client.RetryMax = max
+		o.retryMax = &max
+	}
+}
 
-		o.httpClient = client.StandardClient()
+// withRetry returns a client that retries failed requests up to max times,
+// sending each attempt with client. A nil client uses the retrying client's
+// default.
+func withRetry(client *http.Client, max int) *http.Client {
+	retry := retryablehttp.NewClient()
+	retry.RetryMax = max
+	if client != nil {
+		retry.HTTPClient = client
 	}
+
+	return retry.StandardClient()
 }
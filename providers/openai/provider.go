@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/internal/inflight"
+	"github.com/chriscow/minds/internal/transport"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -16,8 +20,9 @@ const (
 )
 
 type Provider struct {
-	client  *openai.Client
-	options Options
+	client   *openai.Client
+	options  Options
+	requests inflight.Tracker
 }
 
 // NewProvider creates a new OpenAI provider. If no model name is provided, the
@@ -31,9 +36,20 @@ func NewProvider(opts ...Option) (*Provider, error) {
 
 	config := openai.DefaultConfig(options.apiKey)
 
-	if options.httpClient != nil {
-		config.HTTPClient = options.httpClient
+	httpClient := options.httpClient
+	if options.proxyURL != "" {
+		httpClient, err = transport.WithProxy(httpClient, options.proxyURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if options.retryMax != nil {
+		httpClient = withRetry(httpClient, *options.retryMax)
 	}
+	if len(options.headers) > 0 {
+		httpClient = transport.WithHeaders(httpClient, options.headers)
+	}
+	config.HTTPClient = withRetryAfter(httpClient)
 
 	if options.baseURL != "" {
 		config.BaseURL = options.baseURL
@@ -53,8 +69,27 @@ func (p *Provider) ModelName() string {
 	return p.options.modelName
 }
 
+// Close stops the provider immediately. In-flight requests are canceled and
+// new requests fail with ErrShutdown. Use Shutdown to let in-flight requests
+// finish first.
 func (p *Provider) Close() {
-	// nothing to do
+	p.requests.Close()
+}
+
+// Shutdown stops the provider gracefully. New requests fail with ErrShutdown
+// while in-flight requests are allowed to finish. If ctx is done before they
+// complete, the remaining requests are canceled as with Close and ctx.Err() is
+// returned.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := provider.Shutdown(ctx); err != nil {
+//	    log.Printf("forced shutdown: %v", err)
+//	}
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.requests.Shutdown(ctx)
 }
 
 func (p *Provider) GenerateContent(ctx context.Context, req minds.Request) (minds.Response, error) {
@@ -62,14 +97,21 @@ func (p *Provider) GenerateContent(ctx context.Context, req minds.Request) (mind
 		return nil, ctx.Err()
 	}
 
+	ctx, done, err := p.requests.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	request, err := p.prepareRequest(req)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, retryAfter := recordRetryAfter(ctx)
 	raw, err := p.client.CreateChatCompletion(ctx, request)
 	if err != nil {
-		return nil, err
+		return nil, apiError(err, retryAfter.get())
 	}
 
 	calls := make([]minds.ToolCall, 0)
@@ -87,12 +129,48 @@ func (p *Provider) GenerateContent(ctx context.Context, req minds.Request) (mind
 		})
 	}
 
-	calls, err = minds.HandleFunctionCalls(ctx, calls, p.options.registry)
+	calls, err = minds.HandleFunctionCalls(minds.WithThreadMetadata(ctx, req.Metadata), calls, p.toolRegistry(req))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := NewResponse(raw, calls)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewResponse(raw, calls)
+	_, prefill := minds.SplitPrefill(req)
+	return minds.PrefillResponse(resp, prefill), nil
+}
+
+// withSystemPrompt returns messages with the provider's system prompt merged
+// in according to its SystemMergeStrategy.
+func (p *Provider) withSystemPrompt(messages minds.Messages) minds.Messages {
+	if p.options.systemPrompt == nil {
+		return messages
+	}
+
+	system := minds.Message{Role: minds.RoleSystem, Content: *p.options.systemPrompt}
+	leading := 0
+	for leading < len(messages) && messages[leading].Role == minds.RoleSystem {
+		leading++
+	}
+
+	switch {
+	case leading == 0 || p.options.systemMerge == SystemPrepend:
+		return append(minds.Messages{system}, messages...)
+
+	case p.options.systemMerge == SystemReplace:
+		return messages
+
+	default:
+		parts := []string{system.Content}
+		for _, msg := range messages[:leading] {
+			parts = append(parts, msg.Content)
+		}
+		system.Content = strings.Join(parts, "\n\n")
+		return append(minds.Messages{system}, messages[leading:]...)
+	}
 }
 
 func setupOptions(opts ...Option) (Options, error) {
@@ -125,10 +203,44 @@ func setupOptions(opts ...Option) (Options, error) {
 	return options, nil
 }
 
+// role maps a minds role to the role sent to the OpenAI API.
+func (p *Provider) role(msg minds.Message) (string, error) {
+	switch msg.Role {
+	case "":
+		return openai.ChatMessageRoleUser, nil
+	case minds.RoleUser, minds.RoleSystem, minds.RoleDeveloper, minds.RoleAssistant, minds.RoleTool:
+		return string(msg.Role), nil
+	case minds.RoleModel, minds.RoleAI:
+		return openai.ChatMessageRoleAssistant, nil
+	case minds.RoleFunction:
+		if msg.ToolCallID != "" {
+			return openai.ChatMessageRoleTool, nil
+		}
+		return openai.ChatMessageRoleFunction, nil
+	}
+
+	for _, role := range p.options.customRoles {
+		if msg.Role == role {
+			return string(msg.Role), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", minds.ErrUnsupportedRole, msg.Role)
+}
+
+// toolRegistry returns the registry to use for req: the request's own if it
+// sets one, otherwise the provider's.
+func (p *Provider) toolRegistry(req minds.Request) minds.ToolRegistry {
+	if req.Options.ToolRegistry != nil {
+		return req.Options.ToolRegistry
+	}
+	return p.options.registry
+}
+
 func (p *Provider) prepareRequest(req minds.Request) (openai.ChatCompletionRequest, error) {
 	// Convert functions to OpenAI format
 	tools := make([]openai.Tool, 0)
-	for _, f := range p.options.registry.List() {
+	for _, f := range p.toolRegistry(req).List() {
 		schema := f.Parameters()
 		tools = append(tools, openai.Tool{
 			Type: "function",
@@ -148,6 +260,7 @@ func (p *Provider) prepareRequest(req minds.Request) (openai.ChatCompletionReque
 
 	request := openai.ChatCompletionRequest{
 		Model: modelName,
+		User:  req.Options.User,
 	}
 
 	if p.options.temperature != nil {
@@ -157,6 +270,12 @@ func (p *Provider) prepareRequest(req minds.Request) (openai.ChatCompletionReque
 		request.Temperature = *req.Options.Temperature
 	}
 
+	// Reasoning models reject any temperature other than the default, so
+	// leave it unset rather than fail the request.
+	if isReasoningModel(modelName) {
+		request.Temperature = 0
+	}
+
 	if p.options.maxOutputTokens != nil {
 		request.MaxCompletionTokens = *p.options.maxOutputTokens
 	}
@@ -164,6 +283,17 @@ func (p *Provider) prepareRequest(req minds.Request) (openai.ChatCompletionReque
 		request.MaxCompletionTokens = *req.Options.MaxOutputTokens
 	}
 
+	if req.Options.Candidates > 1 {
+		request.N = req.Options.Candidates
+	}
+
+	if len(req.Options.LogitBias) > 0 {
+		request.LogitBias = make(map[string]int, len(req.Options.LogitBias))
+		for id, bias := range req.Options.LogitBias {
+			request.LogitBias[strconv.Itoa(id)] = bias
+		}
+	}
+
 	var responseSchema *minds.ResponseSchema
 	if p.options.schema != nil {
 		responseSchema = p.options.schema
@@ -182,38 +312,31 @@ func (p *Provider) prepareRequest(req minds.Request) (openai.ChatCompletionReque
 				Strict:      true,
 			},
 		}
+	} else if req.Options.JSONMode {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
 	} else {
 		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
 			Type: openai.ChatCompletionResponseFormatTypeText,
 		}
 	}
 
-	if p.options.systemPrompt != nil {
-		request.Messages = append([]openai.ChatCompletionMessage{
-			{
-				Role:    string(minds.RoleSystem),
-				Content: *p.options.systemPrompt,
-			},
-		}, request.Messages...)
+	messages, prefill := minds.SplitPrefill(req)
+	if prefill == "" || p.options.nativePrefill {
+		messages = req.Messages
+	} else {
+		messages = minds.EmulatePrefill(messages, prefill)
 	}
 
-	for i, msg := range req.Messages {
-		if msg.Role == "" {
-			req.Messages[i].Role = minds.RoleUser
-		}
-
-		if msg.Role == minds.RoleModel {
-			req.Messages[i].Role = minds.RoleAssistant
+	for i, msg := range p.withSystemPrompt(messages) {
+		role, err := p.role(msg)
+		if err != nil {
+			return request, fmt.Errorf("message %d: %w", i, err)
 		}
 
-		switch msg.Role {
-		case minds.RoleModel:
-			request.Messages[i].Role = string(minds.RoleAssistant)
-		}
-
-		calls := make([]openai.ToolCall, len(msg.ToolCalls))
+		calls := make([]openai.ToolCall, 0, len(msg.ToolCalls))
 		for _, call := range msg.ToolCalls {
-
 			calls = append(calls, openai.ToolCall{
 				ID:       call.ID,
 				Type:     "function",
@@ -222,10 +345,11 @@ func (p *Provider) prepareRequest(req minds.Request) (openai.ChatCompletionReque
 		}
 
 		request.Messages = append(request.Messages, openai.ChatCompletionMessage{
-			Role:      string(msg.Role),
-			Name:      msg.Name,
-			Content:   msg.Content,
-			ToolCalls: calls,
+			Role:       role,
+			Name:       msg.Name,
+			Content:    msg.Content,
+			ToolCalls:  calls,
+			ToolCallID: msg.ToolCallID,
 		})
 	}
 
@@ -240,3 +364,34 @@ func (p *Provider) prepareRequest(req minds.Request) (openai.ChatCompletionReque
 
 	return request, nil
 }
+
+// isReasoningModel reports whether model is one of OpenAI's reasoning models,
+// the o-series (o1, o3-mini, o4-mini, ...) and gpt-5, which only support the
+// default temperature. A routing prefix such as "openai/" is ignored.
+func isReasoningModel(model string) bool {
+	if i := strings.LastIndexByte(model, '/'); i >= 0 {
+		model = model[i+1:]
+	}
+
+	if len(model) >= 2 && model[0] == 'o' && model[1] >= '0' && model[1] <= '9' {
+		return true
+	}
+
+	return strings.HasPrefix(model, "gpt-5") && !strings.HasPrefix(model, "gpt-5-chat")
+}
+
+// ListModels returns the models available to the API key. The OpenAI models
+// endpoint reports only model IDs, so the other ModelInfo fields are empty.
+func (p *Provider) ListModels(ctx context.Context) ([]minds.ModelInfo, error) {
+	list, err := p.client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	models := make([]minds.ModelInfo, 0, len(list.Models))
+	for _, model := range list.Models {
+		models = append(models, minds.ModelInfo{ID: model.ID})
+	}
+
+	return models, nil
+}
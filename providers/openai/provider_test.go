@@ -3,8 +3,11 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/chriscow/minds"
@@ -92,6 +95,121 @@ func TestProvider_GenerateContent(t *testing.T) {
 	is.Equal(resp.String(), "Hello, world!") // Ensure the mock response matches
 }
 
+func TestProvider_GenerateContent_Headers(t *testing.T) {
+	is := is.New(t)
+
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newMockTextResponse())
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(
+		WithBaseURL(server.URL),
+		WithHeader("HTTP-Referer", "https://example.com"),
+		WithHeaders(map[string]string{"X-Title": "minds"}),
+		WithClient(&http.Client{}),
+	)
+	is.NoErr(err)
+
+	_, err = provider.GenerateContent(context.Background(), minds.Request{
+		Messages: minds.Messages{{Role: minds.RoleUser, Content: "Hello!"}},
+	})
+	is.NoErr(err)
+	is.Equal(got.Get("HTTP-Referer"), "https://example.com")
+	is.Equal(got.Get("X-Title"), "minds")
+	is.True(got.Get("Authorization") != "") // default headers are preserved
+}
+
+func TestProvider_GenerateContent_Proxy(t *testing.T) {
+	is := is.New(t)
+
+	// The proxy answers for the API, which is never contacted directly.
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newMockTextResponse())
+	}))
+	defer proxy.Close()
+
+	for _, opts := range [][]Option{
+		{WithProxy(proxy.URL)},
+		{WithHTTPClient(&http.Client{Transport: &http.Transport{}}), WithProxy(proxy.URL)},
+		{WithRetry(1), WithProxy(proxy.URL)},
+		{WithProxy(proxy.URL), WithRetry(1), WithHTTPClient(&http.Client{})},
+	} {
+		proxied = ""
+		provider, err := NewProvider(append(opts, WithBaseURL("http://api.openai.invalid/v1"))...)
+		is.NoErr(err)
+
+		_, err = provider.GenerateContent(context.Background(), minds.NewRequest(minds.Messages{{Role: minds.RoleUser, Content: "Hello!"}}))
+		is.NoErr(err)
+		is.Equal(proxied, "http://api.openai.invalid/v1/chat/completions")
+	}
+
+	_, err := NewProvider(WithHTTPClient(&http.Client{Transport: http.NewFileTransport(http.Dir("."))}), WithProxy("http://proxy.invalid"))
+	is.True(err != nil) // only an *http.Transport can be given a proxy
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/models")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object": "list", "data": [{"id": "gpt-4o-mini", "object": "model"}, {"id": "gpt-4o", "object": "model"}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(WithBaseURL(server.URL))
+	is.NoErr(err)
+
+	var lister minds.ModelLister = provider
+	models, err := lister.ListModels(context.Background())
+	is.NoErr(err)
+	is.Equal(models, []minds.ModelInfo{{ID: "gpt-4o-mini"}, {ID: "gpt-4o"}})
+}
+
+func TestProvider_GenerateContent_Usage(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := newMockTextResponse()
+		resp.Usage = openai.Usage{
+			PromptTokens:        2048,
+			CompletionTokens:    12,
+			TotalTokens:         2060,
+			PromptTokensDetails: &openai.PromptTokensDetails{CachedTokens: 1920},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(WithBaseURL(server.URL))
+	is.NoErr(err)
+
+	req := minds.NewRequest(minds.Messages{
+		{Role: minds.RoleSystem, Content: "A long, fixed system prompt."},
+		{Role: minds.RoleUser, Content: "Hello!"},
+	}, minds.WithCacheControl(minds.CacheSystem)) // a hint; OpenAI caches automatically
+
+	resp, err := provider.GenerateContent(context.Background(), req)
+	is.NoErr(err)
+
+	reporter, ok := resp.(minds.UsageReporter)
+	is.True(ok) // OpenAI responses report usage
+	is.Equal(reporter.Usage(), minds.Usage{
+		PromptTokens:     2048,
+		CompletionTokens: 12,
+		TotalTokens:      2060,
+		CachedTokens:     1920,
+	})
+}
+
 func TestProvider_HandleThread(t *testing.T) {
 	is := is.New(t)
 
@@ -173,3 +291,268 @@ func TestProvider_GenerateContent_WithToolRegistry(t *testing.T) {
 	is.NoErr(json.Unmarshal(toolCalls[0].Function.Result, &result)) // Should be able to parse the result
 	is.Equal(result["result"], 6)                                   // Ensure the mock function was called correctly
 }
+
+func TestProvider_PrepareRequest_Roles(t *testing.T) {
+	is := is.New(t)
+
+	provider, err := NewProvider(WithCustomRoles("critic"))
+	is.NoErr(err)
+
+	request, err := provider.prepareRequest(minds.Request{Messages: minds.Messages{
+		{Content: "What's 2+2?"},
+		{Role: minds.RoleModel, Content: "Let me check.", ToolCalls: []minds.ToolCall{{
+			ID:       "call_1",
+			Function: minds.FunctionCall{Name: "add", Parameters: []byte(`{"a":2,"b":2}`)},
+		}}},
+		{Role: minds.RoleFunction, Content: "4", ToolCallID: "call_1"},
+		{Role: "critic", Content: "Looks right."},
+	}})
+	is.NoErr(err)
+
+	is.Equal(len(request.Messages), 4)
+	is.Equal(request.Messages[0].Role, openai.ChatMessageRoleUser)
+	is.Equal(request.Messages[1].Role, openai.ChatMessageRoleAssistant)
+	is.Equal(len(request.Messages[1].ToolCalls), 1)
+	is.Equal(request.Messages[1].ToolCalls[0].ID, "call_1")
+	is.Equal(request.Messages[2].Role, openai.ChatMessageRoleTool)
+	is.Equal(request.Messages[2].ToolCallID, "call_1")
+	is.Equal(request.Messages[3].Role, "critic")
+
+	_, err = provider.prepareRequest(minds.Request{Messages: minds.Messages{{Role: "narrator", Content: "Meanwhile..."}}})
+	is.True(errors.Is(err, minds.ErrUnsupportedRole))
+}
+
+func TestProvider_PrepareRequest_ReasoningModels(t *testing.T) {
+	is := is.New(t)
+
+	provider, err := NewProvider(WithTemperature(0.2), WithMaxOutputTokens(500))
+	is.NoErr(err)
+
+	messages := minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}
+	for _, model := range []string{"o1", "o3-mini", "o4-mini", "openai/o4-mini-high", "gpt-5"} {
+		request, err := provider.prepareRequest(minds.NewRequest(messages, minds.WithModel(model)))
+		is.NoErr(err)
+
+		body, err := json.Marshal(request)
+		is.NoErr(err)
+		is.True(!strings.Contains(string(body), `"temperature"`)) // temperature is omitted
+		is.True(strings.Contains(string(body), `"max_completion_tokens":500`))
+	}
+
+	request, err := provider.prepareRequest(minds.NewRequest(messages, minds.WithModel("gpt-4o"), minds.WithTemperature(0.7)))
+	is.NoErr(err)
+	is.Equal(request.Temperature, float32(0.7))
+}
+
+func TestProvider_PrepareRequest_LogitBias(t *testing.T) {
+	is := is.New(t)
+
+	provider, err := NewProvider()
+	is.NoErr(err)
+
+	bias, err := LogitBiasFor("gpt-4o", map[string]int{"Yes": 100, " banned phrase": -100})
+	is.NoErr(err)
+	is.True(len(bias) >= 3)
+
+	request, err := provider.prepareRequest(minds.NewRequest(
+		minds.Messages{{Role: minds.RoleUser, Content: "Is the sky blue?"}},
+		minds.WithLogitBias(bias),
+	))
+	is.NoErr(err)
+
+	is.Equal(len(request.LogitBias), len(bias))
+	for id, value := range bias {
+		is.Equal(request.LogitBias[strconv.Itoa(id)], value)
+	}
+
+	request, err = provider.prepareRequest(minds.NewRequest(minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}))
+	is.NoErr(err)
+	is.Equal(request.LogitBias, nil) // not sent unless set
+}
+
+func TestProvider_PrepareRequest_User(t *testing.T) {
+	is := is.New(t)
+
+	provider, err := NewProvider()
+	is.NoErr(err)
+
+	messages := minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}
+	request, err := provider.prepareRequest(minds.NewRequest(messages, minds.WithUser("user-42")))
+	is.NoErr(err)
+	is.Equal(request.User, "user-42")
+
+	request, err = provider.prepareRequest(minds.NewRequest(messages))
+	is.NoErr(err)
+	is.Equal(request.User, "")
+}
+
+func TestProvider_PrepareRequest_JSONMode(t *testing.T) {
+	is := is.New(t)
+
+	provider, err := NewProvider()
+	is.NoErr(err)
+
+	messages := minds.Messages{{Role: minds.RoleUser, Content: "Describe Go as JSON."}}
+	request, err := provider.prepareRequest(minds.NewRequest(messages, minds.WithJSONMode()))
+	is.NoErr(err)
+	is.Equal(request.ResponseFormat.Type, openai.ChatCompletionResponseFormatTypeJSONObject)
+	is.True(request.ResponseFormat.JSONSchema == nil)
+
+	schema, err := minds.NewResponseSchema("answer", "An answer", struct {
+		Answer string `json:"answer"`
+	}{})
+	is.NoErr(err)
+	request, err = provider.prepareRequest(minds.NewRequest(messages, minds.WithJSONMode(), minds.WithResponseSchema(*schema)))
+	is.NoErr(err)
+	is.Equal(request.ResponseFormat.Type, openai.ChatCompletionResponseFormatTypeJSONSchema) // the schema wins
+}
+
+func TestProvider_GenerateContent_Candidates(t *testing.T) {
+	is := is.New(t)
+
+	var body struct {
+		N int `json:"n"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+
+		resp := newMockTextResponse()
+		second := resp.Choices[0]
+		second.Index = 1
+		second.Message.Content = "Hi there!"
+		resp.Choices = append(resp.Choices, second)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(WithBaseURL(server.URL))
+	is.NoErr(err)
+
+	req := minds.NewRequest(minds.Messages{{Role: minds.RoleUser, Content: "Hello!"}}, minds.WithCandidates(2))
+	resp, err := provider.GenerateContent(context.Background(), req)
+	is.NoErr(err)
+	is.Equal(body.N, 2)
+	is.Equal(resp.String(), "Hello, world!") // the first candidate
+	is.Equal(minds.Candidates(resp), []string{"Hello, world!", "Hi there!"})
+}
+
+func TestProvider_Prefill(t *testing.T) {
+	messages := minds.Messages{
+		{Role: minds.RoleUser, Content: "List three colors as JSON."},
+		{Role: minds.RoleAssistant, Content: "Hello,"},
+	}
+
+	var sent []openai.ChatCompletionMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = body.Messages
+
+		resp := newMockTextResponse()
+		resp.Choices[0].Message.Content = " world!"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	t.Run("emulated by default", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+
+		resp, err := provider.GenerateContent(context.Background(), minds.NewRequest(messages, minds.WithPrefill()))
+		is.NoErr(err)
+		is.Equal(resp.String(), "Hello, world!")
+
+		is.Equal(len(sent), 2)
+		is.Equal(sent[1].Role, openai.ChatMessageRoleSystem)
+		is.True(strings.HasSuffix(sent[1].Content, "Hello,"))
+	})
+
+	t.Run("native", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(WithBaseURL(server.URL), WithNativePrefill(true))
+		is.NoErr(err)
+
+		resp, err := provider.GenerateContent(context.Background(), minds.NewRequest(messages, minds.WithPrefill()))
+		is.NoErr(err)
+		is.Equal(resp.String(), "Hello, world!")
+
+		is.Equal(len(sent), 2)
+		is.Equal(sent[1].Role, openai.ChatMessageRoleAssistant)
+		is.Equal(sent[1].Content, "Hello,")
+	})
+
+	t.Run("off without the option", func(t *testing.T) {
+		is := is.New(t)
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+
+		resp, err := provider.GenerateContent(context.Background(), minds.NewRequest(messages))
+		is.NoErr(err)
+		is.Equal(resp.String(), " world!")
+		is.Equal(sent[1].Role, openai.ChatMessageRoleAssistant)
+	})
+}
+
+func TestProvider_PrepareRequest_SystemMerge(t *testing.T) {
+	messages := minds.Messages{
+		{Role: minds.RoleSystem, Content: "Answer in French."},
+		{Role: minds.RoleUser, Content: "Hi"},
+		{Role: minds.RoleSystem, Content: "Be brief."},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		messages minds.Messages
+		want     []string // role: content
+	}{
+		{
+			name:     "concat by default",
+			messages: messages,
+			want:     []string{"system: You are helpful.\n\nAnswer in French.", "user: Hi", "system: Be brief."},
+		},
+		{
+			name:     "prepend",
+			opts:     []Option{WithSystemMergeStrategy(SystemPrepend)},
+			messages: messages,
+			want:     []string{"system: You are helpful.", "system: Answer in French.", "user: Hi", "system: Be brief."},
+		},
+		{
+			name:     "replace",
+			opts:     []Option{WithSystemMergeStrategy(SystemReplace)},
+			messages: messages,
+			want:     []string{"system: Answer in French.", "user: Hi", "system: Be brief."},
+		},
+		{
+			name:     "no request system message",
+			opts:     []Option{WithSystemMergeStrategy(SystemReplace)},
+			messages: messages[1:],
+			want:     []string{"system: You are helpful.", "user: Hi", "system: Be brief."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			provider, err := NewProvider(append([]Option{WithSystemPrompt("You are helpful.")}, tt.opts...)...)
+			is.NoErr(err)
+
+			request, err := provider.prepareRequest(minds.NewRequest(tt.messages))
+			is.NoErr(err)
+
+			got := make([]string, len(request.Messages))
+			for i, msg := range request.Messages {
+				got[i] = msg.Role + ": " + msg.Content
+			}
+			is.Equal(got, tt.want)
+		})
+	}
+}
@@ -19,10 +19,6 @@ func NewResponse(resp openai.ChatCompletionResponse, calls []minds.ToolCall) (*R
 		return nil, fmt.Errorf("no response from OpenAI")
 	}
 
-	if len(resp.Choices) > 1 {
-		return nil, fmt.Errorf("multiple choices in OpenAI response not supported")
-	}
-
 	if resp.Choices[0].FinishReason != openai.FinishReasonStop && resp.Choices[0].FinishReason != openai.FinishReasonToolCalls {
 		return nil, errors.New(string(resp.Choices[0].FinishReason))
 	}
@@ -67,6 +63,32 @@ func (r Response) String() string {
 	return r.raw.Choices[0].Message.Content
 }
 
+// Candidates returns the content of every choice, requested with
+// minds.WithCandidates. Tool calls are only read from the first.
+func (r Response) Candidates() []string {
+	candidates := make([]string, len(r.raw.Choices))
+	for i, choice := range r.raw.Choices {
+		candidates[i] = choice.Message.Content
+	}
+	return candidates
+}
+
 func (r Response) ToolCalls() []minds.ToolCall {
 	return r.calls
 }
+
+// Usage returns the token counts reported by OpenAI. CachedTokens is set when
+// part of the prompt was served from OpenAI's automatic prompt cache.
+func (r Response) Usage() minds.Usage {
+	usage := minds.Usage{
+		PromptTokens:     r.raw.Usage.PromptTokens,
+		CompletionTokens: r.raw.Usage.CompletionTokens,
+		TotalTokens:      r.raw.Usage.TotalTokens,
+	}
+
+	if r.raw.Usage.PromptTokensDetails != nil {
+		usage.CachedTokens = r.raw.Usage.PromptTokensDetails.CachedTokens
+	}
+
+	return usage
+}
@@ -0,0 +1,83 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chriscow/minds"
+	"github.com/matryer/is"
+)
+
+func TestProvider_Shutdown(t *testing.T) {
+	req := minds.Request{Messages: minds.Messages{{Role: minds.RoleUser, Content: "Hello!"}}}
+
+	t.Run("waits for in-flight requests", func(t *testing.T) {
+		is := is.New(t)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(newMockTextResponse())
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := provider.GenerateContent(context.Background(), req)
+			result <- err
+		}()
+		<-started
+
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- provider.Shutdown(context.Background()) }()
+
+		// New requests are rejected while draining.
+		time.Sleep(10 * time.Millisecond)
+		_, err = provider.GenerateContent(context.Background(), req)
+		is.True(errors.Is(err, ErrShutdown))
+
+		close(release)
+		is.NoErr(<-result)
+		is.NoErr(<-shutdown)
+	})
+
+	t.Run("cancels in-flight requests at the deadline", func(t *testing.T) {
+		is := is.New(t)
+		started := make(chan struct{})
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		provider, err := NewProvider(WithBaseURL(server.URL))
+		is.NoErr(err)
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := provider.GenerateContent(context.Background(), req)
+			result <- err
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		is.True(errors.Is(provider.Shutdown(ctx), context.DeadlineExceeded))
+		is.True(errors.Is(<-result, context.Canceled))
+	})
+}
@@ -28,7 +28,7 @@ func TestSummarize(t *testing.T) {
 
 	systemMsg := "you are a helpful summerization assistant"
 
-	summarizer := handlers.Summarize(llm, systemMsg)
+	summarizer := handlers.NewSummarizer(llm, systemMsg)
 	tc := minds.NewThreadContext(context.Background()).WithMessages(minds.Messages{
 		{Role: minds.RoleSystem, Content: systemMsg},
 		{Role: minds.RoleUser, Content: "What is the meaning of life?"},
@@ -1,6 +1,8 @@
 package openai
 
 import (
+	"fmt"
+
 	"github.com/chriscow/minds"
 
 	"github.com/tiktoken-go/tokenizer"
@@ -28,3 +30,31 @@ func (t Tokenizer) CountTokens(text string) (int, error) {
 
 	return len(ids), nil
 }
+
+// LogitBiasFor converts a bias per string into the bias per token id expected
+// by minds.WithLogitBias, using the tokenizer for modelName. Every token of a
+// string gets its bias. Leading spaces matter: "Yes" and " Yes" are different
+// tokens, so include both forms when the model may produce either.
+//
+// Example:
+//
+//	bias, err := openai.LogitBiasFor("gpt-4o", map[string]int{"Yes": 100, "No": 100})
+func LogitBiasFor(modelName string, bias map[string]int) (map[int]int, error) {
+	codec, err := tokenizer.ForModel(tokenizer.Model(modelName))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[int]int, len(bias))
+	for text, value := range bias {
+		tokens, _, err := codec.Encode(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %q: %w", text, err)
+		}
+		for _, token := range tokens {
+			ids[int(token)] = value
+		}
+	}
+
+	return ids, nil
+}
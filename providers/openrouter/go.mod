@@ -0,0 +1,26 @@
+module github.com/chriscow/minds/providers/openrouter
+
+go 1.18
+
+replace github.com/chriscow/minds => ../../
+
+// The openai provider is resolved through the replace above until it is
+// tagged. Tag providers/openai first and require that version here before
+// tagging this module; see Releasing in CONTRIBUTING.md.
+replace github.com/chriscow/minds/providers/openai => ../openai
+
+require (
+	github.com/chriscow/minds v0.0.5
+	github.com/chriscow/minds/providers/openai v0.0.0-00010101000000-000000000000
+	github.com/matryer/is v1.4.1
+)
+
+require (
+	github.com/dlclark/regexp2 v1.9.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
+	github.com/hashicorp/go-retryablehttp v0.7.7 // indirect
+	github.com/sashabaranov/go-openai v1.36.0 // indirect
+	github.com/tiktoken-go/tokenizer v0.2.1 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
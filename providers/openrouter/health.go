@@ -0,0 +1,34 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/chriscow/minds"
+)
+
+// Ping checks that OpenRouter is reachable and the API key is valid by
+// fetching the key's details, which does not consume credits. OpenRouter's
+// models endpoint does not require a key, so it cannot be used for this. A
+// failure is returned as a *minds.PingError whose Kind is
+// minds.ErrUnauthorized or minds.ErrUnavailable when the cause is known.
+func (p *Provider) Ping(ctx context.Context) error {
+	resp, err := p.get(ctx, "/key")
+	if err != nil {
+		return &minds.PingError{Kind: minds.ErrUnavailable, Err: err}
+	}
+	defer resp.Body.Close()
+
+	var kind error
+	switch status := resp.StatusCode; {
+	case status == http.StatusOK:
+		return nil
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		kind = minds.ErrUnauthorized
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		kind = minds.ErrUnavailable
+	}
+
+	return &minds.PingError{Kind: kind, Err: fmt.Errorf("unexpected status: %s", resp.Status)}
+}
@@ -0,0 +1,90 @@
+package openrouter
+
+import (
+	"net/http"
+
+	"github.com/chriscow/minds/providers/openai"
+)
+
+type Options struct {
+	apiKey     string
+	baseURL    string
+	modelName  string
+	referer    string
+	title      string
+	httpClient *http.Client
+	proxyURL   string
+	openai     []openai.Option
+}
+
+type Option func(*Options)
+
+func WithAPIKey(key string) Option {
+	return func(o *Options) {
+		o.apiKey = key
+	}
+}
+
+func WithBaseURL(url string) Option {
+	return func(o *Options) {
+		o.baseURL = url
+	}
+}
+
+// WithModel sets the model using OpenRouter's "vendor/model" naming, for
+// example "anthropic/claude-3.5-sonnet".
+func WithModel(model string) Option {
+	return func(o *Options) {
+		o.modelName = model
+	}
+}
+
+// WithReferer sets the HTTP-Referer header OpenRouter uses to attribute
+// requests to your site or app.
+func WithReferer(url string) Option {
+	return func(o *Options) {
+		o.referer = url
+	}
+}
+
+// WithTitle sets the X-Title header OpenRouter uses to display your app's
+// name in its rankings.
+func WithTitle(title string) Option {
+	return func(o *Options) {
+		o.title = title
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to call the API, for full control
+// over TLS, proxies and timeouts.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		o.httpClient = client
+	}
+}
+
+// WithClient sets the HTTP client used to call the API. It is the same as
+// WithHTTPClient.
+func WithClient(client *http.Client) Option {
+	return WithHTTPClient(client)
+}
+
+// WithProxy sends requests to the API through the HTTP proxy at proxyURL,
+// such as "http://proxy.corp.example:3128". It applies to the client set with
+// WithHTTPClient too, as long as its transport is an *http.Transport;
+// NewProvider returns an error otherwise, or if proxyURL is invalid.
+func WithProxy(proxyURL string) Option {
+	return func(o *Options) {
+		o.proxyURL = proxyURL
+	}
+}
+
+// WithOpenAIOptions passes options through to the underlying OpenAI
+// provider, for settings such as the temperature, tools or system prompt.
+// The API key, base URL, model and client are always taken from this
+// package's options.
+func WithOpenAIOptions(opts ...openai.Option) Option {
+	return func(o *Options) {
+		o.openai = append(o.openai, opts...)
+	}
+}
@@ -0,0 +1,196 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/chriscow/minds"
+	"github.com/chriscow/minds/internal/transport"
+	"github.com/chriscow/minds/providers/openai"
+)
+
+const (
+	// DefaultBaseURL is the base URL of OpenRouter's OpenAI-compatible API.
+	DefaultBaseURL = "https://openrouter.ai/api/v1"
+
+	defaultModel = "openai/gpt-4o-mini"
+)
+
+// Provider is an OpenRouter content generator and thread handler. It is an
+// OpenAI provider configured for OpenRouter's endpoint and headers.
+type Provider struct {
+	*openai.Provider
+	options Options
+}
+
+// NewProvider creates a new OpenRouter provider. The API key is read from the
+// OPENROUTER_API_KEY environment variable unless passed with WithAPIKey. If no
+// model is provided, the default model is used, currently
+// "openai/gpt-4o-mini". The default model can be overridden by setting the
+// OPENROUTER_DEFAULT_MODEL environment variable.
+//
+// Example:
+//
+//	llm, err := openrouter.NewProvider(
+//	    openrouter.WithModel("anthropic/claude-3.5-sonnet"),
+//	    openrouter.WithReferer("https://example.com"),
+//	    openrouter.WithTitle("Example App"),
+//	)
+func NewProvider(opts ...Option) (*Provider, error) {
+	options := Options{
+		baseURL:   DefaultBaseURL,
+		modelName: defaultModel,
+	}
+
+	if os.Getenv("OPENROUTER_DEFAULT_MODEL") != "" {
+		options.modelName = os.Getenv("OPENROUTER_DEFAULT_MODEL")
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.apiKey == "" {
+		options.apiKey = os.Getenv("OPENROUTER_API_KEY")
+		if options.apiKey == "" {
+			return nil, errors.New("OPENROUTER_API_KEY is not set or passed as an option")
+		}
+	}
+
+	if options.proxyURL != "" {
+		client, err := transport.WithProxy(options.httpClient, options.proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		options.httpClient = client
+	}
+
+	// OpenRouter continues trailing assistant messages, so prefills are sent
+	// as is unless overridden with WithOpenAIOptions.
+	providerOpts := append([]openai.Option{openai.WithNativePrefill(true)}, options.openai...)
+	providerOpts = append(providerOpts,
+		openai.WithAPIKey(options.apiKey),
+		openai.WithBaseURL(options.baseURL),
+		openai.WithModel(options.modelName),
+		openai.WithHeaders(options.headers()),
+	)
+
+	if options.httpClient != nil {
+		providerOpts = append(providerOpts, openai.WithHTTPClient(options.httpClient))
+	}
+
+	provider, err := openai.NewProvider(providerOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		Provider: provider,
+		options:  options,
+	}, nil
+}
+
+// headers returns the OpenRouter attribution headers that have been set.
+func (o Options) headers() map[string]string {
+	headers := map[string]string{}
+	if o.referer != "" {
+		headers["HTTP-Referer"] = o.referer
+	}
+	if o.title != "" {
+		headers["X-Title"] = o.title
+	}
+	return headers
+}
+
+// Model describes a model available through OpenRouter.
+type Model struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	ContextLength int     `json:"context_length"`
+	Pricing       Pricing `json:"pricing"`
+	TopProvider   struct {
+		MaxCompletionTokens int `json:"max_completion_tokens"`
+	} `json:"top_provider"`
+	SupportedParameters []string `json:"supported_parameters"`
+}
+
+// Pricing is the price of a model in US dollars per token, as decimal
+// strings.
+type Pricing struct {
+	Prompt     string `json:"prompt"`
+	Completion string `json:"completion"`
+}
+
+// ListModels returns the models available through OpenRouter. Capabilities
+// are the request parameters each model supports, such as "tools". Use Models
+// for OpenRouter-specific details such as pricing.
+func (p *Provider) ListModels(ctx context.Context) ([]minds.ModelInfo, error) {
+	list, err := p.Models(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]minds.ModelInfo, 0, len(list))
+	for _, model := range list {
+		models = append(models, minds.ModelInfo{
+			ID:              model.ID,
+			Name:            model.Name,
+			Description:     model.Description,
+			ContextWindow:   model.ContextLength,
+			MaxOutputTokens: model.TopProvider.MaxCompletionTokens,
+			Capabilities:    model.SupportedParameters,
+		})
+	}
+
+	return models, nil
+}
+
+// Models returns the models available through OpenRouter as reported by its
+// models endpoint.
+func (p *Provider) Models(ctx context.Context) ([]Model, error) {
+	resp, err := p.get(ctx, "/models")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list models: %s", resp.Status)
+	}
+
+	var list struct {
+		Data []Model `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode models: %w", err)
+	}
+
+	return list.Data, nil
+}
+
+// get sends an authenticated GET request for path, relative to the base URL.
+func (p *Provider) get(ctx context.Context, path string) (*http.Response, error) {
+	url := strings.TrimSuffix(p.options.baseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.options.apiKey)
+	for key, value := range p.options.headers() {
+		req.Header.Set(key, value)
+	}
+
+	client := p.options.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(req)
+}
@@ -0,0 +1,196 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscow/minds"
+	"github.com/matryer/is"
+)
+
+func newTestServer(t *testing.T, requests *[]*http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/chat/completions":
+			var body struct {
+				Model string `json:"model"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(map[string]any{
+				"model": body.Model,
+				"choices": []map[string]any{{
+					"message":       map[string]string{"role": "assistant", "content": "Hello from " + body.Model},
+					"finish_reason": "stop",
+				}},
+			})
+		case "/models":
+			w.Write([]byte(`{"data": [{
+				"id": "anthropic/claude-3.5-sonnet",
+				"name": "Anthropic: Claude 3.5 Sonnet",
+				"context_length": 200000,
+				"pricing": {"prompt": "0.000003", "completion": "0.000015"},
+				"top_provider": {"max_completion_tokens": 8192},
+				"supported_parameters": ["tools", "temperature"]
+			}]}`))
+		case "/key":
+			if r.Header.Get("Authorization") != "Bearer test-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": {"message": "No auth credentials found", "code": 401}}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"label": "test", "usage": 0}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestProvider_GenerateContent(t *testing.T) {
+	is := is.New(t)
+
+	var requests []*http.Request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	provider, err := NewProvider(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithModel("anthropic/claude-3.5-sonnet"),
+		WithReferer("https://example.com"),
+		WithTitle("Example App"),
+	)
+	is.NoErr(err)
+	is.Equal(provider.ModelName(), "anthropic/claude-3.5-sonnet")
+
+	resp, err := provider.GenerateContent(context.Background(), minds.Request{
+		Messages: minds.Messages{{Role: minds.RoleUser, Content: "Hello!"}},
+	})
+	is.NoErr(err)
+	is.Equal(resp.String(), "Hello from anthropic/claude-3.5-sonnet")
+
+	is.Equal(len(requests), 1)
+	is.Equal(requests[0].Header.Get("Authorization"), "Bearer test-key")
+	is.Equal(requests[0].Header.Get("HTTP-Referer"), "https://example.com")
+	is.Equal(requests[0].Header.Get("X-Title"), "Example App")
+}
+
+func TestProvider_Prefill(t *testing.T) {
+	is := is.New(t)
+
+	var sent []minds.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []minds.Message `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = body.Messages
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{
+				"message":       map[string]string{"role": "assistant", "content": `"red"]}`},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	is.NoErr(err)
+
+	resp, err := provider.GenerateContent(context.Background(), minds.NewRequest(minds.Messages{
+		{Role: minds.RoleUser, Content: "Name a color as JSON."},
+		{Role: minds.RoleAssistant, Content: `{"colors": [`},
+	}, minds.WithPrefill()))
+	is.NoErr(err)
+	is.Equal(resp.String(), `{"colors": ["red"]}`)
+
+	is.Equal(len(sent), 2) // the prefill is sent as the last message
+	is.Equal(sent[1].Role, minds.RoleAssistant)
+	is.Equal(sent[1].Content, `{"colors": [`)
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	is := is.New(t)
+
+	var requests []*http.Request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	provider, err := NewProvider(WithAPIKey("test-key"), WithBaseURL(server.URL), WithTitle("Example App"))
+	is.NoErr(err)
+
+	models, err := provider.Models(context.Background())
+	is.NoErr(err)
+	is.Equal(len(models), 1)
+	is.Equal(models[0].ID, "anthropic/claude-3.5-sonnet")
+	is.Equal(models[0].ContextLength, 200000)
+	is.Equal(models[0].Pricing.Completion, "0.000015")
+	is.Equal(requests[0].Header.Get("X-Title"), "Example App")
+
+	var lister minds.ModelLister = provider
+	infos, err := lister.ListModels(context.Background())
+	is.NoErr(err)
+	is.Equal(infos, []minds.ModelInfo{{
+		ID:              "anthropic/claude-3.5-sonnet",
+		Name:            "Anthropic: Claude 3.5 Sonnet",
+		ContextWindow:   200000,
+		MaxOutputTokens: 8192,
+		Capabilities:    []string{"tools", "temperature"},
+	}})
+}
+
+func TestProvider_Proxy(t *testing.T) {
+	is := is.New(t)
+
+	// The test server answers as the proxy for the unreachable API host.
+	var requests []*http.Request
+	proxy := newTestServer(t, &requests)
+	defer proxy.Close()
+
+	provider, err := NewProvider(WithAPIKey("test-key"), WithBaseURL("http://openrouter.invalid"), WithProxy(proxy.URL))
+	is.NoErr(err)
+
+	_, err = provider.GenerateContent(context.Background(), minds.NewRequest(minds.Messages{{Role: minds.RoleUser, Content: "Hi"}}))
+	is.NoErr(err)
+	_, err = provider.Models(context.Background())
+	is.NoErr(err)
+
+	is.Equal(len(requests), 2)
+	for _, r := range requests {
+		is.Equal(r.URL.Host, "openrouter.invalid")
+	}
+}
+
+func TestNewProvider_RequiresAPIKey(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("OPENROUTER_API_KEY", "")
+
+	_, err := NewProvider()
+	is.True(err != nil)
+}
+
+func TestProvider_Ping(t *testing.T) {
+	is := is.New(t)
+
+	var requests []*http.Request
+	server := newTestServer(t, &requests)
+
+	valid, err := NewProvider(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	is.NoErr(err)
+	is.NoErr(valid.Ping(context.Background()))
+
+	invalid, err := NewProvider(WithAPIKey("wrong-key"), WithBaseURL(server.URL))
+	is.NoErr(err)
+	is.True(errors.Is(invalid.Ping(context.Background()), minds.ErrUnauthorized))
+
+	server.Close()
+	is.True(errors.Is(valid.Ping(context.Background()), minds.ErrUnavailable))
+}
@@ -0,0 +1,257 @@
+package minds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNoRecording is returned by a replaying RecordingGenerator when no
+// recorded interaction matches a request. Use errors.Is to detect it.
+var ErrNoRecording = errors.New("no recorded response for request")
+
+// RecordingGenerator records the interactions of a ContentGenerator to a file
+// and replays them, so tests can run against real responses without calling
+// the provider. It is safe for concurrent use.
+type RecordingGenerator struct {
+	inner  ContentGenerator
+	path   string
+	replay bool
+
+	mu           sync.Mutex
+	interactions []recordedInteraction
+	replayed     map[string]int // interactions replayed so far, by key
+}
+
+// recordedInteraction is a request and its response as saved to disk.
+type recordedInteraction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+// recordedRequest holds the parts of a Request sent to the model.
+type recordedRequest struct {
+	Messages        Messages        `json:"messages"`
+	Model           string          `json:"model,omitempty"`
+	Temperature     *float32        `json:"temperature,omitempty"`
+	MaxOutputTokens *int            `json:"max_output_tokens,omitempty"`
+	ResponseSchema  *ResponseSchema `json:"response_schema,omitempty"`
+	JSONMode        bool            `json:"json_mode,omitempty"`
+	Tools           []string        `json:"tools,omitempty"`
+	ToolChoice      string          `json:"tool_choice,omitempty"`
+	LogitBias       map[int]int     `json:"logit_bias,omitempty"`
+	Candidates      int             `json:"candidates,omitempty"`
+	Prefill         bool            `json:"prefill,omitempty"`
+}
+
+// recordedResponse is a Response read back from disk.
+type recordedResponse struct {
+	Text  string     `json:"text"`
+	Calls []ToolCall `json:"tool_calls,omitempty"`
+	Use   *Usage     `json:"usage,omitempty"`
+}
+
+func (r recordedResponse) String() string        { return r.Text }
+func (r recordedResponse) ToolCalls() []ToolCall { return r.Calls }
+
+func (r recordedResponse) Usage() Usage {
+	if r.Use == nil {
+		return Usage{}
+	}
+	return *r.Use
+}
+
+// NewRecordingGenerator returns a generator that records to or replays from
+// the file at path, like go-vcr. If the file exists, it replays: each
+// request is answered with the response recorded for a matching request and
+// inner is not called, so it may be nil. Otherwise it records: requests are
+// passed to inner, and every interaction is written to path as it completes.
+// Delete the file to record again.
+//
+// Requests match when their messages and options match. Messages are
+// compared without surrounding whitespace, tools by name only, and the
+// metadata of the request and its messages is ignored. When several recorded
+// interactions match, they are replayed in the order they were recorded,
+// repeating the last one once they run out.
+//
+// Example:
+//
+//	llm := minds.NewRecordingGenerator(provider, "testdata/summarize.json")
+//	defer llm.Close()
+//	result, err := handlers.NewScratchpad("think", llm).HandleThread(tc, nil)
+func NewRecordingGenerator(inner ContentGenerator, path string) *RecordingGenerator {
+	return &RecordingGenerator{
+		inner:    inner,
+		path:     path,
+		replayed: make(map[string]int),
+	}
+}
+
+// ModelName returns the model name of the inner generator, or "recording"
+// if there is none.
+func (g *RecordingGenerator) ModelName() string {
+	if g.inner == nil {
+		return "recording"
+	}
+	return g.inner.ModelName()
+}
+
+func (g *RecordingGenerator) GenerateContent(ctx context.Context, req Request) (Response, error) {
+	if err := g.load(); err != nil {
+		return nil, err
+	}
+
+	recorded := newRecordedRequest(req)
+	if g.replay {
+		return g.find(recorded)
+	}
+
+	if g.inner == nil {
+		return nil, fmt.Errorf("recording %s: no generator to record", g.path)
+	}
+
+	resp, err := g.inner.GenerateContent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := recordedResponse{Text: resp.String(), Calls: resp.ToolCalls()}
+	if r, ok := resp.(UsageReporter); ok {
+		usage := r.Usage()
+		response.Use = &usage
+	}
+
+	if err := g.save(recordedInteraction{Request: recorded, Response: response}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (g *RecordingGenerator) Close() {
+	if g.inner != nil {
+		g.inner.Close()
+	}
+}
+
+// load reads the recording on first use and decides whether to replay it.
+func (g *RecordingGenerator) load() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.replay || g.interactions != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(g.path)
+	if errors.Is(err, os.ErrNotExist) {
+		g.interactions = []recordedInteraction{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("recording %s: %w", g.path, err)
+	}
+
+	if err := json.Unmarshal(data, &g.interactions); err != nil {
+		return fmt.Errorf("recording %s: %w", g.path, err)
+	}
+	g.replay = true
+	return nil
+}
+
+// find returns the next recorded response for req.
+func (g *RecordingGenerator) find(req recordedRequest) (Response, error) {
+	key, err := req.key()
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var matches []recordedResponse
+	for _, interaction := range g.interactions {
+		k, err := interaction.Request.key()
+		if err != nil {
+			return nil, err
+		}
+		if k == key {
+			matches = append(matches, interaction.Response)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("recording %s: %w", g.path, ErrNoRecording)
+	}
+
+	n := g.replayed[key]
+	g.replayed[key] = n + 1
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	return matches[n], nil
+}
+
+// save adds interaction to the recording and rewrites the file.
+func (g *RecordingGenerator) save(interaction recordedInteraction) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.interactions = append(g.interactions, interaction)
+	data, err := json.MarshalIndent(g.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("recording %s: %w", g.path, err)
+	}
+
+	if err := os.WriteFile(g.path, data, 0o644); err != nil {
+		return fmt.Errorf("recording %s: %w", g.path, err)
+	}
+	return nil
+}
+
+// newRecordedRequest returns the normalized form of req that is saved and
+// matched.
+func newRecordedRequest(req Request) recordedRequest {
+	messages := make(Messages, len(req.Messages))
+	for i, msg := range req.Messages {
+		msg.Content = strings.TrimSpace(msg.Content)
+		msg.Metadata = nil
+		messages[i] = msg
+	}
+
+	recorded := recordedRequest{
+		Messages:        messages,
+		Temperature:     req.Options.Temperature,
+		MaxOutputTokens: req.Options.MaxOutputTokens,
+		ResponseSchema:  req.Options.ResponseSchema,
+		JSONMode:        req.Options.JSONMode,
+		ToolChoice:      req.Options.ToolChoice,
+		LogitBias:       req.Options.LogitBias,
+		Candidates:      req.Options.Candidates,
+		Prefill:         req.Options.Prefill,
+	}
+	if req.Options.ModelName != nil {
+		recorded.Model = *req.Options.ModelName
+	}
+	if req.Options.ToolRegistry != nil {
+		for _, tool := range req.Options.ToolRegistry.List() {
+			recorded.Tools = append(recorded.Tools, tool.Name())
+		}
+		sort.Strings(recorded.Tools)
+	}
+
+	return recorded
+}
+
+// key identifies the request for matching.
+func (r recordedRequest) key() (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
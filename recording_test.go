@@ -0,0 +1,66 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRecordingGenerator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.json")
+
+	answers := []string{"first", "second"}
+	inner := &stubGenerator{fn: func(req Request) (Response, error) {
+		return stubResponse{content: answers[0], usage: Usage{TotalTokens: 7}}, nil
+	}}
+
+	hello := NewRequest(Messages{{Role: RoleUser, Content: "Hello"}}, WithModel("m"))
+	again := NewRequest(Messages{{Role: RoleUser, Content: " Hello\n"}}, WithModel("m"), WithRequestMetadata(Metadata{"tenant": "acme"}))
+	other := NewRequest(Messages{{Role: RoleUser, Content: "Hello"}}, WithModel("other"))
+
+	t.Run("records", func(t *testing.T) {
+		is := is.New(t)
+		recorder := NewRecordingGenerator(inner, path)
+
+		resp, err := recorder.GenerateContent(context.Background(), hello)
+		is.NoErr(err)
+		is.Equal(resp.String(), "first")
+
+		answers = answers[1:]
+		resp, err = recorder.GenerateContent(context.Background(), hello)
+		is.NoErr(err)
+		is.Equal(resp.String(), "second")
+		is.Equal(inner.calls, 2)
+	})
+
+	t.Run("replays without calling the generator", func(t *testing.T) {
+		is := is.New(t)
+		replayer := NewRecordingGenerator(nil, path)
+
+		for _, want := range []string{"first", "second", "second"} {
+			resp, err := replayer.GenerateContent(context.Background(), again)
+			is.NoErr(err)
+			is.Equal(resp.String(), want)
+			is.Equal(resp.(UsageReporter).Usage().TotalTokens, 7)
+		}
+		is.Equal(inner.calls, 2)
+
+		_, err := replayer.GenerateContent(context.Background(), other)
+		is.True(errors.Is(err, ErrNoRecording))
+	})
+
+	t.Run("passes on generator errors", func(t *testing.T) {
+		is := is.New(t)
+		failing := &stubGenerator{fn: func(req Request) (Response, error) {
+			return nil, errors.New("boom")
+		}}
+
+		recorder := NewRecordingGenerator(failing, filepath.Join(t.TempDir(), "failing.json"))
+		_, err := recorder.GenerateContent(context.Background(), hello)
+		is.True(err != nil)
+		is.Equal(len(recorder.interactions), 0) // failures are not recorded
+	})
+}
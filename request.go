@@ -5,8 +5,16 @@ type RequestOptions struct {
 	Temperature     *float32
 	MaxOutputTokens *int
 	ResponseSchema  *ResponseSchema
+	JSONMode        bool
 	ToolRegistry    ToolRegistry
 	ToolChoice      string
+	CacheControl    []CacheSegment
+	LogitBias       map[int]int
+	Candidates      int
+	Prefill         bool
+	User            string
+
+	metadata Metadata
 }
 
 type RequestOption func(*RequestOptions)
@@ -14,6 +22,12 @@ type RequestOption func(*RequestOptions)
 type Request struct {
 	Options  RequestOptions
 	Messages Messages `json:"messages"`
+
+	// Metadata is the metadata of the thread the request was made for. A
+	// provider's HandleThread sets it from the thread context so generators
+	// and GeneratorMiddleware can use it for routing or logging, for example
+	// by tenant id. It is not sent to the model.
+	Metadata Metadata `json:"metadata,omitempty"`
 }
 
 func NewRequest(messages Messages, opts ...RequestOption) Request {
@@ -25,6 +39,60 @@ func NewRequest(messages Messages, opts ...RequestOption) Request {
 	return Request{
 		Options:  options,
 		Messages: messages,
+		Metadata: options.metadata,
+	}
+}
+
+// WithRequestMetadata sets the request's Metadata, typically to the metadata
+// of the thread the request is made for.
+//
+// Example:
+//
+//	req := minds.NewRequest(tc.Messages(), minds.WithRequestMetadata(tc.Metadata()))
+func WithRequestMetadata(metadata Metadata) RequestOption {
+	return func(o *RequestOptions) {
+		o.metadata = metadata
+	}
+}
+
+// WithToolRegistry sets the tools available for a single request, replacing
+// the registry the provider was created with.
+//
+// Example:
+//
+//	req := minds.NewRequest(messages, minds.WithToolRegistry(registry))
+func WithToolRegistry(registry ToolRegistry) RequestOption {
+	return func(o *RequestOptions) {
+		o.ToolRegistry = registry
+	}
+}
+
+// WithToolChoice controls whether the model calls a tool: "auto", the
+// default, lets the model decide, "required" makes it call at least one tool
+// and "none" keeps it from calling any. The option has no effect on requests
+// without tools.
+//
+// Example:
+//
+//	req := minds.NewRequest(messages, minds.WithToolRegistry(registry), minds.WithToolChoice("required"))
+func WithToolChoice(choice string) RequestOption {
+	return func(o *RequestOptions) {
+		o.ToolChoice = choice
+	}
+}
+
+// WithUser identifies the end user the request is made for, with an ID that
+// is stable across requests, such as a hashed account ID. OpenAI uses it to
+// monitor and detect abuse, so one misbehaving user does not get the whole
+// API key blocked. Providers without a user field, such as gemini, ignore
+// the option. Do not send names or email addresses.
+//
+// Example:
+//
+//	req := minds.NewRequest(messages, minds.WithUser("user-4f1c2a"))
+func WithUser(id string) RequestOption {
+	return func(o *RequestOptions) {
+		o.User = id
 	}
 }
 
@@ -52,6 +120,99 @@ func WithResponseSchema(schema ResponseSchema) RequestOption {
 	}
 }
 
+// WithJSONMode asks for a response that is a JSON object of any shape, for
+// extraction where the keys are not known in advance. Use WithResponseSchema
+// instead when the shape is known; a response schema takes precedence over
+// JSON mode. OpenAI requires the word "JSON" to appear in the messages, for
+// example in the system prompt, when JSON mode is on.
+//
+// Example:
+//
+//	req := minds.NewRequest(messages, minds.WithJSONMode())
+func WithJSONMode() RequestOption {
+	return func(o *RequestOptions) {
+		o.JSONMode = true
+	}
+}
+
+// WithLogitBias adjusts the likelihood of specific tokens appearing in the
+// response. Keys are token ids and values range from -100, which bans the
+// token, to 100, which effectively forces it. Token ids depend on the model's
+// tokenizer; openai.LogitBiasFor builds the map from strings.
+//
+// Logit bias is OpenAI-specific. Providers without it, such as gemini, ignore
+// the option.
+//
+// Example:
+//
+//	bias, err := openai.LogitBiasFor("gpt-4o", map[string]int{"Yes": 100, "No": 100})
+//	req := minds.NewRequest(messages, minds.WithLogitBias(bias), minds.WithMaxOutputTokens(1))
+func WithLogitBias(bias map[int]int) RequestOption {
+	return func(o *RequestOptions) {
+		o.LogitBias = bias
+	}
+}
+
+// WithCandidates asks the provider for n alternative completions of the
+// request in one call, which is cheaper than n separate calls when sampling
+// for self-consistency. Responses from providers that support it implement
+// CandidateReporter; use Candidates to read them. String and ToolCalls still
+// describe the first candidate. Values below 2 request a single completion,
+// the default. Gemini returns several candidates only for single-turn
+// requests.
+//
+// Example:
+//
+//	resp, err := llm.GenerateContent(ctx, minds.NewRequest(messages, minds.WithCandidates(5)))
+//	for _, answer := range minds.Candidates(resp) {
+//	    votes[answer]++
+//	}
+func WithCandidates(n int) RequestOption {
+	return func(o *RequestOptions) {
+		o.Candidates = n
+	}
+}
+
+// CacheSegment identifies a part of a request that a provider may cache
+// between calls. Caching pays off when the segment is large and repeated
+// verbatim, such as a long system prompt or a fixed set of tool definitions.
+type CacheSegment int
+
+const (
+	// CacheSystem marks the system messages as cacheable.
+	CacheSystem CacheSegment = iota
+
+	// CacheTools marks the tool definitions as cacheable.
+	CacheTools
+
+	// CacheMessages marks the conversation up to and including the last
+	// message as cacheable.
+	CacheMessages
+)
+
+// WithCacheControl marks segments of the request as cacheable. Providers
+// with explicit cache breakpoints, such as Anthropic's cache_control blocks,
+// are meant to place one at the end of each segment. Neither provider in
+// this module has them, so today the option is a portable hint and changes
+// nothing on the wire:
+//
+//   - openai: ignored. OpenAI caches long prompt prefixes automatically;
+//     keep the cacheable segments first and unchanged between calls.
+//   - gemini: ignored. Use gemini.WithCachedContent for explicit context
+//     caching.
+//
+// Either way, Usage.CachedTokens reports the part of the prompt that was read
+// from a cache, see UsageReporter.
+//
+// Example:
+//
+//	req := minds.NewRequest(messages, minds.WithCacheControl(minds.CacheSystem, minds.CacheTools))
+func WithCacheControl(segments ...CacheSegment) RequestOption {
+	return func(o *RequestOptions) {
+		o.CacheControl = append(o.CacheControl, segments...)
+	}
+}
+
 func (r Request) TokenCount(tokenizer TokenCounter) (int, error) {
 	total := 0
 	for _, msg := range r.Messages {
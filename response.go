@@ -1,5 +1,10 @@
 package minds
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // ResponseType indicates what kind of response we received
 type ResponseType int
 
@@ -9,6 +14,31 @@ const (
 	ResponseTypeToolCall
 )
 
+const (
+	// LastResponseTypeKey is the thread metadata key under which a provider
+	// acting as a ThreadHandler records the ResponseType of its last
+	// generation.
+	LastResponseTypeKey = "last_response_type"
+
+	// LastToolCallsKey is the thread metadata key under which a provider acting
+	// as a ThreadHandler records the []ToolCall of its last generation. It is
+	// empty for text responses.
+	LastToolCallsKey = "last_tool_calls"
+
+	// ResponseSchemaKey is the thread metadata key under which middleware,
+	// such as middleware.RequireSchema, sets a *ResponseSchema to request for
+	// the next generation. Providers acting as ThreadHandlers read it with
+	// ThreadResponseSchema.
+	ResponseSchemaKey = "response_schema"
+)
+
+// ThreadResponseSchema returns the ResponseSchema set on tc under
+// ResponseSchemaKey, or nil if there is none.
+func ThreadResponseSchema(tc ThreadContext) *ResponseSchema {
+	schema, _ := tc.Metadata()[ResponseSchemaKey].(*ResponseSchema)
+	return schema
+}
+
 type ResponseSchema struct {
 	Name        string     `json:"name"`
 	Description string     `json:"description"`
@@ -28,6 +58,80 @@ func NewResponseSchema(name, desc string, v any) (*ResponseSchema, error) {
 	}, nil
 }
 
+// NewResponseSchemaFromJSON creates a response schema from a hand-written
+// JSON Schema, such as one taken from an OpenAPI spec or built at runtime.
+// The schema must be an object schema. Nested types must be valid JSON
+// Schema types and required properties must be defined. Keywords that
+// Definition does not model, such as "format" or "minimum", are ignored.
+//
+// Example:
+//
+//	schema, err := minds.NewResponseSchemaFromJSON("weather", "Current weather", []byte(`{
+//	    "type": "object",
+//	    "properties": {
+//	        "city": {"type": "string"},
+//	        "celsius": {"type": "number"}
+//	    },
+//	    "required": ["city", "celsius"]
+//	}`))
+func NewResponseSchemaFromJSON(name, desc string, schemaJSON []byte) (*ResponseSchema, error) {
+	var def Definition
+	if err := json.Unmarshal(schemaJSON, &def); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	if def.Type != Object {
+		return nil, fmt.Errorf("invalid JSON schema: type must be %q, got %q", Object, def.Type)
+	}
+
+	if err := checkDefinition(def, ""); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	return &ResponseSchema{
+		Name:        name,
+		Description: desc,
+		Definition:  def,
+	}, nil
+}
+
+// checkDefinition reports the first structural problem in def, naming its
+// location with path.
+func checkDefinition(def Definition, path string) error {
+	switch def.Type {
+	case "", Object, Number, Integer, String, Array, Null, Boolean:
+	default:
+		return fmt.Errorf("%s: unknown type %q", schemaPath(path), def.Type)
+	}
+
+	for _, name := range def.Required {
+		if _, ok := def.Properties[name]; !ok {
+			return fmt.Errorf("%s: required property %q is not defined", schemaPath(path), name)
+		}
+	}
+
+	for name, prop := range def.Properties {
+		if err := checkDefinition(prop, joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+
+	if def.Items != nil {
+		if err := checkDefinition(*def.Items, path+"[]"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func schemaPath(path string) string {
+	if path == "" {
+		return "schema"
+	}
+	return path
+}
+
 type Response interface {
 	// String returns a string representation of the response
 	String() string
@@ -36,6 +140,46 @@ type Response interface {
 	ToolCalls() []ToolCall
 }
 
+// Usage reports the tokens consumed by a single generation.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+
+	// CachedTokens is the part of PromptTokens read from the provider's
+	// prompt cache. Cached tokens are typically billed at a discount.
+	CachedTokens int
+}
+
+// UsageReporter is implemented by responses that report token usage.
+//
+// Example:
+//
+//	if r, ok := resp.(minds.UsageReporter); ok {
+//	    log.Printf("prompt: %d (cached %d)", r.Usage().PromptTokens, r.Usage().CachedTokens)
+//	}
+type UsageReporter interface {
+	Usage() Usage
+}
+
+// CandidateReporter is implemented by responses that carry several
+// candidate completions, requested with WithCandidates.
+type CandidateReporter interface {
+	// Candidates returns the text of each candidate, first to last.
+	Candidates() []string
+}
+
+// Candidates returns the text of every candidate in resp. Responses that
+// don't implement CandidateReporter have a single candidate, resp.String().
+func Candidates(resp Response) []string {
+	if r, ok := resp.(CandidateReporter); ok {
+		if candidates := r.Candidates(); len(candidates) > 0 {
+			return candidates
+		}
+	}
+	return []string{resp.String()}
+}
+
 type ResponseHandler func(resp Response) error
 
 func (h ResponseHandler) HandleResponse(resp Response) error {
@@ -0,0 +1,77 @@
+package minds
+
+// MapResponse returns a response whose String applies fn to the text of resp,
+// for uniform post-processing such as trimming whitespace, stripping code
+// fences or normalizing output, without modifying the provider's response.
+// Tool calls are passed through. If resp implements UsageReporter or
+// CandidateReporter, so does the result, with fn also applied to each
+// candidate. The original response is available from the result's Unwrap
+// method.
+//
+// Example:
+//
+//	resp, err := llm.GenerateContent(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	resp = minds.MapResponse(resp, strings.TrimSpace)
+func MapResponse(resp Response, fn func(string) string) Response {
+	m := &mappedResponse{resp: resp, fn: fn}
+
+	usage, hasUsage := resp.(UsageReporter)
+	candidates, hasCandidates := resp.(CandidateReporter)
+	mc := mappedCandidates{candidates: candidates, fn: fn}
+
+	switch {
+	case hasUsage && hasCandidates:
+		return &struct {
+			*mappedResponse
+			UsageReporter
+			mappedCandidates
+		}{m, usage, mc}
+	case hasUsage:
+		return &struct {
+			*mappedResponse
+			UsageReporter
+		}{m, usage}
+	case hasCandidates:
+		return &struct {
+			*mappedResponse
+			mappedCandidates
+		}{m, mc}
+	default:
+		return m
+	}
+}
+
+type mappedResponse struct {
+	resp Response
+	fn   func(string) string
+}
+
+func (m *mappedResponse) String() string {
+	return m.fn(m.resp.String())
+}
+
+func (m *mappedResponse) ToolCalls() []ToolCall {
+	return m.resp.ToolCalls()
+}
+
+// Unwrap returns the response passed to MapResponse.
+func (m *mappedResponse) Unwrap() Response {
+	return m.resp
+}
+
+type mappedCandidates struct {
+	candidates CandidateReporter
+	fn         func(string) string
+}
+
+func (m mappedCandidates) Candidates() []string {
+	candidates := m.candidates.Candidates()
+	mapped := make([]string, len(candidates))
+	for i, c := range candidates {
+		mapped[i] = m.fn(c)
+	}
+	return mapped
+}
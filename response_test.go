@@ -0,0 +1,116 @@
+package minds
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNewResponseSchemaFromJSON(t *testing.T) {
+	t.Run("parses an object schema", func(t *testing.T) {
+		is := is.New(t)
+
+		schema, err := NewResponseSchemaFromJSON("weather", "Current weather", []byte(`{
+			"type": "object",
+			"properties": {
+				"city": {"type": "string", "description": "City name"},
+				"celsius": {"type": "number", "minimum": -90},
+				"conditions": {"type": "array", "items": {"type": "string", "enum": ["sun", "rain"]}}
+			},
+			"required": ["city", "celsius"]
+		}`))
+		is.NoErr(err)
+
+		is.Equal(schema.Name, "weather")
+		is.Equal(schema.Description, "Current weather")
+		is.Equal(schema.Definition.Type, Object)
+		is.Equal(schema.Definition.Properties["city"].Description, "City name")
+		is.Equal(schema.Definition.Properties["conditions"].Items.Enum, []string{"sun", "rain"})
+		is.Equal(schema.Definition.Required, []string{"city", "celsius"})
+
+		is.True(Validate(schema.Definition, map[string]any{"city": "Oslo", "celsius": 4.0}))
+		is.True(!Validate(schema.Definition, map[string]any{"city": "Oslo"}))
+	})
+
+	tests := []struct {
+		name   string
+		schema string
+		errMsg string
+	}{
+		{"malformed JSON", `{"type": "object",`, "invalid JSON schema"},
+		{"not an object", `{"type": "string"}`, `type must be "object"`},
+		{"unknown nested type", `{"type": "object", "properties": {"n": {"type": "float"}}}`, `n: unknown type "float"`},
+		{"unknown item type", `{"type": "object", "properties": {"l": {"type": "array", "items": {"type": "list"}}}}`, `l[]: unknown type "list"`},
+		{"undefined required property", `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["b"]}`, `required property "b" is not defined`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := NewResponseSchemaFromJSON("test", "", []byte(tt.schema))
+			is.True(err != nil)
+			is.True(strings.Contains(err.Error(), tt.errMsg))
+		})
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	is := is.New(t)
+
+	// Responses without candidates have one, their text.
+	is.Equal(Candidates(stubResponse{content: "only"}), []string{"only"})
+}
+
+// candidateResponse is a response with several candidates.
+type candidateResponse struct {
+	candidates []string
+	calls      []ToolCall
+}
+
+func (r candidateResponse) String() string        { return r.candidates[0] }
+func (r candidateResponse) ToolCalls() []ToolCall { return r.calls }
+func (r candidateResponse) Candidates() []string  { return r.candidates }
+
+func TestMapResponse(t *testing.T) {
+	t.Run("maps the text and keeps usage", func(t *testing.T) {
+		is := is.New(t)
+
+		original := stubResponse{content: "  hello \n", usage: Usage{TotalTokens: 7}}
+		resp := MapResponse(original, strings.TrimSpace)
+		is.Equal(resp.String(), "hello")
+		is.Equal(original.String(), "  hello \n") // original unchanged
+
+		usage, ok := resp.(UsageReporter)
+		is.True(ok)
+		is.Equal(usage.Usage().TotalTokens, 7)
+
+		_, ok = resp.(CandidateReporter)
+		is.True(!ok)
+
+		unwrapped := resp.(interface{ Unwrap() Response }).Unwrap()
+		is.Equal(unwrapped, Response(original))
+	})
+
+	t.Run("maps candidates and keeps tool calls", func(t *testing.T) {
+		is := is.New(t)
+
+		calls := []ToolCall{{ID: "1"}}
+		resp := MapResponse(candidateResponse{candidates: []string{"a", "b"}, calls: calls}, strings.ToUpper)
+		is.Equal(resp.String(), "A")
+		is.Equal(Candidates(resp), []string{"A", "B"})
+		is.Equal(resp.ToolCalls(), calls)
+
+		_, ok := resp.(UsageReporter)
+		is.True(!ok)
+	})
+
+	t.Run("composes", func(t *testing.T) {
+		is := is.New(t)
+
+		resp := MapResponse(MapResponse(stubResponse{content: " a "}, strings.TrimSpace), strings.ToUpper)
+		is.Equal(resp.String(), "A")
+		_, ok := resp.(UsageReporter)
+		is.True(ok)
+	})
+}
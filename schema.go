@@ -10,10 +10,12 @@ package minds
 // and modified to fit the needs of the project.
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -54,10 +56,17 @@ type Definition struct {
 	AdditionalProperties any `json:"additionalProperties,omitempty"`
 	// Whether the schema is nullable or not.
 	Nullable bool `json:"nullable,omitempty"`
+	// Ref points to the definition this schema stands for: "#" for the root
+	// schema or "#/$defs/Name" for an entry in the root's Defs. GenerateSchema
+	// uses refs for recursive types.
+	Ref string `json:"$ref,omitempty"`
+	// Defs holds named definitions referenced by Ref. It is only used on the
+	// root schema.
+	Defs map[string]Definition `json:"$defs,omitempty"`
 }
 
 func (d *Definition) MarshalJSON() ([]byte, error) {
-	if d.Properties == nil {
+	if d.Properties == nil && d.Ref == "" {
 		d.Properties = make(map[string]Definition)
 	}
 	type Alias Definition
@@ -72,11 +81,109 @@ func (d *Definition) Unmarshal(content string, v any) error {
 	return VerifySchemaAndUnmarshal(*d, []byte(content), v)
 }
 
+// GenerateSchema returns the JSON schema of v's type. Recursive types, such
+// as a comment with nested replies, are described with refs: the recursive
+// type is stored in the root's Defs and referenced as "#/$defs/Name", or as
+// "#" if it is the root type itself. Providers differ in their support for
+// refs:
+//
+//   - openai: refs are sent as-is; strict mode supports "#" and "#/$defs/..."
+//     refs with $defs on the root schema.
+//   - gemini: the API has no refs, so they are expanded inline to a fixed
+//     depth, beyond which the recursive property is left out.
 func GenerateSchema(v any) (*Definition, error) {
-	return reflectSchema(reflect.TypeOf(v))
+	r := &schemaReflector{
+		root:      reflect.TypeOf(v),
+		visiting:  make(map[reflect.Type]bool),
+		recursive: make(map[reflect.Type]bool),
+		names:     make(map[reflect.Type]string),
+		defs:      make(map[string]Definition),
+	}
+
+	d, err := r.reflectSchema(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.defs) > 0 {
+		d.Defs = r.defs
+	}
+
+	return d, nil
+}
+
+// schemaReflector builds a schema from Go types, detecting recursive struct
+// types so they can be described with refs instead of being expanded forever.
+type schemaReflector struct {
+	root      reflect.Type
+	visiting  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	names     map[reflect.Type]string
+	defs      map[string]Definition
+}
+
+// ref returns the reference to the definition of the struct type t.
+func (r *schemaReflector) ref(t reflect.Type) string {
+	if t == r.root || (r.root != nil && r.root.Kind() == reflect.Ptr && t == r.root.Elem()) {
+		return "#"
+	}
+
+	name, ok := r.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; r.nameTaken(name); i++ {
+			name = fmt.Sprintf("%s%d", t.Name(), i)
+		}
+		r.names[t] = name
+	}
+
+	return "#/$defs/" + name
+}
+
+func (r *schemaReflector) nameTaken(name string) bool {
+	for _, n := range r.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *schemaReflector) reflectStruct(t reflect.Type) (*Definition, error) {
+	if r.visiting[t] {
+		// t contains itself; refer back to the definition being built.
+		r.recursive[t] = true
+		return &Definition{Ref: r.ref(t)}, nil
+	}
+
+	ref := r.ref(t)
+	if name := strings.TrimPrefix(ref, "#/$defs/"); name != ref {
+		if _, ok := r.defs[name]; ok {
+			return &Definition{Ref: ref}, nil
+		}
+	}
+
+	r.visiting[t] = true
+	object, err := r.reflectSchemaObject(t)
+	delete(r.visiting, t)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.recursive[t] && ref != "#" {
+		r.defs[strings.TrimPrefix(ref, "#/$defs/")] = *object
+		return &Definition{Ref: ref}, nil
+	}
+
+	if ref != "#" {
+		// Only recursive types are named; forget the name so it stays free.
+		delete(r.names, t)
+	}
+
+	return object, nil
 }
 
-func reflectSchema(t reflect.Type) (*Definition, error) {
+func (r *schemaReflector) reflectSchema(t reflect.Type) (*Definition, error) {
 	var d Definition
 	switch t.Kind() {
 	case reflect.String:
@@ -90,7 +197,7 @@ func reflectSchema(t reflect.Type) (*Definition, error) {
 		d.Type = Boolean
 	case reflect.Slice, reflect.Array:
 		d.Type = Array
-		items, err := reflectSchema(t.Elem())
+		items, err := r.reflectSchema(t.Elem())
 		if err != nil {
 			return nil, err
 		}
@@ -98,13 +205,13 @@ func reflectSchema(t reflect.Type) (*Definition, error) {
 	case reflect.Struct:
 		d.Type = Object
 		d.AdditionalProperties = false
-		object, err := reflectSchemaObject(t)
+		object, err := r.reflectStruct(t)
 		if err != nil {
 			return nil, err
 		}
 		d = *object
 	case reflect.Ptr:
-		definition, err := reflectSchema(t.Elem())
+		definition, err := r.reflectSchema(t.Elem())
 		if err != nil {
 			return nil, err
 		}
@@ -118,7 +225,7 @@ func reflectSchema(t reflect.Type) (*Definition, error) {
 	return &d, nil
 }
 
-func reflectSchemaObject(t reflect.Type) (*Definition, error) {
+func (r *schemaReflector) reflectSchemaObject(t reflect.Type) (*Definition, error) {
 	var d = Definition{
 		Type:                 Object,
 		Description:          t.Name(),
@@ -146,7 +253,7 @@ func reflectSchemaObject(t reflect.Type) (*Definition, error) {
 			required = false
 		}
 
-		item, err := reflectSchema(field.Type)
+		item, err := r.reflectSchema(field.Type)
 		if err != nil {
 			return nil, err
 		}
@@ -154,7 +261,6 @@ func reflectSchemaObject(t reflect.Type) (*Definition, error) {
 		if description != "" {
 			item.Description = description
 		}
-		properties[jsonTag] = *item
 
 		enumTag := field.Tag.Get("enum")
 		if enumTag != "" {
@@ -165,6 +271,7 @@ func reflectSchemaObject(t reflect.Type) (*Definition, error) {
 			nullable, _ := strconv.ParseBool(n)
 			item.Nullable = nullable
 		}
+		properties[jsonTag] = *item
 
 		if s := field.Tag.Get("required"); s != "" {
 			required, _ = strconv.ParseBool(s)
@@ -191,11 +298,31 @@ func VerifySchemaAndUnmarshal(schema Definition, content []byte, v any) error {
 }
 
 func Validate(schema Definition, data any) bool {
+	return validate(schema, schema, data)
+}
+
+// resolveRef returns the definition schema refers to within root, or schema
+// itself if it is not a ref. Unknown refs resolve to an empty definition.
+func resolveRef(root, schema Definition) Definition {
+	switch {
+	case schema.Ref == "":
+		return schema
+	case schema.Ref == "#":
+		return root
+	case strings.HasPrefix(schema.Ref, "#/$defs/"):
+		return root.Defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+	default:
+		return Definition{}
+	}
+}
+
+func validate(root, schema Definition, data any) bool {
+	schema = resolveRef(root, schema)
 	switch schema.Type {
 	case Object:
-		return validateObject(schema, data)
+		return validateObject(root, schema, data)
 	case Array:
-		return validateArray(schema, data)
+		return validateArray(root, schema, data)
 	case String:
 		_, ok := data.(string)
 		return ok
@@ -222,7 +349,117 @@ func Validate(schema Definition, data any) bool {
 	}
 }
 
-func validateObject(schema Definition, data any) bool {
+// ValidationError describes why a value does not conform to a schema. Path
+// identifies the offending value, e.g. "location.city" or "items[2]"; it is
+// empty when the top-level value is at fault.
+type ValidationError struct {
+	Path   string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// ValidateArguments checks JSON encoded arguments against schema and returns a
+// *ValidationError describing the first violation found. Unlike Validate it
+// also enforces Enum and Nullable. Fields not described by the schema are
+// ignored; see WithDisallowUnknownFields to reject them. An empty schema
+// accepts any arguments.
+func ValidateArguments(schema Definition, args []byte) error {
+	if schema.Type == "" {
+		return nil
+	}
+
+	if len(bytes.TrimSpace(args)) == 0 {
+		args = []byte("{}")
+	}
+
+	var data any
+	if err := json.Unmarshal(args, &data); err != nil {
+		return &ValidationError{Reason: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	if err := validateValue(schema, schema, data, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateValue(root, schema Definition, data any, path string) *ValidationError {
+	schema = resolveRef(root, schema)
+	if data == nil && schema.Nullable {
+		return nil
+	}
+
+	switch schema.Type {
+	case Object:
+		obj, ok := data.(map[string]any)
+		if !ok {
+			return &ValidationError{Path: path, Reason: "expected object"}
+		}
+		for _, field := range schema.Required {
+			if _, exists := obj[field]; !exists {
+				return &ValidationError{Path: joinPath(path, field), Reason: "required field is missing"}
+			}
+		}
+		// Check the fields in sorted order so that the same violation is
+		// reported every time.
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, exists := schema.Properties[key]
+			if !exists {
+				continue
+			}
+			if err := validateValue(root, prop, obj[key], joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case Array:
+		arr, ok := data.([]any)
+		if !ok {
+			return &ValidationError{Path: path, Reason: "expected array"}
+		}
+		if schema.Items == nil {
+			return nil
+		}
+		for i, item := range arr {
+			if err := validateValue(root, *schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case String:
+		s, ok := data.(string)
+		if !ok {
+			return &ValidationError{Path: path, Reason: "expected string"}
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("value %q is not one of %v", s, schema.Enum)}
+		}
+	case Number, Integer, Boolean, Null:
+		if !validate(root, schema, data) {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("expected %s", schema.Type)}
+		}
+	}
+
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func validateObject(root, schema Definition, data any) bool {
 	dataMap, ok := data.(map[string]any)
 	if !ok {
 		return false
@@ -234,7 +471,7 @@ func validateObject(schema Definition, data any) bool {
 	}
 	for key, valueSchema := range schema.Properties {
 		value, exists := dataMap[key]
-		if exists && !Validate(valueSchema, value) {
+		if exists && !validate(root, valueSchema, value) {
 			return false
 		} else if !exists && contains(schema.Required, key) {
 			return false
@@ -243,13 +480,13 @@ func validateObject(schema Definition, data any) bool {
 	return true
 }
 
-func validateArray(schema Definition, data any) bool {
+func validateArray(root, schema Definition, data any) bool {
 	dataArray, ok := data.([]any)
 	if !ok {
 		return false
 	}
 	for _, item := range dataArray {
-		if !Validate(*schema.Items, item) {
+		if !validate(root, *schema.Items, item) {
 			return false
 		}
 	}
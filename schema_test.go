@@ -0,0 +1,86 @@
+package minds
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type comment struct {
+	Author  string    `json:"author"`
+	Text    string    `json:"text"`
+	Replies []comment `json:"replies"`
+}
+
+type discussion struct {
+	Title    string     `json:"title"`
+	Comments []comment  `json:"comments"`
+	Pinned   *comment   `json:"pinned,omitempty"`
+	Related  []category `json:"related"`
+}
+
+type category struct {
+	Name   string    `json:"name"`
+	Parent *category `json:"parent,omitempty"`
+}
+
+func TestGenerateSchemaRecursive(t *testing.T) {
+	t.Run("self-referential root", func(t *testing.T) {
+		is := is.New(t)
+
+		def, err := GenerateSchema(comment{})
+		is.NoErr(err)
+
+		is.Equal(def.Type, Object)
+		is.Equal(def.Properties["replies"].Type, Array)
+		is.Equal(def.Properties["replies"].Items.Ref, "#")
+		is.Equal(len(def.Defs), 0)
+
+		data, err := json.Marshal(def)
+		is.NoErr(err)
+		is.True(json.Valid(data))
+	})
+
+	t.Run("recursive types below the root", func(t *testing.T) {
+		is := is.New(t)
+
+		def, err := GenerateSchema(discussion{})
+		is.NoErr(err)
+
+		is.Equal(def.Properties["comments"].Items.Ref, "#/$defs/comment")
+		is.Equal(def.Properties["pinned"].Ref, "#/$defs/comment")
+		is.Equal(def.Properties["related"].Items.Ref, "#/$defs/category")
+		is.Equal(def.Properties["title"].Type, String)
+
+		is.Equal(len(def.Defs), 2)
+		is.Equal(def.Defs["comment"].Properties["replies"].Items.Ref, "#/$defs/comment")
+		is.Equal(def.Defs["category"].Properties["parent"].Ref, "#/$defs/category")
+	})
+
+	t.Run("validates recursive data", func(t *testing.T) {
+		is := is.New(t)
+
+		def, err := GenerateSchema(discussion{})
+		is.NoErr(err)
+
+		valid := `{"title": "Go", "related": [], "comments": [
+			{"author": "a", "text": "hi", "replies": [{"author": "b", "text": "hello", "replies": []}]}
+		]}`
+		var d discussion
+		is.NoErr(VerifySchemaAndUnmarshal(*def, []byte(valid), &d))
+		is.Equal(d.Comments[0].Replies[0].Author, "b")
+
+		invalid := `{"title": "Go", "related": [], "comments": [
+			{"author": "a", "text": "hi", "replies": [{"author": "b", "replies": []}]}
+		]}`
+		is.True(VerifySchemaAndUnmarshal(*def, []byte(invalid), &d) != nil)
+
+		err = ValidateArguments(*def, []byte(invalid))
+		var verr *ValidationError
+		is.True(err != nil)
+		is.True(errors.As(err, &verr))
+		is.Equal(verr.Path, "comments[0].replies[0].text")
+	})
+}
@@ -2,7 +2,10 @@ package minds
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 )
 
 type ToolType string
@@ -28,42 +31,276 @@ type Tool interface {
 	Call(context.Context, []byte) ([]byte, error)
 }
 
+// ErrToolBudgetExceeded is returned by HandleFunctionCalls when a registry's
+// invocation limit has been reached. Use errors.Is to detect it.
+var ErrToolBudgetExceeded = errors.New("tool budget exceeded")
+
+// ErrToolAlreadyRegistered is returned by ToolRegistry.Register when a tool
+// with the same name is already registered. Use RegisterOrReplace to override
+// a tool intentionally.
+var ErrToolAlreadyRegistered = errors.New("tool already registered")
+
+// ToolRegistry holds the tools available to a model. Implementations must be
+// safe for concurrent use, since a registry may be shared between
+// conversations and changed while they run.
 type ToolRegistry interface {
-	// Register adds a new function to the registry
-	Register(t Tool) error
-	// Lookup retrieves a function by name
+	// Register adds a new function to the registry. It returns an error
+	// wrapping ErrToolAlreadyRegistered if the name is taken.
+	Register(t Tool, opts ...ToolOption) error
+	// RegisterOrReplace adds a new function to the registry, replacing any
+	// function registered under the same name
+	RegisterOrReplace(t Tool, opts ...ToolOption) error
+	// Unregister removes the named function and reports whether it was
+	// registered
+	Unregister(name string) bool
+	// Has reports whether a function with the given name is registered
+	Has(name string) bool
+	// Lookup retrieves a function by name and reports whether it is
+	// registered; it is the registry's Get
 	Lookup(name string) (Tool, bool)
 	// List returns all registered functions
 	List() []Tool
+	// OnInvoke registers a callback that is told about every tool execution
+	// performed by HandleFunctionCalls
+	OnInvoke(fn func(ToolInvocation))
+}
+
+// ToolRegistryKey is the thread metadata key under which middleware, such as
+// middleware.ToolSelector, sets a ToolRegistry to use for the next generation
+// instead of the provider's own. Providers acting as ThreadHandlers read it
+// with ThreadToolRegistry.
+const ToolRegistryKey = "tool_registry"
+
+// ThreadToolRegistry returns the ToolRegistry set on tc under
+// ToolRegistryKey, or nil if there is none.
+func ThreadToolRegistry(tc ThreadContext) ToolRegistry {
+	registry, _ := tc.Metadata()[ToolRegistryKey].(ToolRegistry)
+	return registry
+}
+
+// ToolInvocation describes a single tool execution reported to OnInvoke
+// callbacks.
+type ToolInvocation struct {
+	// ID is the tool call ID assigned by the model, if any.
+	ID         string
+	Name       string
+	Arguments  []byte
+	Duration   time.Duration
+	ResultSize int
+	Err        error
+	// Result is the tool's output. It is nil if the tool failed.
+	Result []byte
+}
+
+// ToolRegistryOption configures a registry created with NewToolRegistry.
+type ToolRegistryOption func(*toolRegistry)
+
+// WithMaxInvocations caps the total number of tool calls HandleFunctionCalls
+// will execute through the registry. Once reached, HandleFunctionCalls returns
+// ErrToolBudgetExceeded. Use a registry per conversation to bound each one
+// independently. A value of zero or less means no limit.
+func WithMaxInvocations(n int) ToolRegistryOption {
+	return func(r *toolRegistry) {
+		r.maxInvocations = n
+	}
+}
+
+// ToolOption configures an individual tool when it is registered.
+type ToolOption func(*toolConfig)
+
+type toolConfig struct {
+	limit int
+}
+
+// WithToolHistory makes providers record every tool execution in the thread
+// metadata under ToolHistoryKey. It is off by default, so threads that don't
+// need the history don't carry it. See TrackToolHistory.
+func WithToolHistory() ToolRegistryOption {
+	return func(r *toolRegistry) {
+		r.history = true
+	}
 }
 
-func NewToolRegistry() ToolRegistry {
-	return &toolRegistry{
-		tools: make(map[string]Tool),
+// WithToolLimit caps the number of times a single tool may be called through
+// the registry. A value of zero or less means no limit.
+func WithToolLimit(n int) ToolOption {
+	return func(c *toolConfig) {
+		c.limit = n
 	}
 }
 
+func NewToolRegistry(opts ...ToolRegistryOption) ToolRegistry {
+	r := &toolRegistry{
+		tools:       make(map[string]Tool),
+		limits:      make(map[string]int),
+		invocations: make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
 type toolRegistry struct {
-	tools map[string]Tool
+	mu             sync.Mutex
+	tools          map[string]Tool
+	limits         map[string]int
+	maxInvocations int
+	total          int
+	invocations    map[string]int
+	hooks          []func(ToolInvocation)
+	history        bool
 }
 
-func (t *toolRegistry) Register(tool Tool) error {
+func (t *toolRegistry) Register(tool Tool, opts ...ToolOption) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	if _, exists := t.tools[tool.Name()]; exists {
-		return fmt.Errorf("tool %s already registered", tool.Name())
+		return fmt.Errorf("%w: %s", ErrToolAlreadyRegistered, tool.Name())
 	}
-	t.tools[tool.Name()] = tool
+
+	t.register(tool, opts)
 	return nil
 }
 
+// RegisterOrReplace registers tool, replacing any tool of the same name along
+// with its call limit. Calls already made to the name still count towards
+// the new tool's limit.
+func (t *toolRegistry) RegisterOrReplace(tool Tool, opts ...ToolOption) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.limits, tool.Name())
+	t.register(tool, opts)
+	return nil
+}
+
+// register adds tool to the registry. The caller must hold t.mu.
+func (t *toolRegistry) register(tool Tool, opts []ToolOption) {
+	var cfg toolConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	t.tools[tool.Name()] = tool
+	if cfg.limit > 0 {
+		t.limits[tool.Name()] = cfg.limit
+	}
+}
+
+// Unregister removes the named tool along with its call limit. Calls already
+// made are still counted, against both the registry's total limit and the
+// limit of a tool later registered under the same name, as with
+// RegisterOrReplace.
+func (t *toolRegistry) Unregister(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, exists := t.tools[name]; !exists {
+		return false
+	}
+
+	delete(t.tools, name)
+	delete(t.limits, name)
+	return true
+}
+
+func (t *toolRegistry) Has(name string) bool {
+	_, ok := t.Lookup(name)
+	return ok
+}
+
 func (t *toolRegistry) Lookup(name string) (Tool, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	tool, ok := t.tools[name]
 	return tool, ok
 }
 
 func (t *toolRegistry) List() []Tool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	tools := make([]Tool, 0, len(t.tools))
 	for _, tool := range t.tools {
 		tools = append(tools, tool)
 	}
 	return tools
 }
+
+// registryWrapper is implemented by registries that wrap another, such as
+// FilteredRegistry and IdempotentRegistry. HandleFunctionCalls and
+// TrackToolHistory look through wrappers for the optional behavior below, so
+// a wrapper doesn't have to forward it.
+type registryWrapper interface {
+	unwrap() ToolRegistry
+}
+
+// registryLayers returns registry followed by every registry it wraps,
+// outermost first.
+func registryLayers(registry ToolRegistry) []ToolRegistry {
+	var layers []ToolRegistry
+	for registry != nil {
+		layers = append(layers, registry)
+		w, ok := registry.(registryWrapper)
+		if !ok {
+			break
+		}
+		registry = w.unwrap()
+	}
+	return layers
+}
+
+// invocationBudget is implemented by registries that limit how many times
+// tools may be called. HandleFunctionCalls reserves an invocation before
+// calling a tool.
+type invocationBudget interface {
+	reserve(name string) error
+}
+
+func (t *toolRegistry) reserve(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.maxInvocations > 0 && t.total >= t.maxInvocations {
+		return fmt.Errorf("%w: limit of %d tool calls reached", ErrToolBudgetExceeded, t.maxInvocations)
+	}
+	if limit, ok := t.limits[name]; ok && t.invocations[name] >= limit {
+		return fmt.Errorf("%w: limit of %d calls to `%s` reached", ErrToolBudgetExceeded, limit, name)
+	}
+
+	t.total++
+	t.invocations[name]++
+	return nil
+}
+
+// OnInvoke registers fn to be called after each tool execution. Callbacks may
+// be registered at any time and are called in registration order. When tools
+// are executed concurrently, fn may be called concurrently too.
+func (t *toolRegistry) OnInvoke(fn func(ToolInvocation)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks = append(t.hooks, fn)
+}
+
+func (t *toolRegistry) recordsHistory() bool {
+	return t.history
+}
+
+// invocationReporter is implemented by registries that report tool
+// executions to OnInvoke callbacks.
+type invocationReporter interface {
+	reportInvocation(ToolInvocation)
+}
+
+func (t *toolRegistry) reportInvocation(inv ToolInvocation) {
+	t.mu.Lock()
+	hooks := append([]func(ToolInvocation){}, t.hooks...)
+	t.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(inv)
+	}
+}
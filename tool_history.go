@@ -0,0 +1,101 @@
+package minds
+
+import (
+	"context"
+	"sync"
+)
+
+// ToolHistoryKey is the thread metadata key under which providers acting as
+// ThreadHandlers record a []ToolInvocation of every tool executed on the
+// thread, oldest first, when their registry was created with
+// WithToolHistory. Use ToolHistory to read it.
+const ToolHistoryKey = "tool_history"
+
+// ToolHistory returns the tool executions recorded on tc, oldest first, or
+// nil if there are none. The result is a copy.
+//
+// Example:
+//
+//	for _, inv := range minds.ToolHistory(tc) {
+//	    fmt.Printf("%s(%s) -> %s\n", inv.Name, inv.Arguments, inv.Result)
+//	}
+func ToolHistory(tc ThreadContext) []ToolInvocation {
+	history, _ := tc.Metadata()[ToolHistoryKey].([]ToolInvocation)
+	return append([]ToolInvocation(nil), history...)
+}
+
+// ToolHistoryRecorder collects the tool executions performed by
+// HandleFunctionCalls during a single call, so they can be added to a thread
+// afterwards. A nil recorder records nothing.
+type ToolHistoryRecorder struct {
+	mu      sync.Mutex
+	entries []ToolInvocation
+}
+
+type toolHistoryContextKey struct{}
+
+// historyRecorder is implemented by registries that can record tool history.
+type historyRecorder interface {
+	recordsHistory() bool
+}
+
+// recordsHistory reports whether registry, or a registry it wraps, records
+// tool history.
+func recordsHistory(registry ToolRegistry) bool {
+	for _, layer := range registryLayers(registry) {
+		if h, ok := layer.(historyRecorder); ok && h.recordsHistory() {
+			return true
+		}
+	}
+	return false
+}
+
+// TrackToolHistory prepares ctx to record the tools HandleFunctionCalls
+// executes with it. If registry was not created with WithToolHistory, ctx is
+// returned unchanged with a nil recorder, so tracking costs nothing when
+// unused. Providers call it around GenerateContent in HandleThread:
+//
+//	ctx, history := minds.TrackToolHistory(tc.Context(), registry)
+//	resp, err := p.GenerateContent(ctx, req)
+//	...
+//	tc = history.Record(tc)
+func TrackToolHistory(ctx context.Context, registry ToolRegistry) (context.Context, *ToolHistoryRecorder) {
+	if !recordsHistory(registry) {
+		return ctx, nil
+	}
+
+	recorder := &ToolHistoryRecorder{}
+	return context.WithValue(ctx, toolHistoryContextKey{}, recorder), recorder
+}
+
+// Record returns tc with the collected executions appended to its tool
+// history. It returns tc unchanged if nothing was collected.
+func (r *ToolHistoryRecorder) Record(tc ThreadContext) ThreadContext {
+	if r == nil {
+		return tc
+	}
+
+	r.mu.Lock()
+	entries := append([]ToolInvocation(nil), r.entries...)
+	r.mu.Unlock()
+
+	if len(entries) == 0 {
+		return tc
+	}
+
+	history := append(ToolHistory(tc), entries...)
+	return tc.With(SetKeyValue(ToolHistoryKey, history))
+}
+
+func (r *ToolHistoryRecorder) add(inv ToolInvocation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, inv)
+}
+
+// recordToolHistory adds inv to the recorder carried by ctx, if any.
+func recordToolHistory(ctx context.Context, inv ToolInvocation) {
+	if r, ok := ctx.Value(toolHistoryContextKey{}).(*ToolHistoryRecorder); ok {
+		r.add(inv)
+	}
+}
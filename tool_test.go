@@ -0,0 +1,268 @@
+package minds
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func newEchoTool(t *testing.T, name string) Tool {
+	t.Helper()
+	tool, err := WrapFunction(name, "Echo the input", struct {
+		Input string `json:"input"`
+	}{}, echoArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tool
+}
+
+func toolCalls(names ...string) []ToolCall {
+	calls := make([]ToolCall, 0, len(names))
+	for _, name := range names {
+		calls = append(calls, ToolCall{Function: FunctionCall{Name: name, Parameters: []byte(`{"input":"x"}`)}})
+	}
+	return calls
+}
+
+func TestToolRegistryBudget(t *testing.T) {
+	t.Run("max invocations", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry(WithMaxInvocations(2))
+		is.NoErr(registry.Register(newEchoTool(t, "echo")))
+
+		results, err := HandleFunctionCalls(context.Background(), toolCalls("echo", "echo", "echo"), registry)
+		is.True(errors.Is(err, ErrToolBudgetExceeded))
+		is.Equal(string(results[0].Function.Result), `{"input":"x"}`)
+		is.Equal(string(results[1].Function.Result), `{"input":"x"}`)
+		is.Equal(len(results[2].Function.Result), 0)
+
+		// The budget spans calls to HandleFunctionCalls.
+		_, err = HandleFunctionCalls(context.Background(), toolCalls("echo"), registry)
+		is.True(errors.Is(err, ErrToolBudgetExceeded))
+	})
+
+	t.Run("per tool limit", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry()
+		is.NoErr(registry.Register(newEchoTool(t, "limited"), WithToolLimit(1)))
+		is.NoErr(registry.Register(newEchoTool(t, "free")))
+
+		_, err := HandleFunctionCalls(context.Background(), toolCalls("limited", "free", "free"), registry)
+		is.NoErr(err)
+
+		_, err = HandleFunctionCalls(context.Background(), toolCalls("limited"), registry)
+		is.True(errors.Is(err, ErrToolBudgetExceeded))
+	})
+
+	t.Run("invalid arguments do not consume budget", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry(WithMaxInvocations(1))
+		is.NoErr(registry.Register(newEchoTool(t, "echo")))
+
+		bad := []ToolCall{{Function: FunctionCall{Name: "echo", Parameters: []byte(`{}`)}}}
+		_, err := HandleFunctionCalls(context.Background(), bad, registry)
+		is.NoErr(err)
+
+		_, err = HandleFunctionCalls(context.Background(), toolCalls("echo"), registry)
+		is.NoErr(err)
+	})
+}
+
+func TestToolRegistryOnInvoke(t *testing.T) {
+	is := is.New(t)
+	registry := NewToolRegistry()
+	is.NoErr(registry.Register(newEchoTool(t, "echo")))
+
+	failing, err := WrapFunction("fail", "Always fails", struct{}{}, func(context.Context, []byte) ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	is.NoErr(err)
+	is.NoErr(registry.Register(failing))
+
+	var mu sync.Mutex
+	var invocations []ToolInvocation
+	registry.OnInvoke(func(inv ToolInvocation) {
+		mu.Lock()
+		defer mu.Unlock()
+		invocations = append(invocations, inv)
+	})
+
+	calls := toolCalls("echo")
+	calls = append(calls, ToolCall{ID: "call_2", Function: FunctionCall{Name: "fail", Parameters: []byte(`{}`)}})
+
+	// Run several batches concurrently to exercise the hook under -race.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch := append([]ToolCall{}, calls...)
+			_, err := HandleFunctionCalls(context.Background(), batch, registry)
+			is.NoErr(err)
+		}()
+	}
+	wg.Wait()
+
+	is.Equal(len(invocations), 8)
+	for _, inv := range invocations {
+		switch inv.Name {
+		case "echo":
+			is.NoErr(inv.Err)
+			is.Equal(string(inv.Arguments), `{"input":"x"}`)
+			is.Equal(inv.ResultSize, len(`{"input":"x"}`))
+		case "fail":
+			is.Equal(inv.ID, "call_2")
+			is.Equal(inv.Err.Error(), "boom")
+		default:
+			t.Fatalf("unexpected invocation %s", inv.Name)
+		}
+	}
+}
+
+func TestToolRegistryUnregister(t *testing.T) {
+	is := is.New(t)
+	registry := NewToolRegistry()
+	is.NoErr(registry.Register(newEchoTool(t, "echo")))
+	is.NoErr(registry.Register(newEchoTool(t, "other")))
+
+	is.True(registry.Has("echo"))
+	tool, ok := registry.Lookup("echo")
+	is.True(ok)
+	is.Equal(tool.Name(), "echo")
+
+	is.True(registry.Unregister("echo"))
+	is.True(!registry.Unregister("echo")) // already removed
+	is.True(!registry.Has("echo"))
+	_, ok = registry.Lookup("echo")
+	is.True(!ok)
+	is.Equal(len(registry.List()), 1)
+
+	// A removed tool can be registered again.
+	is.NoErr(registry.Register(newEchoTool(t, "echo")))
+	is.True(registry.Has("echo"))
+}
+
+func TestToolRegistryUnregisterKeepsCount(t *testing.T) {
+	is := is.New(t)
+	registry := NewToolRegistry()
+	is.NoErr(registry.Register(newEchoTool(t, "echo"), WithToolLimit(1)))
+
+	_, err := HandleFunctionCalls(context.Background(), toolCalls("echo"), registry)
+	is.NoErr(err)
+
+	// Registering the tool again does not reset its limit.
+	is.True(registry.Unregister("echo"))
+	is.NoErr(registry.Register(newEchoTool(t, "echo"), WithToolLimit(1)))
+	_, err = HandleFunctionCalls(context.Background(), toolCalls("echo"), registry)
+	is.True(errors.Is(err, ErrToolBudgetExceeded))
+}
+
+func TestToolRegistryConcurrentChanges(t *testing.T) {
+	is := is.New(t)
+	registry := NewToolRegistry()
+	tools := []Tool{newEchoTool(t, "a"), newEchoTool(t, "b"), newEchoTool(t, "c")}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tool := tools[(i+j)%len(tools)]
+				registry.Register(tool)
+				registry.Has(tool.Name())
+				registry.List()
+				HandleFunctionCalls(context.Background(), toolCalls(tool.Name()), registry)
+				registry.Unregister(tool.Name())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	is.Equal(len(registry.List()), 0)
+}
+
+func TestToolRegistryDuplicates(t *testing.T) {
+	t.Run("register rejects a duplicate name", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry()
+		first := newEchoTool(t, "echo")
+		is.NoErr(registry.Register(first))
+
+		err := registry.Register(newEchoTool(t, "echo"))
+		is.True(errors.Is(err, ErrToolAlreadyRegistered))
+
+		tool, _ := registry.Lookup("echo")
+		is.Equal(tool, first) // the original is kept
+		is.Equal(len(registry.List()), 1)
+	})
+
+	t.Run("register or replace overrides", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry()
+		is.NoErr(registry.Register(newEchoTool(t, "echo"), WithToolLimit(1)))
+
+		replacement := newEchoTool(t, "echo")
+		is.NoErr(registry.RegisterOrReplace(replacement))
+
+		tool, _ := registry.Lookup("echo")
+		is.Equal(tool, replacement)
+		is.Equal(len(registry.List()), 1)
+
+		// The old tool's limit no longer applies.
+		_, err := HandleFunctionCalls(context.Background(), toolCalls("echo", "echo"), registry)
+		is.NoErr(err)
+	})
+
+	t.Run("register or replace adds a new name", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry()
+		is.NoErr(registry.RegisterOrReplace(newEchoTool(t, "echo")))
+		is.True(registry.Has("echo"))
+	})
+}
+
+func TestToolHistory(t *testing.T) {
+	t.Run("records executions across calls", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry(WithToolHistory())
+		is.NoErr(registry.Register(newEchoTool(t, "search")))
+		is.NoErr(registry.Register(newEchoTool(t, "calculate")))
+
+		tc := NewThreadContext(context.Background())
+		for _, name := range []string{"search", "calculate"} {
+			ctx, history := TrackToolHistory(tc.Context(), registry)
+			_, err := HandleFunctionCalls(ctx, toolCalls(name), registry)
+			is.NoErr(err)
+			tc = history.Record(tc)
+		}
+
+		got := ToolHistory(tc)
+		is.Equal(len(got), 2)
+		is.Equal(got[0].Name, "search")
+		is.Equal(got[1].Name, "calculate")
+		is.Equal(string(got[1].Arguments), `{"input":"x"}`)
+		is.Equal(string(got[1].Result), `{"input":"x"}`)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		is := is.New(t)
+		registry := NewToolRegistry()
+		is.NoErr(registry.Register(newEchoTool(t, "echo")))
+
+		ctx, history := TrackToolHistory(context.Background(), registry)
+		is.True(history == nil)
+
+		_, err := HandleFunctionCalls(ctx, toolCalls("echo"), registry)
+		is.NoErr(err)
+
+		tc := history.Record(NewThreadContext(ctx))
+		is.Equal(ToolHistory(tc), nil)
+		_, ok := tc.Metadata()[ToolHistoryKey]
+		is.True(!ok)
+	})
+}
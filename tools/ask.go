@@ -3,10 +3,13 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"slices"
+	"strings"
 
 	"github.com/chriscow/minds"
 
@@ -35,6 +38,12 @@ const (
 	MockModel = "mock-model"
 
 	maxResponseTokens = 1000
+
+	mockStreamChunkSize = 8
+
+	// defaultSchemaName is the schema name sent for anonymous response types
+	// when WithSchemaName is not used.
+	defaultSchemaName = "response"
 )
 
 var MockLLMResponse = "mock-llm-response"
@@ -51,6 +60,10 @@ type options struct {
 	maxTokens int
 	messages  minds.Messages
 	response  *openai.ChatCompletionResponse
+
+	schemaName    string
+	messagesOnly  bool
+	disableRepair bool
 }
 
 func IsDeepSeekModel(model string) bool {
@@ -93,6 +106,34 @@ func WithMessages(messages minds.Messages) Option {
 	}
 }
 
+// WithMessagesOnly sends the WithMessages thread as-is, without appending the
+// prompt as a final user message. Use it when the thread already ends with the
+// user's turn.
+func WithMessagesOnly(messagesOnly bool) Option {
+	return func(o *options) {
+		o.messagesOnly = messagesOnly
+	}
+}
+
+// WithJSONRepair enables or disables repairing malformed JSON with
+// minds.RepairJSON when a structured response fails to unmarshal. Repair is
+// enabled by default.
+func WithJSONRepair(enabled bool) Option {
+	return func(o *options) {
+		o.disableRepair = !enabled
+	}
+}
+
+// WithSchemaName sets the name of the JSON schema sent with structured
+// requests. By default the name of the response type is used, which is empty
+// for anonymous structs and contains brackets for generic types; some
+// endpoints reject both.
+func WithSchemaName(name string) Option {
+	return func(o *options) {
+		o.schemaName = name
+	}
+}
+
 func WantCompletionResponse(response *openai.ChatCompletionResponse) Option {
 	return func(o *options) {
 		o.response = response
@@ -183,27 +224,9 @@ func AskOpenAI(ctx context.Context, prompt string, opts ...Option) (string, erro
 	config := openai.DefaultConfig(o.apiKey)
 	config.BaseURL = o.baseURL
 
-	var messages []openai.ChatCompletionMessage
-
-	if len(o.messages) > 0 {
-		// Use the provided messages
-		messages = convertToOpenAIMessages(o.messages)
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: prompt,
-		})
-	} else {
-		messages = []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		}
-	}
-
 	req := &openai.ChatCompletionRequest{
 		Model:     o.model,
-		Messages:  messages,
+		Messages:  buildMessages(o, prompt),
 		MaxTokens: o.maxTokens,
 	}
 
@@ -265,7 +288,7 @@ func StructuredAsk[T any](ctx context.Context, prompt string, opts ...Option) (T
 		deepSeekOpts = append(deepSeekOpts, WithBaseURL(DeepSeekAPIURL), WithAPIKey(os.Getenv("DEEPSEEK_API_KEY")))
 		return StructuredAskOpenAI[T](ctx, name, prompt, deepSeekOpts...)
 	case MockModel:
-		if err := json.Unmarshal([]byte(MockLLMResponse), &zero); err != nil {
+		if err := unmarshalStructured([]byte(MockLLMResponse), &zero, !o.disableRepair); err != nil {
 			return zero, fmt.Errorf("failed to unmarshal mock response: %w", err)
 		}
 		return zero, MockLLMError
@@ -279,7 +302,217 @@ func StructuredAsk[T any](ctx context.Context, prompt string, opts ...Option) (T
 func StructuredAskOpenAI[T any](ctx context.Context, typeName, prompt string, opts ...Option) (T, error) {
 	var zero T // Zero value to return in error cases
 
-	// Process options
+	o := openAIOptions(opts...)
+
+	// Validate options for conflicts
+	if err := validateOptions(o); err != nil {
+		return zero, err
+	}
+
+	if o.apiKey == "" {
+		return zero, fmt.Errorf("OPENAI_API_KEY is not set")
+	}
+
+	req, err := structuredRequest[T](o, typeName, prompt)
+	if err != nil {
+		return zero, fmt.Errorf("StructuredAskOpenAI: %w", err)
+	}
+
+	client := openai.NewClientWithConfig(o.clientConfig())
+	resp, err := client.CreateChatCompletion(ctx, *req)
+	if err != nil {
+		llm := "OpenAI"
+
+		if IsDeepSeekModel(o.model) {
+			llm = "DeepSeek"
+		}
+
+		return zero, fmt.Errorf("StructuredAskOpenAI: %s API: %w. baseURL:%s maxTokens:%d model:%s key:%s", llm, err, o.baseURL, o.maxTokens, o.model, o.apiKey[0:5]+"...") // Mask API key in error message
+	}
+
+	var result T
+	if err := unmarshalStructured([]byte(resp.Choices[0].Message.Content), &result, !o.disableRepair); err != nil {
+		return zero, fmt.Errorf("StructuredAskOpenAI: failed to unmarshal response: %w. content:%s", err, resp.Choices[0].Message.Content)
+	}
+	return result, nil
+}
+
+// StructuredAskStream sends a prompt to an LLM and streams a structured
+// response of type T. As the JSON arrives, the accumulated buffer is parsed
+// leniently and onPartial is called whenever the partially decoded value
+// changes, so callers can render fields before the response is complete. The
+// fully parsed value is returned once the stream ends.
+// It accepts optional WithModel() to specify a model, otherwise uses default.
+func StructuredAskStream[T any](ctx context.Context, prompt string, onPartial func(partial T), opts ...Option) (T, error) {
+	// Process options only to determine the model
+	o := &options{
+		model:     getDefaultModel(),
+		maxTokens: maxResponseTokens,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var zero T // Zero value to return in error cases
+
+	// Validate options for conflicts
+	if err := validateOptions(o); err != nil {
+		return zero, err
+	}
+
+	t := reflect.TypeOf(zero)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := t.Name()
+
+	switch o.model {
+	case GPT41Nano, GPT41Mini, GPT41, GPT4oMini, O4Mini, O3Mini:
+		return StructuredAskStreamOpenAI[T](ctx, name, prompt, onPartial, opts...)
+	case DeepSeekChat, DeepSeekReasoner:
+		deepSeekOpts := append([]Option{}, opts...)
+		deepSeekOpts = append(deepSeekOpts, WithBaseURL(DeepSeekAPIURL), WithAPIKey(os.Getenv("DEEPSEEK_API_KEY")))
+		return StructuredAskStreamOpenAI[T](ctx, name, prompt, onPartial, deepSeekOpts...)
+	case MockModel:
+		// Replay the mock response in small chunks to exercise partial parsing
+		dec := &partialDecoder[T]{onPartial: onPartial, repair: !o.disableRepair}
+		for i := 0; i < len(MockLLMResponse); i += mockStreamChunkSize {
+			end := i + mockStreamChunkSize
+			if end > len(MockLLMResponse) {
+				end = len(MockLLMResponse)
+			}
+			dec.write(MockLLMResponse[i:end])
+		}
+		result, err := dec.decode()
+		if err != nil {
+			return zero, fmt.Errorf("failed to unmarshal mock response: %w", err)
+		}
+		return result, MockLLMError
+	default:
+		return zero, fmt.Errorf("unknown model: %s", o.model)
+	}
+}
+
+// StructuredAskStreamOpenAI streams a structured response of type T from
+// OpenAI, calling onPartial as the value is filled in.
+// It accepts optional WithModel() to specify a model, otherwise uses default.
+func StructuredAskStreamOpenAI[T any](ctx context.Context, typeName, prompt string, onPartial func(partial T), opts ...Option) (T, error) {
+	var zero T // Zero value to return in error cases
+
+	o := openAIOptions(opts...)
+
+	// Validate options for conflicts
+	if err := validateOptions(o); err != nil {
+		return zero, err
+	}
+
+	if o.apiKey == "" {
+		return zero, fmt.Errorf("OPENAI_API_KEY is not set")
+	}
+
+	req, err := structuredRequest[T](o, typeName, prompt)
+	if err != nil {
+		return zero, fmt.Errorf("StructuredAskStreamOpenAI: %w", err)
+	}
+	req.Stream = true
+
+	client := openai.NewClientWithConfig(o.clientConfig())
+	stream, err := client.CreateChatCompletionStream(ctx, *req)
+	if err != nil {
+		llm := "OpenAI"
+
+		if IsDeepSeekModel(o.model) {
+			llm = "DeepSeek"
+		}
+
+		return zero, fmt.Errorf("StructuredAskStreamOpenAI: %s API: %w. baseURL:%s maxTokens:%d model:%s key:%s", llm, err, o.baseURL, o.maxTokens, o.model, o.apiKey[0:5]+"...") // Mask API key in error message
+	}
+	defer stream.Close()
+
+	dec := &partialDecoder[T]{onPartial: onPartial, repair: !o.disableRepair}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return zero, fmt.Errorf("StructuredAskStreamOpenAI: stream: %w", err)
+		}
+		if len(resp.Choices) > 0 {
+			dec.write(resp.Choices[0].Delta.Content)
+		}
+	}
+
+	result, err := dec.decode()
+	if err != nil {
+		return zero, fmt.Errorf("StructuredAskStreamOpenAI: %w", err)
+	}
+	return result, nil
+}
+
+// partialDecoder accumulates streamed JSON and reports each distinct partial
+// value of T.
+type partialDecoder[T any] struct {
+	buf       strings.Builder
+	last      T
+	reported  bool
+	onPartial func(T)
+	repair    bool
+}
+
+func (d *partialDecoder[T]) write(delta string) {
+	d.buf.WriteString(delta)
+	if d.onPartial == nil || delta == "" {
+		return
+	}
+
+	doc, ok := completePartialJSON(d.buf.String())
+	if !ok {
+		return
+	}
+
+	var partial T
+	if err := json.Unmarshal([]byte(doc), &partial); err != nil {
+		return
+	}
+
+	if d.reported && reflect.DeepEqual(partial, d.last) {
+		return
+	}
+	d.last, d.reported = partial, true
+	d.onPartial(partial)
+}
+
+// decode parses the complete buffer, repairing it first if it is malformed
+// and repair is enabled.
+func (d *partialDecoder[T]) decode() (T, error) {
+	var result T
+	if err := unmarshalStructured([]byte(d.buf.String()), &result, d.repair); err != nil {
+		return result, fmt.Errorf("failed to unmarshal response: %w. content:%s", err, d.buf.String())
+	}
+	return result, nil
+}
+
+// unmarshalStructured decodes a structured response into v, repairing it
+// first if it is malformed and repair is true. The original error is returned
+// if repair fails.
+func unmarshalStructured(data []byte, v any, repair bool) error {
+	err := json.Unmarshal(data, v)
+	if err == nil || !repair {
+		return err
+	}
+
+	repaired, repairErr := minds.RepairJSON(data)
+	if repairErr != nil {
+		return err
+	}
+
+	return json.Unmarshal(repaired, v)
+}
+
+// openAIOptions applies opts over the OpenAI defaults taken from the environment.
+func openAIOptions(opts ...Option) *options {
 	o := &options{
 		model:     os.Getenv("OPENAI_DEFAULT_MODEL"),
 		baseURL:   os.Getenv("OPENAI_BASE_URL"),
@@ -299,21 +532,32 @@ func StructuredAskOpenAI[T any](ctx context.Context, typeName, prompt string, op
 		opt(o)
 	}
 
-	// Validate options for conflicts
-	if err := validateOptions(o); err != nil {
-		return zero, err
-	}
-
-	if o.apiKey == "" {
-		return zero, fmt.Errorf("OPENAI_API_KEY is not set")
-	}
+	return o
+}
 
+func (o *options) clientConfig() openai.ClientConfig {
 	config := openai.DefaultConfig(o.apiKey)
 	config.BaseURL = o.baseURL
+	return config
+}
+
+// structuredRequest builds the chat completion request shared by the
+// structured ask functions.
+func structuredRequest[T any](o *options, typeName, prompt string) (*openai.ChatCompletionRequest, error) {
+	var zero T
 
-	schema, err := GenerateJSONSchema(zero)
+	// The minds definition is sent as-is so recursive types keep their refs,
+	// which jsonschema.Definition cannot represent.
+	schema, err := minds.GenerateSchema(zero)
 	if err != nil {
-		return zero, fmt.Errorf("StructuredAskOpenAI: %w", err)
+		return nil, fmt.Errorf("failed to generate schema: %w", err)
+	}
+
+	if o.schemaName != "" {
+		typeName = o.schemaName
+	}
+	if typeName == "" {
+		typeName = defaultSchemaName
 	}
 
 	responseFormat := openai.ChatCompletionResponseFormat{
@@ -329,49 +573,12 @@ func StructuredAskOpenAI[T any](ctx context.Context, typeName, prompt string, op
 		responseFormat.JSONSchema = nil
 	}
 
-	var messages []openai.ChatCompletionMessage
-
-	if len(o.messages) > 0 {
-		// Use the provided messages
-		messages = convertToOpenAIMessages(o.messages)
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: prompt,
-		})
-	} else {
-		// Build messages from the prompt
-		messages = []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		}
-	}
-
-	req := &openai.ChatCompletionRequest{
+	return &openai.ChatCompletionRequest{
 		Model:          o.model,
-		Messages:       messages,
+		Messages:       buildMessages(o, prompt),
 		MaxTokens:      o.maxTokens,
 		ResponseFormat: &responseFormat,
-	}
-
-	client := openai.NewClientWithConfig(config)
-	resp, err := client.CreateChatCompletion(ctx, *req)
-	if err != nil {
-		llm := "OpenAI"
-
-		if IsDeepSeekModel(o.model) {
-			llm = "DeepSeek"
-		}
-
-		return zero, fmt.Errorf("StructuredAskOpenAI: %s API: %w. baseURL:%s maxTokens:%d model:%s key:%s", llm, err, o.baseURL, o.maxTokens, o.model, o.apiKey[0:5]+"...") // Mask API key in error message
-	}
-
-	var result T
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
-		return zero, fmt.Errorf("StructuredAskOpenAI: failed to unmarshal response: %w. content:%s", err, resp.Choices[0].Message.Content)
-	}
-	return result, nil
+	}, nil
 }
 
 func GenerateJSONSchema(v any) (*jsonschema.Definition, error) {
@@ -409,6 +616,10 @@ func ConvertSchemaDefinition(schema *minds.Definition) (*jsonschema.Definition,
 	// 	AdditionalProperties any `json:"additionalProperties,omitempty"`
 	// }
 
+	if schema.Ref != "" || len(schema.Defs) > 0 {
+		return nil, fmt.Errorf("ConvertSchemaDefinition: refs are not supported by jsonschema.Definition; use the minds.Definition directly")
+	}
+
 	result := jsonschema.Definition{
 		Type:                 jsonschema.DataType(schema.Type),
 		Description:          schema.Description,
@@ -466,6 +677,30 @@ func validateOptions(_ *options) error {
 	return nil
 }
 
+// buildMessages returns the messages to send: the WithMessages thread, if any,
+// followed by prompt as a user message. The prompt is not appended when
+// WithMessagesOnly is set or when it is empty and a thread was provided.
+func buildMessages(o *options, prompt string) []openai.ChatCompletionMessage {
+	if len(o.messages) == 0 {
+		return []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		}
+	}
+
+	messages := convertToOpenAIMessages(o.messages)
+	if o.messagesOnly || prompt == "" {
+		return messages
+	}
+
+	return append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: prompt,
+	})
+}
+
 // convertToOpenAIMessages converts minds.Messages to OpenAI chat completion messages
 func convertToOpenAIMessages(mindsMessages minds.Messages) []openai.ChatCompletionMessage {
 	var messages []openai.ChatCompletionMessage
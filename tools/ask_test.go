@@ -2,11 +2,17 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/chriscow/minds"
+	openai "github.com/sashabaranov/go-openai"
 )
 
 func TestGetDefaultModel(t *testing.T) {
@@ -221,6 +227,28 @@ func TestStructuredAskWithError(t *testing.T) {
 	}
 }
 
+func TestStructuredAskRepairsJSON(t *testing.T) {
+	MockLLMResponse = "```json\n{answer: \"mock-llm-response\",}\n```"
+	MockLLMError = nil
+
+	type result struct {
+		Answer string `json:"answer"`
+	}
+
+	response, err := StructuredAsk[result](context.Background(), "What is the capital of the moon?", WithModel(MockModel))
+	if err != nil {
+		t.Fatalf("expected repaired response, got error: %v", err)
+	}
+	if response.Answer != "mock-llm-response" {
+		t.Fatalf("expected answer 'mock-llm-response', got '%s'", response.Answer)
+	}
+
+	_, err = StructuredAsk[result](context.Background(), "What is the capital of the moon?", WithModel(MockModel), WithJSONRepair(false))
+	if err == nil {
+		t.Fatalf("expected unmarshal error with repair disabled, got nil")
+	}
+}
+
 func TestAskWithDeepSeekURL(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode")
@@ -449,3 +477,164 @@ func TestWithMessagesIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestStructuredAskStream(t *testing.T) {
+	type article struct {
+		Title string   `json:"title"`
+		Tags  []string `json:"tags"`
+	}
+
+	t.Run("mock_model", func(t *testing.T) {
+		MockLLMResponse = `{"title":"Streaming in Go","tags":["go","llm","json"]}`
+		MockLLMError = nil
+
+		var partials []article
+		result, err := StructuredAskStream[article](context.Background(), "Write an article", func(p article) {
+			partials = append(partials, p)
+		}, WithModel(MockModel))
+		if err != nil {
+			t.Fatalf("StructuredAskStream failed: %v", err)
+		}
+
+		want := article{Title: "Streaming in Go", Tags: []string{"go", "llm", "json"}}
+		if !reflect.DeepEqual(result, want) {
+			t.Fatalf("expected %+v, got %+v", want, result)
+		}
+		if len(partials) < 3 {
+			t.Fatalf("expected several partial updates, got %d", len(partials))
+		}
+		if !reflect.DeepEqual(partials[len(partials)-1], want) {
+			t.Fatalf("expected last partial to equal result, got %+v", partials[len(partials)-1])
+		}
+		for i := 1; i < len(partials); i++ {
+			if reflect.DeepEqual(partials[i], partials[i-1]) {
+				t.Fatalf("partial %d repeats the previous value", i)
+			}
+		}
+	})
+
+	t.Run("openai_stream", func(t *testing.T) {
+		chunks := []string{`{"title":"He`, `llo","tags":["a"`, `,"b"]}`}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Stream bool `json:"stream"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+				t.Errorf("expected a streaming request, err=%v", err)
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, c := range chunks {
+				data, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+					Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{Content: c}}},
+				})
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		var titles []string
+		result, err := StructuredAskStreamOpenAI[article](context.Background(), "article", "Write an article", func(p article) {
+			titles = append(titles, p.Title)
+		}, WithBaseURL(server.URL), WithAPIKey("test-key"))
+		if err != nil {
+			t.Fatalf("StructuredAskStreamOpenAI failed: %v", err)
+		}
+
+		if result.Title != "Hello" || !reflect.DeepEqual(result.Tags, []string{"a", "b"}) {
+			t.Fatalf("unexpected result %+v", result)
+		}
+		if titles[0] != "He" {
+			t.Fatalf("expected first partial title to be 'He', got %q", titles[0])
+		}
+	})
+}
+
+func TestWithMessagesOnly(t *testing.T) {
+	var received []openai.ChatCompletionMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []openai.ChatCompletionMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		received = req.Messages
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: "4"}}},
+		})
+	}))
+	defer server.Close()
+
+	messages := minds.Messages{
+		{Role: minds.RoleSystem, Content: "You are a helpful assistant."},
+		{Role: minds.RoleUser, Content: "What is 2+2?"},
+	}
+
+	tests := []struct {
+		name   string
+		prompt string
+		opts   []Option
+		want   int
+	}{
+		{name: "appends prompt by default", prompt: "Answer briefly.", want: 3},
+		{name: "messages only", prompt: "Answer briefly.", opts: []Option{WithMessagesOnly(true)}, want: 2},
+		{name: "empty prompt", prompt: "", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithBaseURL(server.URL), WithAPIKey("test-key"), WithMessages(messages)}, tt.opts...)
+			if _, err := AskOpenAI(context.Background(), tt.prompt, opts...); err != nil {
+				t.Fatalf("AskOpenAI failed: %v", err)
+			}
+
+			if len(received) != tt.want {
+				t.Fatalf("expected %d messages, got %d: %+v", tt.want, len(received), received)
+			}
+			if last := received[len(received)-1]; tt.want == 2 && last.Content != "What is 2+2?" {
+				t.Fatalf("expected thread to end with the original user turn, got %q", last.Content)
+			}
+		})
+	}
+}
+
+func TestWithSchemaName(t *testing.T) {
+	type named struct {
+		Answer string `json:"answer"`
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+		req  func(o *options) (*openai.ChatCompletionRequest, error)
+	}{
+		{"reflected name by default", nil, "named", func(o *options) (*openai.ChatCompletionRequest, error) {
+			return structuredRequest[named](o, "named", "prompt")
+		}},
+		{"override", []Option{WithSchemaName("answer_v2")}, "answer_v2", func(o *options) (*openai.ChatCompletionRequest, error) {
+			return structuredRequest[named](o, "named", "prompt")
+		}},
+		{"anonymous struct", nil, defaultSchemaName, func(o *options) (*openai.ChatCompletionRequest, error) {
+			return structuredRequest[struct {
+				Answer string `json:"answer"`
+			}](o, "", "prompt")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.req(openAIOptions(tt.opts...))
+			if err != nil {
+				t.Fatalf("structuredRequest failed: %v", err)
+			}
+			if got := req.ResponseFormat.JSONSchema.Name; got != tt.want {
+				t.Fatalf("expected schema name %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
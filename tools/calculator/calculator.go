@@ -1,7 +1,10 @@
 package calculator
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/chriscow/minds"
 )
@@ -13,24 +16,79 @@ const (
 	Lua      Syntax = "lua"
 )
 
-func NewCalculator(syntax Syntax) (minds.Tool, error) {
-	var fn minds.CallableFunc
+// Option configures a calculator created with NewCalculator.
+type Option func(*options)
+
+type options struct {
+	raw bool
+}
+
+// WithRawOutput makes the calculator return the evaluator's own string form
+// of the result, such as "12.0" from Starlark or "12" from Lua, instead of
+// the normalized {"result": <number>} JSON. Non-numeric results are allowed
+// in raw mode.
+func WithRawOutput(raw bool) Option {
+	return func(o *options) {
+		o.raw = raw
+	}
+}
+
+// evaluation is the value of an expression as produced by an engine.
+type evaluation struct {
+	// text is the evaluator's string form of the value.
+	text string
+	// number is the value, if isNumber is set.
+	number   float64
+	isNumber bool
+}
+
+// NewCalculator returns a tool that evaluates math expressions with the given
+// syntax. Both engines provide the same math module: floor, ceil, round,
+// sqrt, pow, sin, cos, tan, asin, acos, atan, atan2, abs, exp, log (with an
+// optional base), log10 and the constants pi and e. By default the result is
+// returned as {"result": <number>}, so switching between Lua and Starlark
+// gives identical output; see WithRawOutput.
+func NewCalculator(syntax Syntax, opts ...Option) (minds.Tool, error) {
+	var eval func(string) (evaluation, error)
 	var runtime string
 	switch syntax {
 	case Starlark:
-		fn = withStarlark
+		eval = withStarlark
 		runtime = "starlark"
 	case Lua:
-		fn = withLua
+		eval = withLua
 		runtime = "lua"
 	default:
 		return nil, fmt.Errorf("unsupported syntax: %s", syntax)
 	}
 
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	fn := func(_ context.Context, args []byte) ([]byte, error) {
+		var params struct{ Input string }
+		if err := json.Unmarshal(args, &params); err != nil {
+			return nil, err
+		}
+
+		result, err := eval(params.Input)
+		if err != nil {
+			return nil, err
+		}
+
+		if o.raw {
+			return []byte(result.text), nil
+		}
+
+		return formatResult(result)
+	}
+
 	return minds.WrapFunction(
 		"calculator",
-		fmt.Sprintf(`Useful for getting the result of a math expression. The input to this 
-		tool should be a valid mathematical expression that could be executed by 
+		fmt.Sprintf(`Useful for getting the result of a math expression. The input to this
+		tool should be a valid mathematical expression that could be executed by
 		a %s evaluator.`, runtime),
 		struct {
 			Input string `json:"input" description:"The mathematical expression to evaluate"`
@@ -38,3 +96,17 @@ func NewCalculator(syntax Syntax) (minds.Tool, error) {
 		fn,
 	)
 }
+
+// formatResult returns the normalized {"result": <number>} JSON for result.
+func formatResult(result evaluation) ([]byte, error) {
+	if !result.isNumber {
+		return nil, fmt.Errorf("expression must evaluate to a number, got %s", result.text)
+	}
+	if math.IsNaN(result.number) || math.IsInf(result.number, 0) {
+		return nil, fmt.Errorf("result is not a finite number: %s", result.text)
+	}
+
+	return json.Marshal(struct {
+		Result float64 `json:"result"`
+	}{result.number})
+}
@@ -1,22 +1,14 @@
 package calculator
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"math"
 
 	lua "github.com/yuin/gopher-lua"
 )
 
-// Execute runs the calculatorLua tool with the given input.
-func withLua(_ context.Context, args []byte) ([]byte, error) {
-	var params struct{ Input string }
-	if err := json.Unmarshal(args, &params); err != nil {
-		return nil, err
-	}
-	input := params.Input
-
+// withLua evaluates input as a Lua expression.
+func withLua(input string) (evaluation, error) {
 	L := lua.NewState()
 	defer L.Close()
 
@@ -147,15 +139,20 @@ func withLua(_ context.Context, args []byte) ([]byte, error) {
 	wrappedInput := fmt.Sprintf("return %s", input)
 
 	if err := L.DoString(wrappedInput); err != nil {
-		return nil, fmt.Errorf("error from evaluator: %s", err.Error())
+		return evaluation{}, fmt.Errorf("error from evaluator: %s", err.Error())
 	}
 
 	// Get the result from the stack
 	if L.GetTop() > 0 {
 		result := L.Get(-1)
 		L.Pop(1)
-		return []byte(result.String()), nil
+
+		eval := evaluation{text: result.String()}
+		if n, ok := result.(lua.LNumber); ok {
+			eval.number, eval.isNumber = float64(n), true
+		}
+		return eval, nil
 	}
 
-	return nil, fmt.Errorf("no result value found in script output")
+	return evaluation{}, fmt.Errorf("no result value found in script output")
 }
@@ -15,7 +15,7 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"1 + 2"}`))
 		is.NoErr(err)
-		is.Equal(string(result), "3")
+		is.Equal(string(result), `{"result":3}`)
 	})
 
 	t.Run("basic lua subtraction", func(t *testing.T) {
@@ -23,7 +23,7 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"2 - 1"}`))
 		is.NoErr(err)
-		is.Equal(string(result), "1")
+		is.Equal(string(result), `{"result":1}`)
 	})
 
 	t.Run("basic lua multiplication", func(t *testing.T) {
@@ -31,7 +31,7 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"2 * 3"}`))
 		is.NoErr(err)
-		is.Equal(string(result), "6")
+		is.Equal(string(result), `{"result":6}`)
 	})
 
 	t.Run("basic lua division", func(t *testing.T) {
@@ -39,7 +39,7 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"6 / 2"}`))
 		is.NoErr(err)
-		is.Equal(string(result), "3")
+		is.Equal(string(result), `{"result":3}`)
 	})
 
 	t.Run("basic lua modulo", func(t *testing.T) {
@@ -47,7 +47,7 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"7 % 3"}`)) // In Lua, modulo is also %
 		is.NoErr(err)
-		is.Equal(string(result), "1")
+		is.Equal(string(result), `{"result":1}`)
 	})
 
 	t.Run("basic lua exponentiation", func(t *testing.T) {
@@ -57,7 +57,7 @@ func TestCalculatorLua(t *testing.T) {
 		script := `{"input":"math.sqrt(16) + math.pow(2, 3)"}`
 		result, err := calc.Call(context.Background(), []byte(script))
 		is.NoErr(err)
-		is.Equal(string(result), "12")
+		is.Equal(string(result), `{"result":12}`)
 	})
 
 	// Additional Lua-specific tests
@@ -66,7 +66,7 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"2^3"}`)) // Lua's built-in power operator
 		is.NoErr(err)
-		is.Equal(string(result), "8")
+		is.Equal(string(result), `{"result":8}`)
 	})
 
 	t.Run("lua number formatting", func(t *testing.T) {
@@ -74,7 +74,7 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"22/7"}`)) // Will show decimal places
 		is.NoErr(err)
-		is.Equal(string(result)[:4], "3.14") // Check first 4 characters for approximate pi
+		is.Equal(string(result)[:14], `{"result":3.14`) // Check the leading digits for approximate pi
 	})
 
 	t.Run("lua math constants", func(t *testing.T) {
@@ -82,6 +82,6 @@ func TestCalculatorLua(t *testing.T) {
 		is.NoErr(err)
 		result, err := calc.Call(context.Background(), []byte(`{"input":"math.pi"}`))
 		is.NoErr(err)
-		is.Equal(string(result)[:4], "3.14") // Check first 4 characters for pi
+		is.Equal(string(result)[:14], `{"result":3.14`) // Check the leading digits for pi
 	})
 }
@@ -1,8 +1,6 @@
 package calculator
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"math"
 
@@ -15,14 +13,8 @@ const (
 	calculatorInputTopic = "calculator.tools.minds.thoughtnet.cloud"
 )
 
-// Execute runs the calculator tool with the given input.
-func withStarlark(_ context.Context, args []byte) ([]byte, error) {
-	var params struct{ Input string }
-	if err := json.Unmarshal(args, &params); err != nil {
-		return nil, err
-	}
-	input := params.Input
-
+// withStarlark evaluates input as a Starlark expression.
+func withStarlark(input string) (evaluation, error) {
 	mathModule := &starlarkstruct.Module{
 		Name: "math",
 		Members: starlark.StringDict{
@@ -66,15 +58,19 @@ func withStarlark(_ context.Context, args []byte) ([]byte, error) {
 	opt := syntax.FileOptions{}
 	globals, err := starlark.ExecFileOptions(&opt, thread, "script.star", wrappedInput, globals)
 	if err != nil {
-		return nil, fmt.Errorf("error from evaluator: %s", err.Error())
+		return evaluation{}, fmt.Errorf("error from evaluator: %s", err.Error())
 	}
 
 	// Get the "_" value from globals
 	if result, ok := globals["_"]; ok {
-		return []byte(result.String()), nil
+		eval := evaluation{text: result.String()}
+		if n, ok := starlark.AsFloat(result); ok {
+			eval.number, eval.isNumber = n, true
+		}
+		return eval, nil
 	}
 
-	return nil, fmt.Errorf("no result value found in script output")
+	return evaluation{}, fmt.Errorf("no result value found in script output")
 }
 
 func floor(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
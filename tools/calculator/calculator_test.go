@@ -0,0 +1,103 @@
+package calculator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// TestCalculatorParity runs the same expressions against every engine and
+// expects identical results.
+func TestCalculatorParity(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2", 3},
+		{"7 - 10", -3},
+		{"6 / 2", 3},
+		{"7 / 2", 3.5},
+		{"7 % 3", 1},
+		{"math.floor(2.7)", 2},
+		{"math.ceil(2.1)", 3},
+		{"math.round(2.5)", 3},
+		{"math.sqrt(16) + math.pow(2, 3)", 12},
+		{"math.sin(0)", 0},
+		{"math.cos(0)", 1},
+		{"math.tan(0)", 0},
+		{"math.asin(1)", math.Pi / 2},
+		{"math.acos(1)", 0},
+		{"math.atan(1)", math.Pi / 4},
+		{"math.atan2(1, 1)", math.Pi / 4},
+		{"math.abs(-4)", 4},
+		{"math.exp(0)", 1},
+		{"math.log(math.e)", 1},
+		{"math.log(8, 2)", 3},
+		{"math.log10(1000)", 3},
+		{"math.pi", math.Pi},
+		{"math.e", math.E},
+	}
+
+	failures := []string{
+		"math.sqrt(-1)",
+		"math.asin(2)",
+		"math.pow(0, -1)",
+		"math.log(-1)",
+		"1 < 2",
+		"1 +",
+	}
+
+	for _, syntax := range []Syntax{Lua, Starlark} {
+		calc, err := NewCalculator(syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s %s", syntax, tt.expr), func(t *testing.T) {
+				is := is.New(t)
+
+				args, _ := json.Marshal(map[string]string{"input": tt.expr})
+				out, err := calc.Call(context.Background(), args)
+				is.NoErr(err)
+
+				var result struct {
+					Result *float64 `json:"result"`
+				}
+				is.NoErr(json.Unmarshal(out, &result))
+				is.True(result.Result != nil)
+				is.True(math.Abs(*result.Result-tt.want) < 1e-9) // result matches
+			})
+		}
+
+		for _, expr := range failures {
+			t.Run(fmt.Sprintf("%s %s fails", syntax, expr), func(t *testing.T) {
+				is := is.New(t)
+
+				args, _ := json.Marshal(map[string]string{"input": expr})
+				_, err := calc.Call(context.Background(), args)
+				is.True(err != nil)
+			})
+		}
+	}
+}
+
+func TestCalculatorRawOutput(t *testing.T) {
+	is := is.New(t)
+
+	calc, err := NewCalculator(Starlark, WithRawOutput(true))
+	is.NoErr(err)
+	out, err := calc.Call(context.Background(), []byte(`{"input":"6 / 2"}`))
+	is.NoErr(err)
+	is.Equal(string(out), "3.0")
+
+	calc, err = NewCalculator(Lua, WithRawOutput(true))
+	is.NoErr(err)
+	out, err = calc.Call(context.Background(), []byte(`{"input":"1 < 2"}`))
+	is.NoErr(err)
+	is.Equal(string(out), "true")
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chriscow/minds"
+)
+
+// Chat continues the conversation held in tc. It appends prompt as a user
+// message, sends the whole thread to the LLM with Ask and appends the reply as
+// an assistant message. The returned thread holds the full conversation, so it
+// can be passed to the next call; tc itself is not modified.
+//
+// If prompt is empty the thread is sent as-is, which is useful when it already
+// ends with the user's turn. The thread replaces any WithMessages option.
+//
+// Example:
+//
+//	tc := minds.NewThreadContext(ctx).WithMessages(minds.Message{
+//	    Role: minds.RoleSystem, Content: "You are a helpful assistant.",
+//	})
+//	for scanner.Scan() {
+//	    tc, err = tools.Chat(ctx, tc, scanner.Text())
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    fmt.Println(tc.Messages().Last().Content)
+//	}
+func Chat(ctx context.Context, tc minds.ThreadContext, prompt string, opts ...Option) (minds.ThreadContext, error) {
+	var turn minds.Messages
+	if prompt != "" {
+		turn = append(turn, minds.Message{Role: minds.RoleUser, Content: prompt})
+	}
+
+	messages := append(tc.Messages(), turn...)
+	if len(messages) == 0 {
+		return tc, errors.New("Chat: no prompt or messages to send")
+	}
+
+	chatOpts := append(append([]Option{}, opts...), WithMessages(messages), WithMessagesOnly(true))
+	reply, err := Ask(ctx, "", chatOpts...)
+	if err != nil {
+		return tc, fmt.Errorf("Chat: %w", err)
+	}
+
+	turn = append(turn, minds.Message{Role: minds.RoleAssistant, Content: reply})
+	return tc.With(minds.AppendMessages(turn...)), nil
+}
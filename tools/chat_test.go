@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscow/minds"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func TestChat(t *testing.T) {
+	var received [][]openai.ChatCompletionMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []openai.ChatCompletionMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		received = append(received, req.Messages)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{
+				Role:    "assistant",
+				Content: "reply " + req.Messages[len(req.Messages)-1].Content,
+			}}},
+		})
+	}))
+	defer server.Close()
+
+	opts := []Option{WithModel(GPT41Nano), WithBaseURL(server.URL), WithAPIKey("test-key")}
+	ctx := context.Background()
+
+	start := minds.NewThreadContext(ctx).WithMessages(minds.Message{Role: minds.RoleSystem, Content: "Be brief."})
+	first, err := Chat(ctx, start, "hello", opts...)
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	second, err := Chat(ctx, first, "again", opts...)
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	want := []string{"Be brief.", "hello", "reply hello", "again", "reply again"}
+	got := second.Messages()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d messages, got %d: %+v", len(want), len(got), got)
+	}
+	for i, content := range want {
+		if got[i].Content != content {
+			t.Errorf("message %d: expected %q, got %q", i, content, got[i].Content)
+		}
+	}
+	if got[4].Role != minds.RoleAssistant || got[3].Role != minds.RoleUser {
+		t.Errorf("unexpected roles: %+v", got)
+	}
+
+	if len(received[1]) != 4 {
+		t.Errorf("expected the second request to carry the whole thread, got %+v", received[1])
+	}
+	if len(start.Messages()) != 1 || len(first.Messages()) != 3 {
+		t.Error("Chat modified its input thread")
+	}
+
+	t.Run("empty prompt sends the thread as-is", func(t *testing.T) {
+		tc := minds.NewThreadContext(ctx).WithMessages(minds.Message{Role: minds.RoleUser, Content: "ping"})
+		result, err := Chat(ctx, tc, "", opts...)
+		if err != nil {
+			t.Fatalf("Chat failed: %v", err)
+		}
+		if len(result.Messages()) != 2 || result.Messages().Last().Content != "reply ping" {
+			t.Errorf("unexpected thread: %+v", result.Messages())
+		}
+	})
+
+	t.Run("nothing to send", func(t *testing.T) {
+		if _, err := Chat(ctx, minds.NewThreadContext(ctx), "", opts...); err == nil {
+			t.Error("expected an error for an empty thread and prompt")
+		}
+	})
+}
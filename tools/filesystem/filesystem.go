@@ -0,0 +1,291 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chriscow/minds"
+)
+
+var (
+	ErrPathOutsideRoot = errors.New("path is outside the root directory")
+	ErrReadOnly        = errors.New("filesystem is read-only")
+	ErrFileTooLarge    = errors.New("file exceeds the maximum allowed size")
+)
+
+// DefaultMaxFileSize is the largest file, in bytes, that will be read or
+// written unless overridden with WithMaxFileSize.
+const DefaultMaxFileSize = 1 << 20
+
+type Option func(*FileSystem)
+
+// WithReadOnly prevents the WriteFile tool from modifying the filesystem.
+func WithReadOnly(readOnly bool) Option {
+	return func(fs *FileSystem) {
+		fs.readOnly = readOnly
+	}
+}
+
+// WithMaxFileSize limits the size in bytes of files that can be read or
+// written. A value of zero or less removes the limit.
+func WithMaxFileSize(size int) Option {
+	return func(fs *FileSystem) {
+		fs.maxFileSize = size
+	}
+}
+
+// FileSystem provides file tools that are confined to a root directory. Paths
+// given by the model are interpreted relative to the root, and any path that
+// resolves outside of it (via `..` or a symlink) is rejected.
+type FileSystem struct {
+	root        string
+	readOnly    bool
+	maxFileSize int
+}
+
+// New creates a FileSystem rooted at rootDir, which must be an existing
+// directory.
+//
+// Example:
+//
+//	fs, err := filesystem.New("./workspace", filesystem.WithReadOnly(true))
+//	tools, err := fs.Tools()
+//	for _, t := range tools {
+//		registry.Register(t)
+//	}
+func New(rootDir string, opts ...Option) (*FileSystem, error) {
+	abs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root directory: %w", err)
+	}
+
+	root, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root directory: %w", err)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("root %s is not a directory", rootDir)
+	}
+
+	fs := &FileSystem{
+		root:        root,
+		maxFileSize: DefaultMaxFileSize,
+	}
+	for _, opt := range opts {
+		opt(fs)
+	}
+
+	return fs, nil
+}
+
+// Tools returns the ReadFile, ListDir and, unless the filesystem is
+// read-only, WriteFile tools.
+func (fs *FileSystem) Tools() ([]minds.Tool, error) {
+	constructors := []func() (minds.Tool, error){fs.ReadFile, fs.ListDir}
+	if !fs.readOnly {
+		constructors = append(constructors, fs.WriteFile)
+	}
+
+	tools := make([]minds.Tool, 0, len(constructors))
+	for _, fn := range constructors {
+		tool, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// ReadFile returns a tool that reads the contents of a file.
+func (fs *FileSystem) ReadFile() (minds.Tool, error) {
+	return minds.WrapFunction(
+		"read_file",
+		`Reads the contents of a file. The path is relative to the workspace root.`,
+		struct {
+			Path string `json:"path" description:"Path of the file to read, relative to the workspace root"`
+		}{},
+		fs.readFile,
+	)
+}
+
+// WriteFile returns a tool that creates or overwrites a file. Parent
+// directories are created as needed.
+func (fs *FileSystem) WriteFile() (minds.Tool, error) {
+	return minds.WrapFunction(
+		"write_file",
+		`Writes content to a file, creating it or replacing its contents. The path is relative to the workspace root.`,
+		struct {
+			Path    string `json:"path" description:"Path of the file to write, relative to the workspace root"`
+			Content string `json:"content" description:"The full content to write to the file"`
+		}{},
+		fs.writeFile,
+	)
+}
+
+// ListDir returns a tool that lists the entries of a directory.
+func (fs *FileSystem) ListDir() (minds.Tool, error) {
+	return minds.WrapFunction(
+		"list_dir",
+		`Lists the files and directories in a directory. Directories end with a slash. The path is relative to the workspace root; use "." for the root itself.`,
+		struct {
+			Path string `json:"path" description:"Path of the directory to list, relative to the workspace root"`
+		}{},
+		fs.listDir,
+	)
+}
+
+func (fs *FileSystem) readFile(_ context.Context, args []byte) ([]byte, error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, err
+	}
+
+	path, err := fs.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", params.Path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("reading %s: path is a directory", params.Path)
+	}
+	if fs.maxFileSize > 0 && info.Size() > int64(fs.maxFileSize) {
+		return nil, fmt.Errorf("reading %s: %w (%d > %d bytes)", params.Path, ErrFileTooLarge, info.Size(), fs.maxFileSize)
+	}
+
+	return os.ReadFile(path)
+}
+
+func (fs *FileSystem) writeFile(_ context.Context, args []byte) ([]byte, error) {
+	var params struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, err
+	}
+
+	if fs.readOnly {
+		return nil, fmt.Errorf("writing %s: %w", params.Path, ErrReadOnly)
+	}
+
+	if fs.maxFileSize > 0 && len(params.Content) > fs.maxFileSize {
+		return nil, fmt.Errorf("writing %s: %w (%d > %d bytes)", params.Path, ErrFileTooLarge, len(params.Content), fs.maxFileSize)
+	}
+
+	path, err := fs.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", params.Path, err)
+	}
+
+	if err := os.WriteFile(path, []byte(params.Content), 0o644); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", params.Path, err)
+	}
+
+	return []byte(fmt.Sprintf("wrote %d bytes to %s", len(params.Content), params.Path)), nil
+}
+
+func (fs *FileSystem) listDir(_ context.Context, args []byte) ([]byte, error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, err
+	}
+
+	path, err := fs.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", params.Path, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return []byte(strings.Join(names, "\n")), nil
+}
+
+// resolve maps a model supplied path onto the filesystem, rejecting anything
+// that escapes the root. Symlinks are followed for the longest existing prefix
+// of the path so a link inside the root cannot point outside of it.
+func (fs *FileSystem) resolve(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("%s: %w", path, ErrPathOutsideRoot)
+	}
+
+	full := filepath.Join(fs.root, path)
+	if !fs.within(full) {
+		return "", fmt.Errorf("%s: %w", path, ErrPathOutsideRoot)
+	}
+
+	// Walk up to the nearest existing ancestor and resolve its symlinks.
+	existing := full
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	resolved = filepath.Join(append([]string{resolved}, rest...)...)
+
+	if !fs.within(resolved) {
+		return "", fmt.Errorf("%s: %w", path, ErrPathOutsideRoot)
+	}
+
+	return resolved, nil
+}
+
+func (fs *FileSystem) within(path string) bool {
+	rel, err := filepath.Rel(fs.root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
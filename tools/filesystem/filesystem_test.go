@@ -0,0 +1,102 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestFileSystem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("write, read and list", func(t *testing.T) {
+		is := is.New(t)
+		fs, err := New(t.TempDir())
+		is.NoErr(err)
+
+		write, err := fs.WriteFile()
+		is.NoErr(err)
+		_, err = write.Call(ctx, []byte(`{"path":"notes/todo.txt","content":"buy milk"}`))
+		is.NoErr(err)
+
+		read, err := fs.ReadFile()
+		is.NoErr(err)
+		content, err := read.Call(ctx, []byte(`{"path":"notes/todo.txt"}`))
+		is.NoErr(err)
+		is.Equal(string(content), "buy milk")
+
+		list, err := fs.ListDir()
+		is.NoErr(err)
+		entries, err := list.Call(ctx, []byte(`{"path":"."}`))
+		is.NoErr(err)
+		is.Equal(string(entries), "notes/")
+	})
+
+	t.Run("rejects paths escaping the root", func(t *testing.T) {
+		is := is.New(t)
+		fs, err := New(t.TempDir())
+		is.NoErr(err)
+
+		read, err := fs.ReadFile()
+		is.NoErr(err)
+
+		for _, path := range []string{"../secret", "a/../../secret", "/etc/passwd"} {
+			_, err = read.Call(ctx, []byte(`{"path":"`+path+`"}`))
+			is.True(errors.Is(err, ErrPathOutsideRoot))
+		}
+	})
+
+	t.Run("rejects symlinks escaping the root", func(t *testing.T) {
+		is := is.New(t)
+		root := t.TempDir()
+		outside := t.TempDir()
+		is.NoErr(os.WriteFile(filepath.Join(outside, "secret"), []byte("x"), 0o644))
+		is.NoErr(os.Symlink(outside, filepath.Join(root, "link")))
+
+		fs, err := New(root)
+		is.NoErr(err)
+
+		read, err := fs.ReadFile()
+		is.NoErr(err)
+		_, err = read.Call(ctx, []byte(`{"path":"link/secret"}`))
+		is.True(errors.Is(err, ErrPathOutsideRoot))
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		is := is.New(t)
+		fs, err := New(t.TempDir(), WithReadOnly(true))
+		is.NoErr(err)
+
+		tools, err := fs.Tools()
+		is.NoErr(err)
+		is.Equal(len(tools), 2)
+
+		write, err := fs.WriteFile()
+		is.NoErr(err)
+		_, err = write.Call(ctx, []byte(`{"path":"a.txt","content":"x"}`))
+		is.True(errors.Is(err, ErrReadOnly))
+	})
+
+	t.Run("max file size", func(t *testing.T) {
+		is := is.New(t)
+		root := t.TempDir()
+		is.NoErr(os.WriteFile(filepath.Join(root, "big.txt"), []byte("0123456789"), 0o644))
+
+		fs, err := New(root, WithMaxFileSize(5))
+		is.NoErr(err)
+
+		read, err := fs.ReadFile()
+		is.NoErr(err)
+		_, err = read.Call(ctx, []byte(`{"path":"big.txt"}`))
+		is.True(errors.Is(err, ErrFileTooLarge))
+
+		write, err := fs.WriteFile()
+		is.NoErr(err)
+		_, err = write.Call(ctx, []byte(`{"path":"new.txt","content":"0123456789"}`))
+		is.True(errors.Is(err, ErrFileTooLarge))
+	})
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// completePartialJSON turns a truncated JSON document, such as the buffer of a
+// streamed response, into the longest valid document it can. Open strings,
+// arrays and objects are closed; a trailing member that cannot be completed
+// (a dangling key, a half written literal) is dropped. It returns false if no
+// valid prefix exists.
+//
+//	{"name":"Al        ->  {"name":"Al"}
+//	{"tags":["a","b    ->  {"tags":["a","b"]}
+//	{"a":1,"b":tr      ->  {"a":1}
+func completePartialJSON(s string) (string, bool) {
+	if candidate := closeJSON(s); json.Valid([]byte(candidate)) {
+		return candidate, true
+	}
+
+	// Back off to earlier points where a member or element starts and retry.
+	cuts := cutPoints(s)
+	for i := len(cuts) - 1; i >= 0; i-- {
+		if candidate := closeJSON(s[:cuts[i]]); json.Valid([]byte(candidate)) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// closeJSON appends whatever closing quotes and brackets s needs.
+func closeJSON(s string) string {
+	var stack []byte
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(s)
+	if inString {
+		if escaped {
+			// Drop the dangling backslash so the closing quote isn't escaped.
+			trimmed := b.String()[:b.Len()-1]
+			b.Reset()
+			b.WriteString(trimmed)
+		}
+		b.WriteByte('"')
+	}
+
+	out := strings.TrimRight(b.String(), " \t\r\n")
+	out = strings.TrimSuffix(out, ",")
+	for i := len(stack) - 1; i >= 0; i-- {
+		out += string(stack[i])
+	}
+	return out
+}
+
+// cutPoints returns the offsets, outside of strings, just before each comma
+// and just after each opening bracket.
+func cutPoints(s string) []int {
+	var cuts []int
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			cuts = append(cuts, i)
+		case '{', '[':
+			cuts = append(cuts, i+1)
+		}
+	}
+	return cuts
+}
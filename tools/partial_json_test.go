@@ -0,0 +1,32 @@
+package tools
+
+import "testing"
+
+func TestCompletePartialJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: `{"name":"Al`, want: `{"name":"Al"}`, ok: true},
+		{in: `{"tags":["a","b`, want: `{"tags":["a","b"]}`, ok: true},
+		{in: `{"a":1,"b":tr`, want: `{"a":1}`, ok: true},
+		{in: `{"a":1,"b`, want: `{"a":1}`, ok: true},
+		{in: `{"a":`, want: `{}`, ok: true},
+		{in: `{"a":{"b":[1,2,`, want: `{"a":{"b":[1,2]}}`, ok: true},
+		{in: `{"quote":"say \`, want: `{"quote":"say "}`, ok: true},
+		{in: `{"done":true}`, want: `{"done":true}`, ok: true},
+		{in: ``, ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := completePartialJSON(tt.in)
+		if ok != tt.ok {
+			t.Errorf("completePartialJSON(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("completePartialJSON(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
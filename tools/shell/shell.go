@@ -0,0 +1,156 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/chriscow/minds"
+)
+
+var (
+	ErrNoAllowedCommands = errors.New("no commands are allowed, use WithAllowedCommands")
+	ErrCommandNotAllowed = errors.New("command is not allowed")
+	ErrTimeout           = errors.New("command timed out")
+)
+
+// DefaultTimeout bounds how long a command may run unless overridden with
+// WithTimeout.
+const DefaultTimeout = 30 * time.Second
+
+type Option func(*options)
+
+type options struct {
+	allowed    []string
+	timeout    time.Duration
+	workingDir string
+}
+
+// WithAllowedCommands sets the executables the model may run. Commands are
+// matched exactly against the name the model supplies, so "go" does not
+// permit "/usr/local/go/bin/go".
+func WithAllowedCommands(commands []string) Option {
+	return func(o *options) {
+		o.allowed = append([]string{}, commands...)
+	}
+}
+
+// WithTimeout bounds the runtime of each command. A value of zero or less
+// disables the timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithWorkingDir sets the directory commands are run in.
+func WithWorkingDir(dir string) Option {
+	return func(o *options) {
+		o.workingDir = dir
+	}
+}
+
+// Result is returned to the model as JSON after a command runs. A non-zero
+// exit code is reported here rather than as an error so the model can react
+// to failing builds or tests.
+type Result struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// New creates a tool that runs allowlisted commands. Commands are executed
+// directly, not through a shell, so pipes, redirects and globbing are not
+// interpreted.
+//
+// Example:
+//
+//	tool, err := shell.New(
+//		shell.WithAllowedCommands([]string{"go", "git"}),
+//		shell.WithWorkingDir("./workspace"),
+//		shell.WithTimeout(2*time.Minute),
+//	)
+func New(opts ...Option) (minds.Tool, error) {
+	o := options{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if len(o.allowed) == 0 {
+		return nil, ErrNoAllowedCommands
+	}
+
+	return minds.WrapFunction(
+		"shell",
+		fmt.Sprintf(`Runs a command and returns its stdout, stderr and exit code as JSON.
+		The command is executed directly, not through a shell.
+		Allowed commands: %s`, strings.Join(o.allowed, ", ")),
+		struct {
+			Command string   `json:"command" description:"The executable to run"`
+			Args    []string `json:"args,omitempty" description:"Arguments passed to the command"`
+		}{},
+		o.run,
+	)
+}
+
+func (o options) run(ctx context.Context, args []byte) ([]byte, error) {
+	var params struct {
+		Command string   `json:"command"`
+		Args    []string `json:"args"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, err
+	}
+
+	if !o.isAllowed(params.Command) {
+		return nil, fmt.Errorf("%w: `%s`. Allowed commands: %s", ErrCommandNotAllowed, params.Command, strings.Join(o.allowed, ", "))
+	}
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, params.Command, params.Args...)
+	cmd.Dir = o.workingDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%w after %s: %s", ErrTimeout, o.timeout, params.Command)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	result := Result{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		return nil, fmt.Errorf("running %s: %w", params.Command, err)
+	}
+
+	return json.Marshal(result)
+}
+
+func (o options) isAllowed(command string) bool {
+	for _, allowed := range o.allowed {
+		if command == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestShell(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requires an allowlist", func(t *testing.T) {
+		is := is.New(t)
+		_, err := New()
+		is.True(errors.Is(err, ErrNoAllowedCommands))
+	})
+
+	t.Run("captures output and exit code", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := New(WithAllowedCommands([]string{"sh"}))
+		is.NoErr(err)
+
+		out, err := tool.Call(ctx, []byte(`{"command":"sh","args":["-c","echo hi; echo oops >&2; exit 3"]}`))
+		is.NoErr(err)
+
+		var result Result
+		is.NoErr(json.Unmarshal(out, &result))
+		is.Equal(result, Result{Stdout: "hi\n", Stderr: "oops\n", ExitCode: 3})
+	})
+
+	t.Run("runs in the working directory", func(t *testing.T) {
+		is := is.New(t)
+		dir := t.TempDir()
+		tool, err := New(WithAllowedCommands([]string{"pwd"}), WithWorkingDir(dir))
+		is.NoErr(err)
+
+		out, err := tool.Call(ctx, []byte(`{"command":"pwd"}`))
+		is.NoErr(err)
+
+		var result Result
+		is.NoErr(json.Unmarshal(out, &result))
+		is.Equal(result.Stdout, dir+"\n")
+	})
+
+	t.Run("rejects commands not on the allowlist", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := New(WithAllowedCommands([]string{"echo"}))
+		is.NoErr(err)
+
+		_, err = tool.Call(ctx, []byte(`{"command":"rm","args":["-rf","/"]}`))
+		is.True(errors.Is(err, ErrCommandNotAllowed))
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		is := is.New(t)
+		tool, err := New(WithAllowedCommands([]string{"sleep"}), WithTimeout(50*time.Millisecond))
+		is.NoErr(err)
+
+		_, err = tool.Call(ctx, []byte(`{"command":"sleep","args":["5"]}`))
+		is.True(errors.Is(err, ErrTimeout))
+	})
+}
@@ -0,0 +1,193 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chriscow/minds"
+)
+
+var (
+	ErrReadOnly          = errors.New("only SELECT statements are allowed")
+	ErrMultipleStatement = errors.New("only a single statement is allowed")
+)
+
+// DefaultMaxRows caps the number of rows returned unless overridden with
+// WithMaxRows.
+const DefaultMaxRows = 100
+
+type Option func(*options)
+
+type options struct {
+	readOnly bool
+	maxRows  int
+}
+
+// WithReadOnly rejects any statement that does not begin with SELECT. The
+// tool is read-only by default.
+func WithReadOnly(readOnly bool) Option {
+	return func(o *options) {
+		o.readOnly = readOnly
+	}
+}
+
+// WithMaxRows caps the number of rows returned to the model. A value of zero
+// or less removes the limit.
+func WithMaxRows(n int) Option {
+	return func(o *options) {
+		o.maxRows = n
+	}
+}
+
+// Result is returned to the model as JSON. Truncated is set when more rows
+// were available than the configured maximum.
+type Result struct {
+	Columns      []string `json:"columns,omitempty"`
+	Rows         [][]any  `json:"rows,omitempty"`
+	Truncated    bool     `json:"truncated,omitempty"`
+	RowsAffected int64    `json:"rows_affected,omitempty"`
+}
+
+// New creates a `query` tool that runs SQL against db and returns the result
+// as JSON.
+//
+// The leading keyword check is a guard against a misbehaving model, not a
+// security boundary. Connect with a database user that only has the
+// privileges the agent needs.
+//
+// Example:
+//
+//	import sqltool "github.com/chriscow/minds/tools/sql"
+//
+//	db, _ := sql.Open("sqlite", "data.db")
+//	tool, err := sqltool.New(db, sqltool.WithMaxRows(50))
+func New(db *sql.DB, opts ...Option) (minds.Tool, error) {
+	if db == nil {
+		return nil, errors.New("db is required")
+	}
+
+	o := &options{readOnly: true, maxRows: DefaultMaxRows}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	description := `Runs a SQL query against the database and returns the column names and rows as JSON.`
+	if o.readOnly {
+		description += ` Only SELECT statements are allowed.`
+	}
+
+	return minds.WrapFunction(
+		"query",
+		description,
+		struct {
+			Query string `json:"query" description:"The SQL statement to run"`
+		}{},
+		func(ctx context.Context, args []byte) ([]byte, error) {
+			return o.run(ctx, db, args)
+		},
+	)
+}
+
+func (o *options) run(ctx context.Context, db *sql.DB, args []byte) ([]byte, error) {
+	var params struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(params.Query)
+	query = strings.TrimSpace(strings.TrimSuffix(query, ";"))
+	if strings.Contains(query, ";") {
+		return nil, ErrMultipleStatement
+	}
+
+	keyword := leadingKeyword(query)
+	if o.readOnly && keyword != "SELECT" {
+		return nil, fmt.Errorf("%w, got %s", ErrReadOnly, keyword)
+	}
+
+	if keyword != "SELECT" && keyword != "WITH" {
+		res, err := db.ExecContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(Result{RowsAffected: affected})
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := Result{Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		if o.maxRows > 0 && len(result.Rows) >= o.maxRows {
+			result.Truncated = true
+			break
+		}
+
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(result)
+}
+
+// leadingKeyword returns the first keyword of the statement in upper case,
+// skipping whitespace, comments and opening parentheses.
+func leadingKeyword(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.Index(query, "\n")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+2:]
+		default:
+			fields := strings.Fields(query)
+			if len(fields) == 0 {
+				return ""
+			}
+			return strings.ToUpper(strings.TrimRight(fields[0], "("))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// fakeDriver serves a fixed table for any query and records executed
+// statements.
+type fakeDriver struct {
+	executed []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return 0 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.d.executed = append(s.d.executed, s.query)
+	return driver.RowsAffected(2), nil
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{data: [][]driver.Value{
+		{int64(1), []byte("alice"), 3.5},
+		{int64(2), []byte("bob"), nil},
+		{int64(3), []byte("carol"), 1.0},
+	}}, nil
+}
+
+type fakeRows struct {
+	data [][]driver.Value
+	pos  int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name", "score"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	copy(dest, r.data[r.pos])
+	r.pos++
+	return nil
+}
+
+func openFake(t *testing.T) (*sql.DB, *fakeDriver) {
+	d := &fakeDriver{}
+	name := "fake-" + t.Name()
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns columns and typed rows", func(t *testing.T) {
+		is := is.New(t)
+		db, _ := openFake(t)
+		tool, err := New(db)
+		is.NoErr(err)
+
+		out, err := tool.Call(ctx, []byte(`{"query":"SELECT id, name, score FROM users"}`))
+		is.NoErr(err)
+		is.Equal(string(out), `{"columns":["id","name","score"],"rows":[[1,"alice",3.5],[2,"bob",null],[3,"carol",1]]}`)
+	})
+
+	t.Run("caps rows", func(t *testing.T) {
+		is := is.New(t)
+		db, _ := openFake(t)
+		tool, err := New(db, WithMaxRows(2))
+		is.NoErr(err)
+
+		out, err := tool.Call(ctx, []byte(`{"query":"select * from users"}`))
+		is.NoErr(err)
+
+		var result Result
+		is.NoErr(json.Unmarshal(out, &result))
+		is.Equal(len(result.Rows), 2)
+		is.True(result.Truncated)
+	})
+
+	t.Run("read-only rejects writes", func(t *testing.T) {
+		is := is.New(t)
+		db, d := openFake(t)
+		tool, err := New(db, WithReadOnly(true))
+		is.NoErr(err)
+
+		for _, q := range []string{
+			"DELETE FROM users",
+			"  -- looks harmless\n DROP TABLE users",
+			"/* select */ UPDATE users SET name = 'x'",
+		} {
+			args, _ := json.Marshal(map[string]string{"query": q})
+			_, err = tool.Call(ctx, args)
+			is.True(errors.Is(err, ErrReadOnly))
+		}
+
+		_, err = tool.Call(ctx, []byte(`{"query":"SELECT 1; DROP TABLE users"}`))
+		is.True(errors.Is(err, ErrMultipleStatement))
+		is.Equal(len(d.executed), 0)
+	})
+
+	t.Run("writes allowed when not read-only", func(t *testing.T) {
+		is := is.New(t)
+		db, d := openFake(t)
+		tool, err := New(db, WithReadOnly(false))
+		is.NoErr(err)
+
+		out, err := tool.Call(ctx, []byte(`{"query":"DELETE FROM users WHERE id > 1"}`))
+		is.NoErr(err)
+		is.Equal(string(out), `{"rows_affected":2}`)
+		is.Equal(d.executed, []string{"DELETE FROM users WHERE id > 1"})
+	})
+}